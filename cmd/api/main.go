@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
@@ -13,18 +18,35 @@ import (
 func main() {
 	_ = godotenv.Load()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	dbURL := os.Getenv("DATABASE_URL")
-	pool, err := pgxpool.New(context.Background(), dbURL)
+	pool, err := pgxpool.New(ctx, dbURL)
 	if err != nil {
 		log.Fatalf("Unable to connect to database: %v", err)
 	}
 	defer pool.Close()
 
-	e := bootstrap.NewApp(pool)
+	app := bootstrap.NewApp(pool)
+	waitForWorkers := app.StartWorkers(ctx)
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
-	e.Logger.Fatal(e.Start(":" + port))
+	go func() {
+		if err := app.Echo.Start(":" + port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			app.Echo.Logger.Fatal(err)
+		}
+	}()
+
+	// Stop accepting requests, then let the workers finish their current batch.
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := app.Echo.Shutdown(shutdownCtx); err != nil {
+		app.Echo.Logger.Error(err)
+	}
+	waitForWorkers()
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+	"github.com/ecelayes/pms-backend/internal/service"
+	"github.com/ecelayes/pms-backend/pkg/fieldcrypt"
+)
+
+// encrypt-guests seals guest personal data and users' two-factor secrets that are still
+// plaintext, or sealed with a retired key, using the active GUEST_PII key. Run it after applying migration 030 and after every
+// key rotation; retired keys can be removed from GUEST_PII_KEYS once it finishes without
+// reporting conflicts.
+func main() {
+	_ = godotenv.Load()
+
+	keys, err := fieldcrypt.NewKeyRingFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid encryption keys: %v", err)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatalf("Unable to connect to database: %v", err)
+	}
+	defer pool.Close()
+
+	guestUC := usecase.NewGuestUseCase(pool, repository.NewGuestRepository(pool, keys), repository.NewReservationRepository(pool), repository.NewInvoiceRepository(pool), service.NewGuestMatcher())
+
+	result, err := guestUC.ReencryptPII(ctx, 500)
+	if err != nil {
+		log.Fatalf("Re-encryption stopped after %d guests: %v", result.Guests, err)
+	}
+	log.Printf("Sealed %d guests and %d merge snapshots with key %d", result.Guests, result.Snapshots, keys.ActiveVersion())
+
+	secrets, err := repository.NewUserRepository(pool, keys).ResealTOTPSecrets(ctx)
+	if err != nil {
+		log.Fatalf("Re-encryption stopped after %d two-factor secrets: %v", secrets, err)
+	}
+	log.Printf("Sealed %d two-factor secrets with key %d", secrets, keys.ActiveVersion())
+	if len(result.Conflicts) > 0 {
+		for _, id := range result.Conflicts {
+			log.Printf("Guest %s shares its email with another guest of its organization", id)
+		}
+		log.Fatalf("%d guests were left under their old key; merge the duplicates and run again", len(result.Conflicts))
+	}
+}
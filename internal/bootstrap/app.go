@@ -1,19 +1,55 @@
 package bootstrap
 
 import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"github.com/ecelayes/pms-backend/pkg/auth"
+	"github.com/ecelayes/pms-backend/pkg/fieldcrypt"
 	"github.com/ecelayes/pms-backend/pkg/logger"
+	"github.com/ecelayes/pms-backend/internal/entity"
 	"github.com/ecelayes/pms-backend/internal/handler"
 	"github.com/ecelayes/pms-backend/internal/repository"
 	"github.com/ecelayes/pms-backend/internal/security"
 	"github.com/ecelayes/pms-backend/internal/usecase"
 	"github.com/ecelayes/pms-backend/internal/service"
+	"github.com/ecelayes/pms-backend/internal/worker"
 )
 
-func NewApp(pool *pgxpool.Pool) *echo.Echo {
+// Worker is a background job that runs until its context is cancelled.
+type Worker interface {
+	Start(ctx context.Context)
+}
+
+// App is the HTTP server together with the background workers it relies on. Workers are not
+// started by NewApp; the process that serves the app decides when they run and stop.
+type App struct {
+	Echo    *echo.Echo
+	Workers []Worker
+}
+
+// StartWorkers runs every worker until ctx is cancelled. The returned function blocks until
+// all of them have returned.
+func (a *App) StartWorkers(ctx context.Context) (wait func()) {
+	var wg sync.WaitGroup
+	for _, w := range a.Workers {
+		wg.Add(1)
+		go func(w Worker) {
+			defer wg.Done()
+			w.Start(ctx)
+		}(w)
+	}
+	return wg.Wait
+}
+
+func NewApp(pool *pgxpool.Pool) *App {
 	// 0. Logger
 	log, err := logger.New()
 	if err != nil {
@@ -21,36 +57,88 @@ func NewApp(pool *pgxpool.Pool) *echo.Echo {
 	}
 	defer log.Sync()
 
+	// 0.5 Guest PII keys
+	piiKeys, err := fieldcrypt.NewKeyRingFromEnv()
+	if err != nil {
+		panic(err)
+	}
+
+	// 0.6 Access token signing keys
+	signingKeys, err := auth.NewSigningKeyRingFromEnv()
+	if err != nil {
+		panic(err)
+	}
+
+	// 0.7 Password policy
+	passwordPolicy, err := auth.NewPasswordPolicyFromEnv()
+	if err != nil {
+		panic(err)
+	}
+
 	// 1. Repositories
 	unitTypeRepo := repository.NewUnitTypeRepository(pool)
 	unitRepo := repository.NewUnitRepository(pool)
 	resRepo := repository.NewReservationRepository(pool)
 	propertyRepo := repository.NewPropertyRepository(pool)
 	priceRepo := repository.NewPriceRepository(pool)
-	userRepo := repository.NewUserRepository(pool)
+	userRepo := repository.NewUserRepository(pool, piiKeys)
 	orgRepo := repository.NewOrganizationRepository(pool)
-	guestRepo := repository.NewGuestRepository(pool)
+	guestRepo := repository.NewGuestRepository(pool, piiKeys)
 	amenityRepo := repository.NewAmenityRepository(pool)
 	serviceRepo := repository.NewHotelServiceRepository(pool)
+	propertyServiceRepo := repository.NewPropertyServiceRepository(pool)
+	addOnRepo := repository.NewReservationAddOnRepository(pool)
 	ratePlanRepo := repository.NewRatePlanRepository(pool)
+	invoiceRepo := repository.NewInvoiceRepository(pool)
+	outboxRepo := repository.NewEmailOutboxRepository(pool)
+	webhookRepo := repository.NewWebhookRepository(pool)
+	privacyRepo := repository.NewPrivacyRepository(pool)
+	invitationRepo := repository.NewInvitationRepository(pool)
+	sessionRepo := repository.NewSessionRepository(pool)
 
 	// 1.5 Domain Services
 	pricingService := service.NewPricingService(priceRepo)
 	inventoryService := service.NewInventoryService()
 	emailService := service.NewEmailService()
+	emailSender := service.NewEmailSenderFromEnv(log)
+	invoiceRenderer := service.NewInvoiceRenderer()
+	// Loopback delivery is only for local development and the test suite.
+	webhookSender := service.NewWebhookSender(os.Getenv("WEBHOOK_ALLOW_LOOPBACK") == "true")
+	guestMatcher := service.NewGuestMatcher()
 
 	// 2. UseCases
 	availUC := usecase.NewAvailabilityUseCase(unitTypeRepo, resRepo, ratePlanRepo, pricingService)
-	resUC := usecase.NewReservationUseCase(pool, unitTypeRepo, resRepo, guestRepo, ratePlanRepo, pricingService)
-	pricingUC := usecase.NewPricingUseCase(pool, priceRepo, unitTypeRepo, inventoryService)
-	authUC := usecase.NewAuthUseCase(pool, userRepo, orgRepo, emailService, log)
+	resUC := usecase.NewReservationUseCase(pool, unitTypeRepo, resRepo, guestRepo, ratePlanRepo, propertyRepo, propertyServiceRepo, addOnRepo, pricingService, emailService, outboxRepo, webhookRepo, log)
+	pricingUC := usecase.NewPricingUseCase(pool, priceRepo, unitTypeRepo, webhookRepo, inventoryService)
+	authUC := usecase.NewAuthUseCase(pool, userRepo, orgRepo, sessionRepo, signingKeys, passwordPolicy, emailService, outboxRepo, log)
 	orgUC := usecase.NewOrganizationUseCase(orgRepo)
-	userUC := usecase.NewUserUseCase(pool, userRepo, orgRepo)
+	userUC := usecase.NewUserUseCase(pool, userRepo, orgRepo, sessionRepo, passwordPolicy, log)
+	invitationUC := usecase.NewInvitationUseCase(pool, invitationRepo, orgRepo, userRepo, passwordPolicy, emailService, outboxRepo, log)
 	propertyUC := usecase.NewPropertyUseCase(propertyRepo)
-	unitTypeUC := usecase.NewUnitTypeUseCase(unitTypeRepo)
-	unitUC := usecase.NewUnitUseCase(unitRepo)
+	unitTypeUC := usecase.NewUnitTypeUseCase(unitTypeRepo, amenityRepo, propertyRepo)
+	unitUC := usecase.NewUnitUseCase(unitRepo, unitTypeRepo)
 	catalogUC := usecase.NewCatalogUseCase(amenityRepo, serviceRepo)
-	ratePlanUC := usecase.NewRatePlanUseCase(ratePlanRepo, resRepo)
+	propertyServiceUC := usecase.NewPropertyServiceUseCase(propertyServiceRepo, serviceRepo, propertyRepo)
+	ratePlanUC := usecase.NewRatePlanUseCase(pool, ratePlanRepo, resRepo, webhookRepo, propertyRepo, unitTypeRepo)
+	invoiceUC := usecase.NewInvoiceUseCase(pool, invoiceRepo, resRepo, unitTypeRepo, propertyRepo, guestRepo, ratePlanRepo, addOnRepo, invoiceRenderer)
+	outboxUC := usecase.NewEmailOutboxUseCase(pool, outboxRepo, emailSender, log)
+	webhookUC := usecase.NewWebhookUseCase(pool, webhookRepo, webhookSender, log)
+	guestUC := usecase.NewGuestUseCase(pool, guestRepo, resRepo, invoiceRepo, guestMatcher)
+	guestPortalUC := usecase.NewGuestPortalUseCase(resUC, resRepo, unitTypeRepo, ratePlanRepo)
+	privacyUC := usecase.NewPrivacyUseCase(pool, guestRepo, invoiceRepo, outboxRepo, orgRepo, privacyRepo, log)
+
+	// 2.5 Background Workers
+	reminderDays := 2
+	if v, err := strconv.Atoi(os.Getenv("REMINDER_DAYS_BEFORE")); err == nil && v > 0 {
+		reminderDays = v
+	}
+	workers := []Worker{
+		worker.NewReminderWorker(resUC, time.Hour, reminderDays, log),
+		worker.NewOutboxWorker(outboxUC, 10*time.Second, 50, log),
+		worker.NewWebhookWorker(webhookUC, 5*time.Second, 50, log),
+		worker.NewRetentionWorker(privacyUC, 24*time.Hour, log),
+		worker.NewSignupCleanupWorker(authUC, time.Hour, log),
+	}
 
 	// 3. Handlers
 	availHandler := handler.NewAvailabilityHandler(availUC)
@@ -62,11 +150,22 @@ func NewApp(pool *pgxpool.Pool) *echo.Echo {
 	unitHandler := handler.NewUnitHandler(unitUC)
 	orgHandler := handler.NewOrganizationHandler(orgUC)
 	userHandler := handler.NewUserHandler(userUC)
+	invitationHandler := handler.NewInvitationHandler(invitationUC)
 	catalogHandler := handler.NewCatalogHandler(catalogUC)
+	propertyServiceHandler := handler.NewPropertyServiceHandler(propertyServiceUC)
 	ratePlanHandler := handler.NewRatePlanHandler(ratePlanUC)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceUC)
+	outboxHandler := handler.NewEmailOutboxHandler(outboxUC)
+	webhookHandler := handler.NewWebhookHandler(webhookUC)
+	guestHandler := handler.NewGuestHandler(guestUC)
+	privacyHandler := handler.NewPrivacyHandler(privacyUC)
+	guestPortalHandler := handler.NewGuestPortalHandler(guestPortalUC)
 
 	// 4. Server Setup
 	e := echo.New()
+	// The API is exposed directly, so client IPs come from the connection rather than
+	// spoofable forwarding headers. Put a trusted-proxy extractor here when deploying behind one.
+	e.IPExtractor = echo.ExtractIPDirect()
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
@@ -79,20 +178,72 @@ func NewApp(pool *pgxpool.Pool) *echo.Echo {
 	})
 
 	// 5. Routs
+	e.GET("/.well-known/jwks.json", authHandler.JWKS)
 	v1 := e.Group("/api/v1")
 
+	// Sign-in attempts are throttled per client on top of the per-account delays, and second
+	// factor codes, being short, even more so. Reset tokens share the sign-in budget, so they
+	// cannot be guessed alongside passwords. Endpoints that send email cap every request.
+	loginLimit := security.LimitFailures(security.NewFailureLimiter(20, 15*time.Minute))
+	twoFactorLimit := security.LimitFailures(security.NewFailureLimiter(5, 15*time.Minute))
+	emailLimit := security.LimitRequests(security.NewFailureLimiter(10, time.Hour))
+
 	// Public
-	v1.POST("/auth/login", authHandler.Login)
-	v1.POST("/auth/forgot-password", authHandler.ForgotPassword)
-	v1.POST("/auth/reset-password", authHandler.ResetPassword)
+	v1.POST("/auth/login", authHandler.Login, loginLimit)
+	v1.POST("/auth/refresh", authHandler.Refresh)
+	v1.POST("/auth/two-factor/login", authHandler.LoginTwoFactor, twoFactorLimit)
+	v1.POST("/auth/two-factor/login/setup", authHandler.BeginTwoFactorLoginSetup, twoFactorLimit)
+	v1.GET("/auth/jwks", authHandler.JWKS)
+	v1.POST("/auth/register", authHandler.Register, emailLimit)
+	v1.POST("/auth/verify-email", authHandler.VerifyEmail)
+	v1.POST("/auth/resend-verification", authHandler.ResendVerification, emailLimit)
+	v1.POST("/auth/forgot-password", authHandler.ForgotPassword, emailLimit)
+	v1.POST("/auth/reset-password", authHandler.ResetPassword, loginLimit)
+	v1.POST("/invitations/accept", invitationHandler.Accept)
 	v1.GET("/availability", availHandler.Get)
 	v1.POST("/reservations", resHandler.Create)
-	v1.GET("/reservations/:code", resHandler.GetByCode)
-	v1.POST("/reservations/:id/cancel", resHandler.Cancel)
+	v1.GET("/properties/:id/add-ons", propertyServiceHandler.ListOffered)
+
+	// Guest self-service: requires a guest access token; repeated failures are throttled per client.
+	// Staff cancel through the same route with their session.
+	guestAccessLimit := security.LimitFailures(security.NewFailureLimiter(5, 15*time.Minute))
+	staffAuth := security.Auth(authUC)
+	v1.POST("/reservations/access", resHandler.RequestAccess, guestAccessLimit)
+	v1.GET("/reservations/:code", resHandler.GetForGuest, guestAccessLimit)
+	v1.POST("/reservations/:id/cancel", security.StaffOrGuest(
+		staffAuth(security.RequirePermission(entity.PermReservationsCancel)(resHandler.Cancel)),
+		guestAccessLimit(resHandler.CancelForGuest),
+	))
+
+	// Guest portal: everything is scoped to the reservation behind the guest access token.
+	guestPortal := v1.Group("/guest", guestAccessLimit, security.GuestAuth(resUC))
+	guestPortal.GET("/reservation", guestPortalHandler.Get)
+	guestPortal.POST("/reservation/quote", guestPortalHandler.Quote)
+	guestPortal.PUT("/reservation", guestPortalHandler.Modify)
+	guestPortal.PUT("/reservation/arrival-time", guestPortalHandler.SetArrivalTime)
+	guestPortal.POST("/reservation/cancel", guestPortalHandler.Cancel)
+	guestPortal.GET("/reservation/add-ons", guestPortalHandler.ListAddOns)
+	guestPortal.POST("/reservation/add-ons", guestPortalHandler.AddAddOn)
+	guestPortal.DELETE("/reservation/add-ons/:id", guestPortalHandler.RemoveAddOn)
 
 	// Protected
 	protected := v1.Group("")
-	protected.Use(security.Auth(authUC))
+	protected.Use(staffAuth)
+
+	// Session
+	protected.POST("/auth/switch-organization", authHandler.SwitchOrganization)
+	protected.POST("/auth/logout", authHandler.Logout)
+	protected.POST("/auth/logout-all", authHandler.LogoutAll)
+	protected.GET("/auth/sessions", authHandler.ListSessions)
+	protected.DELETE("/auth/sessions/:id", authHandler.RevokeSession)
+
+	// Two-Factor Authentication
+	protected.POST("/auth/two-factor/setup", authHandler.SetupTwoFactor)
+	protected.POST("/auth/two-factor/enable", authHandler.EnableTwoFactor)
+	protected.POST("/auth/two-factor/disable", authHandler.DisableTwoFactor)
+	protected.POST("/auth/two-factor/recovery-codes", authHandler.RegenerateRecoveryCodes)
+	protected.GET("/two-factor/policy", authHandler.GetTwoFactorPolicy, security.RequirePermission(entity.PermUsersManage))
+	protected.PUT("/two-factor/policy", authHandler.UpdateTwoFactorPolicy, security.RequirePermission(entity.PermUsersManage))
 
 	// Organizations
 	protected.POST("/organizations", orgHandler.Create, security.RequireSuperAdmin)
@@ -101,63 +252,117 @@ func NewApp(pool *pgxpool.Pool) *echo.Echo {
 	protected.PUT("/organizations/:id", orgHandler.Update, security.RequireSuperAdmin)
 	protected.DELETE("/organizations/:id", orgHandler.Delete, security.RequireSuperAdmin)
 
+	// Email Delivery
+	protected.GET("/emails/failed", outboxHandler.ListFailed, security.RequireSuperAdmin)
+
 	// Amenities CRUD
-	protected.POST("/amenities", catalogHandler.CreateAmenity, security.RequireSuperAdmin)
-	protected.PUT("/amenities/:id", catalogHandler.UpdateAmenity, security.RequireSuperAdmin)
-	protected.DELETE("/amenities/:id", catalogHandler.DeleteAmenity, security.RequireSuperAdmin)
-	protected.GET("/amenities", catalogHandler.GetAllAmenities) 
-	protected.GET("/amenities/:id", catalogHandler.GetAmenityByID) 
+	protected.POST("/amenities", catalogHandler.CreateAmenity, security.RequirePermission(entity.PermCatalogManage))
+	protected.PUT("/amenities/:id", catalogHandler.UpdateAmenity, security.RequirePermission(entity.PermCatalogManage))
+	protected.DELETE("/amenities/:id", catalogHandler.DeleteAmenity, security.RequirePermission(entity.PermCatalogManage))
+	protected.GET("/amenities", catalogHandler.GetAllAmenities, security.RequirePermission(entity.PermPropertiesRead))
+	protected.GET("/amenities/:id", catalogHandler.GetAmenityByID, security.RequirePermission(entity.PermPropertiesRead))
 
 	// Services CRUD
-	protected.POST("/services", catalogHandler.CreateService, security.RequireSuperAdmin)
-	protected.PUT("/services/:id", catalogHandler.UpdateService, security.RequireSuperAdmin)
-	protected.DELETE("/services/:id", catalogHandler.DeleteService, security.RequireSuperAdmin)
-	protected.GET("/services", catalogHandler.GetAllServices)
-	protected.GET("/services/:id", catalogHandler.GetServiceByID)
+	protected.POST("/services", catalogHandler.CreateService, security.RequirePermission(entity.PermCatalogManage))
+	protected.PUT("/services/:id", catalogHandler.UpdateService, security.RequirePermission(entity.PermCatalogManage))
+	protected.DELETE("/services/:id", catalogHandler.DeleteService, security.RequirePermission(entity.PermCatalogManage))
+	protected.GET("/services", catalogHandler.GetAllServices, security.RequirePermission(entity.PermPropertiesRead))
+	protected.GET("/services/:id", catalogHandler.GetServiceByID, security.RequirePermission(entity.PermPropertiesRead))
 
 	// Reservation Admin
-	protected.GET("/reservations/:id/cancel-preview", resHandler.PreviewCancel)
+	protected.GET("/reservations/code/:code", resHandler.GetByCode, security.RequirePermission(entity.PermReservationsRead))
+	protected.GET("/reservations/:id/cancel-preview", resHandler.PreviewCancel, security.RequirePermission(entity.PermReservationsCancel))
+	protected.PUT("/reservations/:id", resHandler.Update, security.RequirePermission(entity.PermReservationsWrite))
 	protected.DELETE("/reservations/:id", resHandler.Delete, security.RequireSuperAdmin)
+	protected.POST("/reservations/:id/check-out", invoiceHandler.CheckOut, security.RequirePermission(entity.PermReservationsWrite))
+	protected.GET("/reservations/:id/add-ons", resHandler.ListAddOns, security.RequirePermission(entity.PermReservationsRead))
+	protected.POST("/reservations/:id/add-ons", resHandler.AddAddOn, security.RequirePermission(entity.PermReservationsWrite))
+	protected.DELETE("/reservations/:id/add-ons/:addOnId", resHandler.RemoveAddOn, security.RequirePermission(entity.PermReservationsWrite))
+
+	// Guests
+	protected.POST("/guests", guestHandler.Create, security.RequirePermission(entity.PermGuestsWrite))
+	protected.GET("/guests", guestHandler.GetAll, security.RequirePermission(entity.PermGuestsRead))
+	protected.GET("/guests/duplicates", guestHandler.GetDuplicates, security.RequirePermission(entity.PermGuestsManage))
+	protected.GET("/guests/:id", guestHandler.GetByID, security.RequirePermission(entity.PermGuestsRead))
+	protected.PUT("/guests/:id", guestHandler.Update, security.RequirePermission(entity.PermGuestsWrite))
+	protected.DELETE("/guests/:id", guestHandler.Delete, security.RequirePermission(entity.PermGuestsManage))
+	protected.POST("/guests/:id/notes", guestHandler.AddNote, security.RequirePermission(entity.PermGuestsWrite))
+	protected.POST("/guests/:id/merge", guestHandler.Merge, security.RequirePermission(entity.PermGuestsManage))
+
+	// Guest Privacy
+	protected.GET("/guests/:id/export", privacyHandler.Export, security.RequirePermission(entity.PermPrivacyManage))
+	protected.POST("/guests/:id/erase", privacyHandler.Erase, security.RequirePermission(entity.PermPrivacyManage))
+	protected.GET("/privacy/requests", privacyHandler.ListRequests, security.RequirePermission(entity.PermPrivacyManage))
+	protected.GET("/privacy/retention", privacyHandler.GetRetention, security.RequirePermission(entity.PermPrivacyManage))
+	protected.PUT("/privacy/retention", privacyHandler.UpdateRetention, security.RequirePermission(entity.PermPrivacyManage))
 
 	// Users
-	protected.POST("/users", userHandler.Create)
-	protected.GET("/users", userHandler.GetAll)
-	protected.GET("/users/:id", userHandler.GetByID)
-	protected.PUT("/users/:id", userHandler.Update)
-	protected.DELETE("/users/:id", userHandler.Delete)
+	protected.POST("/users", userHandler.Create, security.RequirePermission(entity.PermUsersManage))
+	protected.GET("/users", userHandler.GetAll, security.RequirePermission(entity.PermUsersManage))
+	protected.GET("/users/:id", userHandler.GetByID, security.RequirePermission(entity.PermUsersManage))
+	protected.PUT("/users/:id", userHandler.Update, security.RequirePermission(entity.PermUsersManage))
+	protected.DELETE("/users/:id", userHandler.Delete, security.RequirePermission(entity.PermUsersManage))
+	protected.DELETE("/users/:id/two-factor", userHandler.ResetTwoFactor, security.RequirePermission(entity.PermUsersManage))
+
+	// Invitations
+	protected.POST("/invitations", invitationHandler.Create, security.RequirePermission(entity.PermMembersInvite))
+	protected.GET("/invitations", invitationHandler.GetAll, security.RequirePermission(entity.PermMembersInvite))
+	protected.POST("/invitations/:id/resend", invitationHandler.Resend, security.RequirePermission(entity.PermMembersInvite))
+	protected.DELETE("/invitations/:id", invitationHandler.Revoke, security.RequirePermission(entity.PermMembersInvite))
 
 	// Properties CRUD
-	protected.POST("/properties", propertyHandler.Create)
-	protected.GET("/properties", propertyHandler.GetAll)
-	protected.GET("/properties/:id", propertyHandler.GetByID)
-	protected.PUT("/properties/:id", propertyHandler.Update)
-	protected.DELETE("/properties/:id", propertyHandler.Delete)
+	protected.POST("/properties", propertyHandler.Create, security.RequirePermission(entity.PermPropertiesWrite))
+	protected.GET("/properties", propertyHandler.GetAll, security.RequirePermission(entity.PermPropertiesRead))
+	protected.GET("/properties/:id", propertyHandler.GetByID, security.RequirePermission(entity.PermPropertiesRead))
+	protected.PUT("/properties/:id", propertyHandler.Update, security.RequirePermission(entity.PermPropertiesWrite))
+	protected.DELETE("/properties/:id", propertyHandler.Delete, security.RequirePermission(entity.PermPropertiesWrite))
 
 	// Unit Types CRUD
-	protected.POST("/unit-types", unitTypeHandler.Create)
-	protected.GET("/unit-types", unitTypeHandler.GetAll)
-	protected.GET("/unit-types/:id", unitTypeHandler.GetByID)
-	protected.PUT("/unit-types/:id", unitTypeHandler.Update)
-	protected.DELETE("/unit-types/:id", unitTypeHandler.Delete)
+	protected.POST("/unit-types", unitTypeHandler.Create, security.RequirePermission(entity.PermPropertiesWrite))
+	protected.GET("/unit-types", unitTypeHandler.GetAll, security.RequirePermission(entity.PermPropertiesRead))
+	protected.GET("/unit-types/:id", unitTypeHandler.GetByID, security.RequirePermission(entity.PermPropertiesRead))
+	protected.PUT("/unit-types/:id", unitTypeHandler.Update, security.RequirePermission(entity.PermPropertiesWrite))
+	protected.DELETE("/unit-types/:id", unitTypeHandler.Delete, security.RequirePermission(entity.PermPropertiesWrite))
 
 	// Units CRUD
-	protected.POST("/units", unitHandler.Create)
-	protected.GET("/units", unitHandler.GetAll)
-	protected.GET("/units/:id", unitHandler.GetByID)
-	protected.PUT("/units/:id", unitHandler.Update)
-	protected.DELETE("/units/:id", unitHandler.Delete)
+	protected.POST("/units", unitHandler.Create, security.RequirePermission(entity.PermPropertiesWrite))
+	protected.GET("/units", unitHandler.GetAll, security.RequirePermission(entity.PermPropertiesRead))
+	protected.GET("/units/:id", unitHandler.GetByID, security.RequirePermission(entity.PermPropertiesRead))
+	protected.PUT("/units/:id", unitHandler.Update, security.RequirePermission(entity.PermPropertiesWrite))
+	protected.DELETE("/units/:id", unitHandler.Delete, security.RequirePermission(entity.PermPropertiesWrite))
 
 	// Pricing CRUD
-	protected.POST("/pricing/bulk", pricingHandler.BulkUpdate)
-	protected.GET("/pricing/rules", pricingHandler.GetRules)
-	protected.DELETE("/pricing/rules/:id", pricingHandler.DeleteRule)
+	protected.POST("/pricing/bulk", pricingHandler.BulkUpdate, security.RequirePermission(entity.PermPricingWrite))
+	protected.GET("/pricing/rules", pricingHandler.GetRules, security.RequirePermission(entity.PermPropertiesRead))
+	protected.DELETE("/pricing/rules/:id", pricingHandler.DeleteRule, security.RequirePermission(entity.PermPricingWrite))
+
+	// Bookable Services
+	protected.POST("/property-services", propertyServiceHandler.Create, security.RequirePermission(entity.PermPropertiesWrite))
+	protected.GET("/property-services", propertyServiceHandler.List, security.RequirePermission(entity.PermPropertiesRead))
+	protected.GET("/property-services/:id", propertyServiceHandler.GetByID, security.RequirePermission(entity.PermPropertiesRead))
+	protected.PUT("/property-services/:id", propertyServiceHandler.Update, security.RequirePermission(entity.PermPropertiesWrite))
+	protected.DELETE("/property-services/:id", propertyServiceHandler.Delete, security.RequirePermission(entity.PermPropertiesWrite))
 
 	// Rate Plans CRUD
-	protected.POST("/rate-plans", ratePlanHandler.Create)
-	protected.GET("/rate-plans", ratePlanHandler.List)
-	protected.GET("/rate-plans/:id", ratePlanHandler.GetByID)
-	protected.PUT("/rate-plans/:id", ratePlanHandler.Update)
-	protected.DELETE("/rate-plans/:id", ratePlanHandler.Delete)
+	protected.POST("/rate-plans", ratePlanHandler.Create, security.RequirePermission(entity.PermRatePlansWrite))
+	protected.GET("/rate-plans", ratePlanHandler.List, security.RequirePermission(entity.PermPropertiesRead))
+	protected.GET("/rate-plans/:id", ratePlanHandler.GetByID, security.RequirePermission(entity.PermPropertiesRead))
+	protected.PUT("/rate-plans/:id", ratePlanHandler.Update, security.RequirePermission(entity.PermRatePlansWrite))
+	protected.DELETE("/rate-plans/:id", ratePlanHandler.Delete, security.RequirePermission(entity.PermRatePlansWrite))
+
+	// Invoicing
+	protected.GET("/invoices", invoiceHandler.GetAll, security.RequirePermission(entity.PermInvoicesRead))
+	protected.GET("/invoices/:id", invoiceHandler.GetByID, security.RequirePermission(entity.PermInvoicesRead))
+	protected.GET("/invoices/:id/pdf", invoiceHandler.GetPDF, security.RequirePermission(entity.PermInvoicesRead))
+	protected.POST("/invoices/:id/credit-notes", invoiceHandler.CreateCreditNote, security.RequirePermission(entity.PermInvoicesWrite))
+
+	// Webhooks
+	protected.POST("/webhooks", webhookHandler.Create, security.RequirePermission(entity.PermWebhooksManage))
+	protected.GET("/webhooks", webhookHandler.GetAll, security.RequirePermission(entity.PermWebhooksManage))
+	protected.GET("/webhooks/:id", webhookHandler.GetByID, security.RequirePermission(entity.PermWebhooksManage))
+	protected.PUT("/webhooks/:id", webhookHandler.Update, security.RequirePermission(entity.PermWebhooksManage))
+	protected.DELETE("/webhooks/:id", webhookHandler.Delete, security.RequirePermission(entity.PermWebhooksManage))
+	protected.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries, security.RequirePermission(entity.PermWebhooksManage))
 
-	return e
+	return &App{Echo: e, Workers: workers}
 }
@@ -0,0 +1,117 @@
+package entity
+
+import "time"
+
+// PricingUnit says what a bookable service is charged per.
+type PricingUnit string
+
+const (
+	PricingPerStay        PricingUnit = "per_stay"
+	PricingPerNight       PricingUnit = "per_night"
+	PricingPerPerson      PricingUnit = "per_person"
+	PricingPerPersonNight PricingUnit = "per_person_night"
+)
+
+func (u PricingUnit) Valid() bool {
+	switch u {
+	case PricingPerStay, PricingPerNight, PricingPerPerson, PricingPerPersonNight:
+		return true
+	}
+	return false
+}
+
+// Nightly reports whether the service is charged, and used, on every night of the stay.
+func (u PricingUnit) Nightly() bool {
+	return u == PricingPerNight || u == PricingPerPersonNight
+}
+
+func (u PricingUnit) PerPerson() bool {
+	return u == PricingPerPerson || u == PricingPerPersonNight
+}
+
+// ChargedUnits is how many times the price applies to quantity services over a stay.
+func (u PricingUnit) ChargedUnits(quantity, pax, nights int) int {
+	units := quantity
+	if u.PerPerson() {
+		units *= pax
+	}
+	if u.Nightly() {
+		units *= nights
+	}
+	return units
+}
+
+// DailyUnits is how much of a service's daily limit quantity services take up on each day they are used.
+func (u PricingUnit) DailyUnits(quantity, pax int) int {
+	if u.PerPerson() {
+		return quantity * pax
+	}
+	return quantity
+}
+
+// PropertyService is a catalog service a property sells as an add-on.
+// A DailyLimit of nil means the service is not capped.
+type PropertyService struct {
+	BaseEntity
+	PropertyID  string      `json:"property_id"`
+	ServiceID   string      `json:"service_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Icon        string      `json:"icon"`
+	Price       float64     `json:"price"`
+	PricingUnit PricingUnit `json:"pricing_unit"`
+	DailyLimit  *int        `json:"daily_limit,omitempty"`
+	Active      bool        `json:"active"`
+}
+
+type CreatePropertyServiceRequest struct {
+	PropertyID  string      `json:"property_id"`
+	ServiceID   string      `json:"service_id"`
+	Price       float64     `json:"price"`
+	PricingUnit PricingUnit `json:"pricing_unit"`
+	DailyLimit  *int        `json:"daily_limit"`
+}
+
+// UpdatePropertyServiceRequest changes a service offer. A daily_limit of 0 removes the limit.
+type UpdatePropertyServiceRequest struct {
+	Price       *float64    `json:"price"`
+	PricingUnit PricingUnit `json:"pricing_unit"`
+	DailyLimit  *int        `json:"daily_limit"`
+	Active      *bool       `json:"active"`
+}
+
+// ReservationAddOn is a service booked with a reservation. Price and pricing unit are
+// copied from the offer when it is booked, so later price changes do not reach existing bookings.
+type ReservationAddOn struct {
+	ID                string      `json:"id"`
+	ReservationID     string      `json:"reservation_id"`
+	PropertyServiceID string      `json:"property_service_id"`
+	Name              string      `json:"name"`
+	PricingUnit       PricingUnit `json:"pricing_unit"`
+	Quantity          int         `json:"quantity"`
+	UnitPrice         float64     `json:"unit_price"`
+	Total             float64     `json:"total"`
+	CreatedAt         time.Time   `json:"created_at"`
+}
+
+type AddOnRequest struct {
+	PropertyServiceID string `json:"property_service_id"`
+	Quantity          int    `json:"quantity"`
+}
+
+// AddOnUsage is one booked add-on counted against a service's daily limit.
+type AddOnUsage struct {
+	ReservationID string
+	PricingUnit   PricingUnit
+	Quantity      int
+	Pax           int
+	Start         time.Time
+	End           time.Time
+}
+
+// AddOnOffer prices one unit of a service for a specific stay.
+type AddOnOffer struct {
+	PropertyService
+	Total     float64 `json:"total"`
+	Available bool    `json:"available"`
+}
@@ -37,7 +37,7 @@ type AvailabilitySearch struct {
 	MaxAdults    int         `json:"max_adults"`
 	MaxChildren  int         `json:"max_children"`
 	
-	Amenities    []string    `json:"amenities"`
+	Amenities    []Amenity   `json:"amenities"`
 
 	Rates        []RateOption `json:"rates"`
 }
@@ -1,17 +1,21 @@
 package entity
 
+// Amenity is a catalog entry. OrganizationID is nil for global entries shared by every organization.
 type Amenity struct {
 	BaseEntity
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Icon        string `json:"icon"`
+	OrganizationID *string `json:"organization_id"`
+	Name           string  `json:"name"`
+	Description    string  `json:"description"`
+	Icon           string  `json:"icon"`
 }
 
+// HotelService is a catalog entry. OrganizationID is nil for global entries shared by every organization.
 type HotelService struct {
 	BaseEntity
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Icon        string `json:"icon"`
+	OrganizationID *string `json:"organization_id"`
+	Name           string  `json:"name"`
+	Description    string  `json:"description"`
+	Icon           string  `json:"icon"`
 }
 
 type CreateCatalogRequest struct {
@@ -1,6 +1,9 @@
 package entity
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	// Input Validation
@@ -15,6 +18,14 @@ var (
 	ErrNoAvailability       = errors.New("no availability for selected dates")
 	ErrReservationNotFound  = errors.New("reservation not found")
 	ErrReservationCancelled = errors.New("reservation is already cancelled")
+	ErrInvalidReservationStatus = errors.New("operation not allowed for the current reservation status")
+	ErrGuestAccessDenied        = errors.New("invalid or missing guest access token")
+	ErrModificationNotAllowed   = errors.New("this change is not available for the reservation")
+	ErrAddOnUnavailable         = errors.New("add-on service is not available for the selected dates")
+
+	// Business Rules (Invoicing)
+	ErrAlreadyInvoiced       = errors.New("reservation has already been invoiced")
+	ErrCreditExceedsInvoice  = errors.New("credit amount exceeds the outstanding invoice total")
 	
 	// Business Rules (Pricing)
 	ErrPriceNegative 		= errors.New("price must be positive")
@@ -28,6 +39,11 @@ var (
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrUserNotFound       = errors.New("user not found")
 	ErrUserInactive       = errors.New("user account is inactive")
+	ErrEmailNotVerified   = errors.New("email address has not been verified")
+	ErrSessionExpired     = errors.New("session expired or revoked")
+	ErrTwoFactorRequired  = errors.New("two-factor authentication is required")
+	ErrInvalidTwoFactor   = errors.New("invalid two-factor code")
+	ErrAccountLocked      = errors.New("too many failed attempts, try again later")
 
 	// Permissions
 	ErrInsufficientPermissions = errors.New("insufficient permissions")
@@ -36,3 +52,12 @@ var (
 	ErrRecordNotFound = errors.New("record not found")
 	ErrInternal       = errors.New("internal server error")
 )
+
+// AccountLockedError tells a caller when a throttled account accepts sign-in attempts again.
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string { return ErrAccountLocked.Error() }
+
+func (e *AccountLockedError) Unwrap() error { return ErrAccountLocked }
@@ -1,12 +1,19 @@
 package entity
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type Guest struct {
 	BaseEntity
 	
+	OrganizationID string `json:"organization_id"`
 	Email     string `json:"email"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Phone     string `json:"phone"`
+	Language  string `json:"language,omitempty"`
 }
 
 type GuestParams struct {
@@ -14,4 +21,92 @@ type GuestParams struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Phone     string `json:"phone"`
+	Language  string `json:"language,omitempty"`
+}
+
+type CreateGuestRequest struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone"`
+	Language  string `json:"language"`
+}
+
+type UpdateGuestRequest struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone"`
+	Language  string `json:"language"`
+}
+
+type GuestNote struct {
+	BaseEntity
+
+	GuestID  string  `json:"guest_id"`
+	AuthorID *string `json:"author_id,omitempty"`
+	Body     string  `json:"body"`
+}
+
+type CreateGuestNoteRequest struct {
+	Body string `json:"body"`
+}
+
+// GuestStay is one reservation in a guest's history.
+type GuestStay struct {
+	ReservationID   string    `json:"reservation_id"`
+	ReservationCode string    `json:"reservation_code"`
+	PropertyID      string    `json:"property_id"`
+	PropertyName    string    `json:"property_name"`
+	UnitTypeName    string    `json:"unit_type_name"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	Status          string    `json:"status"`
+	TotalPrice      float64   `json:"total_price"`
+}
+
+// GuestDetail is the guest profile with its stay history. LifetimeRevenue is the
+// invoiced amount net of credit notes, so it only counts stays that were checked out.
+type GuestDetail struct {
+	Guest
+
+	Stays           []GuestStay `json:"stays"`
+	CompletedStays  int         `json:"completed_stays"`
+	LifetimeRevenue float64     `json:"lifetime_revenue"`
+	Notes           []GuestNote `json:"notes"`
+}
+
+// GuestDuplicate is a pair of profiles that likely belong to the same person.
+type GuestDuplicate struct {
+	Guest     Guest    `json:"guest"`
+	Candidate Guest    `json:"candidate"`
+	Score     float64  `json:"score"`
+	Reasons   []string `json:"reasons"`
+}
+
+type MergeGuestRequest struct {
+	DuplicateID string `json:"duplicate_id"`
+}
+
+// PIIReencryption summarises a run sealing guest personal data with the active key.
+type PIIReencryption struct {
+	Guests    int
+	Snapshots int
+	// Conflicts lists guests left under their old key because another active guest of the
+	// organization has the same email once normalised. They have to be merged first.
+	Conflicts []string
+}
+
+// GuestMerge is the audit record of a merge. MergedSnapshot keeps the retired profile as it was.
+type GuestMerge struct {
+	ID                string          `json:"id"`
+	OrganizationID    string          `json:"organization_id"`
+	SurvivorID        string          `json:"survivor_id"`
+	MergedID          string          `json:"merged_id"`
+	MergedBy          *string         `json:"merged_by,omitempty"`
+	MergedSnapshot    json.RawMessage `json:"merged_snapshot"`
+	ReservationsMoved int             `json:"reservations_moved"`
+	InvoicesMoved     int             `json:"invoices_moved"`
+	NotesMoved        int             `json:"notes_moved"`
+	CreatedAt         time.Time       `json:"created_at"`
 }
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+)
+
+const minPhoneKeyDigits = 7
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a", "ã", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n", "ç", "c",
+)
+
+// MatchKeys are the blocking keys of the duplicate search: a phone suffix, an email prefix and
+// a name prefix. Only guests sharing one of them are compared.
+func (g Guest) MatchKeys() []string {
+	var keys []string
+	if p := PhoneKey(g.Phone); p != "" {
+		keys = append(keys, "phone:"+p)
+	}
+	if local := EmailLocalPart(g.Email); len(local) >= 4 {
+		keys = append(keys, "email:"+local[:4])
+	}
+	last := []rune(NormalizeName(g.LastName))
+	first := []rune(NormalizeName(g.FirstName))
+	if len(last) >= 3 && len(first) >= 1 {
+		keys = append(keys, "name:"+string(last[:3])+string(first[:1]))
+	}
+	return keys
+}
+
+// NormalizeName lowercases, strips accents and collapses whitespace.
+func NormalizeName(s string) string {
+	s = accentReplacer.Replace(strings.ToLower(strings.TrimSpace(s)))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// EmailLocalPart drops the domain, plus-addressing and dots, which rarely tell people apart.
+func EmailLocalPart(email string) string {
+	local := strings.ToLower(strings.TrimSpace(email))
+	if at := strings.LastIndex(local, "@"); at >= 0 {
+		local = local[:at]
+	}
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	return strings.ReplaceAll(local, ".", "")
+}
+
+// PhoneKey keeps the trailing digits so country prefixes and formatting do not matter.
+func PhoneKey(phone string) string {
+	var digits []rune
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) < minPhoneKeyDigits {
+		return ""
+	}
+	return string(digits[len(digits)-minPhoneKeyDigits:])
+}
@@ -0,0 +1,43 @@
+package entity
+
+// GuestRatePlanOption is a rate plan the guest may switch to, priced for the current stay
+// including the add-ons already booked.
+type GuestRatePlanOption struct {
+	RatePlanID   string   `json:"rate_plan_id"`
+	Name         string   `json:"name"`
+	MealPlan     MealPlan `json:"meal_plan"`
+	IsRefundable bool     `json:"is_refundable"`
+	Total        float64  `json:"total"`
+	Current      bool     `json:"current"`
+}
+
+// GuestModificationOptions describes what a guest may still change without the hotel.
+type GuestModificationOptions struct {
+	CanChangeDates    bool                  `json:"can_change_dates"`
+	CanChangeRatePlan bool                  `json:"can_change_rate_plan"`
+	CanSetArrivalTime bool                  `json:"can_set_arrival_time"`
+	CanAddServices    bool                  `json:"can_add_services"`
+	CanRemoveServices bool                  `json:"can_remove_services"`
+	CanCancel         bool                  `json:"can_cancel"`
+	CancellationFee   float64               `json:"cancellation_fee"`
+	Restrictions      []string              `json:"restrictions,omitempty"`
+	RatePlans         []GuestRatePlanOption `json:"rate_plans"`
+}
+
+type GuestPortalReservation struct {
+	Reservation Reservation              `json:"reservation"`
+	Options     GuestModificationOptions `json:"options"`
+}
+
+// GuestModificationRequest is the subset of a reservation a guest may change.
+type GuestModificationRequest struct {
+	Start      string  `json:"start"`
+	End        string  `json:"end"`
+	RatePlanID *string `json:"rate_plan_id"`
+	Adults     *int    `json:"adults"`
+	Children   *int    `json:"children"`
+}
+
+type ArrivalTimeRequest struct {
+	ArrivalTime string `json:"arrival_time"`
+}
@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// Invitation asks someone to join an organization with a preassigned role and, optionally,
+// a restriction to some of its properties.
+type Invitation struct {
+	BaseEntity
+	OrganizationID string     `json:"organization_id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	PropertyIDs    []string   `json:"property_ids,omitempty"`
+	Language       string     `json:"language,omitempty"`
+	Secret         string     `json:"-"`
+	InvitedBy      *string    `json:"invited_by,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	Status         string     `json:"status"`
+}
+
+// ResolveStatus derives Status from the invitation's timestamps.
+func (i *Invitation) ResolveStatus(now time.Time) {
+	switch {
+	case i.AcceptedAt != nil:
+		i.Status = InvitationStatusAccepted
+	case i.RevokedAt != nil:
+		i.Status = InvitationStatusRevoked
+	case !now.Before(i.ExpiresAt):
+		i.Status = InvitationStatusExpired
+	default:
+		i.Status = InvitationStatusPending
+	}
+}
+
+type CreateInvitationRequest struct {
+	OrganizationID string   `json:"organization_id"`
+	Email          string   `json:"email"`
+	Role           string   `json:"role"`
+	PropertyIDs    []string `json:"property_ids"`
+	Language       string   `json:"language"`
+}
+
+// AcceptInvitationRequest completes an invitation. Invitees without an account choose their
+// password and name here; existing users confirm with their current password.
+type AcceptInvitationRequest struct {
+	Token     string `json:"token"`
+	Password  string `json:"password"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone"`
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+const (
+	DocumentTypeInvoice    = "invoice"
+	DocumentTypeCreditNote = "credit_note"
+)
+
+type Invoice struct {
+	BaseEntity
+
+	PropertyID        string  `json:"property_id"`
+	ReservationID     string  `json:"reservation_id"`
+	GuestID           *string `json:"guest_id,omitempty"`
+	DocumentType      string  `json:"document_type"`
+	Number            string  `json:"number"`
+	Sequence          int     `json:"sequence"`
+	CorrectsInvoiceID *string `json:"corrects_invoice_id,omitempty"`
+	Reason            string  `json:"reason,omitempty"`
+
+	CustomerName  string `json:"customer_name"`
+	CustomerEmail string `json:"customer_email"`
+
+	Currency  string  `json:"currency"`
+	TaxRate   float64 `json:"tax_rate"`
+	Subtotal  float64 `json:"subtotal"`
+	TaxAmount float64 `json:"tax_amount"`
+	Total     float64 `json:"total"`
+
+	IssuedAt time.Time     `json:"issued_at"`
+	Lines    []InvoiceLine `json:"lines"`
+}
+
+type InvoiceLine struct {
+	ID          string  `json:"id"`
+	Position    int     `json:"position"`
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	NetAmount   float64 `json:"net_amount"`
+	TaxAmount   float64 `json:"tax_amount"`
+	Total       float64 `json:"total"`
+}
+
+type CreateCreditNoteRequest struct {
+	Reason string   `json:"reason"`
+	Amount *float64 `json:"amount"`
+}
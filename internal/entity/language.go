@@ -0,0 +1,28 @@
+package entity
+
+const (
+	LanguageSpanish = "es"
+	LanguageEnglish = "en"
+
+	// DefaultLanguage is used when neither the recipient nor the property expresses a preference.
+	DefaultLanguage = LanguageSpanish
+)
+
+var supportedLanguages = map[string]bool{
+	LanguageSpanish: true,
+	LanguageEnglish: true,
+}
+
+func IsSupportedLanguage(lang string) bool {
+	return supportedLanguages[lang]
+}
+
+// ResolveLanguage returns the first supported language among the candidates, in order of preference.
+func ResolveLanguage(candidates ...string) string {
+	for _, lang := range candidates {
+		if IsSupportedLanguage(lang) {
+			return lang
+		}
+	}
+	return DefaultLanguage
+}
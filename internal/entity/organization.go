@@ -11,6 +11,21 @@ type OrganizationMember struct {
 	OrganizationID string `json:"organization_id"`
 	UserID         string `json:"user_id"`
 	Role           string `json:"role"`
+	// AllProperties is false for members restricted to PropertyIDs.
+	AllProperties  bool     `json:"all_properties"`
+	PropertyIDs    []string `json:"property_ids,omitempty"`
+}
+
+// Membership is an organization a user can sign in to, with the role held there.
+type Membership struct {
+	OrganizationID string `json:"organization_id"`
+	Name           string `json:"name"`
+	Code           string `json:"code"`
+	Role           string `json:"role"`
+}
+
+type SwitchOrganizationRequest struct {
+	OrganizationID string `json:"organization_id"`
 }
 
 type CreateOrganizationRequest struct {
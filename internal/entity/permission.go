@@ -0,0 +1,74 @@
+package entity
+
+// Permission names an action a member of an organization may perform.
+type Permission string
+
+const (
+	PermPropertiesRead     Permission = "properties.read"
+	PermPropertiesWrite    Permission = "properties.write"
+	PermRatePlansWrite     Permission = "rate_plans.write"
+	PermPricingWrite       Permission = "pricing.write"
+	PermReservationsRead   Permission = "reservations.read"
+	PermReservationsWrite  Permission = "reservations.write"
+	PermReservationsCancel Permission = "reservations.cancel"
+	PermGuestsRead         Permission = "guests.read"
+	PermGuestsWrite        Permission = "guests.write"
+	PermGuestsManage       Permission = "guests.manage"
+	PermPrivacyManage      Permission = "privacy.manage"
+	PermInvoicesRead       Permission = "invoices.read"
+	PermInvoicesWrite      Permission = "invoices.write"
+	PermCatalogManage      Permission = "catalog.manage"
+	PermUsersManage        Permission = "users.manage"
+	PermMembersInvite      Permission = "members.invite"
+	PermWebhooksManage     Permission = "webhooks.manage"
+)
+
+// staffPermissions cover the front desk: looking things up, handling stays and guests.
+var staffPermissions = []Permission{
+	PermPropertiesRead,
+	PermReservationsRead,
+	PermReservationsWrite,
+	PermReservationsCancel,
+	PermGuestsRead,
+	PermGuestsWrite,
+	PermInvoicesRead,
+}
+
+// managerPermissions add running the property: inventory, rates, prices, invoicing and
+// inviting front desk staff.
+var managerPermissions = append(append([]Permission{}, staffPermissions...),
+	PermPropertiesWrite,
+	PermRatePlansWrite,
+	PermPricingWrite,
+	PermGuestsManage,
+	PermInvoicesWrite,
+	PermMembersInvite,
+)
+
+// ownerPermissions add what shapes the organization itself: members, catalogs, privacy and integrations.
+var ownerPermissions = append(append([]Permission{}, managerPermissions...),
+	PermPrivacyManage,
+	PermCatalogManage,
+	PermUsersManage,
+	PermWebhooksManage,
+)
+
+var rolePermissions = map[string][]Permission{
+	OrgRoleOwner:   ownerPermissions,
+	OrgRoleManager: managerPermissions,
+	OrgRoleStaff:   staffPermissions,
+}
+
+// HasPermission reports whether role grants p. Super admins hold every permission;
+// unknown roles hold none.
+func HasPermission(role string, p Permission) bool {
+	if role == RoleSuperAdmin {
+		return true
+	}
+	for _, granted := range rolePermissions[role] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "time"
+
+const (
+	PrivacyRequestExport  = "export"
+	PrivacyRequestErasure = "erasure"
+
+	PrivacySourceRequest   = "request"
+	PrivacySourceRetention = "retention"
+)
+
+// PrivacyRequest is the log entry kept for every export and erasure.
+type PrivacyRequest struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	GuestID        string    `json:"guest_id"`
+	RequestType    string    `json:"request_type"`
+	Source         string    `json:"source"`
+	RequestedBy    *string   `json:"requested_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type GuestCommunication struct {
+	ID        string     `json:"id"`
+	Recipient string     `json:"recipient"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Status    string     `json:"status"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// GuestDataExport is everything held about a guest, as handed over on a subject access request.
+type GuestDataExport struct {
+	ExportedAt     time.Time            `json:"exported_at"`
+	Profile        Guest                `json:"profile"`
+	Reservations   []GuestStay          `json:"reservations"`
+	Folios         []Invoice            `json:"folios"`
+	Communications []GuestCommunication `json:"communications"`
+	Notes          []GuestNote          `json:"notes"`
+	MergedProfiles []Guest              `json:"merged_profiles"`
+}
+
+type RetentionSettings struct {
+	GuestRetentionDays *int `json:"guest_retention_days"`
+}
@@ -7,6 +7,8 @@ type Property struct {
 	Name    string `json:"name"`
 	Code    string `json:"code"`
 	Type    string `json:"type"`
+	TaxRate float64 `json:"tax_rate"`
+	DefaultLanguage string `json:"default_language"`
 }
 
 type CreatePropertyRequest struct {
@@ -14,10 +16,14 @@ type CreatePropertyRequest struct {
 	Name string `json:"name"`
 	Code string `json:"code"`
 	Type string `json:"type"`
+	TaxRate float64 `json:"tax_rate"`
+	DefaultLanguage string `json:"default_language"`
 }
 
 type UpdatePropertyRequest struct {
 	Name string `json:"name"`
 	Code string `json:"code"`
 	Type string `json:"type"`
+	TaxRate *float64 `json:"tax_rate"`
+	DefaultLanguage string `json:"default_language"`
 }
@@ -16,6 +16,10 @@ type Reservation struct {
 	
 	Adults          int       `json:"adults"`
 	Children        int       `json:"children"`
+
+	ArrivalTime *string `json:"arrival_time,omitempty"`
+
+	AddOns []ReservationAddOn `json:"add_ons,omitempty"`
 }
 
 type CreateReservationRequest struct {
@@ -26,10 +30,52 @@ type CreateReservationRequest struct {
 	GuestFirstName string `json:"guest_first_name"`
 	GuestLastName  string `json:"guest_last_name"`
 	GuestPhone     string `json:"guest_phone"`
+	GuestLanguage  string `json:"guest_language"`
+
+	// UpdateGuestProfile lets booking data overwrite an existing guest profile.
+	// Without it, booking data only fills fields the profile is missing.
+	UpdateGuestProfile bool `json:"update_guest_profile"`
 	
 	Start    string `json:"start"`
 	End      string `json:"end"`
 	
 	Adults   int `json:"adults"`
 	Children int `json:"children"`
+
+	AddOns []AddOnRequest `json:"add_ons"`
+}
+
+// GuestAccess lets a guest manage one reservation without an account.
+type GuestAccess struct {
+	ReservationID   string `json:"reservation_id"`
+	ReservationCode string `json:"reservation_code"`
+	AccessToken     string `json:"access_token"`
+}
+
+// GuestAccessRequest verifies a guest by booking code and last name.
+type GuestAccessRequest struct {
+	ReservationCode string `json:"reservation_code"`
+	LastName        string `json:"last_name"`
+}
+
+type UpdateReservationRequest struct {
+	UnitTypeID string  `json:"unit_type_id"`
+	RatePlanID *string `json:"rate_plan_id"`
+
+	Start string `json:"start"`
+	End   string `json:"end"`
+
+	Adults   *int `json:"adults"`
+	Children *int `json:"children"`
+}
+
+// ModificationQuote prices a change to a reservation before it is applied.
+type ModificationQuote struct {
+	Reservation  Reservation `json:"reservation"`
+	RatePlan     *RatePlan   `json:"rate_plan,omitempty"`
+	NightlyRates []DailyRate `json:"nightly_rates"`
+	CurrentTotal float64     `json:"current_total"`
+	NewTotal     float64     `json:"new_total"`
+	Difference   float64     `json:"difference"`
+	Available    bool        `json:"available"`
 }
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// Session is a signed-in device. It lives as long as its refresh token keeps being used
+// and ends when the user logs out or it is revoked.
+type Session struct {
+	ID             string    `json:"id"`
+	UserID         string    `json:"user_id"`
+	OrganizationID string    `json:"organization_id,omitempty"`
+	UserAgent      string    `json:"user_agent"`
+	IPAddress      string    `json:"ip_address"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastUsedAt     time.Time `json:"last_used_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	// Current marks the session the listing request was made with.
+	Current bool `json:"current"`
+}
+
+// SessionClient describes the device a session is started from.
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,41 @@
+package entity
+
+// TwoFactorSetup is handed out when enrolment starts: the secret for manual entry and the
+// otpauth:// URI clients render as a QR code.
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorPolicy is an organization's two-factor setting.
+type TwoFactorPolicy struct {
+	// Required makes members enrol before they can sign in to the organization.
+	Required bool `json:"required"`
+}
+
+// TwoFactorLoginRequest completes a password login with either a TOTP code or a recovery code.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// TwoFactorSetupRequest starts enrolment during login, for members whose organization
+// requires two-factor authentication before they have set it up.
+type TwoFactorSetupRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// RecoveryCodesResponse lists freshly generated recovery codes. They are shown only once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
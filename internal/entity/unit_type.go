@@ -14,7 +14,7 @@ type UnitType struct {
 	MaxAdults     int      `json:"max_adults"`
 	MaxChildren   int      `json:"max_children"`
 	
-	Amenities     []string `json:"amenities"`
+	Amenities     []Amenity `json:"amenities"`
 }
 
 type CreateUnitTypeRequest struct {
@@ -26,7 +26,7 @@ type CreateUnitTypeRequest struct {
 	MaxOccupancy  int      `json:"max_occupancy"`
 	MaxAdults     int      `json:"max_adults"`
 	MaxChildren   int      `json:"max_children"`
-	Amenities     []string `json:"amenities"`
+	AmenityIDs    []string `json:"amenity_ids"`
 }
 
 type UpdateUnitTypeRequest struct {
@@ -40,5 +40,6 @@ type UpdateUnitTypeRequest struct {
 	
 	BasePrice     *float64 `json:"base_price"`
 
-	Amenities []string `json:"amenities"`
+	// AmenityIDs replaces the unit type's amenities when present; an empty list clears them.
+	AmenityIDs []string `json:"amenity_ids"`
 }
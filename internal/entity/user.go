@@ -1,5 +1,7 @@
 package entity
 
+import "time"
+
 const (
 	RoleUser       = "user"
 	RoleSuperAdmin = "super_admin"
@@ -15,6 +17,27 @@ type User struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Phone     string `json:"phone"`
+	Language  string `json:"language,omitempty"`
+
+	// TokenVersion is embedded in access tokens; raising it revokes every token issued before.
+	TokenVersion int `json:"-"`
+
+	// EmailVerifiedAt is nil for self-registered owners who have not confirmed their address yet.
+	EmailVerifiedAt *time.Time `json:"-"`
+
+	// TwoFactorEnabled is set once TOTP enrolment has been confirmed with a first code.
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
+	TOTPSecret       string `json:"-"`
+
+	// FailedLoginAttempts counts sign-in attempts since the last successful one; while
+	// LockedUntil lies ahead no attempt is checked at all.
+	FailedLoginAttempts int        `json:"-"`
+	LockedUntil         *time.Time `json:"-"`
+
+	// AllProperties lets a member work with every property of the organization; otherwise
+	// PropertyIDs lists the only ones they reach, which may be none.
+	AllProperties bool     `json:"all_properties"`
+	PropertyIDs   []string `json:"property_ids,omitempty"`
 }
 
 type CreateUserRequest struct {
@@ -26,22 +49,31 @@ type CreateUserRequest struct {
 	FirstName      string `json:"first_name"`
 	LastName       string `json:"last_name"`
 	Phone          string `json:"phone"`
+	Language       string `json:"language"`
+	PropertyIDs    []string `json:"property_ids"`
 }
 
 type AuthRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// OrganizationID picks the organization to sign in to; defaults to the user's first membership.
+	OrganizationID string `json:"organization_id"`
 }
 
 type ForgotPasswordRequest struct {
 	Email string `json:"email"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
 type ResetPasswordRequest struct {
 	Token       string `json:"token"`
 	NewPassword string `json:"new_password"`
 }
 
+// RegisterOwnerRequest signs up a new organization together with its owner.
 type RegisterOwnerRequest struct {
 	Email     string `json:"email"`
 	Password  string `json:"password"`
@@ -57,8 +89,27 @@ type UpdateUserRequest struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Phone     string `json:"phone"`
+	Language  string `json:"language"`
+	// PropertyIDs replaces the member's property restriction when present; an empty list lifts it.
+	PropertyIDs *[]string `json:"property_ids"`
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
+	// Token is empty while a second factor is owed; TwoFactorRequired is set instead.
+	Token string `json:"token,omitempty"`
+	// ExpiresIn is the access token lifetime in seconds.
+	ExpiresIn int `json:"expires_in"`
+	// RefreshToken is only returned when a session starts or is refreshed; it rotates on every use.
+	RefreshToken   string       `json:"refresh_token,omitempty"`
+	OrganizationID string       `json:"organization_id,omitempty"`
+	Organizations  []Membership `json:"organizations"`
+
+	// TwoFactorRequired asks the client to complete the login with a code and ChallengeToken.
+	// TwoFactorSetupRequired means the organization requires two-factor authentication and the
+	// user still has to enrol, using the same challenge.
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"`
+	ChallengeToken         string `json:"challenge_token,omitempty"`
+	// RecoveryCodes are returned once, when a login completes a required enrolment.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
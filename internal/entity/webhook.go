@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventReservationCreated   = "reservation.created"
+	EventReservationModified  = "reservation.modified"
+	EventReservationCancelled = "reservation.cancelled"
+	EventRatePlanUpdated      = "rate_plan.updated"
+	EventPriceRuleChanged     = "price_rule.changed"
+)
+
+var webhookEventTypes = map[string]bool{
+	EventReservationCreated:   true,
+	EventReservationModified:  true,
+	EventReservationCancelled: true,
+	EventRatePlanUpdated:      true,
+	EventPriceRuleChanged:     true,
+}
+
+func IsWebhookEventType(eventType string) bool {
+	return webhookEventTypes[eventType]
+}
+
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusFailed    = "failed"
+)
+
+type WebhookSubscription struct {
+	BaseEntity
+
+	OrganizationID string   `json:"organization_id"`
+	URL            string   `json:"url"`
+	Secret         string   `json:"secret,omitempty"`
+	EventTypes     []string `json:"event_types"`
+	Active         bool     `json:"active"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active"`
+}
+
+// WebhookEvent is the envelope posted to subscribers.
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type WebhookDelivery struct {
+	BaseEntity
+
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	MaxAttempts    int             `json:"max_attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+
+	// Resolved from the subscription when a delivery is claimed for sending.
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// PriceRuleChange is the data of a price_rule.changed event.
+type PriceRuleChange struct {
+	Action     string    `json:"action"`
+	RuleID     string    `json:"rule_id,omitempty"`
+	UnitTypeID string    `json:"unit_type_id"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Price      float64   `json:"price"`
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/ecelayes/pms-backend/internal/entity"
@@ -16,21 +19,324 @@ func NewAuthHandler(uc *usecase.AuthUseCase) *AuthHandler {
 	return &AuthHandler{uc: uc}
 }
 
+// accountLocked answers a signed-in user's second factor checks while their account is
+// throttled, saying when to retry. Sign-in itself answers like a wrong password instead.
+func accountLocked(c echo.Context, err error) error {
+	var locked *entity.AccountLockedError
+	if errors.As(err, &locked) {
+		if wait := time.Until(locked.Until); wait > 0 {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		}
+	}
+	return c.JSON(http.StatusTooManyRequests, map[string]string{"error": entity.ErrAccountLocked.Error()})
+}
+
+func twoFactorError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrAccountLocked):
+		return accountLocked(c, err)
+	case errors.Is(err, entity.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrConflict):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidTwoFactor), errors.Is(err, entity.ErrSessionExpired):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidCredentials):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
+	case errors.Is(err, entity.ErrTwoFactorRequired), errors.Is(err, entity.ErrInsufficientPermissions):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrRecordNotFound), errors.Is(err, entity.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
 func (h *AuthHandler) Login(c echo.Context) error {
 	var req entity.AuthRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
 	}
 
-	token, err := h.uc.Login(c.Request().Context(), req)
+	client := entity.SessionClient{UserAgent: c.Request().UserAgent(), IPAddress: c.RealIP()}
+	res, err := h.uc.Login(c.Request().Context(), req, client)
 	if err != nil {
 		if err == entity.ErrInvalidCredentials {
 			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
 		}
+		if errors.Is(err, entity.ErrInsufficientPermissions) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, entity.ErrEmailNotVerified) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// LoginTwoFactor is public: the challenge token from Login authenticates the request.
+func (h *AuthHandler) LoginTwoFactor(c echo.Context) error {
+	var req entity.TwoFactorLoginRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	client := entity.SessionClient{UserAgent: c.Request().UserAgent(), IPAddress: c.RealIP()}
+	res, err := h.uc.LoginTwoFactor(c.Request().Context(), req, client)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// BeginTwoFactorLoginSetup hands out an enrolment secret to users whose organization
+// requires two-factor authentication before they have set it up.
+func (h *AuthHandler) BeginTwoFactorLoginSetup(c echo.Context) error {
+	var req entity.TwoFactorSetupRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	setup, err := h.uc.BeginTwoFactorLoginSetup(c.Request().Context(), req.ChallengeToken)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+	return c.JSON(http.StatusOK, setup)
+}
+
+func (h *AuthHandler) SetupTwoFactor(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+	setup, err := h.uc.SetupTwoFactor(c.Request().Context(), userID)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+	return c.JSON(http.StatusOK, setup)
+}
+
+func (h *AuthHandler) EnableTwoFactor(c echo.Context) error {
+	var req entity.TwoFactorCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	userID, _ := c.Get("user_id").(string)
+	client := entity.SessionClient{UserAgent: c.Request().UserAgent(), IPAddress: c.RealIP()}
+	codes, err := h.uc.EnableTwoFactor(c.Request().Context(), userID, req.Code, client)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+	return c.JSON(http.StatusOK, entity.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *AuthHandler) DisableTwoFactor(c echo.Context) error {
+	var req entity.DisableTwoFactorRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	userID, _ := c.Get("user_id").(string)
+	orgID, _ := c.Get("organization_id").(string)
+	client := entity.SessionClient{UserAgent: c.Request().UserAgent(), IPAddress: c.RealIP()}
+	if err := h.uc.DisableTwoFactor(c.Request().Context(), userID, orgID, req, client); err != nil {
+		return twoFactorError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "two-factor authentication disabled"})
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	var req entity.TwoFactorCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	userID, _ := c.Get("user_id").(string)
+	client := entity.SessionClient{UserAgent: c.Request().UserAgent(), IPAddress: c.RealIP()}
+	codes, err := h.uc.RegenerateRecoveryCodes(c.Request().Context(), userID, req.Code, client)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+	return c.JSON(http.StatusOK, entity.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *AuthHandler) GetTwoFactorPolicy(c echo.Context) error {
+	orgID, _ := c.Get("organization_id").(string)
+	policy, err := h.uc.GetTwoFactorPolicy(c.Request().Context(), orgID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "organization not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+func (h *AuthHandler) UpdateTwoFactorPolicy(c echo.Context) error {
+	var req entity.TwoFactorPolicy
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	if err := h.uc.UpdateTwoFactorPolicy(c.Request().Context(), orgID, req); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "organization not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "two-factor policy updated"})
+}
+
+// Register is the public signup for a new organization and its owner.
+func (h *AuthHandler) Register(c echo.Context) error {
+	var req entity.RegisterOwnerRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	if err := h.uc.Register(c.Request().Context(), req); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidInput):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrConflict):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	// The same answer whether or not the email already had an account.
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "Check your inbox to verify your email address.",
+	})
+}
+
+func (h *AuthHandler) VerifyEmail(c echo.Context) error {
+	var req entity.VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	if err := h.uc.VerifyEmail(c.Request().Context(), req.Token); err != nil {
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "email verified"})
+}
+
+func (h *AuthHandler) ResendVerification(c echo.Context) error {
+	var req entity.ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	if err := h.uc.ResendVerification(c.Request().Context(), req.Email); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not process request"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "If the account is waiting for verification, a new link has been sent.",
+	})
+}
+
+// SwitchOrganization trades the caller's token for one scoped to another of their organizations.
+func (h *AuthHandler) SwitchOrganization(c echo.Context) error {
+	var req entity.SwitchOrganizationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	userID, _ := c.Get("user_id").(string)
+	sessionID, _ := c.Get("session_id").(string)
+	res, err := h.uc.SwitchOrganization(c.Request().Context(), userID, sessionID, req.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidInput):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrInsufficientPermissions), errors.Is(err, entity.ErrTwoFactorRequired):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrSessionExpired):
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// JWKS serves the public signing keys so other services can verify access tokens.
+func (h *AuthHandler) JWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.uc.JWKS())
+}
+
+// Refresh is public: the refresh token itself authenticates the request.
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req entity.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	res, err := h.uc.Refresh(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrSessionExpired):
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrInsufficientPermissions), errors.Is(err, entity.ErrTwoFactorRequired):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *AuthHandler) Logout(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+	sessionID, _ := c.Get("session_id").(string)
+	if err := h.uc.Logout(c.Request().Context(), userID, sessionID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "logged out"})
+}
+
+func (h *AuthHandler) LogoutAll(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+	revoked, err := h.uc.LogoutAll(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"message": "all sessions logged out", "revoked": revoked})
+}
+
+func (h *AuthHandler) ListSessions(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+	sessionID, _ := c.Get("session_id").(string)
+	sessions, err := h.uc.ListSessions(c.Request().Context(), userID, sessionID)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	if sessions == nil {
+		sessions = []entity.Session{}
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
 
-	return c.JSON(http.StatusOK, map[string]string{"token": token})
+func (h *AuthHandler) RevokeSession(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+	if err := h.uc.RevokeSession(c.Request().Context(), userID, c.Param("id")); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "session not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "session revoked"})
 }
 
 func (h *AuthHandler) ForgotPassword(c echo.Context) error {
@@ -55,6 +361,9 @@ func (h *AuthHandler) ResetPassword(c echo.Context) error {
 	}
 
 	if err := h.uc.ResetPassword(c.Request().Context(), req); err != nil {
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
@@ -26,13 +26,18 @@ func getRoleFromToken(c echo.Context) string {
 	return role
 }
 
+func getOrgFromToken(c echo.Context) string {
+	orgID, _ := c.Get("organization_id").(string)
+	return orgID
+}
+
 func (h *CatalogHandler) CreateAmenity(c echo.Context) error {
 	var req entity.CreateCatalogRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
 	}
 
-	id, err := h.uc.CreateAmenity(c.Request().Context(), getRoleFromToken(c), req)
+	id, err := h.uc.CreateAmenity(c.Request().Context(), getRoleFromToken(c), getOrgFromToken(c), req)
 	if err != nil {
 		if errors.Is(err, entity.ErrInvalidInput) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
@@ -63,7 +68,7 @@ func (h *CatalogHandler) GetAllAmenities(c echo.Context) error {
 		pagination = entity.PaginationRequest{Page: page, Limit: limit}
 	}
 
-	list, total, err := h.uc.GetAllAmenities(c.Request().Context(), pagination)
+	list, total, err := h.uc.GetAllAmenities(c.Request().Context(), getOrgFromToken(c), pagination)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -100,7 +105,7 @@ func (h *CatalogHandler) GetAllAmenities(c echo.Context) error {
 func (h *CatalogHandler) GetAmenityByID(c echo.Context) error {
 	id := c.Param("id")
 
-	amenity, err := h.uc.GetAmenityByID(c.Request().Context(), id)
+	amenity, err := h.uc.GetAmenityByID(c.Request().Context(), getOrgFromToken(c), id)
 	if err != nil {
 		if errors.Is(err, entity.ErrRecordNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "amenity not found"})
@@ -119,7 +124,7 @@ func (h *CatalogHandler) UpdateAmenity(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
 	}
 
-	err := h.uc.UpdateAmenity(c.Request().Context(), role, id, req)
+	err := h.uc.UpdateAmenity(c.Request().Context(), role, getOrgFromToken(c), id, req)
 	if err != nil {
 		if errors.Is(err, entity.ErrInsufficientPermissions) {
 			return c.JSON(http.StatusForbidden, map[string]string{"error": "insufficient permissions"})
@@ -130,6 +135,9 @@ func (h *CatalogHandler) UpdateAmenity(c echo.Context) error {
 		if errors.Is(err, entity.ErrInvalidInput) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 		}
+		if errors.Is(err, entity.ErrConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, map[string]string{"message": "updated successfully"})
@@ -139,7 +147,7 @@ func (h *CatalogHandler) DeleteAmenity(c echo.Context) error {
 	role := getRoleFromToken(c)
 	id := c.Param("id")
 	
-	err := h.uc.DeleteAmenity(c.Request().Context(), role, id)
+	err := h.uc.DeleteAmenity(c.Request().Context(), role, getOrgFromToken(c), id)
 	if err != nil {
 		if errors.Is(err, entity.ErrInsufficientPermissions) {
 			return c.JSON(http.StatusForbidden, map[string]string{"error": "insufficient permissions"})
@@ -158,7 +166,7 @@ func (h *CatalogHandler) CreateService(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
 	}
 
-	id, err := h.uc.CreateService(c.Request().Context(), getRoleFromToken(c), req)
+	id, err := h.uc.CreateService(c.Request().Context(), getRoleFromToken(c), getOrgFromToken(c), req)
 	if err != nil {
 		if errors.Is(err, entity.ErrInvalidInput) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
@@ -189,7 +197,7 @@ func (h *CatalogHandler) GetAllServices(c echo.Context) error {
 		pagination = entity.PaginationRequest{Page: page, Limit: limit}
 	}
 
-	list, total, err := h.uc.GetAllServices(c.Request().Context(), pagination)
+	list, total, err := h.uc.GetAllServices(c.Request().Context(), getOrgFromToken(c), pagination)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -226,7 +234,7 @@ func (h *CatalogHandler) GetAllServices(c echo.Context) error {
 func (h *CatalogHandler) GetServiceByID(c echo.Context) error {
 	id := c.Param("id")
 
-	service, err := h.uc.GetServiceByID(c.Request().Context(), id)
+	service, err := h.uc.GetServiceByID(c.Request().Context(), getOrgFromToken(c), id)
 	if err != nil {
 		if errors.Is(err, entity.ErrRecordNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "service not found"})
@@ -245,7 +253,7 @@ func (h *CatalogHandler) UpdateService(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
 	}
 
-	err := h.uc.UpdateService(c.Request().Context(), role, id, req)
+	err := h.uc.UpdateService(c.Request().Context(), role, getOrgFromToken(c), id, req)
 	if err != nil {
 		if errors.Is(err, entity.ErrInsufficientPermissions) {
 			return c.JSON(http.StatusForbidden, map[string]string{"error": "insufficient permissions"})
@@ -256,6 +264,9 @@ func (h *CatalogHandler) UpdateService(c echo.Context) error {
 		if errors.Is(err, entity.ErrInvalidInput) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 		}
+		if errors.Is(err, entity.ErrConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, map[string]string{"message": "updated successfully"})
@@ -265,7 +276,7 @@ func (h *CatalogHandler) DeleteService(c echo.Context) error {
 	role := getRoleFromToken(c)
 	id := c.Param("id")
 	
-	err := h.uc.DeleteService(c.Request().Context(), role, id)
+	err := h.uc.DeleteService(c.Request().Context(), role, getOrgFromToken(c), id)
 	if err != nil {
 		if errors.Is(err, entity.ErrInsufficientPermissions) {
 			return c.JSON(http.StatusForbidden, map[string]string{"error": "insufficient permissions"})
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type EmailOutboxHandler struct {
+	uc *usecase.EmailOutboxUseCase
+}
+
+func NewEmailOutboxHandler(uc *usecase.EmailOutboxUseCase) *EmailOutboxHandler {
+	return &EmailOutboxHandler{uc: uc}
+}
+
+func (h *EmailOutboxHandler) ListFailed(c echo.Context) error {
+	var pagination entity.PaginationRequest
+	if err := c.Bind(&pagination); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid pagination params"})
+	}
+	if pagination.Page < 1 {
+		pagination.Page = 1
+	}
+	if pagination.Limit < 1 {
+		pagination.Limit = 10
+	}
+
+	emails, total, err := h.uc.ListFailed(c.Request().Context(), pagination)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	if emails == nil {
+		emails = []entity.OutboxEmail{}
+	}
+
+	totalPage := int(total) / pagination.Limit
+	if int(total)%pagination.Limit != 0 {
+		totalPage++
+	}
+
+	response := entity.PaginatedResponse[entity.OutboxEmail]{
+		Data: emails,
+		Meta: entity.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: total,
+			TotalPages: totalPage,
+		},
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type GuestHandler struct {
+	uc *usecase.GuestUseCase
+}
+
+func NewGuestHandler(uc *usecase.GuestUseCase) *GuestHandler {
+	return &GuestHandler{uc: uc}
+}
+
+func (h *GuestHandler) Create(c echo.Context) error {
+	var req entity.CreateGuestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	id, err := h.uc.Create(c.Request().Context(), orgID, req)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, entity.ErrConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "a guest with this email already exists"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]string{"guest_id": id})
+}
+
+func (h *GuestHandler) GetAll(c echo.Context) error {
+	var pagination entity.PaginationRequest
+	if err := c.Bind(&pagination); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid pagination params"})
+	}
+	if pagination.Page < 1 {
+		pagination.Page = 1
+	}
+	if pagination.Limit < 1 {
+		pagination.Limit = 10
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	guests, total, err := h.uc.Search(c.Request().Context(), orgID, c.QueryParam("q"), pagination)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	if guests == nil {
+		guests = []entity.Guest{}
+	}
+
+	totalPage := int(total) / pagination.Limit
+	if int(total)%pagination.Limit != 0 {
+		totalPage++
+	}
+
+	response := entity.PaginatedResponse[entity.Guest]{
+		Data: guests,
+		Meta: entity.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: total,
+			TotalPages: totalPage,
+		},
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *GuestHandler) GetByID(c echo.Context) error {
+	orgID, _ := c.Get("organization_id").(string)
+	detail, err := h.uc.GetDetail(c.Request().Context(), orgID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "guest not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, detail)
+}
+
+func (h *GuestHandler) Update(c echo.Context) error {
+	var req entity.UpdateGuestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	if err := h.uc.Update(c.Request().Context(), orgID, c.Param("id"), req); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "guest not found"})
+		}
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, entity.ErrConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "a guest with this email already exists"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "guest updated"})
+}
+
+func (h *GuestHandler) Delete(c echo.Context) error {
+	orgID, _ := c.Get("organization_id").(string)
+	if err := h.uc.Delete(c.Request().Context(), orgID, c.Param("id")); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "guest not found"})
+		}
+		if errors.Is(err, entity.ErrConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "guest deleted"})
+}
+
+func (h *GuestHandler) AddNote(c echo.Context) error {
+	var req entity.CreateGuestNoteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	authorID, _ := c.Get("user_id").(string)
+	note, err := h.uc.AddNote(c.Request().Context(), orgID, c.Param("id"), authorID, req)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "guest not found"})
+		}
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, note)
+}
+
+func (h *GuestHandler) GetDuplicates(c echo.Context) error {
+	var pagination entity.PaginationRequest
+	if err := c.Bind(&pagination); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid pagination params"})
+	}
+	if pagination.Page < 1 {
+		pagination.Page = 1
+	}
+	if pagination.Limit < 1 {
+		pagination.Limit = 10
+	}
+
+	threshold := 0.0
+	if raw := c.QueryParam("min_score"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid min_score"})
+		}
+		threshold = v
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	duplicates, total, err := h.uc.FindDuplicates(c.Request().Context(), orgID, threshold, pagination)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if duplicates == nil {
+		duplicates = []entity.GuestDuplicate{}
+	}
+
+	totalPage := int(total) / pagination.Limit
+	if int(total)%pagination.Limit != 0 {
+		totalPage++
+	}
+
+	return c.JSON(http.StatusOK, entity.PaginatedResponse[entity.GuestDuplicate]{
+		Data: duplicates,
+		Meta: entity.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: total,
+			TotalPages: totalPage,
+		},
+	})
+}
+
+func (h *GuestHandler) Merge(c echo.Context) error {
+	var req entity.MergeGuestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	actorID, _ := c.Get("user_id").(string)
+	merge, err := h.uc.Merge(c.Request().Context(), orgID, c.Param("id"), actorID, req)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "guest not found"})
+		}
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, merge)
+}
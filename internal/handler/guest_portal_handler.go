@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type GuestPortalHandler struct {
+	uc *usecase.GuestPortalUseCase
+}
+
+func NewGuestPortalHandler(uc *usecase.GuestPortalUseCase) *GuestPortalHandler {
+	return &GuestPortalHandler{uc: uc}
+}
+
+func (h *GuestPortalHandler) Get(c echo.Context) error {
+	reservationID, _ := c.Get("reservation_id").(string)
+	res, err := h.uc.Get(c.Request().Context(), reservationID)
+	if err != nil {
+		return guestPortalError(c, err)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *GuestPortalHandler) Quote(c echo.Context) error {
+	var req entity.GuestModificationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	reservationID, _ := c.Get("reservation_id").(string)
+	quote, err := h.uc.Quote(c.Request().Context(), reservationID, req)
+	if err != nil {
+		return guestPortalError(c, err)
+	}
+	return c.JSON(http.StatusOK, quote)
+}
+
+func (h *GuestPortalHandler) Modify(c echo.Context) error {
+	var req entity.GuestModificationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	reservationID, _ := c.Get("reservation_id").(string)
+	res, err := h.uc.Modify(c.Request().Context(), reservationID, req)
+	if err != nil {
+		return guestPortalError(c, err)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *GuestPortalHandler) SetArrivalTime(c echo.Context) error {
+	var req entity.ArrivalTimeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	reservationID, _ := c.Get("reservation_id").(string)
+	res, err := h.uc.SetArrivalTime(c.Request().Context(), reservationID, req)
+	if err != nil {
+		return guestPortalError(c, err)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *GuestPortalHandler) Cancel(c echo.Context) error {
+	reservationID, _ := c.Get("reservation_id").(string)
+	if err := h.uc.Cancel(c.Request().Context(), reservationID); err != nil {
+		return guestPortalError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "cancelled"})
+}
+
+func (h *GuestPortalHandler) ListAddOns(c echo.Context) error {
+	reservationID, _ := c.Get("reservation_id").(string)
+	offers, err := h.uc.OfferAddOns(c.Request().Context(), reservationID)
+	if err != nil {
+		return guestPortalError(c, err)
+	}
+	return c.JSON(http.StatusOK, offers)
+}
+
+func (h *GuestPortalHandler) AddAddOn(c echo.Context) error {
+	var req entity.AddOnRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	reservationID, _ := c.Get("reservation_id").(string)
+	res, err := h.uc.AddAddOn(c.Request().Context(), reservationID, req)
+	if err != nil {
+		return guestPortalError(c, err)
+	}
+	return c.JSON(http.StatusCreated, res)
+}
+
+func (h *GuestPortalHandler) RemoveAddOn(c echo.Context) error {
+	reservationID, _ := c.Get("reservation_id").(string)
+	res, err := h.uc.RemoveAddOn(c.Request().Context(), reservationID, c.Param("id"))
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "add-on not found"})
+	}
+	if err != nil {
+		return guestPortalError(c, err)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func guestPortalError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrInvalidDateFormat),
+	     errors.Is(err, entity.ErrInvalidDateRange),
+	     errors.Is(err, entity.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrRecordNotFound), errors.Is(err, entity.ErrReservationNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "reservation not found"})
+	case errors.Is(err, entity.ErrNoAvailability),
+	     errors.Is(err, entity.ErrAddOnUnavailable),
+	     errors.Is(err, entity.ErrReservationCancelled),
+	     errors.Is(err, entity.ErrInvalidReservationStatus),
+	     errors.Is(err, entity.ErrModificationNotAllowed):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
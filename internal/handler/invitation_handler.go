@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type InvitationHandler struct {
+	uc *usecase.InvitationUseCase
+}
+
+func NewInvitationHandler(uc *usecase.InvitationUseCase) *InvitationHandler {
+	return &InvitationHandler{uc: uc}
+}
+
+// invitationError maps the invitation use case errors to their HTTP responses.
+func invitationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "invitation not found"})
+	case errors.Is(err, entity.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrConflict):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrInsufficientPermissions):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidCredentials):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+func (h *InvitationHandler) Create(c echo.Context) error {
+	var req entity.CreateInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	role, _ := c.Get("role").(string)
+	userID, _ := c.Get("user_id").(string)
+	inv, err := h.uc.Create(c.Request().Context(), role, userID, req)
+	if err != nil {
+		return invitationError(c, err)
+	}
+	return c.JSON(http.StatusCreated, inv)
+}
+
+func (h *InvitationHandler) GetAll(c echo.Context) error {
+	invitations, err := h.uc.List(c.Request().Context(), c.QueryParam("organization_id"))
+	if err != nil {
+		return invitationError(c, err)
+	}
+	if invitations == nil {
+		invitations = []entity.Invitation{}
+	}
+	return c.JSON(http.StatusOK, invitations)
+}
+
+func (h *InvitationHandler) Resend(c echo.Context) error {
+	role, _ := c.Get("role").(string)
+	if err := h.uc.Resend(c.Request().Context(), role, c.Param("id")); err != nil {
+		return invitationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "invitation resent"})
+}
+
+func (h *InvitationHandler) Revoke(c echo.Context) error {
+	role, _ := c.Get("role").(string)
+	if err := h.uc.Revoke(c.Request().Context(), role, c.Param("id")); err != nil {
+		return invitationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "invitation revoked"})
+}
+
+// Accept is public: the signed link is what authorizes it.
+func (h *InvitationHandler) Accept(c echo.Context) error {
+	var req entity.AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	orgID, userID, err := h.uc.Accept(c.Request().Context(), req)
+	if err != nil {
+		return invitationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{
+		"organization_id": orgID,
+		"user_id":         userID,
+		"message":         "invitation accepted",
+	})
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type InvoiceHandler struct {
+	uc *usecase.InvoiceUseCase
+}
+
+func NewInvoiceHandler(uc *usecase.InvoiceUseCase) *InvoiceHandler {
+	return &InvoiceHandler{uc: uc}
+}
+
+func (h *InvoiceHandler) CheckOut(c echo.Context) error {
+	id := c.Param("id")
+
+	invoice, err := h.uc.CheckOut(c.Request().Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrRecordNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "reservation not found"})
+		case errors.Is(err, entity.ErrInvalidReservationStatus), errors.Is(err, entity.ErrAlreadyInvoiced):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusCreated, invoice)
+}
+
+func (h *InvoiceHandler) GetAll(c echo.Context) error {
+	propertyID := c.QueryParam("property_id")
+	if propertyID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "property_id is required"})
+	}
+
+	var pagination entity.PaginationRequest
+	if err := c.Bind(&pagination); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid pagination params"})
+	}
+	if pagination.Page < 1 {
+		pagination.Page = 1
+	}
+	if pagination.Limit < 1 {
+		pagination.Limit = 10
+	}
+
+	invoices, total, err := h.uc.ListByProperty(c.Request().Context(), propertyID, pagination)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	if invoices == nil {
+		invoices = []entity.Invoice{}
+	}
+
+	totalPage := int(total) / pagination.Limit
+	if int(total)%pagination.Limit != 0 {
+		totalPage++
+	}
+
+	response := entity.PaginatedResponse[entity.Invoice]{
+		Data: invoices,
+		Meta: entity.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: total,
+			TotalPages: totalPage,
+		},
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *InvoiceHandler) GetByID(c echo.Context) error {
+	id := c.Param("id")
+	invoice, err := h.uc.GetByID(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "invoice not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, invoice)
+}
+
+func (h *InvoiceHandler) GetPDF(c echo.Context) error {
+	id := c.Param("id")
+	content, invoice, err := h.uc.RenderPDF(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "invoice not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", invoice.Number+".pdf"))
+	return c.Blob(http.StatusOK, "application/pdf", content)
+}
+
+func (h *InvoiceHandler) CreateCreditNote(c echo.Context) error {
+	id := c.Param("id")
+	var req entity.CreateCreditNoteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	note, err := h.uc.CreateCreditNote(c.Request().Context(), id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrRecordNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "invoice not found"})
+		case errors.Is(err, entity.ErrInvalidInput):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrCreditExceedsInvoice):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusCreated, note)
+}
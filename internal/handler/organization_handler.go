@@ -29,6 +29,9 @@ func (h *OrganizationHandler) Create(c echo.Context) error {
 		if errors.Is(err, entity.ErrInvalidInput) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 		}
+		if errors.Is(err, entity.ErrConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "organization name already taken"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusCreated, map[string]string{"organization_id": id})
@@ -109,6 +112,9 @@ func (h *OrganizationHandler) Update(c echo.Context) error {
 		if errors.Is(err, entity.ErrInvalidInput) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 		}
+		if errors.Is(err, entity.ErrConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "organization name already taken"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, map[string]string{"message": "updated successfully"})
@@ -27,8 +27,8 @@ func (h *PricingHandler) BulkUpdate(c echo.Context) error {
 		if errors.Is(err, entity.ErrInvalidInput) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 		}
-		if err.Error() == "unit type not found" {
-			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		if errors.Is(err, entity.ErrUnitTypeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "unit type not found"})
 		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type PrivacyHandler struct {
+	uc *usecase.PrivacyUseCase
+}
+
+func NewPrivacyHandler(uc *usecase.PrivacyUseCase) *PrivacyHandler {
+	return &PrivacyHandler{uc: uc}
+}
+
+func (h *PrivacyHandler) Export(c echo.Context) error {
+	orgID, _ := c.Get("organization_id").(string)
+	actorID, _ := c.Get("user_id").(string)
+
+	export, err := h.uc.Export(c.Request().Context(), orgID, c.Param("id"), actorID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "guest not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=guest-"+export.Profile.ID+".json")
+	return c.JSON(http.StatusOK, export)
+}
+
+func (h *PrivacyHandler) Erase(c echo.Context) error {
+	orgID, _ := c.Get("organization_id").(string)
+	actorID, _ := c.Get("user_id").(string)
+
+	req, err := h.uc.Erase(c.Request().Context(), orgID, c.Param("id"), actorID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "guest not found"})
+		}
+		if errors.Is(err, entity.ErrConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, req)
+}
+
+func (h *PrivacyHandler) ListRequests(c echo.Context) error {
+	var pagination entity.PaginationRequest
+	if err := c.Bind(&pagination); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid pagination params"})
+	}
+	if pagination.Page < 1 {
+		pagination.Page = 1
+	}
+	if pagination.Limit < 1 {
+		pagination.Limit = 10
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	requests, total, err := h.uc.ListRequests(c.Request().Context(), orgID, pagination)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	if requests == nil {
+		requests = []entity.PrivacyRequest{}
+	}
+
+	totalPage := int(total) / pagination.Limit
+	if int(total)%pagination.Limit != 0 {
+		totalPage++
+	}
+
+	response := entity.PaginatedResponse[entity.PrivacyRequest]{
+		Data: requests,
+		Meta: entity.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: total,
+			TotalPages: totalPage,
+		},
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *PrivacyHandler) GetRetention(c echo.Context) error {
+	orgID, _ := c.Get("organization_id").(string)
+	settings, err := h.uc.GetRetention(c.Request().Context(), orgID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "organization not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, settings)
+}
+
+func (h *PrivacyHandler) UpdateRetention(c echo.Context) error {
+	var req entity.RetentionSettings
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	if err := h.uc.UpdateRetention(c.Request().Context(), orgID, req); err != nil {
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "organization not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "retention updated"})
+}
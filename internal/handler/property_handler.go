@@ -31,6 +31,9 @@ func (h *PropertyHandler) Create(c echo.Context) error {
 		if errors.Is(err, entity.ErrConflict) {
 			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
 		}
+		if errors.Is(err, entity.ErrInsufficientPermissions) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type PropertyServiceHandler struct {
+	uc *usecase.PropertyServiceUseCase
+}
+
+func NewPropertyServiceHandler(uc *usecase.PropertyServiceUseCase) *PropertyServiceHandler {
+	return &PropertyServiceHandler{uc: uc}
+}
+
+func (h *PropertyServiceHandler) Create(c echo.Context) error {
+	var req entity.CreatePropertyServiceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	id, err := h.uc.Create(c.Request().Context(), req)
+	if err != nil {
+		return propertyServiceError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]string{"property_service_id": id})
+}
+
+// List returns every service a property has set up, including those withdrawn from sale.
+func (h *PropertyServiceHandler) List(c echo.Context) error {
+	propertyID := c.QueryParam("property_id")
+	if propertyID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "property_id is required"})
+	}
+
+	list, err := h.uc.ListByProperty(c.Request().Context(), propertyID, false)
+	if err != nil {
+		return propertyServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// ListOffered is the public list of add-ons guests can book at a property.
+func (h *PropertyServiceHandler) ListOffered(c echo.Context) error {
+	list, err := h.uc.ListByProperty(c.Request().Context(), c.Param("id"), true)
+	if err != nil {
+		return propertyServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+func (h *PropertyServiceHandler) GetByID(c echo.Context) error {
+	offer, err := h.uc.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return propertyServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, offer)
+}
+
+func (h *PropertyServiceHandler) Update(c echo.Context) error {
+	var req entity.UpdatePropertyServiceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	if err := h.uc.Update(c.Request().Context(), c.Param("id"), req); err != nil {
+		return propertyServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "property service updated"})
+}
+
+func (h *PropertyServiceHandler) Delete(c echo.Context) error {
+	if err := h.uc.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return propertyServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "property service deleted"})
+}
+
+func propertyServiceError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrInvalidInput), errors.Is(err, entity.ErrPriceNegative):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "property service not found"})
+	case errors.Is(err, entity.ErrConflict):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "the property already offers this service"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
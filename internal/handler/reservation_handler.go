@@ -30,14 +30,14 @@ func (h *ReservationHandler) Create(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "guest name is required"})
 	}
 
-	code, err := h.uc.Create(c.Request().Context(), req)
+	access, err := h.uc.Create(c.Request().Context(), req)
 	if err != nil {
 		switch {
 		case errors.Is(err, entity.ErrInvalidDateFormat), 
 		     errors.Is(err, entity.ErrInvalidDateRange),
 		     errors.Is(err, entity.ErrInvalidInput):
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
-		case errors.Is(err, entity.ErrNoAvailability):
+		case errors.Is(err, entity.ErrNoAvailability), errors.Is(err, entity.ErrAddOnUnavailable):
 			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
 		case errors.Is(err, entity.ErrUnitTypeNotFound):
 			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
@@ -46,18 +46,64 @@ func (h *ReservationHandler) Create(c echo.Context) error {
 		}
 	}
 
-	return c.JSON(http.StatusCreated, map[string]string{"reservation_code": code})
+	return c.JSON(http.StatusCreated, access)
 }
 
+// guestToken reads the guest access token from the X-Guest-Token header. It is never taken
+// from the query string, which ends up in access logs.
+func guestToken(c echo.Context) string {
+	return c.Request().Header.Get("X-Guest-Token")
+}
+
+// GetByCode serves staff looking a booking up by its code.
 func (h *ReservationHandler) GetByCode(c echo.Context) error {
+	res, err := h.uc.GetByCode(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		if errors.Is(err, entity.ErrReservationNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "reservation not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *ReservationHandler) GetForGuest(c echo.Context) error {
 	code := c.Param("code")
-	res, err := h.uc.GetByCode(c.Request().Context(), code)
+	res, err := h.uc.GetForGuest(c.Request().Context(), code, guestToken(c))
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "reservation not found"})
+		switch {
+		case errors.Is(err, entity.ErrGuestAccessDenied):
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrReservationNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "reservation not found"})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
 	}
 	return c.JSON(http.StatusOK, res)
 }
 
+// RequestAccess exchanges a booking code and the guest's last name for an access token.
+func (h *ReservationHandler) RequestAccess(c echo.Context) error {
+	var req entity.GuestAccessRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	access, err := h.uc.RequestGuestAccess(c.Request().Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidInput):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrGuestAccessDenied):
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "reservation code and last name do not match"})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+	return c.JSON(http.StatusOK, access)
+}
+
 func (h *ReservationHandler) PreviewCancel(c echo.Context) error {
 	id := c.Param("id")
 	
@@ -75,11 +121,70 @@ func (h *ReservationHandler) PreviewCancel(c echo.Context) error {
 	})
 }
 
+func (h *ReservationHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+	var req entity.UpdateReservationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	res, err := h.uc.Modify(c.Request().Context(), id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrRecordNotFound), errors.Is(err, entity.ErrReservationNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "reservation not found"})
+		case errors.Is(err, entity.ErrUnitTypeNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrInvalidDateFormat),
+		     errors.Is(err, entity.ErrInvalidDateRange),
+		     errors.Is(err, entity.ErrInvalidInput):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrNoAvailability),
+		     errors.Is(err, entity.ErrAddOnUnavailable),
+		     errors.Is(err, entity.ErrReservationCancelled),
+		     errors.Is(err, entity.ErrInvalidReservationStatus):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Cancel serves staff cancelling a booking of their organization.
 func (h *ReservationHandler) Cancel(c echo.Context) error {
-	id := c.Param("id") // TODO: Here it is still by internal UUID for operations, or it could be by code.
-	err := h.uc.Cancel(c.Request().Context(), id)
+	if err := h.uc.Cancel(c.Request().Context(), c.Param("id")); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrReservationNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrReservationCancelled),
+		     errors.Is(err, entity.ErrInvalidReservationStatus),
+		     errors.Is(err, entity.ErrModificationNotAllowed):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "cancelled"})
+}
+
+func (h *ReservationHandler) CancelForGuest(c echo.Context) error {
+	id := c.Param("id")
+	err := h.uc.CancelForGuest(c.Request().Context(), id, guestToken(c))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		switch {
+		case errors.Is(err, entity.ErrGuestAccessDenied):
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrReservationNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, entity.ErrReservationCancelled),
+		     errors.Is(err, entity.ErrInvalidReservationStatus),
+		     errors.Is(err, entity.ErrModificationNotAllowed):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
 	}
 	return c.JSON(http.StatusOK, map[string]string{"message": "cancelled"})
 }
@@ -91,3 +196,49 @@ func (h *ReservationHandler) Delete(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, map[string]string{"message": "reservation deleted"})
 }
+
+func (h *ReservationHandler) ListAddOns(c echo.Context) error {
+	addOns, err := h.uc.ListAddOns(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return addOnError(c, err)
+	}
+	return c.JSON(http.StatusOK, addOns)
+}
+
+func (h *ReservationHandler) AddAddOn(c echo.Context) error {
+	var req entity.AddOnRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	res, err := h.uc.AddAddOn(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return addOnError(c, err)
+	}
+	return c.JSON(http.StatusCreated, res)
+}
+
+func (h *ReservationHandler) RemoveAddOn(c echo.Context) error {
+	res, err := h.uc.RemoveAddOn(c.Request().Context(), c.Param("id"), c.Param("addOnId"))
+	if err != nil {
+		return addOnError(c, err)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func addOnError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrReservationNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "reservation not found"})
+	case errors.Is(err, entity.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "add-on not found"})
+	case errors.Is(err, entity.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, entity.ErrAddOnUnavailable),
+	     errors.Is(err, entity.ErrInvalidReservationStatus),
+	     errors.Is(err, entity.ErrModificationNotAllowed):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
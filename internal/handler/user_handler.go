@@ -47,9 +47,6 @@ func (h *UserHandler) Create(c echo.Context) error {
 
 func (h *UserHandler) GetAll(c echo.Context) error {
 	orgID := c.QueryParam("organization_id")
-	if orgID == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "organization_id query param required"})
-	}
 
 	var pagination entity.PaginationRequest
 	if err := c.Bind(&pagination); err != nil {
@@ -64,6 +61,9 @@ func (h *UserHandler) GetAll(c echo.Context) error {
 
 	users, total, err := h.uc.GetAll(c.Request().Context(), orgID, pagination)
 	if err != nil {
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "organization_id query param required"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	
@@ -103,10 +103,7 @@ func (h *UserHandler) GetByID(c echo.Context) error {
 
 func (h *UserHandler) Update(c echo.Context) error {
 	id := c.Param("id")
-	orgID := c.QueryParam("organization_id") 
-	if orgID == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "organization_id query param required for role update"})
-	}
+	orgID := c.QueryParam("organization_id")
 
 	var req entity.UpdateUserRequest
 	if err := c.Bind(&req); err != nil {
@@ -118,6 +115,12 @@ func (h *UserHandler) Update(c echo.Context) error {
 		if errors.Is(err, entity.ErrRecordNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found in this organization"})
 		}
+		if errors.Is(err, entity.ErrInsufficientPermissions) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, map[string]string{"message": "user updated"})
@@ -132,5 +135,19 @@ func (h *UserHandler) Delete(c echo.Context) error {
 		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusOK, map[string]string{"message": "user deleted"})
+	return c.JSON(http.StatusOK, map[string]string{"message": "user removed"})
+}
+
+func (h *UserHandler) ResetTwoFactor(c echo.Context) error {
+	requesterID, _ := c.Get("user_id").(string)
+	if err := h.uc.ResetTwoFactor(c.Request().Context(), requesterID, c.Param("id")); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) || errors.Is(err, entity.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		if errors.Is(err, entity.ErrInsufficientPermissions) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "two-factor authentication reset"})
 }
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type WebhookHandler struct {
+	uc *usecase.WebhookUseCase
+}
+
+func NewWebhookHandler(uc *usecase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{uc: uc}
+}
+
+func (h *WebhookHandler) Create(c echo.Context) error {
+	var req entity.CreateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	sub, err := h.uc.Create(c.Request().Context(), orgID, req)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, sub)
+}
+
+func (h *WebhookHandler) GetAll(c echo.Context) error {
+	orgID, _ := c.Get("organization_id").(string)
+	subs, err := h.uc.List(c.Request().Context(), orgID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if subs == nil {
+		subs = []entity.WebhookSubscription{}
+	}
+	return c.JSON(http.StatusOK, subs)
+}
+
+func (h *WebhookHandler) GetByID(c echo.Context) error {
+	orgID, _ := c.Get("organization_id").(string)
+	sub, err := h.uc.GetByID(c.Request().Context(), orgID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "webhook not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) Update(c echo.Context) error {
+	var req entity.UpdateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	if err := h.uc.Update(c.Request().Context(), orgID, c.Param("id"), req); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "webhook not found"})
+		}
+		if errors.Is(err, entity.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "webhook updated"})
+}
+
+func (h *WebhookHandler) Delete(c echo.Context) error {
+	orgID, _ := c.Get("organization_id").(string)
+	if err := h.uc.Delete(c.Request().Context(), orgID, c.Param("id")); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "webhook not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "webhook deleted"})
+}
+
+func (h *WebhookHandler) ListDeliveries(c echo.Context) error {
+	var pagination entity.PaginationRequest
+	if err := c.Bind(&pagination); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid pagination params"})
+	}
+	if pagination.Page < 1 {
+		pagination.Page = 1
+	}
+	if pagination.Limit < 1 {
+		pagination.Limit = 10
+	}
+
+	orgID, _ := c.Get("organization_id").(string)
+	deliveries, total, err := h.uc.ListDeliveries(c.Request().Context(), orgID, c.Param("id"), pagination)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "webhook not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	if deliveries == nil {
+		deliveries = []entity.WebhookDelivery{}
+	}
+
+	totalPage := int(total) / pagination.Limit
+	if int(total)%pagination.Limit != 0 {
+		totalPage++
+	}
+
+	response := entity.PaginatedResponse[entity.WebhookDelivery]{
+		Data: deliveries,
+		Meta: entity.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: total,
+			TotalPages: totalPage,
+		},
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -19,9 +19,18 @@ func NewAmenityRepository(db *pgxpool.Pool) *AmenityRepository {
 	return &AmenityRepository{db: db}
 }
 
+// catalogScope turns the caller's organization into the query argument that also matches
+// global catalog entries; callers without an organization only see global ones.
+func catalogScope(orgID string) *string {
+	if orgID == "" {
+		return nil
+	}
+	return &orgID
+}
+
 func (r *AmenityRepository) Create(ctx context.Context, a entity.Amenity) error {
-	query := `INSERT INTO amenities (id, name, description, icon, created_at, updated_at) VALUES ($1, $2, $3, $4, NOW(), NOW())`
-	_, err := r.db.Exec(ctx, query, a.ID, a.Name, a.Description, a.Icon)
+	query := `INSERT INTO amenities (id, organization_id, name, description, icon, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, NOW(), NOW())`
+	_, err := r.db.Exec(ctx, query, a.ID, a.OrganizationID, a.Name, a.Description, a.Icon)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -32,10 +41,10 @@ func (r *AmenityRepository) Create(ctx context.Context, a entity.Amenity) error
 	return nil
 }
 
-func (r *AmenityRepository) GetAll(ctx context.Context, pagination entity.PaginationRequest) ([]entity.Amenity, int64, error) {
-	countQuery := `SELECT COUNT(*) FROM amenities WHERE deleted_at IS NULL`
+func (r *AmenityRepository) GetAll(ctx context.Context, orgID string, pagination entity.PaginationRequest) ([]entity.Amenity, int64, error) {
+	countQuery := `SELECT COUNT(*) FROM amenities WHERE deleted_at IS NULL AND (organization_id IS NULL OR organization_id = $1)`
 	var total int64
-	if err := r.db.QueryRow(ctx, countQuery).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, countQuery, catalogScope(orgID)).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count amenities: %w", err)
 	}
 
@@ -44,21 +53,22 @@ func (r *AmenityRepository) GetAll(ctx context.Context, pagination entity.Pagina
 
 	if pagination.Unlimited {
 		query = `
-			SELECT id, name, description, icon 
+			SELECT id, organization_id, name, description, icon 
 			FROM amenities 
-			WHERE deleted_at IS NULL 
+			WHERE deleted_at IS NULL AND (organization_id IS NULL OR organization_id = $1)
 			ORDER BY name ASC
 		`
+		args = append(args, catalogScope(orgID))
 	} else {
 		query = `
-			SELECT id, name, description, icon 
+			SELECT id, organization_id, name, description, icon 
 			FROM amenities 
-			WHERE deleted_at IS NULL 
+			WHERE deleted_at IS NULL AND (organization_id IS NULL OR organization_id = $1)
 			ORDER BY name ASC
-			LIMIT $1 OFFSET $2
+			LIMIT $2 OFFSET $3
 		`
 		offset := (pagination.Page - 1) * pagination.Limit
-		args = append(args, pagination.Limit, offset)
+		args = append(args, catalogScope(orgID), pagination.Limit, offset)
 	}
 	
 	rows, err := r.db.Query(ctx, query, args...)
@@ -68,16 +78,19 @@ func (r *AmenityRepository) GetAll(ctx context.Context, pagination entity.Pagina
 	var list []entity.Amenity
 	for rows.Next() {
 		var a entity.Amenity
-		if err := rows.Scan(&a.ID, &a.Name, &a.Description, &a.Icon); err != nil { return nil, 0, err }
+		if err := rows.Scan(&a.ID, &a.OrganizationID, &a.Name, &a.Description, &a.Icon); err != nil { return nil, 0, err }
 		list = append(list, a)
 	}
 	return list, total, nil
 }
 
-func (r *AmenityRepository) GetByID(ctx context.Context, id string) (*entity.Amenity, error) {
-	query := `SELECT id, name, description, icon FROM amenities WHERE id = $1 AND deleted_at IS NULL`
+func (r *AmenityRepository) GetByID(ctx context.Context, id, orgID string) (*entity.Amenity, error) {
+	query := `
+		SELECT id, organization_id, name, description, icon FROM amenities
+		WHERE id = $1 AND deleted_at IS NULL AND (organization_id IS NULL OR organization_id = $2)
+	`
 	var a entity.Amenity
-	err := r.db.QueryRow(ctx, query, id).Scan(&a.ID, &a.Name, &a.Description, &a.Icon)
+	err := r.db.QueryRow(ctx, query, id, catalogScope(orgID)).Scan(&a.ID, &a.OrganizationID, &a.Name, &a.Description, &a.Icon)
 	
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -88,6 +101,29 @@ func (r *AmenityRepository) GetByID(ctx context.Context, id string) (*entity.Ame
 	return &a, nil
 }
 
+// CountExisting returns how many of ids are live amenities visible to the organization.
+func (r *AmenityRepository) CountExisting(ctx context.Context, ids []string, orgID string) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM amenities
+		WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL AND (organization_id IS NULL OR organization_id = $2)
+	`
+	var count int
+	if err := r.db.QueryRow(ctx, query, ids, catalogScope(orgID)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count amenities: %w", err)
+	}
+	return count, nil
+}
+
+// GlobalNameExists reports whether a live global entry already uses name.
+func (r *AmenityRepository) GlobalNameExists(ctx context.Context, name string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM amenities WHERE organization_id IS NULL AND name = $1 AND deleted_at IS NULL)`
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check amenity name: %w", err)
+	}
+	return exists, nil
+}
+
 func (r *AmenityRepository) Update(ctx context.Context, id string, req entity.UpdateCatalogRequest) error {
 	query := `UPDATE amenities SET name=$2, description=$3, icon=$4, updated_at=NOW() WHERE id=$1 AND deleted_at IS NULL`
 	cmd, err := r.db.Exec(ctx, query, id, req.Name, req.Description, req.Icon)
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type EmailOutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEmailOutboxRepository(db *pgxpool.Pool) *EmailOutboxRepository {
+	return &EmailOutboxRepository{db: db}
+}
+
+const outboxColumns = `id, recipient, subject, body, status, attempts, max_attempts,
+	next_attempt_at, last_error, sent_at, created_at, updated_at`
+
+func scanOutboxEmail(row pgx.Row, e *entity.OutboxEmail) error {
+	return row.Scan(
+		&e.ID, &e.Recipient, &e.Subject, &e.Body, &e.Status, &e.Attempts, &e.MaxAttempts,
+		&e.NextAttemptAt, &e.LastError, &e.SentAt, &e.CreatedAt, &e.UpdatedAt,
+	)
+}
+
+// Enqueue stores a message for asynchronous delivery. Passing the business transaction
+// guarantees the email only exists if the change that triggered it was committed.
+func (r *EmailOutboxRepository) Enqueue(ctx context.Context, tx pgx.Tx, msg entity.EmailMessage) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+
+	query := `INSERT INTO email_outbox (id, recipient, subject, body, guest_id) VALUES ($1, $2, $3, $4, NULLIF($5, '')::UUID)`
+	if _, err := querier.Exec(ctx, query, id.String(), msg.To, msg.Subject, msg.Body, msg.GuestID); err != nil {
+		return fmt.Errorf("enqueue email: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue locks up to limit pending messages that are due. Rows locked by another
+// worker are skipped, so several instances can drain the outbox concurrently.
+func (r *EmailOutboxRepository) ClaimDue(ctx context.Context, tx pgx.Tx, limit int) ([]entity.OutboxEmail, error) {
+	query := `
+		SELECT ` + outboxColumns + `
+		FROM email_outbox
+		WHERE status = 'pending' AND next_attempt_at <= NOW()
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox emails: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.OutboxEmail
+	for rows.Next() {
+		var e entity.OutboxEmail
+		if err := scanOutboxEmail(rows, &e); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+func (r *EmailOutboxRepository) MarkSent(ctx context.Context, tx pgx.Tx, id string, attempts int) error {
+	query := `UPDATE email_outbox SET status = 'sent', attempts = $2, sent_at = NOW(), last_error = NULL WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, id, attempts); err != nil {
+		return fmt.Errorf("mark email sent: %w", err)
+	}
+	return nil
+}
+
+func (r *EmailOutboxRepository) ScheduleRetry(ctx context.Context, tx pgx.Tx, id string, attempts int, nextAttempt time.Time, lastError string) error {
+	query := `UPDATE email_outbox SET attempts = $2, next_attempt_at = $3, last_error = $4 WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, id, attempts, nextAttempt, lastError); err != nil {
+		return fmt.Errorf("schedule email retry: %w", err)
+	}
+	return nil
+}
+
+func (r *EmailOutboxRepository) MarkFailed(ctx context.Context, tx pgx.Tx, id string, attempts int, lastError string) error {
+	query := `UPDATE email_outbox SET status = 'failed', attempts = $2, last_error = $3 WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, id, attempts, lastError); err != nil {
+		return fmt.Errorf("mark email failed: %w", err)
+	}
+	return nil
+}
+
+func (r *EmailOutboxRepository) ListFailed(ctx context.Context, pagination entity.PaginationRequest) ([]entity.OutboxEmail, int64, error) {
+	countQuery := `SELECT COUNT(*) FROM email_outbox WHERE status = 'failed'`
+	var total int64
+	if err := r.db.QueryRow(ctx, countQuery).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count failed emails: %w", err)
+	}
+
+	query := `
+		SELECT ` + outboxColumns + `
+		FROM email_outbox
+		WHERE status = 'failed'
+		ORDER BY updated_at DESC
+		LIMIT $1 OFFSET $2
+	`
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	rows, err := r.db.Query(ctx, query, pagination.Limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list failed emails: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.OutboxEmail
+	for rows.Next() {
+		var e entity.OutboxEmail
+		if err := scanOutboxEmail(rows, &e); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, e)
+	}
+	return list, total, nil
+}
+
+func (r *EmailOutboxRepository) ListByGuest(ctx context.Context, guestID string) ([]entity.GuestCommunication, error) {
+	query := `
+		SELECT id, recipient, subject, body, status, sent_at, created_at
+		FROM email_outbox
+		WHERE guest_id = $1
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.Query(ctx, query, guestID)
+	if err != nil {
+		return nil, fmt.Errorf("list guest emails: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.GuestCommunication
+	for rows.Next() {
+		var c entity.GuestCommunication
+		if err := rows.Scan(&c.ID, &c.Recipient, &c.Subject, &c.Body, &c.Status, &c.SentAt, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// RedactGuest strips personal data from a guest's emails. Messages not sent yet are
+// dead-lettered so nothing is delivered to an erased guest.
+func (r *EmailOutboxRepository) RedactGuest(ctx context.Context, tx pgx.Tx, guestID, placeholder string) error {
+	query := `
+		UPDATE email_outbox
+		SET recipient = $2, subject = '[erased]', body = '',
+			status = CASE WHEN status = 'pending' THEN 'failed' ELSE status END,
+			last_error = CASE WHEN status = 'pending' THEN 'guest data erased' ELSE last_error END
+		WHERE guest_id = $1
+	`
+	if _, err := tx.Exec(ctx, query, guestID, placeholder); err != nil {
+		return fmt.Errorf("redact guest emails: %w", err)
+	}
+	return nil
+}
@@ -2,38 +2,192 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/pkg/fieldcrypt"
 )
 
 type GuestRepository struct {
-	db *pgxpool.Pool
+	db   *pgxpool.Pool
+	keys *fieldcrypt.KeyRing
 }
 
-func NewGuestRepository(db *pgxpool.Pool) *GuestRepository {
-	return &GuestRepository{db: db}
+func NewGuestRepository(db *pgxpool.Pool, keys *fieldcrypt.KeyRing) *GuestRepository {
+	return &GuestRepository{db: db, keys: keys}
+}
+
+const guestColumns = `id, organization_id, email, first_name, last_name, COALESCE(phone, ''), COALESCE(language, ''), created_at, updated_at, pii_key_version`
+
+// scanGuest reads a guest row and decrypts its personal data. Rows without a key version
+// predate encryption and are returned as stored.
+func (r *GuestRepository) scanGuest(row pgx.Row) (*entity.Guest, error) {
+	var g entity.Guest
+	var keyVersion *int
+	if err := row.Scan(&g.ID, &g.OrganizationID, &g.Email, &g.FirstName, &g.LastName, &g.Phone, &g.Language, &g.CreatedAt, &g.UpdatedAt, &keyVersion); err != nil {
+		return nil, err
+	}
+	if keyVersion == nil {
+		return &g, nil
+	}
+
+	for _, field := range []*string{&g.Email, &g.FirstName, &g.LastName, &g.Phone} {
+		plain, err := r.keys.Decrypt(*field)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt guest %s: %w", g.ID, err)
+		}
+		*field = plain
+	}
+	return &g, nil
+}
+
+// sealedGuest is the stored form of a guest's personal data.
+type sealedGuest struct {
+	email       string
+	emailIndex  string
+	searchIndex []string
+	matchIndex  []string
+	firstName   string
+	lastName    string
+	phone       string
+	keyVersion  int
+}
+
+func (r *GuestRepository) seal(g entity.Guest) (sealedGuest, error) {
+	s := sealedGuest{emailIndex: r.keys.BlindIndex(g.Email), keyVersion: r.keys.ActiveVersion()}
+	for _, token := range searchTokens(g) {
+		s.searchIndex = append(s.searchIndex, r.keys.BlindIndex(token))
+	}
+	for _, key := range g.MatchKeys() {
+		s.matchIndex = append(s.matchIndex, r.keys.BlindIndex(key))
+	}
+	fields := []struct {
+		dst   *string
+		plain string
+	}{
+		{&s.email, g.Email},
+		{&s.firstName, g.FirstName},
+		{&s.lastName, g.LastName},
+		{&s.phone, g.Phone},
+	}
+	for _, f := range fields {
+		sealed, err := r.keys.Encrypt(f.plain)
+		if err != nil {
+			return sealedGuest{}, fmt.Errorf("encrypt guest: %w", err)
+		}
+		*f.dst = sealed
+	}
+	return s, nil
+}
+
+const (
+	minSearchPrefix = 3
+	minSearchDigits = 4
+)
+
+// searchTokens lists the terms a guest can be found by: prefixes of each name word and of the
+// email, and runs of digits of the phone number. They are stored as blind indexes, so List
+// searches without decrypting the organization's guests.
+func searchTokens(g entity.Guest) []string {
+	seen := map[string]bool{}
+	var tokens []string
+	add := func(token string) {
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+	addPrefixes := func(word string) {
+		runes := []rune(word)
+		for n := min(minSearchPrefix, len(runes)); n <= len(runes); n++ {
+			if n > 0 {
+				add(string(runes[:n]))
+			}
+		}
+	}
+
+	for _, word := range strings.Fields(strings.ToLower(g.FirstName + " " + g.LastName)) {
+		addPrefixes(word)
+	}
+	addPrefixes(strings.ToLower(strings.TrimSpace(g.Email)))
+
+	digits := phoneDigits(g.Phone)
+	for i := range digits {
+		for j := i + minSearchDigits; j <= len(digits); j++ {
+			add(digits[i:j])
+		}
+	}
+	return tokens
+}
+
+// searchWords splits a search query into the terms a matching guest must all carry. Words made
+// of phone punctuation and digits are reduced to their digits.
+func searchWords(search string) []string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(search)) {
+		if strings.Trim(word, "+-().0123456789") == "" && phoneDigits(word) != "" {
+			word = phoneDigits(word)
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// plaintextSearch matches rows not encrypted yet by the same rules searchTokens indexes: each
+// word is a whole name word, a prefix of at least minSearchPrefix characters of a name word or
+// the email, or a run of at least minSearchDigits phone digits. param is the placeholder number
+// of the first word.
+func plaintextSearch(words []string, param int) string {
+	conds := make([]string, len(words))
+	for i := range words {
+		conds[i] = strings.ReplaceAll(plaintextWordMatch, "$word", fmt.Sprintf("$%d", param+i))
+	}
+	return strings.Join(conds, " AND ")
+}
+
+var plaintextWordMatch = fmt.Sprintf(`(
+	EXISTS (
+		SELECT 1 FROM regexp_split_to_table(lower(first_name || ' ' || last_name), '\s+') AS w(word)
+		WHERE w.word <> '' AND (w.word = $word OR (char_length($word) >= %[1]d AND starts_with(w.word, $word)))
+	)
+	OR lower(trim(email)) = $word
+	OR (char_length($word) >= %[1]d AND starts_with(lower(trim(email)), $word))
+	OR ($word ~ '^[0-9]{%[2]d,}$' AND strpos(regexp_replace(COALESCE(phone, ''), '[^0-9]', '', 'g'), $word) > 0)
+)`, minSearchPrefix, minSearchDigits)
+
+func phoneDigits(phone string) string {
+	return strings.Map(func(c rune) rune {
+		if c >= '0' && c <= '9' {
+			return c
+		}
+		return -1
+	}, phone)
 }
 
 func (r *GuestRepository) Create(ctx context.Context, tx pgx.Tx, g entity.Guest) (string, error) {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+
+	sealed, err := r.seal(g)
+	if err != nil {
+		return "", err
+	}
+
 	query := `
-		INSERT INTO guests (id, email, first_name, last_name, phone, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		INSERT INTO guests (id, organization_id, email, email_index, search_index, match_index, first_name, last_name, phone, language, pii_key_version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, NOW(), NOW())
 		RETURNING id
 	`
 	var id string
-	var err error
-	
-	if tx != nil {
-		err = tx.QueryRow(ctx, query, g.ID, g.Email, g.FirstName, g.LastName, g.Phone).Scan(&id)
-	} else {
-		err = r.db.QueryRow(ctx, query, g.ID, g.Email, g.FirstName, g.LastName, g.Phone).Scan(&id)
-	}
-
+	err = querier.QueryRow(ctx, query, g.ID, g.OrganizationID, sealed.email, sealed.emailIndex, sealed.searchIndex, sealed.matchIndex, sealed.firstName, sealed.lastName, sealed.phone, g.Language, sealed.keyVersion).Scan(&id)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -44,35 +198,571 @@ func (r *GuestRepository) Create(ctx context.Context, tx pgx.Tx, g entity.Guest)
 	return id, nil
 }
 
-func (r *GuestRepository) GetByEmail(ctx context.Context, email string) (*entity.Guest, error) {
-	query := `SELECT id, email, first_name, last_name, phone FROM guests WHERE email = $1 AND deleted_at IS NULL`
-	var g entity.Guest
-	err := r.db.QueryRow(ctx, query, email).Scan(&g.ID, &g.Email, &g.FirstName, &g.LastName, &g.Phone)
+// GetByEmail looks the guest up through the email blind index, falling back to the plain
+// column for rows that have not been encrypted yet.
+func (r *GuestRepository) GetByEmail(ctx context.Context, orgID, email string) (*entity.Guest, error) {
+	query := `
+		SELECT ` + guestColumns + `
+		FROM guests
+		WHERE organization_id = $1 AND deleted_at IS NULL
+			AND (email_index = $2 OR (pii_key_version IS NULL AND email = $3))
+		LIMIT 1
+	`
+	g, err := r.scanGuest(r.db.QueryRow(ctx, query, orgID, r.keys.BlindIndex(email), email))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("get guest by email: %w", err)
 	}
-	return &g, nil
+	return g, nil
+}
+
+func (r *GuestRepository) GetByID(ctx context.Context, id string) (*entity.Guest, error) {
+	query := `SELECT ` + guestColumns + ` FROM guests WHERE id = $1 AND deleted_at IS NULL`
+	g, err := r.scanGuest(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get guest by id: %w", err)
+	}
+	return g, nil
+}
+
+func (r *GuestRepository) GetByIDLocked(ctx context.Context, tx pgx.Tx, id string) (*entity.Guest, error) {
+	query := `SELECT ` + guestColumns + ` FROM guests WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	g, err := r.scanGuest(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("lock guest: %w", err)
+	}
+	return g, nil
+}
+
+// GetByIDWithDeleted also returns soft-deleted and merged guests, which privacy requests still cover.
+func (r *GuestRepository) GetByIDWithDeleted(ctx context.Context, id string) (*entity.Guest, error) {
+	query := `SELECT ` + guestColumns + ` FROM guests WHERE id = $1`
+	g, err := r.scanGuest(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get guest by id: %w", err)
+	}
+	return g, nil
+}
+
+// GetByIDWithDeletedLocked locks the guest row whether or not it was deleted. Holding the
+// lock keeps new reservations from referencing the guest until the transaction ends.
+func (r *GuestRepository) GetByIDWithDeletedLocked(ctx context.Context, tx pgx.Tx, id string) (*entity.Guest, error) {
+	query := `SELECT ` + guestColumns + ` FROM guests WHERE id = $1 FOR UPDATE`
+	g, err := r.scanGuest(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("lock guest: %w", err)
+	}
+	return g, nil
 }
 
-func (r *GuestRepository) Update(ctx context.Context, tx pgx.Tx, g entity.Guest) error {
+// ListMergedInto returns the guests merged into the survivor, following earlier merges of
+// those duplicates as well.
+func (r *GuestRepository) ListMergedInto(ctx context.Context, tx pgx.Tx, survivorID string) ([]string, error) {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+
 	query := `
-		UPDATE guests 
-		SET first_name = $2, last_name = $3, phone = $4, updated_at = NOW()
-		WHERE email = $1 AND deleted_at IS NULL
+		WITH RECURSIVE merged AS (
+			SELECT merged_id FROM guest_merges WHERE survivor_id = $1
+			UNION
+			SELECT m.merged_id FROM guest_merges m JOIN merged ON m.survivor_id = merged.merged_id
+		)
+		SELECT merged_id FROM merged
 	`
-	var err error
-	
-	if tx != nil {
-		_, err = tx.Exec(ctx, query, g.Email, g.FirstName, g.LastName, g.Phone)
-	} else {
-		_, err = r.db.Exec(ctx, query, g.Email, g.FirstName, g.LastName, g.Phone)
+	rows, err := querier.Query(ctx, query, survivorID)
+	if err != nil {
+		return nil, fmt.Errorf("list merged guests: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// List pages through the organization's guests, newest first. Searches match the blind
+// indexes written with each guest, so nothing is decrypted beyond the page returned; rows not
+// encrypted yet are matched on their plaintext columns by the same rules. Every word of the
+// query must be at least minSearchPrefix characters (or a whole name word), or at least
+// minSearchDigits phone digits; shorter fragments match nothing.
+func (r *GuestRepository) List(ctx context.Context, orgID, search string, pagination entity.PaginationRequest) ([]entity.Guest, int64, error) {
+	where := `organization_id = $1 AND deleted_at IS NULL`
+	args := []interface{}{orgID}
+	if words := searchWords(search); len(words) > 0 {
+		terms := make([]string, len(words))
+		for i, word := range words {
+			terms[i] = r.keys.BlindIndex(word)
+		}
+		where += ` AND (search_index @> $2 OR (pii_key_version IS NULL AND ` + plaintextSearch(words, 3) + `))`
+		args = append(args, terms)
+		for _, word := range words {
+			args = append(args, word)
+		}
+	}
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM guests WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count guests: %w", err)
+	}
+
+	query := `SELECT ` + guestColumns + ` FROM guests WHERE ` + where + ` ORDER BY created_at DESC, id`
+	if !pagination.Unlimited {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+		args = append(args, pagination.Limit, (pagination.Page-1)*pagination.Limit)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list guests: %w", err)
+	}
+	defer rows.Close()
+
+	var guests []entity.Guest
+	for rows.Next() {
+		g, err := r.scanGuest(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		guests = append(guests, *g)
+	}
+	return guests, total, rows.Err()
+}
+
+// maxDuplicateGroup caps how many guests may share a match key for it to pair them. Larger
+// groups come from shared placeholders (a front-desk phone, a common surname) and would only
+// produce noise at a quadratic cost.
+const maxDuplicateGroup = 50
+
+// ListDuplicateCandidates returns the pairs of active guests of the organization that share a
+// match index, each pair once with the lower id first. Rows not encrypted yet carry no match
+// index and are left out until `make db-encrypt-guests` runs.
+func (r *GuestRepository) ListDuplicateCandidates(ctx context.Context, orgID string) ([][2]string, error) {
+	query := `
+		WITH groups AS (
+			SELECT array_agg(g.id) AS ids
+			FROM guests g, unnest(g.match_index) AS k(key)
+			WHERE g.organization_id = $1 AND g.deleted_at IS NULL
+			GROUP BY k.key
+			HAVING COUNT(*) BETWEEN 2 AND $2
+		)
+		SELECT DISTINCT a.id, b.id
+		FROM groups, unnest(groups.ids) AS a(id), unnest(groups.ids) AS b(id)
+		WHERE a.id < b.id
+		ORDER BY a.id, b.id
+	`
+	rows, err := r.db.Query(ctx, query, orgID, maxDuplicateGroup)
+	if err != nil {
+		return nil, fmt.Errorf("list duplicate candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs [][2]string
+	for rows.Next() {
+		var pair [2]string
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, rows.Err()
+}
+
+// GetByIDs loads the active guests among ids; missing ones are left out.
+func (r *GuestRepository) GetByIDs(ctx context.Context, ids []string) ([]entity.Guest, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+guestColumns+` FROM guests WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get guests: %w", err)
+	}
+	defer rows.Close()
+
+	var guests []entity.Guest
+	for rows.Next() {
+		g, err := r.scanGuest(rows)
+		if err != nil {
+			return nil, err
+		}
+		guests = append(guests, *g)
+	}
+	return guests, rows.Err()
+}
+
+// Update applies the non-empty fields of req to the guest. The personal data is sealed again
+// as a whole, so the row always ends up under the active key.
+func (r *GuestRepository) Update(ctx context.Context, tx pgx.Tx, id string, req entity.UpdateGuestRequest) error {
+	if tx == nil {
+		ownTx, err := r.db.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer ownTx.Rollback(ctx)
+
+		if err := r.Update(ctx, ownTx, id, req); err != nil {
+			return err
+		}
+		return ownTx.Commit(ctx)
 	}
 
+	g, err := r.GetByIDLocked(ctx, tx, id)
 	if err != nil {
+		return err
+	}
+
+	if req.Email != "" { g.Email = req.Email }
+	if req.FirstName != "" { g.FirstName = req.FirstName }
+	if req.LastName != "" { g.LastName = req.LastName }
+	if req.Phone != "" { g.Phone = req.Phone }
+	if req.Language != "" { g.Language = req.Language }
+
+	return r.writeSealed(ctx, tx, *g)
+}
+
+// ListStaleEncryption locks up to limit guests, retired ones included, whose personal data is
+// still plaintext, sealed with a key other than the active one, or missing its search or
+// match index.
+// Guests in skip are left out.
+func (r *GuestRepository) ListStaleEncryption(ctx context.Context, tx pgx.Tx, skip []string, limit int) ([]entity.Guest, error) {
+	query := `
+		SELECT ` + guestColumns + `
+		FROM guests
+		WHERE (pii_key_version IS NULL OR pii_key_version <> $1 OR search_index IS NULL OR match_index IS NULL)
+			AND id <> ALL($2::uuid[])
+		ORDER BY id
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`
+	if skip == nil {
+		skip = []string{}
+	}
+	rows, err := tx.Query(ctx, query, r.keys.ActiveVersion(), skip, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list stale guest encryption: %w", err)
+	}
+	defer rows.Close()
+
+	var guests []entity.Guest
+	for rows.Next() {
+		g, err := r.scanGuest(rows)
+		if err != nil {
+			return nil, err
+		}
+		guests = append(guests, *g)
+	}
+	return guests, rows.Err()
+}
+
+// Reseal writes the guest's personal data again with the active key.
+func (r *GuestRepository) Reseal(ctx context.Context, tx pgx.Tx, g entity.Guest) error {
+	return r.writeSealed(ctx, tx, g)
+}
+
+func (r *GuestRepository) writeSealed(ctx context.Context, tx pgx.Tx, g entity.Guest) error {
+	sealed, err := r.seal(g)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE guests
+		SET email = $2, email_index = $3, search_index = $4, match_index = $5, first_name = $6, last_name = $7,
+			phone = NULLIF($8, ''), language = NULLIF($9, ''), pii_key_version = $10, updated_at = NOW()
+		WHERE id = $1
+	`
+	cmd, err := tx.Exec(ctx, query, g.ID, sealed.email, sealed.emailIndex, sealed.searchIndex, sealed.matchIndex, sealed.firstName, sealed.lastName, sealed.phone, g.Language, sealed.keyVersion)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return entity.ErrConflict
+		}
 		return fmt.Errorf("update guest: %w", err)
 	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GuestRepository) Delete(ctx context.Context, tx pgx.Tx, id string) error {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+
+	query := `UPDATE guests SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	cmd, err := querier.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete guest: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GuestRepository) CountActiveReservations(ctx context.Context, tx pgx.Tx, id string) (int, error) {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+
+	query := `
+		SELECT COUNT(*) FROM reservations
+		WHERE guest_id = $1 AND status IN ('confirmed', 'checked_in') AND deleted_at IS NULL
+	`
+	var count int
+	if err := querier.QueryRow(ctx, query, id).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count guest reservations: %w", err)
+	}
+	return count, nil
+}
+
+func (r *GuestRepository) ListStays(ctx context.Context, id string) ([]entity.GuestStay, error) {
+	query := `
+		SELECT r.id, r.reservation_code, p.id, p.name, ut.name,
+			lower(r.stay_range), upper(r.stay_range), r.status, r.total_price
+		FROM reservations r
+		JOIN unit_types ut ON ut.id = r.unit_type_id
+		JOIN properties p ON p.id = ut.property_id
+		WHERE r.guest_id = $1 AND r.deleted_at IS NULL
+		ORDER BY lower(r.stay_range) DESC
+	`
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("list guest stays: %w", err)
+	}
+	defer rows.Close()
+
+	var stays []entity.GuestStay
+	for rows.Next() {
+		var s entity.GuestStay
+		if err := rows.Scan(
+			&s.ReservationID, &s.ReservationCode, &s.PropertyID, &s.PropertyName, &s.UnitTypeName,
+			&s.Start, &s.End, &s.Status, &s.TotalPrice,
+		); err != nil {
+			return nil, err
+		}
+		stays = append(stays, s)
+	}
+	return stays, rows.Err()
+}
+
+// LifetimeRevenue sums every document issued to the guest. Credit notes carry negative
+// totals, so corrections are netted out.
+func (r *GuestRepository) LifetimeRevenue(ctx context.Context, id string) (float64, error) {
+	query := `SELECT COALESCE(SUM(total), 0) FROM invoices WHERE guest_id = $1`
+	var total float64
+	if err := r.db.QueryRow(ctx, query, id).Scan(&total); err != nil {
+		return 0, fmt.Errorf("sum guest revenue: %w", err)
+	}
+	return total, nil
+}
+
+func (r *GuestRepository) CreateNote(ctx context.Context, note entity.GuestNote) error {
+	query := `
+		INSERT INTO guest_notes (id, guest_id, author_id, body, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+	`
+	if _, err := r.db.Exec(ctx, query, note.ID, note.GuestID, note.AuthorID, note.Body); err != nil {
+		return fmt.Errorf("create guest note: %w", err)
+	}
 	return nil
 }
+
+func (r *GuestRepository) ListNotes(ctx context.Context, guestID string) ([]entity.GuestNote, error) {
+	query := `
+		SELECT id, guest_id, author_id, body, created_at, updated_at
+		FROM guest_notes
+		WHERE guest_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, guestID)
+	if err != nil {
+		return nil, fmt.Errorf("list guest notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []entity.GuestNote
+	for rows.Next() {
+		var n entity.GuestNote
+		if err := rows.Scan(&n.ID, &n.GuestID, &n.AuthorID, &n.Body, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+func (r *GuestRepository) ReassignNotes(ctx context.Context, tx pgx.Tx, fromID, toID string) (int, error) {
+	cmd, err := tx.Exec(ctx, `UPDATE guest_notes SET guest_id = $2 WHERE guest_id = $1`, fromID, toID)
+	if err != nil {
+		return 0, fmt.Errorf("reassign guest notes: %w", err)
+	}
+	return int(cmd.RowsAffected()), nil
+}
+
+func (r *GuestRepository) CreateMerge(ctx context.Context, tx pgx.Tx, m *entity.GuestMerge) error {
+	query := `
+		INSERT INTO guest_merges (id, organization_id, survivor_id, merged_id, merged_by, merged_snapshot,
+			reservations_moved, invoices_moved, notes_moved, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING created_at
+	`
+	snapshot, err := r.sealSnapshot(m.MergedSnapshot)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, query, m.ID, m.OrganizationID, m.SurvivorID, m.MergedID, m.MergedBy, snapshot,
+		m.ReservationsMoved, m.InvoicesMoved, m.NotesMoved,
+	).Scan(&m.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("record guest merge: %w", err)
+	}
+	return nil
+}
+
+// sealSnapshot encrypts a merge snapshot. It holds the retired profile's personal data, so it
+// is stored sealed as a JSON string.
+func (r *GuestRepository) sealSnapshot(snapshot json.RawMessage) ([]byte, error) {
+	sealed, err := r.keys.Encrypt(string(snapshot))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt merge snapshot: %w", err)
+	}
+	return json.Marshal(sealed)
+}
+
+// ListStaleMergeSnapshots locks up to limit merge records whose snapshot is still plaintext or
+// sealed with a key other than the active one, and returns them with the snapshot opened.
+// Snapshots redacted by an erasure are skipped.
+func (r *GuestRepository) ListStaleMergeSnapshots(ctx context.Context, tx pgx.Tx, limit int) ([]entity.GuestMerge, error) {
+	query := `
+		SELECT id, merged_snapshot
+		FROM guest_merges
+		WHERE merged_snapshot <> '{}'::jsonb
+			AND (jsonb_typeof(merged_snapshot) <> 'string' OR merged_snapshot #>> '{}' NOT LIKE $1)
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.Query(ctx, query, fmt.Sprintf("v%d:%%", r.keys.ActiveVersion()), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list stale merge snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var merges []entity.GuestMerge
+	for rows.Next() {
+		var m entity.GuestMerge
+		var raw []byte
+		if err := rows.Scan(&m.ID, &raw); err != nil {
+			return nil, err
+		}
+		m.MergedSnapshot = raw
+
+		var sealed string
+		if json.Unmarshal(raw, &sealed) == nil {
+			plain, err := r.keys.Decrypt(sealed)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt merge snapshot %s: %w", m.ID, err)
+			}
+			m.MergedSnapshot = json.RawMessage(plain)
+		}
+		merges = append(merges, m)
+	}
+	return merges, rows.Err()
+}
+
+// ResealMergeSnapshot writes the merge snapshot again with the active key.
+func (r *GuestRepository) ResealMergeSnapshot(ctx context.Context, tx pgx.Tx, m entity.GuestMerge) error {
+	snapshot, err := r.sealSnapshot(m.MergedSnapshot)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE guest_merges SET merged_snapshot = $2 WHERE id = $1`, m.ID, snapshot); err != nil {
+		return fmt.Errorf("reseal merge snapshot: %w", err)
+	}
+	return nil
+}
+
+// Anonymize replaces the guest's personal data with placeholders and retires the profile.
+// The row itself stays so reservations and issued documents keep their references.
+func (r *GuestRepository) Anonymize(ctx context.Context, tx pgx.Tx, id, placeholderEmail string) error {
+	sealed, err := r.seal(entity.Guest{Email: placeholderEmail, FirstName: "Erased", LastName: "Guest"})
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE guests
+		SET email = $2, email_index = $3, search_index = '{}', match_index = '{}', first_name = $4, last_name = $5, phone = NULL, language = NULL,
+			pii_key_version = $6, anonymized_at = NOW(), deleted_at = COALESCE(deleted_at, NOW())
+		WHERE id = $1 AND anonymized_at IS NULL
+	`
+	cmd, err := tx.Exec(ctx, query, id, sealed.email, sealed.emailIndex, sealed.firstName, sealed.lastName, sealed.keyVersion)
+	if err != nil {
+		return fmt.Errorf("anonymize guest: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM guest_notes WHERE guest_id = $1`, id); err != nil {
+		return fmt.Errorf("delete guest notes: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE guest_merges SET merged_snapshot = '{}'::jsonb WHERE survivor_id = $1 OR merged_id = $1`, id); err != nil {
+		return fmt.Errorf("redact guest merges: %w", err)
+	}
+	return nil
+}
+
+// ListExpired returns guests of the organization not yet anonymised, deleted ones included, with
+// no open reservation whose last stay ended, or who were created, more than retentionDays ago.
+// Merged duplicates are left out: they are erased together with their survivor.
+func (r *GuestRepository) ListExpired(ctx context.Context, orgID string, retentionDays int) ([]string, error) {
+	query := `
+		SELECT g.id
+		FROM guests g
+		LEFT JOIN reservations r ON r.guest_id = g.id AND r.deleted_at IS NULL
+		WHERE g.organization_id = $1 AND g.anonymized_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM guest_merges m WHERE m.merged_id = g.id)
+		GROUP BY g.id, g.created_at
+		HAVING COUNT(r.id) FILTER (WHERE r.status IN ('confirmed', 'checked_in')) = 0
+			AND COALESCE(MAX(upper(r.stay_range)), g.created_at::date) < CURRENT_DATE - $2::INT
+	`
+	rows, err := r.db.Query(ctx, query, orgID, retentionDays)
+	if err != nil {
+		return nil, fmt.Errorf("list expired guests: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -21,10 +21,10 @@ func NewHotelServiceRepository(db *pgxpool.Pool) *HotelServiceRepository {
 
 func (r *HotelServiceRepository) Create(ctx context.Context, s entity.HotelService) error {
 	query := `
-		INSERT INTO hotel_services (id, name, description, icon, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		INSERT INTO hotel_services (id, organization_id, name, description, icon, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 	`
-	_, err := r.db.Exec(ctx, query, s.ID, s.Name, s.Description, s.Icon)
+	_, err := r.db.Exec(ctx, query, s.ID, s.OrganizationID, s.Name, s.Description, s.Icon)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -35,10 +35,10 @@ func (r *HotelServiceRepository) Create(ctx context.Context, s entity.HotelServi
 	return nil
 }
 
-func (r *HotelServiceRepository) GetAll(ctx context.Context, pagination entity.PaginationRequest) ([]entity.HotelService, int64, error) {
-	countQuery := `SELECT COUNT(*) FROM hotel_services WHERE deleted_at IS NULL`
+func (r *HotelServiceRepository) GetAll(ctx context.Context, orgID string, pagination entity.PaginationRequest) ([]entity.HotelService, int64, error) {
+	countQuery := `SELECT COUNT(*) FROM hotel_services WHERE deleted_at IS NULL AND (organization_id IS NULL OR organization_id = $1)`
 	var total int64
-	if err := r.db.QueryRow(ctx, countQuery).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, countQuery, catalogScope(orgID)).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count hotel services: %w", err)
 	}
 
@@ -47,21 +47,22 @@ func (r *HotelServiceRepository) GetAll(ctx context.Context, pagination entity.P
 
 	if pagination.Unlimited {
 		query = `
-			SELECT id, name, description, icon 
+			SELECT id, organization_id, name, description, icon 
 			FROM hotel_services 
-			WHERE deleted_at IS NULL 
+			WHERE deleted_at IS NULL AND (organization_id IS NULL OR organization_id = $1)
 			ORDER BY name ASC
 		`
+		args = append(args, catalogScope(orgID))
 	} else {
 		query = `
-			SELECT id, name, description, icon 
+			SELECT id, organization_id, name, description, icon 
 			FROM hotel_services 
-			WHERE deleted_at IS NULL 
+			WHERE deleted_at IS NULL AND (organization_id IS NULL OR organization_id = $1)
 			ORDER BY name ASC
-			LIMIT $1 OFFSET $2
+			LIMIT $2 OFFSET $3
 		`
 		offset := (pagination.Page - 1) * pagination.Limit
-		args = append(args, pagination.Limit, offset)
+		args = append(args, catalogScope(orgID), pagination.Limit, offset)
 	}
 
 	rows, err := r.db.Query(ctx, query, args...)
@@ -73,7 +74,7 @@ func (r *HotelServiceRepository) GetAll(ctx context.Context, pagination entity.P
 	var list []entity.HotelService
 	for rows.Next() {
 		var s entity.HotelService
-		if err := rows.Scan(&s.ID, &s.Name, &s.Description, &s.Icon); err != nil {
+		if err := rows.Scan(&s.ID, &s.OrganizationID, &s.Name, &s.Description, &s.Icon); err != nil {
 			return nil, 0, err
 		}
 		list = append(list, s)
@@ -81,10 +82,13 @@ func (r *HotelServiceRepository) GetAll(ctx context.Context, pagination entity.P
 	return list, total, nil
 }
 
-func (r *HotelServiceRepository) GetByID(ctx context.Context, id string) (*entity.HotelService, error) {
-	query := `SELECT id, name, description, icon FROM hotel_services WHERE id = $1 AND deleted_at IS NULL`
+func (r *HotelServiceRepository) GetByID(ctx context.Context, id, orgID string) (*entity.HotelService, error) {
+	query := `
+		SELECT id, organization_id, name, description, icon FROM hotel_services
+		WHERE id = $1 AND deleted_at IS NULL AND (organization_id IS NULL OR organization_id = $2)
+	`
 	var s entity.HotelService
-	err := r.db.QueryRow(ctx, query, id).Scan(&s.ID, &s.Name, &s.Description, &s.Icon)
+	err := r.db.QueryRow(ctx, query, id, catalogScope(orgID)).Scan(&s.ID, &s.OrganizationID, &s.Name, &s.Description, &s.Icon)
 	
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -95,6 +99,16 @@ func (r *HotelServiceRepository) GetByID(ctx context.Context, id string) (*entit
 	return &s, nil
 }
 
+// GlobalNameExists reports whether a live global entry already uses name.
+func (r *HotelServiceRepository) GlobalNameExists(ctx context.Context, name string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM hotel_services WHERE organization_id IS NULL AND name = $1 AND deleted_at IS NULL)`
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check service name: %w", err)
+	}
+	return exists, nil
+}
+
 func (r *HotelServiceRepository) Update(ctx context.Context, id string, req entity.UpdateCatalogRequest) error {
 	query := `
 		UPDATE hotel_services 
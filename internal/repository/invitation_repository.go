@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type InvitationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewInvitationRepository(db *pgxpool.Pool) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+const invitationColumns = `
+	id, organization_id, email, role, property_ids::text[], COALESCE(language, ''), secret,
+	invited_by, expires_at, accepted_at, revoked_at, created_at, updated_at
+`
+
+func scanInvitation(row pgx.Row) (*entity.Invitation, error) {
+	var inv entity.Invitation
+	err := row.Scan(
+		&inv.ID, &inv.OrganizationID, &inv.Email, &inv.Role, &inv.PropertyIDs, &inv.Language, &inv.Secret,
+		&inv.InvitedBy, &inv.ExpiresAt, &inv.AcceptedAt, &inv.RevokedAt, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inv.ResolveStatus(time.Now())
+	return &inv, nil
+}
+
+func (r *InvitationRepository) Create(ctx context.Context, tx pgx.Tx, inv entity.Invitation) error {
+	query := `
+		INSERT INTO organization_invitations (
+			id, organization_id, email, role, property_ids, language, secret, invited_by, expires_at, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5::uuid[], NULLIF($6, ''), $7, $8, $9, NOW(), NOW())
+	`
+	propertyIDs := inv.PropertyIDs
+	if propertyIDs == nil {
+		propertyIDs = []string{}
+	}
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+
+	_, err := querier.Exec(ctx, query, inv.ID, inv.OrganizationID, inv.Email, inv.Role, propertyIDs, inv.Language, inv.Secret, inv.InvitedBy, inv.ExpiresAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return entity.ErrConflict
+		}
+		return fmt.Errorf("create invitation: %w", err)
+	}
+	return nil
+}
+
+// ListByOrganization returns every invitation of the organization, newest first.
+func (r *InvitationRepository) ListByOrganization(ctx context.Context, orgID string) ([]entity.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM organization_invitations WHERE organization_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.Invitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *inv)
+	}
+	return list, rows.Err()
+}
+
+func (r *InvitationRepository) GetByID(ctx context.Context, id string) (*entity.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM organization_invitations WHERE id = $1 AND ($2::uuid IS NULL OR organization_id = $2)`
+	inv, err := scanInvitation(r.db.QueryRow(ctx, query, id, tenantArg(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get invitation: %w", err)
+	}
+	return inv, nil
+}
+
+// Renew gives an open invitation a new secret and expiry, invalidating the links sent before.
+func (r *InvitationRepository) Renew(ctx context.Context, id, secret string, expiresAt time.Time) error {
+	query := `
+		UPDATE organization_invitations SET secret = $2, expires_at = $3, updated_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	cmd, err := r.db.Exec(ctx, query, id, secret, expiresAt)
+	if err != nil {
+		return fmt.Errorf("renew invitation: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *InvitationRepository) Revoke(ctx context.Context, id string) error {
+	query := `
+		UPDATE organization_invitations SET revoked_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	cmd, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("revoke invitation: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarkAccepted closes an open invitation. It fails with ErrRecordNotFound when the invitation
+// was accepted or revoked in the meantime.
+func (r *InvitationRepository) MarkAccepted(ctx context.Context, tx pgx.Tx, id string) error {
+	query := `
+		UPDATE organization_invitations SET accepted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+	`
+	cmd, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("accept invitation: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
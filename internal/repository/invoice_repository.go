@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type InvoiceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewInvoiceRepository(db *pgxpool.Pool) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+// NextNumber reserves the next sequence of a property's series. The series row stays
+// locked until the transaction ends, so a rollback releases the number and keeps the series gapless.
+func (r *InvoiceRepository) NextNumber(ctx context.Context, tx pgx.Tx, propertyID, documentType, defaultPrefix string) (string, int, error) {
+	ensureQuery := `
+		INSERT INTO invoice_series (property_id, document_type, prefix, next_number, created_at, updated_at)
+		VALUES ($1, $2, $3, 1, NOW(), NOW())
+		ON CONFLICT (property_id, document_type) DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, ensureQuery, propertyID, documentType, defaultPrefix); err != nil {
+		return "", 0, fmt.Errorf("ensure invoice series: %w", err)
+	}
+
+	query := `
+		UPDATE invoice_series
+		SET next_number = next_number + 1
+		WHERE property_id = $1 AND document_type = $2
+		RETURNING prefix, next_number - 1
+	`
+	var prefix string
+	var sequence int
+	if err := tx.QueryRow(ctx, query, propertyID, documentType).Scan(&prefix, &sequence); err != nil {
+		return "", 0, fmt.Errorf("reserve invoice number: %w", err)
+	}
+	return prefix, sequence, nil
+}
+
+func (r *InvoiceRepository) Create(ctx context.Context, tx pgx.Tx, inv entity.Invoice) error {
+	query := `
+		INSERT INTO invoices (
+			id, property_id, reservation_id, guest_id, document_type, number, sequence,
+			corrects_invoice_id, reason, customer_name, customer_email,
+			currency, tax_rate, subtotal, tax_amount, total,
+			issued_at, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, NOW(), NOW())
+	`
+	_, err := tx.Exec(ctx, query,
+		inv.ID, inv.PropertyID, inv.ReservationID, inv.GuestID, inv.DocumentType, inv.Number, inv.Sequence,
+		inv.CorrectsInvoiceID, inv.Reason, inv.CustomerName, inv.CustomerEmail,
+		inv.Currency, inv.TaxRate, inv.Subtotal, inv.TaxAmount, inv.Total,
+		inv.IssuedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return entity.ErrConflict
+		}
+		return fmt.Errorf("create invoice: %w", err)
+	}
+
+	lineQuery := `
+		INSERT INTO invoice_lines (
+			id, invoice_id, position, description, quantity, unit_price,
+			net_amount, tax_amount, total, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+	`
+	for _, line := range inv.Lines {
+		_, err := tx.Exec(ctx, lineQuery,
+			line.ID, inv.ID, line.Position, line.Description, line.Quantity, line.UnitPrice,
+			line.NetAmount, line.TaxAmount, line.Total,
+		)
+		if err != nil {
+			return fmt.Errorf("create invoice line: %w", err)
+		}
+	}
+	return nil
+}
+
+const invoiceColumns = `
+	id, property_id, reservation_id, guest_id, document_type, number, sequence,
+	corrects_invoice_id, reason, customer_name, customer_email,
+	currency, tax_rate, subtotal, tax_amount, total,
+	issued_at, created_at, updated_at
+`
+
+func scanInvoice(row pgx.Row, inv *entity.Invoice) error {
+	return row.Scan(
+		&inv.ID, &inv.PropertyID, &inv.ReservationID, &inv.GuestID, &inv.DocumentType, &inv.Number, &inv.Sequence,
+		&inv.CorrectsInvoiceID, &inv.Reason, &inv.CustomerName, &inv.CustomerEmail,
+		&inv.Currency, &inv.TaxRate, &inv.Subtotal, &inv.TaxAmount, &inv.Total,
+		&inv.IssuedAt, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+}
+
+func (r *InvoiceRepository) GetByID(ctx context.Context, id string) (*entity.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + ` FROM invoices
+		WHERE id = $1 AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
+	`
+	var inv entity.Invoice
+	if err := scanInvoice(r.db.QueryRow(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx)), &inv); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get invoice: %w", err)
+	}
+
+	lines, err := r.getLines(ctx, inv.ID)
+	if err != nil {
+		return nil, err
+	}
+	inv.Lines = lines
+	return &inv, nil
+}
+
+func (r *InvoiceRepository) getLines(ctx context.Context, invoiceID string) ([]entity.InvoiceLine, error) {
+	query := `
+		SELECT id, position, description, quantity, unit_price, net_amount, tax_amount, total
+		FROM invoice_lines
+		WHERE invoice_id = $1
+		ORDER BY position ASC
+	`
+	rows, err := r.db.Query(ctx, query, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("list invoice lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []entity.InvoiceLine{}
+	for rows.Next() {
+		var l entity.InvoiceLine
+		if err := rows.Scan(&l.ID, &l.Position, &l.Description, &l.Quantity, &l.UnitPrice, &l.NetAmount, &l.TaxAmount, &l.Total); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+func (r *InvoiceRepository) ListByProperty(ctx context.Context, propertyID string, pagination entity.PaginationRequest) ([]entity.Invoice, int64, error) {
+	countQuery := `
+		SELECT COUNT(*) FROM invoices
+		WHERE property_id = $1 AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
+	`
+	var total int64
+	if err := r.db.QueryRow(ctx, countQuery, propertyID, tenantArg(ctx), propertyScopeArg(ctx)).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count invoices: %w", err)
+	}
+
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices
+		WHERE property_id = $1 AND ($4::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $4 AND ($5::uuid[] IS NULL OR id = ANY($5))))
+		ORDER BY document_type ASC, sequence DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	rows, err := r.db.Query(ctx, query, propertyID, pagination.Limit, offset, tenantArg(ctx), propertyScopeArg(ctx))
+	if err != nil {
+		return nil, 0, fmt.Errorf("list invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.Invoice
+	for rows.Next() {
+		var inv entity.Invoice
+		if err := scanInvoice(rows, &inv); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, inv)
+	}
+	return list, total, nil
+}
+
+// CreditedTotal returns how much of an invoice has already been reversed by credit notes.
+// Credit note totals are stored as negative amounts.
+func (r *InvoiceRepository) CreditedTotal(ctx context.Context, tx pgx.Tx, invoiceID string) (float64, error) {
+	query := `
+		SELECT COALESCE(-SUM(total), 0)
+		FROM invoices
+		WHERE corrects_invoice_id = $1 AND document_type = 'credit_note'
+	`
+	var credited float64
+	if err := tx.QueryRow(ctx, query, invoiceID).Scan(&credited); err != nil {
+		return 0, fmt.Errorf("sum credit notes: %w", err)
+	}
+	return credited, nil
+}
+
+// LockForCorrection serializes concurrent credit notes against the same invoice.
+func (r *InvoiceRepository) LockForCorrection(ctx context.Context, tx pgx.Tx, invoiceID string) error {
+	query := `
+		SELECT id FROM invoices
+		WHERE id = $1 AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
+		FOR UPDATE
+	`
+	var id string
+	if err := tx.QueryRow(ctx, query, invoiceID, tenantArg(ctx), propertyScopeArg(ctx)).Scan(&id); err != nil {
+		if err == pgx.ErrNoRows {
+			return entity.ErrRecordNotFound
+		}
+		return fmt.Errorf("lock invoice: %w", err)
+	}
+	return nil
+}
+
+// ReassignGuest moves issued documents to another guest profile. Only the guest link
+// changes; the database rejects edits to any other invoice column.
+func (r *InvoiceRepository) ReassignGuest(ctx context.Context, tx pgx.Tx, fromID, toID string) (int, error) {
+	cmd, err := tx.Exec(ctx, `UPDATE invoices SET guest_id = $2 WHERE guest_id = $1`, fromID, toID)
+	if err != nil {
+		return 0, fmt.Errorf("reassign invoices: %w", err)
+	}
+	return int(cmd.RowsAffected()), nil
+}
+
+func (r *InvoiceRepository) ListByGuest(ctx context.Context, guestID string) ([]entity.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE guest_id = $1 ORDER BY issued_at ASC`
+	rows, err := r.db.Query(ctx, query, guestID)
+	if err != nil {
+		return nil, fmt.Errorf("list guest invoices: %w", err)
+	}
+
+	var list []entity.Invoice
+	for rows.Next() {
+		var inv entity.Invoice
+		if err := scanInvoice(rows, &inv); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, inv)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range list {
+		if list[i].Lines, err = r.getLines(ctx, list[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
@@ -87,6 +87,16 @@ func (r *OrganizationRepository) GetAll(ctx context.Context, pagination entity.P
 	return list, total, nil
 }
 
+// NameExists reports whether an active organization already uses name, ignoring case and surrounding spaces.
+func (r *OrganizationRepository) NameExists(ctx context.Context, name string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM organizations WHERE lower(btrim(name)) = lower(btrim($1)) AND deleted_at IS NULL)`
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check org name: %w", err)
+	}
+	return exists, nil
+}
+
 func (r *OrganizationRepository) GetByID(ctx context.Context, id string) (*entity.Organization, error) {
 	query := `
 		SELECT id, name, code, created_at, updated_at 
@@ -108,6 +118,10 @@ func (r *OrganizationRepository) Update(ctx context.Context, id string, req enti
 	query := `UPDATE organizations SET name = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 	cmd, err := r.db.Exec(ctx, query, id, req.Name)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return entity.ErrConflict
+		}
 		return fmt.Errorf("update org: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
@@ -150,15 +164,217 @@ func (r *OrganizationRepository) AddMember(ctx context.Context, tx pgx.Tx, membe
 	return nil
 }
 
-func (r *OrganizationRepository) GetUserOrganization(ctx context.Context, userID string) (string, error) {
-	var orgID string
-	query := `SELECT organization_id FROM organization_members WHERE user_id = $1 LIMIT 1`
-	err := r.db.QueryRow(ctx, query, userID).Scan(&orgID)
+// SetMemberProperties replaces the properties a member is restricted to. The properties
+// must belong to the organization; an empty list lifts the restriction. Deleting a property
+// later only narrows the restriction, it never lifts it.
+func (r *OrganizationRepository) SetMemberProperties(ctx context.Context, tx pgx.Tx, orgID, userID string, propertyIDs []string) error {
+	var memberID string
+	err := tx.QueryRow(ctx, `
+		UPDATE organization_members SET all_properties = $3, updated_at = NOW()
+		WHERE user_id = $1 AND organization_id = $2
+		RETURNING id
+	`, userID, orgID, len(propertyIDs) == 0).Scan(&memberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ErrRecordNotFound
+		}
+		return fmt.Errorf("find member: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM organization_member_properties WHERE member_id = $1`, memberID); err != nil {
+		return fmt.Errorf("clear member properties: %w", err)
+	}
+	if len(propertyIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO organization_member_properties (member_id, property_id)
+		SELECT $1, id FROM properties
+		WHERE organization_id = $2 AND id = ANY($3::uuid[]) AND deleted_at IS NULL
+	`
+	cmd, err := tx.Exec(ctx, query, memberID, orgID, propertyIDs)
+	if err != nil {
+		return fmt.Errorf("set member properties: %w", err)
+	}
+	if int(cmd.RowsAffected()) != len(propertyIDs) {
+		return fmt.Errorf("%w: unknown property", entity.ErrInvalidInput)
+	}
+	return nil
+}
+
+// GetMember returns a user's membership in an organization, including the properties the
+// member is restricted to.
+func (r *OrganizationRepository) GetMember(ctx context.Context, userID, orgID string) (*entity.OrganizationMember, error) {
+	query := `
+		SELECT om.id, om.organization_id, om.user_id, om.role, om.all_properties,
+		       ARRAY(SELECT mp.property_id::text FROM organization_member_properties mp WHERE mp.member_id = om.id ORDER BY mp.property_id)
+		FROM organization_members om
+		JOIN organizations o ON o.id = om.organization_id
+		WHERE om.user_id = $1 AND om.organization_id = $2 AND o.deleted_at IS NULL
+	`
+	var m entity.OrganizationMember
+	err := r.db.QueryRow(ctx, query, userID, orgID).Scan(&m.ID, &m.OrganizationID, &m.UserID, &m.Role, &m.AllProperties, &m.PropertyIDs)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get member: %w", err)
+	}
+	return &m, nil
+}
+
+// OwnsProperties reports whether every one of the properties belongs to the organization.
+func (r *OrganizationRepository) OwnsProperties(ctx context.Context, orgID string, propertyIDs []string) (bool, error) {
+	query := `SELECT COUNT(*) FROM properties WHERE organization_id = $1 AND id = ANY($2::uuid[]) AND deleted_at IS NULL`
+	var count int
+	if err := r.db.QueryRow(ctx, query, orgID, propertyIDs).Scan(&count); err != nil {
+		return false, fmt.Errorf("check org properties: %w", err)
+	}
+	return count == len(propertyIDs), nil
+}
+
+// IsMemberEmail reports whether a user with this email already belongs to the organization.
+func (r *OrganizationRepository) IsMemberEmail(ctx context.Context, orgID, email string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM organization_members om
+			JOIN users u ON u.id = om.user_id
+			WHERE om.organization_id = $1 AND lower(u.email) = lower($2) AND u.deleted_at IS NULL
+		)
+	`
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, orgID, email).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check member email: %w", err)
+	}
+	return exists, nil
+}
+
+// RemoveMember takes a user out of the organization. The account itself is left alone, since
+// it may belong to other organizations too.
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, orgID, userID string) error {
+	query := `DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+	cmd, err := r.db.Exec(ctx, query, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("remove member: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+// HasOtherMemberships reports whether the user also belongs to an organization other than orgID.
+func (r *OrganizationRepository) HasOtherMemberships(ctx context.Context, tx pgx.Tx, userID, orgID string) (bool, error) {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM organization_members om
+			JOIN organizations o ON o.id = om.organization_id
+			WHERE om.user_id = $1 AND om.organization_id <> $2 AND o.deleted_at IS NULL
+		)
+	`
+	var exists bool
+	if err := querier.QueryRow(ctx, query, userID, orgID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check other memberships: %w", err)
+	}
+	return exists, nil
+}
+
+// ListUserMemberships returns the organizations a user belongs to, oldest membership first.
+func (r *OrganizationRepository) ListUserMemberships(ctx context.Context, userID string) ([]entity.Membership, error) {
+	query := `
+		SELECT o.id, o.name, o.code, om.role
+		FROM organization_members om
+		JOIN organizations o ON o.id = om.organization_id
+		WHERE om.user_id = $1 AND o.deleted_at IS NULL
+		ORDER BY om.created_at, o.name
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list memberships: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := []entity.Membership{}
+	for rows.Next() {
+		var m entity.Membership
+		if err := rows.Scan(&m.OrganizationID, &m.Name, &m.Code, &m.Role); err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, m)
+	}
+	return memberships, rows.Err()
+}
+
+func (r *OrganizationRepository) GetRetention(ctx context.Context, id string) (*int, error) {
+	query := `SELECT guest_retention_days FROM organizations WHERE id = $1 AND deleted_at IS NULL`
+	var days *int
+	if err := r.db.QueryRow(ctx, query, id).Scan(&days); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get retention: %w", err)
+	}
+	return days, nil
+}
+
+func (r *OrganizationRepository) SetRetention(ctx context.Context, id string, days *int) error {
+	query := `UPDATE organizations SET guest_retention_days = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	cmd, err := r.db.Exec(ctx, query, id, days)
 	if err != nil {
+		return fmt.Errorf("set retention: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListRetentionPolicies maps every organization with a retention period to its days.
+func (r *OrganizationRepository) ListRetentionPolicies(ctx context.Context) (map[string]int, error) {
+	query := `SELECT id, guest_retention_days FROM organizations WHERE guest_retention_days IS NOT NULL AND deleted_at IS NULL`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := map[string]int{}
+	for rows.Next() {
+		var id string
+		var days int
+		if err := rows.Scan(&id, &days); err != nil {
+			return nil, err
+		}
+		policies[id] = days
+	}
+	return policies, rows.Err()
+}
+
+func (r *OrganizationRepository) GetTwoFactorRequired(ctx context.Context, id string) (bool, error) {
+	query := `SELECT require_two_factor FROM organizations WHERE id = $1 AND deleted_at IS NULL`
+	var required bool
+	if err := r.db.QueryRow(ctx, query, id).Scan(&required); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", nil 
+			return false, entity.ErrRecordNotFound
 		}
-		return "", fmt.Errorf("fetch user org: %w", err)
+		return false, fmt.Errorf("get two-factor policy: %w", err)
+	}
+	return required, nil
+}
+
+func (r *OrganizationRepository) SetTwoFactorRequired(ctx context.Context, id string, required bool) error {
+	query := `UPDATE organizations SET require_two_factor = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	cmd, err := r.db.Exec(ctx, query, id, required)
+	if err != nil {
+		return fmt.Errorf("set two-factor policy: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
 	}
-	return orgID, nil
+	return nil
 }
@@ -92,9 +92,10 @@ func (r *PriceRepository) ListByUnitType(ctx context.Context, unitTypeID string,
 		SELECT COUNT(*)
 		FROM price_rules
 		WHERE unit_type_id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR unit_type_id IN (SELECT ut.id FROM unit_types ut JOIN properties p ON p.id = ut.property_id WHERE p.organization_id = $2 AND ($3::uuid[] IS NULL OR p.id = ANY($3))))
 	`
 	var total int64
-	if err := r.db.QueryRow(ctx, countQuery, unitTypeID).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, countQuery, unitTypeID, tenantArg(ctx), propertyScopeArg(ctx)).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count price rules: %w", err)
 	}
 
@@ -102,13 +103,14 @@ func (r *PriceRepository) ListByUnitType(ctx context.Context, unitTypeID string,
 		SELECT id, unit_type_id, LOWER(validity_range), UPPER(validity_range), price, created_at, updated_at
 		FROM price_rules
 		WHERE unit_type_id = $1 AND deleted_at IS NULL
+		  AND ($4::uuid IS NULL OR unit_type_id IN (SELECT ut.id FROM unit_types ut JOIN properties p ON p.id = ut.property_id WHERE p.organization_id = $4 AND ($5::uuid[] IS NULL OR p.id = ANY($5))))
 		ORDER BY validity_range ASC
 		LIMIT $2 OFFSET $3
 	`
 	
 	offset := (pagination.Page - 1) * pagination.Limit
 	
-	rows, err := r.db.Query(ctx, query, unitTypeID, pagination.Limit, offset)
+	rows, err := r.db.Query(ctx, query, unitTypeID, pagination.Limit, offset, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil { return nil, 0, fmt.Errorf("list: %w", err) }
 	defer rows.Close()
 
@@ -123,9 +125,17 @@ func (r *PriceRepository) ListByUnitType(ctx context.Context, unitTypeID string,
 	return rules, total, nil
 }
 
-func (r *PriceRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE price_rules SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
-	cmd, err := r.db.Exec(ctx, query, id)
+func (r *PriceRepository) Delete(ctx context.Context, tx pgx.Tx, id string) error {
+	query := `
+		UPDATE price_rules SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR unit_type_id IN (SELECT ut.id FROM unit_types ut JOIN properties p ON p.id = ut.property_id WHERE p.organization_id = $2 AND ($3::uuid[] IS NULL OR p.id = ANY($3))))
+	`
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	cmd, err := querier.Exec(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return fmt.Errorf("delete price rule: %w", err)
 	}
@@ -136,9 +146,13 @@ func (r *PriceRepository) Delete(ctx context.Context, id string) error {
 }
 
 func (r *PriceRepository) GetByID(ctx context.Context, id string) (*entity.PriceRule, error) {
-	query := `SELECT id, unit_type_id, LOWER(validity_range), UPPER(validity_range), price FROM price_rules WHERE id=$1 AND deleted_at IS NULL`
+	query := `
+		SELECT id, unit_type_id, LOWER(validity_range), UPPER(validity_range), price FROM price_rules
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR unit_type_id IN (SELECT ut.id FROM unit_types ut JOIN properties p ON p.id = ut.property_id WHERE p.organization_id = $2 AND ($3::uuid[] IS NULL OR p.id = ANY($3))))
+	`
 	var pr entity.PriceRule
-	err := r.db.QueryRow(ctx, query, id).Scan(&pr.ID, &pr.UnitTypeID, &pr.Start, &pr.End, &pr.Price)
+	err := r.db.QueryRow(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx)).Scan(&pr.ID, &pr.UnitTypeID, &pr.Start, &pr.End, &pr.Price)
 	if err != nil { 
 		if err == pgx.ErrNoRows { return nil, entity.ErrRecordNotFound }
 		return nil, err 
@@ -152,9 +166,10 @@ func (r *PriceRepository) ListByProperty(ctx context.Context, propertyID string,
 		FROM price_rules pr
 		JOIN unit_types ut ON pr.unit_type_id = ut.id
 		WHERE ut.property_id = $1 AND ut.deleted_at IS NULL AND pr.deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR ut.property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
 	`
 	var total int64
-	if err := r.db.QueryRow(ctx, countQuery, propertyID).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, countQuery, propertyID, tenantArg(ctx), propertyScopeArg(ctx)).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count price rules: %w", err)
 	}
 
@@ -163,13 +178,14 @@ func (r *PriceRepository) ListByProperty(ctx context.Context, propertyID string,
 		FROM price_rules pr
 		JOIN unit_types ut ON pr.unit_type_id = ut.id
 		WHERE ut.property_id = $1 AND ut.deleted_at IS NULL AND pr.deleted_at IS NULL
+		  AND ($4::uuid IS NULL OR ut.property_id IN (SELECT id FROM properties WHERE organization_id = $4 AND ($5::uuid[] IS NULL OR id = ANY($5))))
 		ORDER BY pr.validity_range ASC
 		LIMIT $2 OFFSET $3
 	`
 	
 	offset := (pagination.Page - 1) * pagination.Limit
 	
-	rows, err := r.db.Query(ctx, query, propertyID, pagination.Limit, offset)
+	rows, err := r.db.Query(ctx, query, propertyID, pagination.Limit, offset, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return nil, 0, fmt.Errorf("list by property: %w", err)
 	}
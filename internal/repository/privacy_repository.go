@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type PrivacyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPrivacyRepository(db *pgxpool.Pool) *PrivacyRepository {
+	return &PrivacyRepository{db: db}
+}
+
+func (r *PrivacyRepository) LogRequest(ctx context.Context, tx pgx.Tx, req *entity.PrivacyRequest) error {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+
+	query := `
+		INSERT INTO privacy_requests (id, organization_id, guest_id, request_type, source, requested_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at
+	`
+	err := querier.QueryRow(ctx, query, req.ID, req.OrganizationID, req.GuestID, req.RequestType, req.Source, req.RequestedBy).Scan(&req.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("log privacy request: %w", err)
+	}
+	return nil
+}
+
+func (r *PrivacyRepository) ListByOrganization(ctx context.Context, orgID string, pagination entity.PaginationRequest) ([]entity.PrivacyRequest, int64, error) {
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM privacy_requests WHERE organization_id = $1`, orgID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count privacy requests: %w", err)
+	}
+
+	query := `
+		SELECT id, organization_id, guest_id, request_type, source, requested_by, created_at
+		FROM privacy_requests
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	rows, err := r.db.Query(ctx, query, orgID, pagination.Limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list privacy requests: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.PrivacyRequest
+	for rows.Next() {
+		var p entity.PrivacyRequest
+		if err := rows.Scan(&p.ID, &p.OrganizationID, &p.GuestID, &p.RequestType, &p.Source, &p.RequestedBy, &p.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, p)
+	}
+	return list, total, nil
+}
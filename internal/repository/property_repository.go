@@ -21,12 +21,12 @@ func NewPropertyRepository(db *pgxpool.Pool) *PropertyRepository {
 
 func (r *PropertyRepository) Create(ctx context.Context, p entity.Property) (string, error) {
 	query := `
-		INSERT INTO properties (id, organization_id, name, code, type, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		INSERT INTO properties (id, organization_id, name, code, type, tax_rate, default_language, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
 		RETURNING id
 	`
 	var id string
-	err := r.db.QueryRow(ctx, query, p.ID, p.OrganizationID, p.Name, p.Code, p.Type).Scan(&id)
+	err := r.db.QueryRow(ctx, query, p.ID, p.OrganizationID, p.Name, p.Code, p.Type, p.TaxRate, p.DefaultLanguage).Scan(&id)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -41,24 +41,24 @@ func (r *PropertyRepository) ListByOrganization(ctx context.Context, orgID strin
 	countQuery := `
 		SELECT COUNT(*)
 		FROM properties
-		WHERE organization_id = $1 AND deleted_at IS NULL
+		WHERE organization_id = $1 AND deleted_at IS NULL AND ($2::uuid IS NULL OR organization_id = $2) AND ($3::uuid[] IS NULL OR id = ANY($3))
 	`
 	var total int64
-	if err := r.db.QueryRow(ctx, countQuery, orgID).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, countQuery, orgID, tenantArg(ctx), propertyScopeArg(ctx)).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count properties: %w", err)
 	}
 
 	query := `
-		SELECT id, organization_id, name, code, type, created_at, updated_at 
+		SELECT id, organization_id, name, code, type, tax_rate, default_language, created_at, updated_at 
 		FROM properties 
-		WHERE organization_id = $1 AND deleted_at IS NULL
+		WHERE organization_id = $1 AND deleted_at IS NULL AND ($4::uuid IS NULL OR organization_id = $4) AND ($5::uuid[] IS NULL OR id = ANY($5))
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 	
 	offset := (pagination.Page - 1) * pagination.Limit
 	
-	rows, err := r.db.Query(ctx, query, orgID, pagination.Limit, offset)
+	rows, err := r.db.Query(ctx, query, orgID, pagination.Limit, offset, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return nil, 0, fmt.Errorf("list properties: %w", err)
 	}
@@ -67,7 +67,7 @@ func (r *PropertyRepository) ListByOrganization(ctx context.Context, orgID strin
 	var properties []entity.Property
 	for rows.Next() {
 		var p entity.Property
-		if err := rows.Scan(&p.ID, &p.OrganizationID, &p.Name, &p.Code, &p.Type, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.OrganizationID, &p.Name, &p.Code, &p.Type, &p.TaxRate, &p.DefaultLanguage, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, 0, err
 		}
 		properties = append(properties, p)
@@ -77,12 +77,12 @@ func (r *PropertyRepository) ListByOrganization(ctx context.Context, orgID strin
 
 func (r *PropertyRepository) GetByID(ctx context.Context, id string) (*entity.Property, error) {
 	query := `
-		SELECT id, organization_id, name, code, type, created_at, updated_at 
+		SELECT id, organization_id, name, code, type, tax_rate, default_language, created_at, updated_at 
 		FROM properties 
-		WHERE id = $1 AND deleted_at IS NULL
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::uuid IS NULL OR organization_id = $2) AND ($3::uuid[] IS NULL OR id = ANY($3))
 	`
 	var p entity.Property
-	err := r.db.QueryRow(ctx, query, id).Scan(&p.ID, &p.OrganizationID, &p.Name, &p.Code, &p.Type, &p.CreatedAt, &p.UpdatedAt)
+	err := r.db.QueryRow(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx)).Scan(&p.ID, &p.OrganizationID, &p.Name, &p.Code, &p.Type, &p.TaxRate, &p.DefaultLanguage, &p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, entity.ErrRecordNotFound
@@ -112,9 +112,19 @@ func (r *PropertyRepository) Update(ctx context.Context, id string, req entity.U
 		args = append(args, req.Type)
 		argID++
 	}
+	if req.TaxRate != nil {
+		query += fmt.Sprintf(", tax_rate = $%d", argID)
+		args = append(args, *req.TaxRate)
+		argID++
+	}
+	if req.DefaultLanguage != "" {
+		query += fmt.Sprintf(", default_language = $%d", argID)
+		args = append(args, req.DefaultLanguage)
+		argID++
+	}
 
-	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL", argID)
-	args = append(args, id)
+	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL AND ($%d::uuid IS NULL OR organization_id = $%d) AND ($%d::uuid[] IS NULL OR id = ANY($%d))", argID, argID+1, argID+1, argID+2, argID+2)
+	args = append(args, id, tenantArg(ctx), propertyScopeArg(ctx))
 
 	cmd, err := r.db.Exec(ctx, query, args...)
 	if err != nil {
@@ -127,8 +137,11 @@ func (r *PropertyRepository) Update(ctx context.Context, id string, req entity.U
 }
 
 func (r *PropertyRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE properties SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
-	cmd, err := r.db.Exec(ctx, query, id)
+	query := `
+		UPDATE properties SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::uuid IS NULL OR organization_id = $2) AND ($3::uuid[] IS NULL OR id = ANY($3))
+	`
+	cmd, err := r.db.Exec(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return fmt.Errorf("delete property: %w", err)
 	}
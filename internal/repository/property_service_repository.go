@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+const propertyServiceColumns = `
+	ps.id, ps.property_id, ps.service_id, hs.name, COALESCE(hs.description, ''), COALESCE(hs.icon, ''),
+	ps.price, ps.pricing_unit, ps.daily_limit, ps.active, ps.created_at, ps.updated_at
+`
+
+type PropertyServiceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPropertyServiceRepository(db *pgxpool.Pool) *PropertyServiceRepository {
+	return &PropertyServiceRepository{db: db}
+}
+
+func scanPropertyService(row pgx.Row) (*entity.PropertyService, error) {
+	var s entity.PropertyService
+	err := row.Scan(
+		&s.ID, &s.PropertyID, &s.ServiceID, &s.Name, &s.Description, &s.Icon,
+		&s.Price, &s.PricingUnit, &s.DailyLimit, &s.Active, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *PropertyServiceRepository) Create(ctx context.Context, s entity.PropertyService) error {
+	query := `
+		INSERT INTO property_services (id, property_id, service_id, price, pricing_unit, daily_limit, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+	`
+	_, err := r.db.Exec(ctx, query, s.ID, s.PropertyID, s.ServiceID, s.Price, s.PricingUnit, s.DailyLimit, s.Active)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return entity.ErrConflict
+		}
+		return fmt.Errorf("create property service: %w", err)
+	}
+	return nil
+}
+
+// ListByProperty returns the services a property offers; activeOnly hides those withdrawn from sale.
+func (r *PropertyServiceRepository) ListByProperty(ctx context.Context, propertyID string, activeOnly bool) ([]entity.PropertyService, error) {
+	query := `
+		SELECT ` + propertyServiceColumns + `
+		FROM property_services ps
+		JOIN hotel_services hs ON hs.id = ps.service_id
+		WHERE ps.property_id = $1 AND ps.deleted_at IS NULL AND hs.deleted_at IS NULL
+		  AND (ps.active OR NOT $2)
+		  AND ($3::uuid IS NULL OR ps.property_id IN (SELECT id FROM properties WHERE organization_id = $3 AND ($4::uuid[] IS NULL OR id = ANY($4))))
+		ORDER BY hs.name ASC
+	`
+	rows, err := r.db.Query(ctx, query, propertyID, activeOnly, tenantArg(ctx), propertyScopeArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list property services: %w", err)
+	}
+	defer rows.Close()
+
+	list := []entity.PropertyService{}
+	for rows.Next() {
+		s, err := scanPropertyService(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *s)
+	}
+	return list, rows.Err()
+}
+
+func (r *PropertyServiceRepository) GetByID(ctx context.Context, id string) (*entity.PropertyService, error) {
+	return r.getByID(ctx, r.db, id, "")
+}
+
+// GetByIDLocked locks the offer until tx ends, serialising bookings against its daily limit.
+func (r *PropertyServiceRepository) GetByIDLocked(ctx context.Context, tx pgx.Tx, id string) (*entity.PropertyService, error) {
+	return r.getByID(ctx, tx, id, "FOR UPDATE OF ps")
+}
+
+func (r *PropertyServiceRepository) getByID(ctx context.Context, querier DBTX, id, lock string) (*entity.PropertyService, error) {
+	query := `
+		SELECT ` + propertyServiceColumns + `
+		FROM property_services ps
+		JOIN hotel_services hs ON hs.id = ps.service_id
+		WHERE ps.id = $1 AND ps.deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR ps.property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
+	` + lock
+	s, err := scanPropertyService(querier.QueryRow(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get property service: %w", err)
+	}
+	return s, nil
+}
+
+func (r *PropertyServiceRepository) Update(ctx context.Context, id string, req entity.UpdatePropertyServiceRequest) error {
+	query := `UPDATE property_services SET updated_at = NOW()`
+	var args []interface{}
+	argID := 1
+	addSet := func(column string, value interface{}) {
+		query += fmt.Sprintf(", %s = $%d", column, argID)
+		args = append(args, value)
+		argID++
+	}
+
+	if req.Price != nil {
+		addSet("price", *req.Price)
+	}
+	if req.PricingUnit != "" {
+		addSet("pricing_unit", req.PricingUnit)
+	}
+	if req.DailyLimit != nil {
+		if *req.DailyLimit == 0 {
+			addSet("daily_limit", nil)
+		} else {
+			addSet("daily_limit", *req.DailyLimit)
+		}
+	}
+	if req.Active != nil {
+		addSet("active", *req.Active)
+	}
+
+	query += fmt.Sprintf(
+		" WHERE id = $%d AND deleted_at IS NULL AND ($%d::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $%d AND ($%d::uuid[] IS NULL OR id = ANY($%d))))",
+		argID, argID+1, argID+1, argID+2, argID+2,
+	)
+	args = append(args, id, tenantArg(ctx), propertyScopeArg(ctx))
+
+	cmd, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("update property service: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *PropertyServiceRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE property_services SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
+	`
+	cmd, err := r.db.Exec(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx))
+	if err != nil {
+		return fmt.Errorf("delete property service: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
@@ -41,9 +41,10 @@ func (r *RatePlanRepository) ListByProperty(ctx context.Context, propertyID stri
 		SELECT COUNT(*)
 		FROM rate_plans
 		WHERE property_id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
 	`
 	var total int64
-	if err := r.db.QueryRow(ctx, countQuery, propertyID).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, countQuery, propertyID, tenantArg(ctx), propertyScopeArg(ctx)).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count rate plans: %w", err)
 	}
 
@@ -52,13 +53,14 @@ func (r *RatePlanRepository) ListByProperty(ctx context.Context, propertyID stri
 		       meal_plan, cancellation_policy, payment_policy, active, created_at, updated_at
 		FROM rate_plans
 		WHERE property_id = $1 AND deleted_at IS NULL
+		  AND ($4::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $4 AND ($5::uuid[] IS NULL OR id = ANY($5))))
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 	
 	offset := (pagination.Page - 1) * pagination.Limit
 	
-	rows, err := r.db.Query(ctx, query, propertyID, pagination.Limit, offset)
+	rows, err := r.db.Query(ctx, query, propertyID, pagination.Limit, offset, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return nil, 0, fmt.Errorf("list rate plans: %w", err)
 	}
@@ -81,14 +83,24 @@ func (r *RatePlanRepository) ListByProperty(ctx context.Context, propertyID stri
 }
 
 func (r *RatePlanRepository) GetByID(ctx context.Context, id string) (*entity.RatePlan, error) {
+	return r.getByID(ctx, r.db, id, "")
+}
+
+// GetByIDLocked reads the plan inside tx and locks it until tx ends.
+func (r *RatePlanRepository) GetByIDLocked(ctx context.Context, tx pgx.Tx, id string) (*entity.RatePlan, error) {
+	return r.getByID(ctx, tx, id, "FOR UPDATE")
+}
+
+func (r *RatePlanRepository) getByID(ctx context.Context, querier DBTX, id, lock string) (*entity.RatePlan, error) {
 	query := `
 		SELECT id, property_id, unit_type_id, name, description, 
 		       meal_plan, cancellation_policy, payment_policy, active, created_at, updated_at
 		FROM rate_plans
 		WHERE id = $1 AND deleted_at IS NULL
-	`
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
+	` + lock
 	var rp entity.RatePlan
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := querier.QueryRow(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx)).Scan(
 		&rp.ID, &rp.PropertyID, &rp.UnitTypeID, &rp.Name, &rp.Description,
 		&rp.MealPlan, &rp.CancellationPolicy, &rp.PaymentPolicy, &rp.Active,
 		&rp.CreatedAt, &rp.UpdatedAt,
@@ -102,7 +114,7 @@ func (r *RatePlanRepository) GetByID(ctx context.Context, id string) (*entity.Ra
 	return &rp, nil
 }
 
-func (r *RatePlanRepository) Update(ctx context.Context, id string, req entity.UpdateRatePlanRequest) error {
+func (r *RatePlanRepository) Update(ctx context.Context, tx pgx.Tx, id string, req entity.UpdateRatePlanRequest) error {
 	query := `UPDATE rate_plans SET updated_at = NOW()`
 	var args []interface{}
 	argID := 1
@@ -132,10 +144,17 @@ func (r *RatePlanRepository) Update(ctx context.Context, id string, req entity.U
 		addSet("payment_policy", req.PaymentPolicy)
 	}
 
-	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL", argID)
-	args = append(args, id)
+	query += fmt.Sprintf(
+		" WHERE id = $%d AND deleted_at IS NULL AND ($%d::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $%d AND ($%d::uuid[] IS NULL OR id = ANY($%d))))",
+		argID, argID+1, argID+1, argID+2, argID+2,
+	)
+	args = append(args, id, tenantArg(ctx), propertyScopeArg(ctx))
 
-	cmd, err := r.db.Exec(ctx, query, args...)
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	cmd, err := querier.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("update rate plan: %w", err)
 	}
@@ -146,8 +165,12 @@ func (r *RatePlanRepository) Update(ctx context.Context, id string, req entity.U
 }
 
 func (r *RatePlanRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE rate_plans SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
-	cmd, err := r.db.Exec(ctx, query, id)
+	query := `
+		UPDATE rate_plans SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
+	`
+	cmd, err := r.db.Exec(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return fmt.Errorf("delete rate plan: %w", err)
 	}
@@ -185,3 +208,35 @@ func (r *RatePlanRepository) GetAll(ctx context.Context) ([]entity.RatePlan, err
 	}
 	return plans, nil
 }
+
+// ListApplicable returns the active rate plans of the property that can be sold with the unit type.
+func (r *RatePlanRepository) ListApplicable(ctx context.Context, propertyID, unitTypeID string) ([]entity.RatePlan, error) {
+	query := `
+		SELECT id, property_id, unit_type_id, name, description,
+		       meal_plan, cancellation_policy, payment_policy, active, created_at, updated_at
+		FROM rate_plans
+		WHERE property_id = $1 AND active = true AND deleted_at IS NULL
+		  AND (unit_type_id IS NULL OR unit_type_id = $2)
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(ctx, query, propertyID, unitTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("list applicable rate plans: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []entity.RatePlan
+	for rows.Next() {
+		var rp entity.RatePlan
+		err := rows.Scan(
+			&rp.ID, &rp.PropertyID, &rp.UnitTypeID, &rp.Name, &rp.Description,
+			&rp.MealPlan, &rp.CancellationPolicy, &rp.PaymentPolicy, &rp.Active,
+			&rp.CreatedAt, &rp.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, rp)
+	}
+	return plans, rows.Err()
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ecelayes/pms-backend/internal/tenant"
 )
 
 type DBTX interface {
@@ -12,3 +13,25 @@ type DBTX interface {
 	Query(context.Context, string, ...interface{}) (pgx.Rows, error)
 	QueryRow(context.Context, string, ...interface{}) pgx.Row
 }
+
+// tenantArg is the query argument that restricts rows to the organization ctx is scoped to.
+// Queries compare it as `($n::uuid IS NULL OR <organization column> = $n)`, so unscoped
+// contexts get nil and match every organization.
+func tenantArg(ctx context.Context) *string {
+	orgID, ok := tenant.OrganizationID(ctx)
+	if !ok {
+		return nil
+	}
+	return &orgID
+}
+
+// propertyScopeArg is the query argument that restricts rows to the properties ctx is limited to.
+// Queries compare it as `($n::uuid[] IS NULL OR <property column> = ANY($n))`; it is nil for
+// callers that may see every property of their organization.
+func propertyScopeArg(ctx context.Context) []string {
+	ids, ok := tenant.PropertyIDs(ctx)
+	if !ok {
+		return nil
+	}
+	return ids
+}
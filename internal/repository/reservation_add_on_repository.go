@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type ReservationAddOnRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReservationAddOnRepository(db *pgxpool.Pool) *ReservationAddOnRepository {
+	return &ReservationAddOnRepository{db: db}
+}
+
+func (r *ReservationAddOnRepository) Create(ctx context.Context, tx pgx.Tx, a entity.ReservationAddOn) error {
+	query := `
+		INSERT INTO reservation_add_ons (
+			id, reservation_id, property_service_id, name, pricing_unit, quantity, unit_price, total, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+	`
+	_, err := tx.Exec(ctx, query,
+		a.ID, a.ReservationID, a.PropertyServiceID, a.Name, a.PricingUnit, a.Quantity, a.UnitPrice, a.Total,
+	)
+	if err != nil {
+		return fmt.Errorf("create reservation add-on: %w", err)
+	}
+	return nil
+}
+
+func (r *ReservationAddOnRepository) ListByReservation(ctx context.Context, tx pgx.Tx, reservationID string) ([]entity.ReservationAddOn, error) {
+	query := `
+		SELECT id, reservation_id, property_service_id, name, pricing_unit, quantity, unit_price, total, created_at
+		FROM reservation_add_ons
+		WHERE reservation_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	rows, err := querier.Query(ctx, query, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("list reservation add-ons: %w", err)
+	}
+	defer rows.Close()
+
+	list := []entity.ReservationAddOn{}
+	for rows.Next() {
+		var a entity.ReservationAddOn
+		if err := rows.Scan(
+			&a.ID, &a.ReservationID, &a.PropertyServiceID, &a.Name, &a.PricingUnit,
+			&a.Quantity, &a.UnitPrice, &a.Total, &a.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+func (r *ReservationAddOnRepository) UpdateTotal(ctx context.Context, tx pgx.Tx, id string, total float64) error {
+	query := `UPDATE reservation_add_ons SET total = $2 WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, id, total); err != nil {
+		return fmt.Errorf("update reservation add-on: %w", err)
+	}
+	return nil
+}
+
+func (r *ReservationAddOnRepository) Delete(ctx context.Context, tx pgx.Tx, reservationID, id string) error {
+	query := `DELETE FROM reservation_add_ons WHERE id = $1 AND reservation_id = $2`
+	cmd, err := tx.Exec(ctx, query, id, reservationID)
+	if err != nil {
+		return fmt.Errorf("delete reservation add-on: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListUsage returns the bookings of a service on active reservations overlapping [start, end].
+// The end is inclusive because per-stay services are used on the arrival day.
+func (r *ReservationAddOnRepository) ListUsage(ctx context.Context, tx pgx.Tx, propertyServiceID string, start, end time.Time) ([]entity.AddOnUsage, error) {
+	query := `
+		SELECT a.reservation_id, a.pricing_unit, a.quantity, r.adults + r.children,
+		       lower(r.stay_range), upper(r.stay_range)
+		FROM reservation_add_ons a
+		JOIN reservations r ON r.id = a.reservation_id
+		WHERE a.property_service_id = $1
+		  AND r.status IN ('confirmed', 'checked_in')
+		  AND r.deleted_at IS NULL
+		  AND lower(r.stay_range) <= $3::date
+		  AND upper(r.stay_range) >= $2::date
+	`
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	rows, err := querier.Query(ctx, query, propertyServiceID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("list add-on usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []entity.AddOnUsage
+	for rows.Next() {
+		var u entity.AddOnUsage
+		if err := rows.Scan(&u.ReservationID, &u.PricingUnit, &u.Quantity, &u.Pax, &u.Start, &u.End); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
@@ -42,9 +42,13 @@ func (r *ReservationRepository) Create(ctx context.Context, tx pgx.Tx, res entit
 	return nil
 }
 
-func (r *ReservationRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+func (r *ReservationRepository) UpdateStatus(ctx context.Context, tx pgx.Tx, id string, status string) error {
 	query := `UPDATE reservations SET status = $2 WHERE id = $1 AND deleted_at IS NULL`
-	result, err := r.db.Exec(ctx, query, id, status)
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	result, err := querier.Exec(ctx, query, id, status)
 	if err != nil {
 		return fmt.Errorf("update status: %w", err)
 	}
@@ -54,6 +58,69 @@ func (r *ReservationRepository) UpdateStatus(ctx context.Context, id string, sta
 	return nil
 }
 
+func (r *ReservationRepository) Update(ctx context.Context, tx pgx.Tx, res entity.Reservation) error {
+	query := `
+		UPDATE reservations
+		SET unit_type_id = $2, stay_range = daterange($3::date, $4::date), total_price = $5,
+		    adults = $6, children = $7, rate_plan_id = $8, reminder_sent_at = NULL
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	result, err := tx.Exec(ctx, query,
+		res.ID, res.UnitTypeID, res.Start, res.End, res.TotalPrice,
+		res.Adults, res.Children, res.RatePlanID,
+	)
+	if err != nil {
+		return fmt.Errorf("update reservation: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return entity.ErrReservationNotFound
+	}
+	return nil
+}
+
+// ListPendingReminders returns confirmed stays arriving within the next daysAhead days
+// whose pre-arrival reminder has not been sent yet.
+func (r *ReservationRepository) ListPendingReminders(ctx context.Context, daysAhead int) ([]entity.Reservation, error) {
+	query := `
+		SELECT id, reservation_code, unit_type_id, guest_id, lower(stay_range), upper(stay_range), 
+		       total_price, status, adults, children, rate_plan_id, to_char(arrival_time, 'HH24:MI'), created_at, updated_at
+		FROM reservations
+		WHERE status = 'confirmed'
+		  AND reminder_sent_at IS NULL
+		  AND deleted_at IS NULL
+		  AND lower(stay_range) BETWEEN CURRENT_DATE AND CURRENT_DATE + $1::int
+		ORDER BY lower(stay_range)
+	`
+	rows, err := r.db.Query(ctx, query, daysAhead)
+	if err != nil {
+		return nil, fmt.Errorf("list pending reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.Reservation
+	for rows.Next() {
+		var res entity.Reservation
+		if err := rows.Scan(
+			&res.ID, &res.ReservationCode, &res.UnitTypeID, &res.GuestID,
+			&res.Start, &res.End, &res.TotalPrice, &res.Status,
+			&res.Adults, &res.Children, &res.RatePlanID, &res.ArrivalTime,
+			&res.CreatedAt, &res.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, res)
+	}
+	return list, nil
+}
+
+func (r *ReservationRepository) MarkReminderSent(ctx context.Context, tx pgx.Tx, id string) error {
+	query := `UPDATE reservations SET reminder_sent_at = NOW() WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("mark reminder sent: %w", err)
+	}
+	return nil
+}
+
 func (r *ReservationRepository) CountOverlapping(ctx context.Context, unitTypeID string, start, end time.Time) (int, error) {
 	query := `
 		SELECT COUNT(*)
@@ -92,15 +159,18 @@ func (r *ReservationRepository) CountActiveByRatePlan(ctx context.Context, rateP
 func (r *ReservationRepository) GetByID(ctx context.Context, id string) (*entity.Reservation, error) {
 	query := `
 		SELECT id, reservation_code, unit_type_id, guest_id, lower(stay_range), upper(stay_range), 
-		       total_price, status, adults, children, rate_plan_id, created_at, updated_at
+		       total_price, status, adults, children, rate_plan_id, to_char(arrival_time, 'HH24:MI'), created_at, updated_at
 		FROM reservations
 		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR unit_type_id IN (
+		      SELECT ut.id FROM unit_types ut JOIN properties p ON p.id = ut.property_id WHERE p.organization_id = $2 AND ($3::uuid[] IS NULL OR p.id = ANY($3))
+		  ))
 	`
 	var res entity.Reservation
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := r.db.QueryRow(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx)).Scan(
 		&res.ID, &res.ReservationCode, &res.UnitTypeID, &res.GuestID, 
 		&res.Start, &res.End, &res.TotalPrice, &res.Status, 
-		&res.Adults, &res.Children, &res.RatePlanID,
+		&res.Adults, &res.Children, &res.RatePlanID, &res.ArrivalTime,
 		&res.CreatedAt, &res.UpdatedAt,
 	)
 	if err != nil {
@@ -112,29 +182,128 @@ func (r *ReservationRepository) GetByID(ctx context.Context, id string) (*entity
 	return &res, nil
 }
 
+func (r *ReservationRepository) GetByIDLocked(ctx context.Context, tx pgx.Tx, id string) (*entity.Reservation, error) {
+	query := `
+		SELECT id, reservation_code, unit_type_id, guest_id, lower(stay_range), upper(stay_range), 
+		       total_price, status, adults, children, rate_plan_id, to_char(arrival_time, 'HH24:MI'), created_at, updated_at
+		FROM reservations
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR unit_type_id IN (
+		      SELECT ut.id FROM unit_types ut JOIN properties p ON p.id = ut.property_id WHERE p.organization_id = $2 AND ($3::uuid[] IS NULL OR p.id = ANY($3))
+		  ))
+		FOR UPDATE
+	`
+	var res entity.Reservation
+	err := tx.QueryRow(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx)).Scan(
+		&res.ID, &res.ReservationCode, &res.UnitTypeID, &res.GuestID, 
+		&res.Start, &res.End, &res.TotalPrice, &res.Status, 
+		&res.Adults, &res.Children, &res.RatePlanID, &res.ArrivalTime,
+		&res.CreatedAt, &res.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, entity.ErrRecordNotFound 
+		}
+		return nil, fmt.Errorf("get reservation locked: %w", err)
+	}
+	return &res, nil
+}
+
 func (r *ReservationRepository) GetByCode(ctx context.Context, code string) (*entity.Reservation, error) {
 	query := `
 		SELECT id, reservation_code, unit_type_id, guest_id, lower(stay_range), upper(stay_range), 
-		       total_price, status, adults, children, rate_plan_id, created_at, updated_at
+		       total_price, status, adults, children, rate_plan_id, to_char(arrival_time, 'HH24:MI'), created_at, updated_at
 		FROM reservations
 		WHERE reservation_code = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR unit_type_id IN (
+		      SELECT ut.id FROM unit_types ut JOIN properties p ON p.id = ut.property_id WHERE p.organization_id = $2 AND ($3::uuid[] IS NULL OR p.id = ANY($3))
+		  ))
 	`
 	var res entity.Reservation
-	err := r.db.QueryRow(ctx, query, code).Scan(
+	err := r.db.QueryRow(ctx, query, code, tenantArg(ctx), propertyScopeArg(ctx)).Scan(
 		&res.ID, &res.ReservationCode, &res.UnitTypeID, &res.GuestID, 
 		&res.Start, &res.End, &res.TotalPrice, &res.Status, 
-		&res.Adults, &res.Children, &res.RatePlanID,
+		&res.Adults, &res.Children, &res.RatePlanID, &res.ArrivalTime,
 		&res.CreatedAt, &res.UpdatedAt,
 	)
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, entity.ErrRecordNotFound
+		}
 		return nil, fmt.Errorf("reservation not found: %w", err)
 	}
 	return &res, nil
 }
 
+// UpdateTotal changes only the price, e.g. when add-ons are booked or removed.
+func (r *ReservationRepository) UpdateTotal(ctx context.Context, tx pgx.Tx, id string, total float64) error {
+	query := `UPDATE reservations SET total_price = $2 WHERE id = $1 AND deleted_at IS NULL`
+	result, err := tx.Exec(ctx, query, id, total)
+	if err != nil {
+		return fmt.Errorf("update reservation total: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return entity.ErrReservationNotFound
+	}
+	return nil
+}
+
+func (r *ReservationRepository) SetArrivalTime(ctx context.Context, id, arrival string) error {
+	query := `UPDATE reservations SET arrival_time = NULLIF($2, '')::TIME WHERE id = $1 AND deleted_at IS NULL`
+	cmd, err := r.db.Exec(ctx, query, id, arrival)
+	if err != nil {
+		return fmt.Errorf("set arrival time: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrReservationNotFound
+	}
+	return nil
+}
+
+// EnsureAccessSecret stores candidate as the reservation's guest access secret unless one is
+// already set, and returns the secret in effect.
+func (r *ReservationRepository) EnsureAccessSecret(ctx context.Context, tx pgx.Tx, id, candidate string) (string, error) {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+
+	query := `
+		UPDATE reservations SET access_secret = COALESCE(access_secret, $2)
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING access_secret
+	`
+	var secret string
+	if err := querier.QueryRow(ctx, query, id, candidate).Scan(&secret); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", entity.ErrReservationNotFound
+		}
+		return "", fmt.Errorf("ensure access secret: %w", err)
+	}
+	return secret, nil
+}
+
+func (r *ReservationRepository) GetAccessSecret(ctx context.Context, id string) (string, error) {
+	query := `SELECT COALESCE(access_secret, '') FROM reservations WHERE id = $1 AND deleted_at IS NULL`
+	var secret string
+	if err := r.db.QueryRow(ctx, query, id).Scan(&secret); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", entity.ErrReservationNotFound
+		}
+		return "", fmt.Errorf("get access secret: %w", err)
+	}
+	return secret, nil
+}
+
 func (r *ReservationRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE reservations SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
-	cmd, err := r.db.Exec(ctx, query, id)
+	query := `
+		UPDATE reservations SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR unit_type_id IN (
+		      SELECT ut.id FROM unit_types ut JOIN properties p ON p.id = ut.property_id WHERE p.organization_id = $2 AND ($3::uuid[] IS NULL OR p.id = ANY($3))
+		  ))
+	`
+	cmd, err := r.db.Exec(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return fmt.Errorf("delete reservation: %w", err)
 	}
@@ -143,3 +312,11 @@ func (r *ReservationRepository) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+func (r *ReservationRepository) ReassignGuest(ctx context.Context, tx pgx.Tx, fromID, toID string) (int, error) {
+	cmd, err := tx.Exec(ctx, `UPDATE reservations SET guest_id = $2, updated_at = NOW() WHERE guest_id = $1`, fromID, toID)
+	if err != nil {
+		return 0, fmt.Errorf("reassign reservations: %w", err)
+	}
+	return int(cmd.RowsAffected()), nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type SessionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, s entity.Session, tokenHash string) error {
+	query := `
+		INSERT INTO user_sessions (id, user_id, organization_id, refresh_token_hash, user_agent, ip_address, expires_at, created_at, last_used_at)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7, NOW(), NOW())
+	`
+	_, err := r.db.Exec(ctx, query, s.ID, s.UserID, s.OrganizationID, tokenHash, s.UserAgent, s.IPAddress, s.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+	return nil
+}
+
+// GetActiveByTokenHash finds the live session a refresh token currently belongs to.
+func (r *SessionRepository) GetActiveByTokenHash(ctx context.Context, tokenHash string) (*entity.Session, error) {
+	query := `
+		SELECT id, user_id, COALESCE(organization_id::text, ''), user_agent, ip_address, created_at, last_used_at, expires_at
+		FROM user_sessions
+		WHERE refresh_token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+	`
+	var s entity.Session
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&s.ID, &s.UserID, &s.OrganizationID, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	return &s, nil
+}
+
+// RevokeByPreviousTokenHash ends the session a rotated refresh token used to belong to. A
+// rotated token showing up again means it leaked, so the whole session is cut off. It
+// returns the user of the revoked session, or ErrRecordNotFound.
+func (r *SessionRepository) RevokeByPreviousTokenHash(ctx context.Context, tokenHash string) (string, error) {
+	query := `
+		UPDATE user_sessions SET revoked_at = NOW()
+		WHERE previous_token_hash = $1 AND revoked_at IS NULL
+		RETURNING user_id
+	`
+	var userID string
+	if err := r.db.QueryRow(ctx, query, tokenHash).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", entity.ErrRecordNotFound
+		}
+		return "", fmt.Errorf("revoke replayed session: %w", err)
+	}
+	return userID, nil
+}
+
+// Rotate replaces the session's refresh token and extends it. It only succeeds for the
+// caller holding the current token, so concurrent refreshes cannot both win.
+func (r *SessionRepository) Rotate(ctx context.Context, id, oldHash, newHash, orgID string, expiresAt time.Time) error {
+	query := `
+		UPDATE user_sessions
+		SET previous_token_hash = refresh_token_hash, refresh_token_hash = $3,
+		    organization_id = NULLIF($4, '')::uuid, expires_at = $5, last_used_at = NOW()
+		WHERE id = $1 AND refresh_token_hash = $2 AND revoked_at IS NULL
+	`
+	cmd, err := r.db.Exec(ctx, query, id, oldHash, newHash, orgID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("rotate session: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Touch confirms the session is live and records its use, at most once a minute.
+func (r *SessionRepository) Touch(ctx context.Context, id, userID string) error {
+	query := `
+		UPDATE user_sessions
+		SET last_used_at = CASE WHEN last_used_at < NOW() - INTERVAL '1 minute' THEN NOW() ELSE last_used_at END
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+	`
+	cmd, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("touch session: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetOrganization records the organization the session now refreshes into.
+func (r *SessionRepository) SetOrganization(ctx context.Context, id, orgID string) error {
+	query := `UPDATE user_sessions SET organization_id = NULLIF($2, '')::uuid WHERE id = $1 AND revoked_at IS NULL`
+	cmd, err := r.db.Exec(ctx, query, id, orgID)
+	if err != nil {
+		return fmt.Errorf("set session organization: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListActive returns the user's live sessions, most recently used first.
+func (r *SessionRepository) ListActive(ctx context.Context, userID string) ([]entity.Session, error) {
+	query := `
+		SELECT id, user_id, COALESCE(organization_id::text, ''), user_agent, ip_address, created_at, last_used_at, expires_at
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.Session
+	for rows.Next() {
+		var s entity.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.OrganizationID, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, id, userID string) error {
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	cmd, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeAll ends every live session of the user and returns how many there were.
+func (r *SessionRepository) RevokeAll(ctx context.Context, tx pgx.Tx, userID string) (int64, error) {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	cmd, err := querier.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("revoke sessions: %w", err)
+	}
+	return cmd.RowsAffected(), nil
+}
@@ -50,8 +50,11 @@ func (r *UnitRepository) Update(ctx context.Context, id string, req entity.Updat
 		addSet("status", req.Status)
 	}
 
-	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL", argID)
-	args = append(args, id)
+	query += fmt.Sprintf(
+		" WHERE id = $%d AND deleted_at IS NULL AND ($%d::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $%d AND ($%d::uuid[] IS NULL OR id = ANY($%d))))",
+		argID, argID+1, argID+1, argID+2, argID+2,
+	)
+	args = append(args, id, tenantArg(ctx), propertyScopeArg(ctx))
 
 	cmd, err := r.db.Exec(ctx, query, args...)
 	if err != nil {
@@ -68,9 +71,10 @@ func (r *UnitRepository) ListByProperty(ctx context.Context, propertyID string)
 		SELECT id, property_id, unit_type_id, name, status, created_at, updated_at
 		FROM units
 		WHERE property_id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
 		ORDER BY name ASC
 	`
-	rows, err := r.db.Query(ctx, query, propertyID)
+	rows, err := r.db.Query(ctx, query, propertyID, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("list units: %w", err)
 	}
@@ -94,9 +98,10 @@ func (r *UnitRepository) GetByID(ctx context.Context, id string) (*entity.Unit,
 		SELECT id, property_id, unit_type_id, name, status, created_at, updated_at
 		FROM units
 		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
 	`
 	var u entity.Unit
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := r.db.QueryRow(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx)).Scan(
 		&u.ID, &u.PropertyID, &u.UnitTypeID, &u.Name, &u.Status, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
@@ -109,8 +114,12 @@ func (r *UnitRepository) GetByID(ctx context.Context, id string) (*entity.Unit,
 }
 
 func (r *UnitRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE units SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
-	cmd, err := r.db.Exec(ctx, query, id)
+	query := `
+		UPDATE units SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
+	`
+	cmd, err := r.db.Exec(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return fmt.Errorf("delete unit: %w", err)
 	}
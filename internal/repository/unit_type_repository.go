@@ -20,20 +20,26 @@ func NewUnitTypeRepository(db *pgxpool.Pool) *UnitTypeRepository {
 	return &UnitTypeRepository{db: db}
 }
 
-func (r *UnitTypeRepository) Create(ctx context.Context, ut entity.UnitType) (string, error) {
+func (r *UnitTypeRepository) Create(ctx context.Context, ut entity.UnitType, amenityIDs []string) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		INSERT INTO unit_types (
 			property_id, name, code, total_quantity, base_price, 
-			max_occupancy, max_adults, max_children, amenities,
+			max_occupancy, max_adults, max_children,
 			created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
 		RETURNING id
 	`
 	var id string
-	err := r.db.QueryRow(ctx, query, 
+	err = tx.QueryRow(ctx, query, 
 		ut.PropertyID, ut.Name, ut.Code, ut.TotalQuantity, ut.BasePrice,
-		ut.MaxOccupancy, ut.MaxAdults, ut.MaxChildren, ut.Amenities,
+		ut.MaxOccupancy, ut.MaxAdults, ut.MaxChildren,
 	).Scan(&id)
 	
 	if err != nil {
@@ -43,18 +49,90 @@ func (r *UnitTypeRepository) Create(ctx context.Context, ut entity.UnitType) (st
 		}
 		return "", fmt.Errorf("create unit type: %w", err)
 	}
+
+	if err := r.setAmenities(ctx, tx, id, amenityIDs); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
 	return id, nil
 }
 
+// setAmenities replaces the catalog amenities linked to a unit type.
+func (r *UnitTypeRepository) setAmenities(ctx context.Context, tx pgx.Tx, unitTypeID string, amenityIDs []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM unit_type_amenities WHERE unit_type_id = $1`, unitTypeID); err != nil {
+		return fmt.Errorf("clear unit type amenities: %w", err)
+	}
+	if len(amenityIDs) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO unit_type_amenities (unit_type_id, amenity_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, unitTypeID, amenityIDs); err != nil {
+		return fmt.Errorf("link unit type amenities: %w", err)
+	}
+	return nil
+}
+
+// attachAmenities loads the catalog amenities of the given unit types in one query.
+func (r *UnitTypeRepository) attachAmenities(ctx context.Context, querier DBTX, unitTypes []entity.UnitType) error {
+	if len(unitTypes) == 0 {
+		return nil
+	}
+	ids := make([]string, len(unitTypes))
+	for i, ut := range unitTypes {
+		ids[i] = ut.ID
+		unitTypes[i].Amenities = []entity.Amenity{}
+	}
+
+	query := `
+		SELECT uta.unit_type_id, a.id, a.name, COALESCE(a.description, ''), COALESCE(a.icon, '')
+		FROM unit_type_amenities uta
+		JOIN amenities a ON a.id = uta.amenity_id
+		WHERE uta.unit_type_id = ANY($1::uuid[]) AND a.deleted_at IS NULL
+		ORDER BY a.name ASC
+	`
+	rows, err := querier.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("load unit type amenities: %w", err)
+	}
+	defer rows.Close()
+
+	byUnitType := map[string][]entity.Amenity{}
+	for rows.Next() {
+		var unitTypeID string
+		var a entity.Amenity
+		if err := rows.Scan(&unitTypeID, &a.ID, &a.Name, &a.Description, &a.Icon); err != nil {
+			return err
+		}
+		byUnitType[unitTypeID] = append(byUnitType[unitTypeID], a)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range unitTypes {
+		if amenities, ok := byUnitType[unitTypes[i].ID]; ok {
+			unitTypes[i].Amenities = amenities
+		}
+	}
+	return nil
+}
+
 func (r *UnitTypeRepository) GetAll(ctx context.Context) ([]entity.UnitType, error) {
 	query := `
 		SELECT id, property_id, name, code, total_quantity, base_price,
-		       max_occupancy, max_adults, max_children, amenities, 
+		       max_occupancy, max_adults, max_children,
 		       created_at, updated_at
 		FROM unit_types
 		WHERE deleted_at IS NULL
+		  AND ($1::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $1 AND ($2::uuid[] IS NULL OR id = ANY($2))))
 	`
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get all unit types: %w", err)
 	}
@@ -65,7 +143,7 @@ func (r *UnitTypeRepository) GetAll(ctx context.Context) ([]entity.UnitType, err
 		var ut entity.UnitType
 		err := rows.Scan(
 			&ut.ID, &ut.PropertyID, &ut.Name, &ut.Code, &ut.TotalQuantity, &ut.BasePrice,
-			&ut.MaxOccupancy, &ut.MaxAdults, &ut.MaxChildren, &ut.Amenities,
+			&ut.MaxOccupancy, &ut.MaxAdults, &ut.MaxChildren,
 			&ut.CreatedAt, &ut.UpdatedAt,
 		)
 		if err != nil {
@@ -73,21 +151,25 @@ func (r *UnitTypeRepository) GetAll(ctx context.Context) ([]entity.UnitType, err
 		}
 		unitTypes = append(unitTypes, ut)
 	}
+	if err := r.attachAmenities(ctx, r.db, unitTypes); err != nil {
+		return nil, err
+	}
 	return unitTypes, nil
 }
 
 func (r *UnitTypeRepository) GetByID(ctx context.Context, id string) (*entity.UnitType, error) {
 	query := `
 		SELECT id, property_id, name, code, total_quantity, base_price,
-		       max_occupancy, max_adults, max_children, amenities, 
+		       max_occupancy, max_adults, max_children,
 		       created_at, updated_at
 		FROM unit_types
 		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
 	`
 	var ut entity.UnitType
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := r.db.QueryRow(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx)).Scan(
 		&ut.ID, &ut.PropertyID, &ut.Name, &ut.Code, &ut.TotalQuantity, &ut.BasePrice,
-		&ut.MaxOccupancy, &ut.MaxAdults, &ut.MaxChildren, &ut.Amenities,
+		&ut.MaxOccupancy, &ut.MaxAdults, &ut.MaxChildren,
 		&ut.CreatedAt, &ut.UpdatedAt,
 	)
 	if err != nil {
@@ -96,22 +178,27 @@ func (r *UnitTypeRepository) GetByID(ctx context.Context, id string) (*entity.Un
 		}
 		return nil, fmt.Errorf("get unit type: %w", err)
 	}
-	return &ut, nil
+	unitTypes := []entity.UnitType{ut}
+	if err := r.attachAmenities(ctx, r.db, unitTypes); err != nil {
+		return nil, err
+	}
+	return &unitTypes[0], nil
 }
 
 func (r *UnitTypeRepository) GetByIDLocked(ctx context.Context, tx pgx.Tx, id string) (*entity.UnitType, error) {
 	query := `
 		SELECT id, property_id, name, code, total_quantity, base_price,
-		       max_occupancy, max_adults, max_children, amenities, 
+		       max_occupancy, max_adults, max_children,
 		       created_at, updated_at
 		FROM unit_types
 		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
 		FOR UPDATE
 	`
 	var ut entity.UnitType
-	err := tx.QueryRow(ctx, query, id).Scan(
+	err := tx.QueryRow(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx)).Scan(
 		&ut.ID, &ut.PropertyID, &ut.Name, &ut.Code, &ut.TotalQuantity, &ut.BasePrice,
-		&ut.MaxOccupancy, &ut.MaxAdults, &ut.MaxChildren, &ut.Amenities,
+		&ut.MaxOccupancy, &ut.MaxAdults, &ut.MaxChildren,
 		&ut.CreatedAt, &ut.UpdatedAt,
 	)
 	if err != nil {
@@ -120,7 +207,11 @@ func (r *UnitTypeRepository) GetByIDLocked(ctx context.Context, tx pgx.Tx, id st
 		}
 		return nil, fmt.Errorf("get unit type locked: %w", err)
 	}
-	return &ut, nil
+	unitTypes := []entity.UnitType{ut}
+	if err := r.attachAmenities(ctx, tx, unitTypes); err != nil {
+		return nil, err
+	}
+	return &unitTypes[0], nil
 }
 
 func (r *UnitTypeRepository) ListByProperty(ctx context.Context, propertyID string, pagination entity.PaginationRequest) ([]entity.UnitType, int64, error) {
@@ -128,25 +219,27 @@ func (r *UnitTypeRepository) ListByProperty(ctx context.Context, propertyID stri
 		SELECT COUNT(*)
 		FROM unit_types
 		WHERE property_id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
 	`
 	var total int64
-	if err := r.db.QueryRow(ctx, countQuery, propertyID).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, countQuery, propertyID, tenantArg(ctx), propertyScopeArg(ctx)).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count unit types: %w", err)
 	}
 
 	query := `
 		SELECT id, property_id, name, code, total_quantity, base_price,
-		       max_occupancy, max_adults, max_children, amenities, 
+		       max_occupancy, max_adults, max_children,
 		       created_at, updated_at
 		FROM unit_types
 		WHERE property_id = $1 AND deleted_at IS NULL
+		  AND ($4::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $4 AND ($5::uuid[] IS NULL OR id = ANY($5))))
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 	
 	offset := (pagination.Page - 1) * pagination.Limit
 	
-	rows, err := r.db.Query(ctx, query, propertyID, pagination.Limit, offset)
+	rows, err := r.db.Query(ctx, query, propertyID, pagination.Limit, offset, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return nil, 0, fmt.Errorf("list unit types: %w", err)
 	}
@@ -157,7 +250,7 @@ func (r *UnitTypeRepository) ListByProperty(ctx context.Context, propertyID stri
 		var ut entity.UnitType
 		err := rows.Scan(
 			&ut.ID, &ut.PropertyID, &ut.Name, &ut.Code, &ut.TotalQuantity, &ut.BasePrice,
-			&ut.MaxOccupancy, &ut.MaxAdults, &ut.MaxChildren, &ut.Amenities,
+			&ut.MaxOccupancy, &ut.MaxAdults, &ut.MaxChildren,
 			&ut.CreatedAt, &ut.UpdatedAt,
 		)
 		if err != nil {
@@ -165,6 +258,9 @@ func (r *UnitTypeRepository) ListByProperty(ctx context.Context, propertyID stri
 		}
 		unitTypes = append(unitTypes, ut)
 	}
+	if err := r.attachAmenities(ctx, r.db, unitTypes); err != nil {
+		return nil, 0, err
+	}
 	return unitTypes, total, nil
 }
 
@@ -221,14 +317,19 @@ func (r *UnitTypeRepository) Update(ctx context.Context, id string, req entity.U
 		addSet("base_price", *req.BasePrice)
 	}
 
-	if req.Amenities != nil {
-		addSet("amenities", req.Amenities)
-	}
+	query += fmt.Sprintf(
+		" WHERE id = $%d AND deleted_at IS NULL AND ($%d::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $%d AND ($%d::uuid[] IS NULL OR id = ANY($%d))))",
+		argID, argID+1, argID+1, argID+2, argID+2,
+	)
+	args = append(args, id, tenantArg(ctx), propertyScopeArg(ctx))
 
-	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL", argID)
-	args = append(args, id)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-	cmd, err := r.db.Exec(ctx, query, args...)
+	cmd, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("update unit type: %w", err)
 	}
@@ -236,12 +337,22 @@ func (r *UnitTypeRepository) Update(ctx context.Context, id string, req entity.U
 		return entity.ErrRecordNotFound
 	}
 
-	return nil
+	if req.AmenityIDs != nil {
+		if err := r.setAmenities(ctx, tx, id, req.AmenityIDs); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (r *UnitTypeRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE unit_types SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
-	cmd, err := r.db.Exec(ctx, query, id)
+	query := `
+		UPDATE unit_types SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR property_id IN (SELECT id FROM properties WHERE organization_id = $2 AND ($3::uuid[] IS NULL OR id = ANY($3))))
+	`
+	cmd, err := r.db.Exec(ctx, query, id, tenantArg(ctx), propertyScopeArg(ctx))
 	if err != nil {
 		return fmt.Errorf("delete unit type: %w", err)
 	}
@@ -286,6 +397,28 @@ func (r *UnitTypeRepository) CountReservations(ctx context.Context, db DBTX, uni
 	return count, nil
 }
 
+// CountReservationsExcluding counts overlapping bookings while ignoring the reservation being modified.
+func (r *UnitTypeRepository) CountReservationsExcluding(ctx context.Context, db DBTX, unitTypeID string, start, end time.Time, excludeID string) (int, error) {
+	var querier DBTX = db
+	if querier == nil {
+		querier = r.db
+	}
+	query := `
+		SELECT COUNT(*) FROM reservations 
+		WHERE unit_type_id = $1 
+		AND id <> $4
+		AND status = 'confirmed' 
+		AND deleted_at IS NULL
+		AND stay_range && daterange($2::date, $3::date)
+	`
+	var count int
+	err := querier.QueryRow(ctx, query, unitTypeID, start, end, excludeID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count reservations: %w", err)
+	}
+	return count, nil
+}
+
 func (r *UnitTypeRepository) GetDailyPrices(ctx context.Context, unitTypeID string, start, end time.Time) ([]entity.DailyRate, error) {
 	query := `
 		WITH booking_days AS (
@@ -4,35 +4,55 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/pkg/fieldcrypt"
 )
 
+// UserRepository stores accounts. TOTP secrets are sealed with the same key ring as guest
+// personal data.
 type UserRepository struct {
-	db *pgxpool.Pool
+	db   *pgxpool.Pool
+	keys *fieldcrypt.KeyRing
 }
 
-func NewUserRepository(db *pgxpool.Pool) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *pgxpool.Pool, keys *fieldcrypt.KeyRing) *UserRepository {
+	return &UserRepository{db: db, keys: keys}
+}
+
+// openTOTPSecret decrypts a stored TOTP secret. Secrets enrolled before they were sealed are
+// plain base32, which never contains the key version separator.
+func (r *UserRepository) openTOTPSecret(stored string) (string, error) {
+	if !strings.Contains(stored, ":") {
+		return stored, nil
+	}
+	secret, err := r.keys.Decrypt(stored)
+	if err != nil {
+		return "", fmt.Errorf("open totp secret: %w", err)
+	}
+	return secret, nil
 }
 
 func (r *UserRepository) Create(ctx context.Context, tx pgx.Tx, u entity.User) error {
 	query := `
 		INSERT INTO users (
 			id, email, password, salt, role, 
-			first_name, last_name, phone,
+			first_name, last_name, phone, language, email_verified_at,
 			created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, NOW(), NOW())
 	`
 	var err error
 	if tx != nil {
-		_, err = tx.Exec(ctx, query, u.ID, u.Email, u.Password, u.Salt, u.Role, u.FirstName, u.LastName, u.Phone)
+		_, err = tx.Exec(ctx, query, u.ID, u.Email, u.Password, u.Salt, u.Role, u.FirstName, u.LastName, u.Phone, u.Language, u.EmailVerifiedAt)
 	} else {
-		_, err = r.db.Exec(ctx, query, u.ID, u.Email, u.Password, u.Salt, u.Role, u.FirstName, u.LastName, u.Phone)
+		_, err = r.db.Exec(ctx, query, u.ID, u.Email, u.Password, u.Salt, u.Role, u.FirstName, u.LastName, u.Phone, u.Language, u.EmailVerifiedAt)
 	}
 
 	if err != nil {
@@ -58,16 +78,48 @@ func (r *UserRepository) GetSaltByID(ctx context.Context, userID string) (string
 	return salt, nil
 }
 
+func (r *UserRepository) GetTokenVersion(ctx context.Context, userID string) (int, error) {
+	var version int
+	query := `SELECT token_version FROM users WHERE id = $1 AND deleted_at IS NULL`
+	err := r.db.QueryRow(ctx, query, userID).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, entity.ErrUserNotFound
+		}
+		return 0, fmt.Errorf("failed to fetch token version: %w", err)
+	}
+	return version, nil
+}
+
+// BumpTokenVersion revokes every access token issued to the user so far.
+func (r *UserRepository) BumpTokenVersion(ctx context.Context, tx pgx.Tx, userID string) error {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	query := `UPDATE users SET token_version = token_version + 1, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	cmd, err := querier.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("bump token version: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrUserNotFound
+	}
+	return nil
+}
+
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var u entity.User
 	query := `
-		SELECT id, email, password, salt, role, first_name, last_name, phone 
+		SELECT id, email, password, salt, role, first_name, last_name, phone, COALESCE(language, ''), email_verified_at, token_version,
+		       COALESCE(totp_secret, ''), totp_enabled_at IS NOT NULL, failed_login_attempts, locked_until
 		FROM users 
 		WHERE email=$1 AND deleted_at IS NULL
 	`
 	err := r.db.QueryRow(ctx, query, email).Scan(
 		&u.ID, &u.Email, &u.Password, &u.Salt, &u.Role, 
-		&u.FirstName, &u.LastName, &u.Phone,
+		&u.FirstName, &u.LastName, &u.Phone, &u.Language, &u.EmailVerifiedAt, &u.TokenVersion,
+		&u.TOTPSecret, &u.TwoFactorEnabled, &u.FailedLoginAttempts, &u.LockedUntil,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -75,19 +127,25 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entity.
 		}
 		return nil, fmt.Errorf("user lookup failed: %w", err)
 	}
+	if u.TOTPSecret, err = r.openTOTPSecret(u.TOTPSecret); err != nil {
+		return nil, err
+	}
 	return &u, nil
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
 	query := `
-		SELECT id, email, password, salt, role, first_name, last_name, phone, created_at, updated_at 
+		SELECT id, email, password, salt, role, first_name, last_name, phone, COALESCE(language, ''), token_version,
+		       COALESCE(totp_secret, ''), totp_enabled_at IS NOT NULL, failed_login_attempts, locked_until, created_at, updated_at 
 		FROM users 
 		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR id IN (SELECT user_id FROM organization_members WHERE organization_id = $2))
 	`
 	var u entity.User
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := r.db.QueryRow(ctx, query, id, tenantArg(ctx)).Scan(
 		&u.ID, &u.Email, &u.Password, &u.Salt, &u.Role, 
-		&u.FirstName, &u.LastName, &u.Phone,
+		&u.FirstName, &u.LastName, &u.Phone, &u.Language, &u.TokenVersion,
+		&u.TOTPSecret, &u.TwoFactorEnabled, &u.FailedLoginAttempts, &u.LockedUntil,
 		&u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
@@ -96,6 +154,9 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*entity.User,
 		}
 		return nil, fmt.Errorf("get user: %w", err)
 	}
+	if u.TOTPSecret, err = r.openTOTPSecret(u.TOTPSecret); err != nil {
+		return nil, err
+	}
 	return &u, nil
 }
 
@@ -112,7 +173,9 @@ func (r *UserRepository) GetAllByOrganization(ctx context.Context, orgID string,
 	}
 
 	query := `
-		SELECT u.id, u.email, u.first_name, u.last_name, u.phone, u.created_at, u.updated_at, om.role
+		SELECT u.id, u.email, u.first_name, u.last_name, u.phone, COALESCE(u.language, ''), u.created_at, u.updated_at, om.role,
+		       u.totp_enabled_at IS NOT NULL, om.all_properties,
+		       ARRAY(SELECT mp.property_id::text FROM organization_member_properties mp WHERE mp.member_id = om.id ORDER BY mp.property_id)
 		FROM users u
 		JOIN organization_members om ON u.id = om.user_id
 		WHERE om.organization_id = $1 AND u.deleted_at IS NULL
@@ -132,8 +195,8 @@ func (r *UserRepository) GetAllByOrganization(ctx context.Context, orgID string,
 	for rows.Next() {
 		var u entity.User
 		if err := rows.Scan(
-			&u.ID, &u.Email, &u.FirstName, &u.LastName, &u.Phone, 
-			&u.CreatedAt, &u.UpdatedAt, &u.Role,
+			&u.ID, &u.Email, &u.FirstName, &u.LastName, &u.Phone, &u.Language,
+			&u.CreatedAt, &u.UpdatedAt, &u.Role, &u.TwoFactorEnabled, &u.AllProperties, &u.PropertyIDs,
 		); err != nil {
 			return nil, 0, err
 		}
@@ -142,7 +205,12 @@ func (r *UserRepository) GetAllByOrganization(ctx context.Context, orgID string,
 	return users, total, nil
 }
 
-func (r *UserRepository) Update(ctx context.Context, userID, orgID string, req entity.UpdateUserRequest) error {
+func (r *UserRepository) Update(ctx context.Context, tx pgx.Tx, userID, orgID string, req entity.UpdateUserRequest) error {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+
 	query := `UPDATE users SET updated_at = NOW()`
 	args := []interface{}{}
 	argID := 1
@@ -153,23 +221,31 @@ func (r *UserRepository) Update(ctx context.Context, userID, orgID string, req e
 		argID++
 	}
 
-	if req.Email != "" { addSet("email", req.Email) }
+	if req.Email != "" {
+		addSet("email", req.Email)
+		// A new address has to be verified again before it signs the account in.
+		query += fmt.Sprintf(", email_verified_at = CASE WHEN email = $%d THEN email_verified_at END", argID-1)
+	}
 	if req.FirstName != "" { addSet("first_name", req.FirstName) }
 	if req.LastName != "" { addSet("last_name", req.LastName) }
 	if req.Phone != "" { addSet("phone", req.Phone) }
+	if req.Language != "" { addSet("language", req.Language) }
 
 	if len(args) > 0 {
-		query += fmt.Sprintf(" WHERE id = $%d", argID)
-		args = append(args, userID)
+		query += fmt.Sprintf(
+			" WHERE id = $%d AND ($%d::uuid IS NULL OR id IN (SELECT user_id FROM organization_members WHERE organization_id = $%d))",
+			argID, argID+1, argID+1,
+		)
+		args = append(args, userID, tenantArg(ctx))
 		
-		cmd, err := r.db.Exec(ctx, query, args...)
+		cmd, err := querier.Exec(ctx, query, args...)
 		if err != nil { return err }
 		if cmd.RowsAffected() == 0 { return entity.ErrRecordNotFound }
 	}
 
 	if req.Role != "" {
 		queryRole := `UPDATE organization_members SET role = $3, updated_at = NOW() WHERE user_id = $1 AND organization_id = $2`
-		cmd, err := r.db.Exec(ctx, queryRole, userID, orgID, req.Role)
+		cmd, err := querier.Exec(ctx, queryRole, userID, orgID, req.Role)
 		if err != nil { return err }
 		if cmd.RowsAffected() == 0 { return entity.ErrRecordNotFound }
 	}
@@ -177,13 +253,34 @@ func (r *UserRepository) Update(ctx context.Context, userID, orgID string, req e
 	return nil
 }
 
-func (r *UserRepository) UpdatePassword(ctx context.Context, userID, hashedPassword, newSalt string) error {
+// MarkEmailVerified activates a self-registered account. Verifying twice is a no-op.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID string) error {
+	query := `
+		UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	cmd, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("verify email: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, tx pgx.Tx, userID, hashedPassword, newSalt string) error {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
 	query := `
 		UPDATE users 
-		SET password = $2, salt = $3, updated_at = NOW() 
+		SET password = $2, salt = $3, token_version = token_version + 1,
+		    failed_login_attempts = 0, locked_until = NULL, updated_at = NOW() 
 		WHERE id = $1 AND deleted_at IS NULL
 	`
-	cmd, err := r.db.Exec(ctx, query, userID, hashedPassword, newSalt)
+	cmd, err := querier.Exec(ctx, query, userID, hashedPassword, newSalt)
 	if err != nil {
 		return fmt.Errorf("update password: %w", err)
 	}
@@ -193,10 +290,305 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, userID, hashedPassw
 	return nil
 }
 
+// ClaimLoginAttempt counts a sign-in attempt against the account before its password or code
+// is checked, and makes the account wait delays[n-1] before the next one, the last delay
+// applying from then on. Checking and counting in one statement means concurrent guesses
+// cannot all slip in before the delay is set. Attempts made while the account waits are
+// refused: it returns 0 with the time it accepts them again. An account whose lockout has
+// run out starts counting from scratch.
+func (r *UserRepository) ClaimLoginAttempt(ctx context.Context, userID string, delays []time.Duration) (int, *time.Time, error) {
+	seconds := make([]float64, len(delays))
+	for i, d := range delays {
+		seconds[i] = d.Seconds()
+	}
+	const attempt = `CASE WHEN locked_until IS NOT NULL AND failed_login_attempts >= cardinality($2::float8[]) THEN 1 ELSE failed_login_attempts + 1 END`
+	query := `
+		UPDATE users SET
+			failed_login_attempts = ` + attempt + `,
+			locked_until = NULLIF(NOW() + make_interval(secs => ($2::float8[])[LEAST(` + attempt + `, cardinality($2::float8[]))]), NOW())
+		WHERE id = $1 AND deleted_at IS NULL AND (locked_until IS NULL OR locked_until <= NOW())
+		RETURNING failed_login_attempts
+	`
+	var attempts int
+	err := r.db.QueryRow(ctx, query, userID, seconds).Scan(&attempts)
+	if err == nil {
+		return attempts, nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil, fmt.Errorf("claim login attempt: %w", err)
+	}
+
+	var lockedUntil *time.Time
+	if err := r.db.QueryRow(ctx, `SELECT locked_until FROM users WHERE id = $1 AND deleted_at IS NULL`, userID).Scan(&lockedUntil); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil, entity.ErrUserNotFound
+		}
+		return 0, nil, fmt.Errorf("claim login attempt: %w", err)
+	}
+	return 0, lockedUntil, nil
+}
+
+// ResetLoginFailures clears the attempt count and any wait once a sign-in succeeds.
+func (r *UserRepository) ResetLoginFailures(ctx context.Context, userID string) error {
+	query := `
+		UPDATE users SET failed_login_attempts = 0, locked_until = NULL
+		WHERE id = $1 AND (failed_login_attempts > 0 OR locked_until IS NOT NULL)
+	`
+	if _, err := r.db.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("reset login failures: %w", err)
+	}
+	return nil
+}
+
+// ClaimPasswordReset records a password reset request unless another one was made within
+// the cooldown. It reports whether the caller may send the email.
+func (r *UserRepository) ClaimPasswordReset(ctx context.Context, userID string, cooldown time.Duration) (bool, error) {
+	query := `
+		UPDATE users SET password_reset_requested_at = NOW()
+		WHERE id = $1 AND (password_reset_requested_at IS NULL OR password_reset_requested_at <= $2)
+	`
+	cmd, err := r.db.Exec(ctx, query, userID, time.Now().Add(-cooldown))
+	if err != nil {
+		return false, fmt.Errorf("claim password reset: %w", err)
+	}
+	return cmd.RowsAffected() > 0, nil
+}
+
+// SetPendingTOTPSecret stores the secret of an enrolment in progress. It does not touch
+// users who already have two-factor authentication enabled.
+func (r *UserRepository) SetPendingTOTPSecret(ctx context.Context, userID, secret string) error {
+	sealed, err := r.keys.Encrypt(secret)
+	if err != nil {
+		return fmt.Errorf("seal totp secret: %w", err)
+	}
+	query := `
+		UPDATE users SET totp_secret = $2, totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $1 AND totp_enabled_at IS NULL AND deleted_at IS NULL
+	`
+	cmd, err := r.db.Exec(ctx, query, userID, sealed)
+	if err != nil {
+		return fmt.Errorf("set totp secret: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrConflict
+	}
+	return nil
+}
+
+// EnableTOTP confirms a pending enrolment, recording the step of the code that confirmed it.
+func (r *UserRepository) EnableTOTP(ctx context.Context, tx pgx.Tx, userID string, step int64) error {
+	query := `
+		UPDATE users SET totp_enabled_at = NOW(), totp_last_step = $2, updated_at = NOW()
+		WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL AND deleted_at IS NULL
+	`
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	cmd, err := querier.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("enable totp: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrConflict
+	}
+	return nil
+}
+
+// UseTOTPStep records that a code of the given time step was accepted. It reports false when
+// that step, or a later one, was used already, which makes every code single-use.
+func (r *UserRepository) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `
+		UPDATE users SET totp_last_step = $2
+		WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)
+	`
+	cmd, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("use totp step: %w", err)
+	}
+	return cmd.RowsAffected() > 0, nil
+}
+
+// ClearTwoFactor removes the user's TOTP secret and recovery codes.
+func (r *UserRepository) ClearTwoFactor(ctx context.Context, tx pgx.Tx, userID string) error {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	query := `
+		UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	cmd, err := querier.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("clear two-factor: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrUserNotFound
+	}
+	if _, err := querier.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("clear recovery codes: %w", err)
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes swaps the user's recovery codes for a new set of hashes.
+func (r *UserRepository) ReplaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string, hashes []string) error {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	if _, err := querier.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete recovery codes: %w", err)
+	}
+	query := `INSERT INTO user_recovery_codes (id, user_id, code_hash, created_at) VALUES ($1, $2, $3, NOW())`
+	for _, hash := range hashes {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return fmt.Errorf("failed to generate uuid v7: %w", err)
+		}
+		if _, err := querier.Exec(ctx, query, id.String(), userID, hash); err != nil {
+			return fmt.Errorf("insert recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode spends one of the user's recovery codes, reporting false when no unused
+// code matches.
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, userID, hash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	cmd, err := r.db.Exec(ctx, query, userID, hash)
+	if err != nil {
+		return false, fmt.Errorf("use recovery code: %w", err)
+	}
+	return cmd.RowsAffected() > 0, nil
+}
+
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	query := `UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
-	cmd, err := r.db.Exec(ctx, query, id)
+	query := `
+		UPDATE users SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR id IN (SELECT user_id FROM organization_members WHERE organization_id = $2))
+	`
+	cmd, err := r.db.Exec(ctx, query, id, tenantArg(ctx))
 	if err != nil { return fmt.Errorf("delete user: %w", err) }
 	if cmd.RowsAffected() == 0 { return entity.ErrRecordNotFound }
 	return nil
 }
+
+// DeleteUnverified removes self-registered accounts created before the cutoff that never
+// confirmed their email, together with the organizations they signed up.
+func (r *UserRepository) DeleteUnverified(ctx context.Context, createdBefore time.Time) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteUnverified(ctx, tx, `u.created_at < $1`, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// DeleteUnverifiedUser removes one account that never confirmed its email, along with the
+// organization it signed up, so the address can be claimed by someone who proves it.
+func (r *UserRepository) DeleteUnverifiedUser(ctx context.Context, tx pgx.Tx, userID string) error {
+	deleted, err := deleteUnverified(ctx, tx, `u.id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%w: email is already registered", entity.ErrConflict)
+	}
+	return nil
+}
+
+// deleteUnverified deletes the unverified accounts matching filter, and their organizations.
+// Accounts sharing an organization with anyone else, or whose organization already has
+// properties, are kept.
+func deleteUnverified(ctx context.Context, tx pgx.Tx, filter string, arg interface{}) (int64, error) {
+	expired := `
+		SELECT u.id FROM users u
+		WHERE u.email_verified_at IS NULL AND ` + filter + `
+		  AND NOT EXISTS (
+			SELECT 1 FROM organization_members om
+			WHERE om.user_id = u.id AND (
+				EXISTS (SELECT 1 FROM organization_members other WHERE other.organization_id = om.organization_id AND other.user_id <> u.id)
+				OR EXISTS (SELECT 1 FROM properties p WHERE p.organization_id = om.organization_id)
+			)
+		  )
+		FOR UPDATE
+	`
+	rows, err := tx.Query(ctx, expired, arg)
+	if err != nil {
+		return 0, fmt.Errorf("list unverified users: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, fmt.Errorf("list unverified users: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM organizations
+		WHERE id IN (SELECT organization_id FROM organization_members WHERE user_id = ANY($1::uuid[]))
+	`, ids); err != nil {
+		return 0, fmt.Errorf("delete unverified organizations: %w", err)
+	}
+	cmd, err := tx.Exec(ctx, `DELETE FROM users WHERE id = ANY($1::uuid[])`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("delete unverified users: %w", err)
+	}
+	return cmd.RowsAffected(), nil
+}
+
+// ResealTOTPSecrets seals every TOTP secret that is still plaintext, or sealed with a retired
+// key, with the active key, and returns how many it rewrote.
+func (r *UserRepository) ResealTOTPSecrets(ctx context.Context) (int, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, totp_secret FROM users WHERE totp_secret IS NOT NULL AND totp_secret NOT LIKE $1`,
+		fmt.Sprintf("v%d:%%", r.keys.ActiveVersion()),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("list stale totp secrets: %w", err)
+	}
+	type staleSecret struct{ id, stored string }
+	stale, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (staleSecret, error) {
+		var s staleSecret
+		err := row.Scan(&s.id, &s.stored)
+		return s, err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list stale totp secrets: %w", err)
+	}
+
+	resealed := 0
+	for _, s := range stale {
+		secret, err := r.openTOTPSecret(s.stored)
+		if err != nil {
+			return resealed, err
+		}
+		sealed, err := r.keys.Encrypt(secret)
+		if err != nil {
+			return resealed, fmt.Errorf("seal totp secret: %w", err)
+		}
+		// A secret replaced meanwhile by a new enrolment is already sealed with the active key.
+		cmd, err := r.db.Exec(ctx, `UPDATE users SET totp_secret = $3 WHERE id = $1 AND totp_secret = $2`, s.id, s.stored, sealed)
+		if err != nil {
+			return resealed, fmt.Errorf("reseal totp secret: %w", err)
+		}
+		resealed += int(cmd.RowsAffected())
+	}
+	return resealed, nil
+}
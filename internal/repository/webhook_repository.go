@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, sub entity.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (id, organization_id, url, secret, event_types, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+	`
+	_, err := r.db.Exec(ctx, query, sub.ID, sub.OrganizationID, sub.URL, sub.Secret, sub.EventTypes, sub.Active)
+	if err != nil {
+		return fmt.Errorf("create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ListByOrganization(ctx context.Context, orgID string) ([]entity.WebhookSubscription, error) {
+	query := `
+		SELECT id, organization_id, url, event_types, active, created_at, updated_at
+		FROM webhook_subscriptions
+		WHERE organization_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.WebhookSubscription
+	for rows.Next() {
+		var s entity.WebhookSubscription
+		if err := rows.Scan(&s.ID, &s.OrganizationID, &s.URL, &s.EventTypes, &s.Active, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id string) (*entity.WebhookSubscription, error) {
+	query := `
+		SELECT id, organization_id, url, event_types, active, created_at, updated_at
+		FROM webhook_subscriptions
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	var s entity.WebhookSubscription
+	err := r.db.QueryRow(ctx, query, id).Scan(&s.ID, &s.OrganizationID, &s.URL, &s.EventTypes, &s.Active, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, entity.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get webhook subscription: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, tx pgx.Tx, id string, req entity.UpdateWebhookRequest) error {
+	query := `UPDATE webhook_subscriptions SET updated_at = NOW()`
+	args := []interface{}{}
+	argID := 1
+
+	addSet := func(col string, val interface{}) {
+		query += fmt.Sprintf(", %s = $%d", col, argID)
+		args = append(args, val)
+		argID++
+	}
+
+	if req.URL != "" { addSet("url", req.URL) }
+	if len(req.EventTypes) > 0 { addSet("event_types", req.EventTypes) }
+	if req.Active != nil { addSet("active", *req.Active) }
+
+	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL", argID)
+	args = append(args, id)
+
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	cmd, err := querier.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("update webhook subscription: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, tx pgx.Tx, id string) error {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+	query := `UPDATE webhook_subscriptions SET deleted_at = NOW(), active = FALSE WHERE id = $1 AND deleted_at IS NULL`
+	cmd, err := querier.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeadLetterPending fails every delivery still queued for a subscription, so nothing more is
+// signed with its secret once it is deleted or deactivated.
+func (r *WebhookRepository) DeadLetterPending(ctx context.Context, tx pgx.Tx, subscriptionID, reason string) error {
+	query := `UPDATE webhook_deliveries SET status = 'failed', last_error = $2 WHERE subscription_id = $1 AND status = 'pending'`
+	if _, err := tx.Exec(ctx, query, subscriptionID, reason); err != nil {
+		return fmt.Errorf("dead-letter webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// EnqueueForProperty queues one delivery per active subscription of the property's organization
+// that listens to the event. Passing the business transaction makes the event durable with the change.
+func (r *WebhookRepository) EnqueueForProperty(ctx context.Context, tx pgx.Tx, propertyID string, event entity.WebhookEvent, payload []byte) error {
+	var querier DBTX = r.db
+	if tx != nil {
+		querier = tx
+	}
+
+	query := `
+		SELECT s.id
+		FROM webhook_subscriptions s
+		JOIN properties p ON p.organization_id = s.organization_id
+		WHERE p.id = $1 AND $2 = ANY(s.event_types) AND s.active AND s.deleted_at IS NULL
+	`
+	rows, err := querier.Query(ctx, query, propertyID, event.Type)
+	if err != nil {
+		return fmt.Errorf("find webhook subscribers: %w", err)
+	}
+	var subscriptionIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		subscriptionIDs = append(subscriptionIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("find webhook subscribers: %w", err)
+	}
+
+	insert := `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, subscriptionID := range subscriptionIDs {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return fmt.Errorf("failed to generate uuid v7: %w", err)
+		}
+		if _, err := querier.Exec(ctx, insert, id.String(), subscriptionID, event.ID, event.Type, payload); err != nil {
+			return fmt.Errorf("enqueue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+const deliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.max_attempts, d.next_attempt_at, d.last_status_code, d.last_error, d.delivered_at, d.created_at, d.updated_at`
+
+func scanDelivery(row pgx.Row, d *entity.WebhookDelivery, extra ...interface{}) error {
+	dest := []interface{}{
+		&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.MaxAttempts, &d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// ClaimDue leases up to limit due deliveries of live subscriptions by pushing their next
+// attempt to leaseUntil, and returns them with their endpoint. The claim commits on its own,
+// so no locks are held while the requests are sent; a worker that dies mid-batch leaves its
+// deliveries to be retried once the lease runs out.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, leaseUntil time.Time) ([]entity.WebhookDelivery, error) {
+	query := `
+		WITH due AS (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+			  AND s.active AND s.deleted_at IS NULL
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM due, webhook_subscriptions s
+		WHERE d.id = due.id AND s.id = d.subscription_id
+		RETURNING ` + deliveryColumns + `, s.url, s.secret
+	`
+	rows, err := r.db.Query(ctx, query, limit, leaseUntil)
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.WebhookDelivery
+	for rows.Next() {
+		var d entity.WebhookDelivery
+		if err := scanDelivery(rows, &d, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// MarkDelivered records a successful attempt. Like the other outcomes it only applies while
+// the delivery is pending, so one dead-lettered mid-send stays failed.
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id string, attempts, statusCode int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = $2, last_status_code = $3, last_error = NULL, delivered_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`
+	if _, err := r.db.Exec(ctx, query, id, attempts, statusCode); err != nil {
+		return fmt.Errorf("mark webhook delivered: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ScheduleRetry(ctx context.Context, id string, attempts int, statusCode *int, nextAttempt time.Time, lastError string) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = $2, last_status_code = $3, next_attempt_at = $4, last_error = $5
+		WHERE id = $1 AND status = 'pending'
+	`
+	if _, err := r.db.Exec(ctx, query, id, attempts, statusCode, nextAttempt, lastError); err != nil {
+		return fmt.Errorf("schedule webhook retry: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id string, attempts int, statusCode *int, lastError string) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'failed', attempts = $2, last_status_code = $3, last_error = $4
+		WHERE id = $1 AND status = 'pending'
+	`
+	if _, err := r.db.Exec(ctx, query, id, attempts, statusCode, lastError); err != nil {
+		return fmt.Errorf("mark webhook failed: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, pagination entity.PaginationRequest) ([]entity.WebhookDelivery, int64, error) {
+	countQuery := `SELECT COUNT(*) FROM webhook_deliveries WHERE subscription_id = $1`
+	var total int64
+	if err := r.db.QueryRow(ctx, countQuery, subscriptionID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count webhook deliveries: %w", err)
+	}
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1
+		ORDER BY d.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	rows, err := r.db.Query(ctx, query, subscriptionID, pagination.Limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var list []entity.WebhookDelivery
+	for rows.Next() {
+		var d entity.WebhookDelivery
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, d)
+	}
+	return list, total, nil
+}
@@ -0,0 +1,138 @@
+package security
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// FailureLimiter counts failed attempts per key within a sliding window.
+type FailureLimiter struct {
+	mu        sync.Mutex
+	max       int
+	window    time.Duration
+	failures  map[string][]time.Time
+	lastSweep time.Time
+}
+
+func NewFailureLimiter(max int, window time.Duration) *FailureLimiter {
+	return &FailureLimiter{max: max, window: window, failures: map[string][]time.Time{}}
+}
+
+// Blocked reports whether key has used up its failed attempts for the current window.
+func (l *FailureLimiter) Blocked(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.recent(key, time.Now())) >= l.max
+}
+
+// Fail counts an attempt against key and reports whether that used up the last one.
+func (l *FailureLimiter) Fail(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.sweep(now)
+	l.failures[key] = append(l.recent(key, now), now)
+	return len(l.failures[key]) == l.max
+}
+
+// sweep forgets keys whose failures all fell out of the window, at most once per window, so
+// clients that never come back do not stay in memory. Callers hold the lock.
+func (l *FailureLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+	for key := range l.failures {
+		l.recent(key, now)
+	}
+}
+
+// RetryAfter is how long until key gets an attempt back.
+func (l *FailureLimiter) RetryAfter(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	recent := l.recent(key, now)
+	if len(recent) < l.max {
+		return 0
+	}
+	return l.window - now.Sub(recent[len(recent)-l.max])
+}
+
+// recent drops failures that fell out of the window. Callers hold the lock.
+func (l *FailureLimiter) recent(key string, now time.Time) []time.Time {
+	kept := l.failures[key][:0]
+	for _, at := range l.failures[key] {
+		if now.Sub(at) < l.window {
+			kept = append(kept, at)
+		}
+	}
+	if len(kept) == 0 {
+		delete(l.failures, key)
+		return nil
+	}
+	l.failures[key] = kept
+	return kept
+}
+
+// LimitFailures rejects clients that keep failing: every 401, 403 or 404 answered to a
+// client IP counts against it, and once the limit is reached it gets 429 until the window passes.
+func LimitFailures(limiter *FailureLimiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.RealIP()
+			if limiter.Blocked(key) {
+				return tooManyAttempts(c, limiter, key)
+			}
+
+			err := next(c)
+			switch c.Response().Status {
+			case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+				if limiter.Fail(key) {
+					logBlocked(c, key)
+				}
+			}
+			return err
+		}
+	}
+}
+
+// LimitRequests caps how often a client IP may call an endpoint at all. It suits endpoints
+// that answer the same whatever happens, such as password reset requests, where failures
+// cannot be told apart.
+func LimitRequests(limiter *FailureLimiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.RealIP()
+			if limiter.Blocked(key) {
+				return tooManyAttempts(c, limiter, key)
+			}
+			if limiter.Fail(key) {
+				logBlocked(c, key)
+			}
+			return next(c)
+		}
+	}
+}
+
+func tooManyAttempts(c echo.Context, limiter *FailureLimiter, key string) error {
+	if wait := limiter.RetryAfter(key); wait > 0 {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	}
+	return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "too many attempts, try again later"})
+}
+
+// logBlocked records the moment a client is throttled, as a security event.
+func logBlocked(c echo.Context, key string) {
+	if log, ok := c.Get("logger").(*zap.Logger); ok {
+		log.Warn("client throttled after repeated attempts",
+			zap.String("ip", key),
+			zap.String("path", c.Path()),
+		)
+	}
+}
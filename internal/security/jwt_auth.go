@@ -2,18 +2,27 @@ package security
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/tenant"
 	"github.com/ecelayes/pms-backend/pkg/auth"
 )
 
-type SaltProvider interface {
-	GetUserSalt(ctx context.Context, userID string) (string, error)
+// AuthProvider verifies access tokens against the signing keys, checks the user's token
+// version and the session behind a token, and resolves the caller's membership in the
+// organization their token is for.
+type AuthProvider interface {
+	VerifyAccessToken(token string) (*auth.Claims, error)
+	GetTokenVersion(ctx context.Context, userID string) (int, error)
+	ValidateSession(ctx context.Context, sessionID, userID string) error
+	GetMembership(ctx context.Context, userID, orgID string) (*entity.OrganizationMember, error)
 }
 
-func Auth(provider SaltProvider) echo.MiddlewareFunc {
+func Auth(provider AuthProvider) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
@@ -27,29 +36,63 @@ func Auth(provider SaltProvider) echo.MiddlewareFunc {
 			}
 			tokenString := parts[1]
 
-			claims, err := auth.ParseTokenClaimsUnsafe(tokenString)
+			// Nothing in the token is trusted before its signature has been checked.
+			validClaims, err := provider.VerifyAccessToken(tokenString)
 			if err != nil {
-				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "malformed token"})
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
 			}
 
-			if claims.Purpose != auth.PurposeAuth {
-				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token purpose"})
-			}
-
-			userSalt, err := provider.GetUserSalt(c.Request().Context(), claims.UserID)
+			version, err := provider.GetTokenVersion(c.Request().Context(), validClaims.UserID)
 			if err != nil {
 				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "user not found or inactive"})
 			}
+			if validClaims.TokenVersion != version {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "token has been revoked"})
+			}
 
-			validClaims, err := auth.ValidateSignature(tokenString, userSalt)
-			if err != nil {
-				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token signature"})
+			// Access tokens die with their session, so logging out takes effect immediately.
+			if validClaims.SessionID == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token session"})
+			}
+			if err := provider.ValidateSession(c.Request().Context(), validClaims.SessionID, validClaims.UserID); err != nil {
+				if errors.Is(err, entity.ErrRecordNotFound) {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": "session expired or revoked"})
+				}
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to resolve session"})
 			}
 
 			c.Set("user_id", validClaims.UserID)
+			c.Set("session_id", validClaims.SessionID)
 			c.Set("organization_id", validClaims.OrganizationID)
 			c.Set("role", validClaims.Role)
 
+			// Everyone but super admins only reaches their own organization's data.
+			if validClaims.Role != entity.RoleSuperAdmin {
+				if validClaims.OrganizationID == "" {
+					return c.JSON(http.StatusForbidden, map[string]string{"error": "user has no organization"})
+				}
+				ctx := tenant.WithOrganization(c.Request().Context(), validClaims.OrganizationID)
+
+				// The membership is checked on every request, so removed members lose access and
+				// role changes apply at once. Members restricted to some properties only reach those.
+				member, err := provider.GetMembership(ctx, validClaims.UserID, validClaims.OrganizationID)
+				if err != nil {
+					if errors.Is(err, entity.ErrRecordNotFound) {
+						return c.JSON(http.StatusForbidden, map[string]string{"error": "not a member of this organization"})
+					}
+					return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to resolve membership"})
+				}
+				c.Set("role", member.Role)
+				if !member.AllProperties {
+					scope := member.PropertyIDs
+					if scope == nil {
+						scope = []string{}
+					}
+					ctx = tenant.WithProperties(ctx, scope)
+				}
+				c.SetRequest(c.Request().WithContext(ctx))
+			}
+
 			return next(c)
 		}
 	}
@@ -22,3 +22,21 @@ func RequireSuperAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// RequirePermission admits callers whose role grants perm; see entity.HasPermission.
+func RequirePermission(perm entity.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role, ok := c.Get("role").(string)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			}
+
+			if !entity.HasPermission(role, perm) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "missing permission: " + string(perm)})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+// EmailSender delivers an already rendered message. The outbox worker is its only caller.
+type EmailSender interface {
+	Send(ctx context.Context, msg entity.EmailMessage) error
+}
+
+// NewEmailSenderFromEnv uses SMTP when SMTP_HOST is configured and falls back to the log sink,
+// which keeps development and test environments from needing a mail server.
+func NewEmailSenderFromEnv(logger *zap.Logger) EmailSender {
+	if os.Getenv("SMTP_HOST") != "" {
+		return NewSMTPSender(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USER"),
+			os.Getenv("SMTP_PASS"),
+		)
+	}
+	return NewLogSender(os.Getenv("EMAIL_SINK_FILE"), logger)
+}
+
+type SMTPSender struct {
+	host string
+	port string
+	user string
+	pass string
+}
+
+func NewSMTPSender(host, port, user, pass string) *SMTPSender {
+	return &SMTPSender{host: host, port: port, user: user, pass: pass}
+}
+
+// Send delivers msg over a single SMTP exchange. Cancelling ctx aborts the exchange, so a
+// stalled mail server cannot hold up the outbox worker past shutdown.
+func (s *SMTPSender) Send(ctx context.Context, msg entity.EmailMessage) error {
+	headers := "MIME-version: 1.0;\n" +
+		"Content-Type: text/html; charset=\"UTF-8\";\n" +
+		fmt.Sprintf("From: PMS Support <%s>\n", s.user) +
+		fmt.Sprintf("To: %s\n", msg.To) +
+		fmt.Sprintf("Subject: %s\n\n", msg.Subject)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.host, s.port))
+	if err != nil {
+		return fmt.Errorf("sending email via smtp: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := s.deliver(conn, msg.To, []byte(headers+msg.Body)); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("sending email via smtp: %w", ctxErr)
+		}
+		return fmt.Errorf("sending email via smtp: %w", err)
+	}
+	return nil
+}
+
+// deliver runs the SMTP conversation smtp.SendMail would, over an already dialled conn.
+func (s *SMTPSender) deliver(conn net.Conn, to string, body []byte) error {
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok && s.user != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.user, s.pass, s.host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.user); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// LogSender records messages instead of delivering them. When a file path is set the
+// full message is appended to it so it can be inspected after the fact.
+type LogSender struct {
+	path   string
+	logger *zap.Logger
+	mu     sync.Mutex
+}
+
+func NewLogSender(path string, logger *zap.Logger) *LogSender {
+	return &LogSender{path: path, logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg entity.EmailMessage) error {
+	s.logger.Info("email delivered to log sink",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+	)
+
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening email sink: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().UTC().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+	if err != nil {
+		return fmt.Errorf("writing email sink: %w", err)
+	}
+	return nil
+}
@@ -4,38 +4,60 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
+	"html"
 	"html/template"
-	"net/smtp"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
 )
 
 //go:embed templates
 var templateFS embed.FS
 
+// EmailService renders transactional emails. Delivery is handled by the outbox worker
+// through an EmailSender, so composing a message never blocks on a mail server.
 type EmailService struct {
-	smtpHost string
-	smtpPort string
-	smtpUser string
-	smtpPass string
-	baseURL  string
+	baseURL string
 }
 
 func NewEmailService() *EmailService {
 	return &EmailService{
-		smtpHost: os.Getenv("SMTP_HOST"),
-		smtpPort: os.Getenv("SMTP_PORT"),
-		smtpUser: os.Getenv("SMTP_USER"),
-		smtpPass: os.Getenv("SMTP_PASS"),
-		baseURL:  os.Getenv("FRONTEND_URL"),
+		baseURL: os.Getenv("FRONTEND_URL"),
 	}
 }
 
-func (s *EmailService) SendPasswordReset(toEmail, userName, token string) error {
-	tmpl, err := template.ParseFS(templateFS, "templates/reset_password.html")
-	if err != nil {
-		return fmt.Errorf("parsing email template: %w", err)
-	}
+// ReservationEmail carries everything the guest-facing booking templates render.
+type ReservationEmail struct {
+	Language        string
+	GuestName       string
+	GuestEmail      string
+	PropertyName    string
+	ReservationCode string
+	UnitTypeName    string
+	CheckIn         time.Time
+	CheckOut        time.Time
+	Nights          int
+	Adults          int
+	Children        int
+
+	RatePlan           *entity.RatePlan
+	NightlyRates       []entity.DailyRate
+	AccommodationTotal float64
+	MealPlanTotal      float64
+	AddOns             []entity.ReservationAddOn
+	Total              float64
+	Currency           string
+
+	PenaltyAmount float64
 
+	// AccessToken, when set, adds a self-service link to the booking.
+	AccessToken string
+	ManageLink  string
+}
+
+func (s *EmailService) PasswordReset(lang, toEmail, userName, token string) (entity.EmailMessage, error) {
 	link := fmt.Sprintf("%s/reset-password?token=%s", s.baseURL, token)
 	data := struct {
 		Name string
@@ -45,25 +67,168 @@ func (s *EmailService) SendPasswordReset(toEmail, userName, token string) error
 		Link: link,
 	}
 
-	var body bytes.Buffer
-	if err := tmpl.Execute(&body, data); err != nil {
-		return fmt.Errorf("executing email template: %w", err)
+	return s.compose(lang, toEmail, "reset_password.html", data)
+}
+
+func (s *EmailService) EmailVerification(lang, toEmail, userName, orgName, token string) (entity.EmailMessage, error) {
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.baseURL, token)
+	data := struct {
+		Name         string
+		Organization string
+		Link         string
+	}{
+		Name:         userName,
+		Organization: orgName,
+		Link:         link,
+	}
+
+	return s.compose(lang, toEmail, "verify_email.html", data)
+}
+
+func (s *EmailService) Invitation(lang, toEmail, orgName, role, token string) (entity.EmailMessage, error) {
+	link := fmt.Sprintf("%s/accept-invitation?token=%s", s.baseURL, token)
+	data := struct {
+		Organization string
+		Role         string
+		Link         string
+	}{
+		Organization: orgName,
+		Role:         role,
+		Link:         link,
+	}
+
+	return s.compose(lang, toEmail, "invitation.html", data)
+}
+
+func (s *EmailService) ReservationConfirmation(data ReservationEmail) (entity.EmailMessage, error) {
+	data = s.withManageLink(data)
+	return s.compose(data.Language, data.GuestEmail, "reservation_confirmation.html", data, "reservation_details.html")
+}
+
+func (s *EmailService) ReservationModification(data ReservationEmail) (entity.EmailMessage, error) {
+	data = s.withManageLink(data)
+	return s.compose(data.Language, data.GuestEmail, "reservation_modification.html", data, "reservation_details.html")
+}
+
+func (s *EmailService) ReservationCancellation(data ReservationEmail) (entity.EmailMessage, error) {
+	data = s.withManageLink(data)
+	return s.compose(data.Language, data.GuestEmail, "reservation_cancellation.html", data, "reservation_details.html")
+}
+
+func (s *EmailService) PreArrivalReminder(data ReservationEmail) (entity.EmailMessage, error) {
+	data = s.withManageLink(data)
+	return s.compose(data.Language, data.GuestEmail, "pre_arrival_reminder.html", data, "reservation_details.html")
+}
+
+// withManageLink points the guest at the self-service page of the booking.
+func (s *EmailService) withManageLink(data ReservationEmail) ReservationEmail {
+	if data.AccessToken != "" {
+		data.ManageLink = fmt.Sprintf("%s/reservations/%s?token=%s", s.baseURL, data.ReservationCode, data.AccessToken)
 	}
+	return data
+}
 
-	headers := "MIME-version: 1.0;\n" +
-		"Content-Type: text/html; charset=\"UTF-8\";\n" +
-		fmt.Sprintf("From: PMS Support <%s>\n", s.smtpUser) +
-		fmt.Sprintf("To: %s\n", toEmail) +
-		"Subject: Recuperación de Contraseña\n\n"
+// compose renders the template set of the requested language. Every template defines
+// a "subject" block next to its body, so subjects are translated alongside the content.
+func (s *EmailService) compose(lang, to, name string, data interface{}, partials ...string) (entity.EmailMessage, error) {
+	lang = entity.ResolveLanguage(lang)
 
-	msg := []byte(headers + body.String())
+	files := []string{fmt.Sprintf("templates/%s/%s", lang, name)}
+	for _, partial := range partials {
+		files = append(files, fmt.Sprintf("templates/%s/%s", lang, partial))
+	}
 
-	auth := smtp.PlainAuth("", s.smtpUser, s.smtpPass, s.smtpHost)
-	addr := fmt.Sprintf("%s:%s", s.smtpHost, s.smtpPort)
+	tmpl, err := template.New(name).Funcs(emailFuncs(lang)).ParseFS(templateFS, files...)
+	if err != nil {
+		return entity.EmailMessage{}, fmt.Errorf("parsing email template: %w", err)
+	}
 
-	if err := smtp.SendMail(addr, auth, s.smtpUser, []string{toEmail}, msg); err != nil {
-		return fmt.Errorf("sending email via smtp: %w", err)
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return entity.EmailMessage{}, fmt.Errorf("executing email subject: %w", err)
 	}
+	if err := tmpl.ExecuteTemplate(&body, name, data); err != nil {
+		return entity.EmailMessage{}, fmt.Errorf("executing email template: %w", err)
+	}
+
+	return entity.EmailMessage{
+		To:      to,
+		Subject: html.UnescapeString(strings.TrimSpace(subject.String())),
+		Body:    body.String(),
+	}, nil
+}
+
+var emailLabels = map[string]map[string]string{
+	entity.LanguageSpanish: {
+		"date":        "02/01/2006",
+		"percentage":  "%.0f%% del total",
+		"nights":      "%.0f noche(s)",
+		"continental": "Desayuno continental",
+		"buffet":      "Desayuno buffet",
+		"american":    "Desayuno americano",
+		"half_board":  "Media pensión",
+		"full_board":  "Pensión completa",
+		"room_only":   "Solo alojamiento",
+		"prepay_part": "Prepago del %.0f%% al reservar",
+		"prepay_full": "Prepago total al reservar",
+		"on_arrival":  "Pago en el alojamiento",
+	},
+	entity.LanguageEnglish: {
+		"date":        "Jan 2, 2006",
+		"percentage":  "%.0f%% of the total",
+		"nights":      "%.0f night(s)",
+		"continental": "Continental breakfast",
+		"buffet":      "Buffet breakfast",
+		"american":    "American breakfast",
+		"half_board":  "Half board",
+		"full_board":  "Full board",
+		"room_only":   "Room only",
+		"prepay_part": "%.0f%% prepayment at booking",
+		"prepay_full": "Full prepayment at booking",
+		"on_arrival":  "Pay at the property",
+	},
+}
 
-	return nil
+func emailFuncs(lang string) template.FuncMap {
+	labels := emailLabels[lang]
+
+	return template.FuncMap{
+		"money": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+		"date":  func(t time.Time) string { return t.Format(labels["date"]) },
+		"penalty": func(rule entity.CancellationRule) string {
+			switch rule.PenaltyType {
+			case entity.PenaltyPercentage:
+				return fmt.Sprintf(labels["percentage"], rule.PenaltyValue)
+			case entity.PenaltyNights:
+				return fmt.Sprintf(labels["nights"], rule.PenaltyValue)
+			default:
+				return fmt.Sprintf("%.2f", rule.PenaltyValue)
+			}
+		},
+		"mealPlan": func(t entity.MealType) string {
+			switch t {
+			case entity.MealTypeContinental:
+				return labels["continental"]
+			case entity.MealTypeBuffet:
+				return labels["buffet"]
+			case entity.MealTypeAmerican:
+				return labels["american"]
+			case entity.MealTypeHalfBoard:
+				return labels["half_board"]
+			case entity.MealTypeFullBoard:
+				return labels["full_board"]
+			default:
+				return labels["room_only"]
+			}
+		},
+		"payment": func(p entity.PaymentPolicy) string {
+			if p.Timing == entity.PayPrepaid {
+				if p.PrepayPercent > 0 && p.PrepayPercent < 100 {
+					return fmt.Sprintf(labels["prepay_part"], p.PrepayPercent)
+				}
+				return labels["prepay_full"]
+			}
+			return labels["on_arrival"]
+		},
+	}
 }
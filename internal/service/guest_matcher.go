@@ -0,0 +1,114 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+const (
+	nameWeight  = 0.5
+	emailWeight = 0.3
+	phoneWeight = 0.2
+)
+
+// GuestMatcher scores guest profiles that likely belong to the same person.
+type GuestMatcher struct{}
+
+func NewGuestMatcher() *GuestMatcher {
+	return &GuestMatcher{}
+}
+
+// ScorePairs scores the candidate pairs and returns those reaching threshold, best matches
+// first. Pairs whose guests are missing from guests are skipped.
+func (m *GuestMatcher) ScorePairs(guests map[string]entity.Guest, pairs [][2]string, threshold float64) []entity.GuestDuplicate {
+	var result []entity.GuestDuplicate
+	for _, pair := range pairs {
+		a, okA := guests[pair[0]]
+		b, okB := guests[pair[1]]
+		if !okA || !okB {
+			continue
+		}
+		score, reasons := m.Score(a, b)
+		if score < threshold {
+			continue
+		}
+		result = append(result, entity.GuestDuplicate{
+			Guest:     a,
+			Candidate: b,
+			Score:     score,
+			Reasons:   reasons,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		if result[i].Guest.ID != result[j].Guest.ID {
+			return result[i].Guest.ID < result[j].Guest.ID
+		}
+		return result[i].Candidate.ID < result[j].Candidate.ID
+	})
+	return result
+}
+
+// Score weighs name, email and phone similarity into a value between 0 and 1.
+func (m *GuestMatcher) Score(a, b entity.Guest) (float64, []string) {
+	var reasons []string
+
+	nameScore := similarity(
+		entity.NormalizeName(a.FirstName+" "+a.LastName),
+		entity.NormalizeName(b.FirstName+" "+b.LastName),
+	)
+	if nameScore >= 0.85 {
+		reasons = append(reasons, "similar_name")
+	}
+
+	emailScore := similarity(entity.EmailLocalPart(a.Email), entity.EmailLocalPart(b.Email))
+	if emailScore >= 0.8 {
+		reasons = append(reasons, "similar_email")
+	}
+
+	phoneScore := 0.0
+	if pa, pb := entity.PhoneKey(a.Phone), entity.PhoneKey(b.Phone); pa != "" && pa == pb {
+		phoneScore = 1
+		reasons = append(reasons, "same_phone")
+	}
+
+	score := nameWeight*nameScore + emailWeight*emailScore + phoneWeight*phoneScore
+	return math.Round(score*100) / 100, reasons
+}
+
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
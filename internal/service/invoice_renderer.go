@@ -0,0 +1,45 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/pkg/pdf"
+)
+
+type InvoiceRenderer struct{}
+
+func NewInvoiceRenderer() *InvoiceRenderer {
+	return &InvoiceRenderer{}
+}
+
+type InvoiceDocument struct {
+	Invoice         entity.Invoice
+	Property        entity.Property
+	ReservationCode string
+	CorrectedNumber string
+}
+
+func (r *InvoiceRenderer) RenderPDF(doc InvoiceDocument) ([]byte, error) {
+	funcs := template.FuncMap{
+		"money":  func(v float64) string { return fmt.Sprintf("%.2f", v) },
+		"repeat": strings.Repeat,
+	}
+
+	tmpl, err := template.New("invoice.txt").Funcs(funcs).ParseFS(templateFS, "templates/invoice.txt")
+	if err != nil {
+		return nil, fmt.Errorf("parsing invoice template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, doc); err != nil {
+		return nil, fmt.Errorf("executing invoice template: %w", err)
+	}
+
+	document := pdf.New()
+	document.AddText(body.String())
+	return document.Bytes(), nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// ErrWebhookTargetBlocked is returned when a webhook URL points at an address the
+// sender refuses to reach: loopback, private, link-local or unspecified.
+var ErrWebhookTargetBlocked = errors.New("webhook target address is not allowed")
+
+type WebhookSender struct {
+	client        *http.Client
+	allowLoopback bool
+}
+
+// NewWebhookSender builds a sender that only connects to public addresses. The check
+// runs on the resolved address at dial time, so DNS names pointing inside the network
+// are refused too, and redirects are never followed. allowLoopback exists for tests
+// that deliver to a local receiver.
+func NewWebhookSender(allowLoopback bool) *WebhookSender {
+	s := &WebhookSender{allowLoopback: allowLoopback}
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrWebhookTargetBlocked, address)
+			}
+			if !s.allowed(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrWebhookTargetBlocked, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	s.client = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return s
+}
+
+func (s *WebhookSender) allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() {
+		return s.allowLoopback
+	}
+	return !(addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsUnspecified())
+}
+
+// CheckURL rejects URLs whose host is a literal blocked address or localhost. Names
+// that resolve to such addresses are caught when a delivery dials them.
+func (s *WebhookSender) CheckURL(u *url.URL) error {
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !s.allowed(addr) {
+			return ErrWebhookTargetBlocked
+		}
+		return nil
+	}
+	if !s.allowLoopback && (host == "localhost" || strings.HasSuffix(host, ".localhost")) {
+		return ErrWebhookTargetBlocked
+	}
+	return nil
+}
+
+// SignWebhook computes the signature subscribers verify: hex HMAC-SHA256 of "<timestamp>.<body>".
+// Including the timestamp lets receivers reject replayed requests.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts a delivery and returns the response status code. Any non-2xx answer is an error.
+func (s *WebhookSender) Send(ctx context.Context, delivery entity.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("building webhook request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pms-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("posting webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,39 @@
+// Package tenant carries the organization a request acts for through its context,
+// so repositories can restrict every query to that organization's data.
+package tenant
+
+import "context"
+
+type ctxKey struct{}
+
+// WithOrganization scopes ctx to an organization.
+func WithOrganization(ctx context.Context, orgID string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, orgID)
+}
+
+// OrganizationID returns the organization ctx is scoped to. Contexts of super admins,
+// public endpoints and background workers are not scoped and see every organization.
+func OrganizationID(ctx context.Context) (string, bool) {
+	orgID, ok := ctx.Value(ctxKey{}).(string)
+	return orgID, ok
+}
+
+// Allows reports whether a scoped caller may act on data of orgID.
+func Allows(ctx context.Context, orgID string) bool {
+	scoped, ok := OrganizationID(ctx)
+	return !ok || scoped == orgID
+}
+
+type propertiesKey struct{}
+
+// WithProperties further restricts a scoped ctx to the given properties of its organization.
+func WithProperties(ctx context.Context, propertyIDs []string) context.Context {
+	return context.WithValue(ctx, propertiesKey{}, propertyIDs)
+}
+
+// PropertyIDs returns the properties ctx is restricted to. Members without a restriction
+// work with every property of their organization.
+func PropertyIDs(ctx context.Context) ([]string, bool) {
+	ids, ok := ctx.Value(propertiesKey{}).([]string)
+	return ids, ok
+}
@@ -3,8 +3,14 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 	"go.uber.org/zap"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ecelayes/pms-backend/internal/entity"
 	"github.com/ecelayes/pms-backend/internal/repository"
@@ -16,7 +22,11 @@ type AuthUseCase struct {
 	db           *pgxpool.Pool
 	userRepo     *repository.UserRepository
 	orgRepo      *repository.OrganizationRepository
+	sessionRepo  *repository.SessionRepository
+	keys         *auth.SigningKeyRing
+	passwords    auth.PasswordPolicy
 	emailService *service.EmailService
+	outboxRepo   *repository.EmailOutboxRepository
 	logger       *zap.Logger
 }
 
@@ -24,38 +34,881 @@ func NewAuthUseCase(
 	db *pgxpool.Pool, 
 	userRepo *repository.UserRepository, 
 	orgRepo *repository.OrganizationRepository,
+	sessionRepo *repository.SessionRepository,
+	keys *auth.SigningKeyRing,
+	passwords auth.PasswordPolicy,
 	emailService *service.EmailService,
+	outboxRepo *repository.EmailOutboxRepository,
 	logger *zap.Logger,
 ) *AuthUseCase {
 	return &AuthUseCase{
 		db:           db,
 		userRepo:     userRepo,
 		orgRepo:      orgRepo,
+		sessionRepo:  sessionRepo,
+		keys:         keys,
+		passwords:    passwords,
 		emailService: emailService,
+		outboxRepo:   outboxRepo,
 		logger:       logger,
 	}
 }
 
-func (uc *AuthUseCase) Login(ctx context.Context, req entity.AuthRequest) (string, error) {
+// refreshTokenTTL is how long a session survives without being refreshed.
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+// twoFactorIssuer names the account in authenticator apps.
+const twoFactorIssuer = "PMS"
+
+// Failed sign-ins, wrong passwords and wrong second factors alike, are counted per account.
+// The first few are free; each further one makes the account wait twice as long as the last
+// before it accepts another attempt, until it is locked out for a while.
+const (
+	freeLoginAttempts = 3
+	loginDelayBase    = time.Second
+	lockoutThreshold  = 10
+	lockoutDuration   = 15 * time.Minute
+)
+
+// unverifiedSignupTTL is how long a self-registered account may go without confirming its
+// email before it is deleted, freeing the address and the organization name.
+const unverifiedSignupTTL = 7 * 24 * time.Hour
+
+// passwordResetCooldown is the minimum time between two reset emails for the same account.
+const passwordResetCooldown = 5 * time.Minute
+
+// loginDelays lists loginDelay for every attempt up to the lockout, for the database to apply.
+var loginDelays = func() []time.Duration {
+	delays := make([]time.Duration, lockoutThreshold)
+	for i := range delays {
+		delays[i] = loginDelay(i + 1)
+	}
+	return delays
+}()
+
+// dummyPasswordHash is checked against when there is no account or the account is throttled,
+// so those answers take as long as a wrong password and timing does not reveal the account.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, err := auth.HashPassword("dummy password for unknown accounts")
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// loginDelay is how long an account waits after its nth consecutive failure.
+func loginDelay(failures int) time.Duration {
+	switch {
+	case failures >= lockoutThreshold:
+		return lockoutDuration
+	case failures > freeLoginAttempts:
+		return loginDelayBase << (failures - freeLoginAttempts - 1)
+	default:
+		return 0
+	}
+}
+
+// Login signs a user in to one of their organizations: the one requested, or their first
+// membership. It starts a session for the client and returns its refresh token along with
+// the access token; the response lists every membership so clients can offer switching.
+//
+// Users with two-factor authentication, or whose organization requires it, get a challenge
+// token instead and finish with LoginTwoFactor.
+func (uc *AuthUseCase) Login(ctx context.Context, req entity.AuthRequest, client entity.SessionClient) (*entity.AuthResponse, error) {
 	user, err := uc.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
-		return "", err
+		if errors.Is(err, entity.ErrInvalidCredentials) {
+			auth.CheckPassword(req.Password, dummyPasswordHash())
+			uc.logger.Warn("sign-in failed for unknown account", zap.String("ip", client.IPAddress))
+		}
+		return nil, err
 	}
 
+	// Throttled accounts are refused without looking at the password, so guessing on
+	// cannot tell whether a guess was right. They answer like unknown emails, in the same
+	// time, so throttling does not reveal that an account exists either.
+	attempt, err := uc.claimAttempt(ctx, user, client)
+	if err != nil {
+		if errors.Is(err, entity.ErrAccountLocked) {
+			auth.CheckPassword(req.Password, dummyPasswordHash())
+			return nil, entity.ErrInvalidCredentials
+		}
+		return nil, err
+	}
 	if !auth.CheckPassword(req.Password, user.Password) {
-		return "", entity.ErrInvalidCredentials
+		uc.logLoginFailure(user, client, "password", attempt)
+		return nil, entity.ErrInvalidCredentials
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, entity.ErrEmailNotVerified
+	}
+
+	grant, err := uc.resolveGrant(ctx, user.ID, user.Role, req.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := uc.twoFactorRequired(ctx, grant)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled || required {
+		challenge, err := auth.GenerateTwoFactorChallenge(uc.keys, user.ID, grant.OrganizationID, user.TokenVersion)
+		if err != nil {
+			return nil, err
+		}
+		return &entity.AuthResponse{
+			TwoFactorRequired:      true,
+			TwoFactorSetupRequired: !user.TwoFactorEnabled,
+			ChallengeToken:         challenge,
+		}, nil
+	}
+
+	return uc.startSession(ctx, user, grant, client)
+}
+
+// LoginTwoFactor completes a login that was answered with a challenge. Users enrolling
+// because their organization requires it confirm the secret from BeginTwoFactorLoginSetup
+// with their first code and receive their recovery codes in the response.
+func (uc *AuthUseCase) LoginTwoFactor(ctx context.Context, req entity.TwoFactorLoginRequest, client entity.SessionClient) (*entity.AuthResponse, error) {
+	user, claims, err := uc.resolveChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	attempt, err := uc.claimAttempt(ctx, user, client)
+	if err != nil {
+		if errors.Is(err, entity.ErrAccountLocked) {
+			return nil, entity.ErrInvalidTwoFactor
+		}
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	if user.TwoFactorEnabled {
+		err = uc.verifySecondFactor(ctx, user, req.Code, req.RecoveryCode)
+	} else {
+		recoveryCodes, err = uc.confirmEnrolment(ctx, user, req.Code)
+	}
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidTwoFactor) {
+			uc.logLoginFailure(user, client, "two_factor", attempt)
+		}
+		return nil, err
+	}
+
+	grant, err := uc.resolveGrant(ctx, user.ID, user.Role, claims.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := uc.startSession(ctx, user, grant, client)
+	if err != nil {
+		return nil, err
+	}
+	res.RecoveryCodes = recoveryCodes
+	return res, nil
+}
+
+// BeginTwoFactorLoginSetup starts enrolment for a user who was challenged at login because
+// their organization requires two-factor authentication.
+func (uc *AuthUseCase) BeginTwoFactorLoginSetup(ctx context.Context, challengeToken string) (*entity.TwoFactorSetup, error) {
+	user, _, err := uc.resolveChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return uc.beginEnrolment(ctx, user)
+}
+
+// claimAttempt counts a sign-in attempt against the account before anything is checked, and
+// refuses it while the account is throttled. A successful sign-in clears the count again.
+func (uc *AuthUseCase) claimAttempt(ctx context.Context, user *entity.User, client entity.SessionClient) (int, error) {
+	attempt, lockedUntil, err := uc.userRepo.ClaimLoginAttempt(ctx, user.ID, loginDelays)
+	if err != nil {
+		return 0, err
+	}
+	if attempt > 0 {
+		return attempt, nil
+	}
+	locked := &entity.AccountLockedError{}
+	fields := []zap.Field{zap.String("user_id", user.ID), zap.String("ip", client.IPAddress)}
+	if lockedUntil != nil {
+		locked.Until = *lockedUntil
+		fields = append(fields, zap.Time("locked_until", *lockedUntil))
+	}
+	uc.logger.Warn("sign-in attempt on throttled account", fields...)
+	return 0, locked
+}
+
+// logLoginFailure records a failed attempt as a security event, noting the wait it earned.
+func (uc *AuthUseCase) logLoginFailure(user *entity.User, client entity.SessionClient, factor string, attempt int) {
+	fields := []zap.Field{
+		zap.String("user_id", user.ID),
+		zap.String("ip", client.IPAddress),
+		zap.String("factor", factor),
+		zap.Int("failures", attempt),
+	}
+	switch delay := loginDelay(attempt); {
+	case attempt >= lockoutThreshold:
+		uc.logger.Warn("account locked after repeated sign-in failures", append(fields, zap.Duration("delay", delay))...)
+	case delay > 0:
+		uc.logger.Warn("sign-in failed, account throttled", append(fields, zap.Duration("delay", delay))...)
+	default:
+		uc.logger.Warn("sign-in failed", fields...)
+	}
+}
+
+// guardSecondFactor runs a second-factor check for a signed-in user under the same
+// per-account throttle as sign-in, so the account endpoints cannot be used to guess codes
+// without limit.
+func (uc *AuthUseCase) guardSecondFactor(ctx context.Context, user *entity.User, client entity.SessionClient, check func() error) error {
+	attempt, err := uc.claimAttempt(ctx, user, client)
+	if err != nil {
+		return err
+	}
+	if err := check(); err != nil {
+		if errors.Is(err, entity.ErrInvalidTwoFactor) || errors.Is(err, entity.ErrInvalidCredentials) {
+			uc.logLoginFailure(user, client, "two_factor", attempt)
+		}
+		return err
+	}
+	return uc.userRepo.ResetLoginFailures(ctx, user.ID)
+}
+
+// startSession opens a session for the client and issues its first pair of tokens. A
+// completed sign-in clears the account's failure count.
+func (uc *AuthUseCase) startSession(ctx context.Context, user *entity.User, grant *grant, client entity.SessionClient) (*entity.AuthResponse, error) {
+	if err := uc.userRepo.ResetLoginFailures(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	refreshToken, tokenHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	sessionID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+	session := entity.Session{
+		ID:             sessionID.String(),
+		UserID:         user.ID,
+		OrganizationID: grant.OrganizationID,
+		UserAgent:      truncate(client.UserAgent, 512),
+		IPAddress:      truncate(client.IPAddress, 45),
+		ExpiresAt:      time.Now().Add(refreshTokenTTL),
+	}
+	if err := uc.sessionRepo.Create(ctx, session, tokenHash); err != nil {
+		return nil, err
+	}
+
+	res, err := grant.sign(uc.keys, user.ID, session.ID, user.TokenVersion)
+	if err != nil {
+		return nil, err
+	}
+	res.RefreshToken = refreshToken
+	return res, nil
+}
+
+// Refresh trades a refresh token for a new access token and a new refresh token. The old
+// refresh token stops working; presenting it again revokes the whole session.
+func (uc *AuthUseCase) Refresh(ctx context.Context, refreshToken string) (*entity.AuthResponse, error) {
+	if refreshToken == "" {
+		return nil, entity.ErrSessionExpired
+	}
+	tokenHash := auth.HashRefreshToken(refreshToken)
+
+	session, err := uc.sessionRepo.GetActiveByTokenHash(ctx, tokenHash)
+	if err != nil {
+		if !errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, err
+		}
+		userID, replayErr := uc.sessionRepo.RevokeByPreviousTokenHash(ctx, tokenHash)
+		if replayErr == nil {
+			uc.logger.Warn("rotated refresh token reused, session revoked", zap.String("user_id", userID))
+		} else if !errors.Is(replayErr, entity.ErrRecordNotFound) {
+			return nil, replayErr
+		}
+		return nil, entity.ErrSessionExpired
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, entity.ErrSessionExpired
+		}
+		return nil, err
+	}
+	grant, err := uc.resolveGrant(ctx, user.ID, user.Role, session.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkTwoFactorPolicy(ctx, user, grant); err != nil {
+		return nil, err
+	}
+
+	newToken, newHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.sessionRepo.Rotate(ctx, session.ID, tokenHash, newHash, grant.OrganizationID, time.Now().Add(refreshTokenTTL)); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, entity.ErrSessionExpired
+		}
+		return nil, err
+	}
+
+	res, err := grant.sign(uc.keys, user.ID, session.ID, user.TokenVersion)
+	if err != nil {
+		return nil, err
+	}
+	res.RefreshToken = newToken
+	return res, nil
+}
+
+// SwitchOrganization issues a token for another organization the signed-in user belongs to.
+// The session follows along, so later refreshes stay in the new organization.
+func (uc *AuthUseCase) SwitchOrganization(ctx context.Context, userID, sessionID, orgID string) (*entity.AuthResponse, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("%w: organization_id is required", entity.ErrInvalidInput)
+	}
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	grant, err := uc.resolveGrant(ctx, user.ID, user.Role, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkTwoFactorPolicy(ctx, user, grant); err != nil {
+		return nil, err
+	}
+	if err := uc.sessionRepo.SetOrganization(ctx, sessionID, grant.OrganizationID); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, entity.ErrSessionExpired
+		}
+		return nil, err
+	}
+	return grant.sign(uc.keys, user.ID, sessionID, user.TokenVersion)
+}
+
+// Logout ends the session the request was made with.
+func (uc *AuthUseCase) Logout(ctx context.Context, userID, sessionID string) error {
+	if err := uc.sessionRepo.Revoke(ctx, sessionID, userID); err != nil && !errors.Is(err, entity.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// LogoutAll ends every session of the user, the current one included, and retires every
+// access token issued to them.
+func (uc *AuthUseCase) LogoutAll(ctx context.Context, userID string) (int64, error) {
+	revoked, err := uc.sessionRepo.RevokeAll(ctx, nil, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := uc.userRepo.BumpTokenVersion(ctx, nil, userID); err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
+
+// ListSessions returns the user's signed-in devices, flagging the one making the request.
+func (uc *AuthUseCase) ListSessions(ctx context.Context, userID, currentSessionID string) ([]entity.Session, error) {
+	sessions, err := uc.sessionRepo.ListActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends one of the user's own sessions.
+func (uc *AuthUseCase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return entity.ErrRecordNotFound
+	}
+	return uc.sessionRepo.Revoke(ctx, sessionID, userID)
+}
+
+// ValidateSession confirms an access token's session is still live.
+func (uc *AuthUseCase) ValidateSession(ctx context.Context, sessionID, userID string) error {
+	return uc.sessionRepo.Touch(ctx, sessionID, userID)
+}
+
+// grant is what a token is issued for: an organization, the role held there and the
+// user's memberships.
+type grant struct {
+	OrganizationID string
+	Role           string
+	Memberships    []entity.Membership
+}
+
+func (g grant) sign(keys *auth.SigningKeyRing, userID, sessionID string, tokenVersion int) (*entity.AuthResponse, error) {
+	token, err := auth.GenerateToken(keys, userID, g.OrganizationID, g.Role, sessionID, tokenVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.AuthResponse{
+		Token:          token,
+		ExpiresIn:      int(auth.AccessTokenTTL.Seconds()),
+		OrganizationID: g.OrganizationID,
+		Organizations:  g.Memberships,
+	}, nil
+}
+
+// resolveGrant picks the organization to issue a token for: orgID, or the user's first
+// membership when it is empty.
+func (uc *AuthUseCase) resolveGrant(ctx context.Context, userID, userRole, orgID string) (*grant, error) {
+	memberships, err := uc.orgRepo.ListUserMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var chosen *entity.Membership
+	for i := range memberships {
+		if orgID == "" || memberships[i].OrganizationID == orgID {
+			chosen = &memberships[i]
+			break
+		}
+	}
+	if orgID != "" && chosen == nil {
+		return nil, fmt.Errorf("%w: not a member of this organization", entity.ErrInsufficientPermissions)
+	}
+
+	// Permissions follow the role held in the organization; super admins keep their global role.
+	g := &grant{Role: userRole, Memberships: memberships}
+	if chosen != nil {
+		g.OrganizationID = chosen.OrganizationID
+		if g.Role != entity.RoleSuperAdmin {
+			g.Role = chosen.Role
+		}
+	}
+	return g, nil
+}
+
+// twoFactorRequired reports whether the organization a grant is for makes two-factor
+// authentication mandatory.
+func (uc *AuthUseCase) twoFactorRequired(ctx context.Context, g *grant) (bool, error) {
+	if g.OrganizationID == "" {
+		return false, nil
+	}
+	return uc.orgRepo.GetTwoFactorRequired(ctx, g.OrganizationID)
+}
+
+// checkTwoFactorPolicy keeps users who have not enrolled out of organizations that require
+// two-factor authentication, including ones that turned the requirement on mid-session.
+func (uc *AuthUseCase) checkTwoFactorPolicy(ctx context.Context, user *entity.User, g *grant) error {
+	if user.TwoFactorEnabled {
+		return nil
+	}
+	required, err := uc.twoFactorRequired(ctx, g)
+	if err != nil {
+		return err
+	}
+	if required {
+		return fmt.Errorf("%w: the organization requires it, sign in again to set it up", entity.ErrTwoFactorRequired)
+	}
+	return nil
+}
+
+// truncate caps client supplied strings to what the sessions table stores.
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
+
+// GetMembership returns the caller's membership in the organization their token is for.
+func (uc *AuthUseCase) GetMembership(ctx context.Context, userID, orgID string) (*entity.OrganizationMember, error) {
+	return uc.orgRepo.GetMember(ctx, userID, orgID)
+}
+
+// VerifyAccessToken checks an access token against the signing key ring.
+func (uc *AuthUseCase) VerifyAccessToken(token string) (*auth.Claims, error) {
+	return auth.ValidateAccessToken(uc.keys, token)
+}
+
+func (uc *AuthUseCase) GetTokenVersion(ctx context.Context, userID string) (int, error) {
+	return uc.userRepo.GetTokenVersion(ctx, userID)
+}
+
+// JWKS publishes the public signing keys for services that verify our tokens.
+func (uc *AuthUseCase) JWKS() auth.JWKSet {
+	return uc.keys.JWKS()
+}
+
+// resolveChallenge returns the user a login challenge was issued to. Challenges die with a
+// password change, as the token version moves on.
+func (uc *AuthUseCase) resolveChallenge(ctx context.Context, challengeToken string) (*entity.User, *auth.Claims, error) {
+	claims, err := auth.ValidateTwoFactorChallenge(uc.keys, challengeToken)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: login challenge expired, sign in again", entity.ErrSessionExpired)
+	}
+	user, err := uc.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("%w: login challenge expired, sign in again", entity.ErrSessionExpired)
+		}
+		return nil, nil, err
+	}
+	if user.TokenVersion != claims.TokenVersion {
+		return nil, nil, fmt.Errorf("%w: login challenge expired, sign in again", entity.ErrSessionExpired)
+	}
+	return user, claims, nil
+}
+
+// verifySecondFactor accepts a current TOTP code or an unused recovery code. Either works once.
+func (uc *AuthUseCase) verifySecondFactor(ctx context.Context, user *entity.User, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		used, err := uc.userRepo.UseRecoveryCode(ctx, user.ID, auth.HashRecoveryCode(recoveryCode))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return entity.ErrInvalidTwoFactor
+		}
+		uc.logger.Info("recovery code used", zap.String("user_id", user.ID))
+		return nil
+	}
+
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return entity.ErrInvalidTwoFactor
+	}
+	fresh, err := uc.userRepo.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return entity.ErrInvalidTwoFactor
+	}
+	return nil
+}
+
+// beginEnrolment stores a new pending secret. Nothing changes for the user until
+// confirmEnrolment accepts a first code generated from it.
+func (uc *AuthUseCase) beginEnrolment(ctx context.Context, user *entity.User) (*entity.TwoFactorSetup, error) {
+	if user.TwoFactorEnabled {
+		return nil, fmt.Errorf("%w: two-factor authentication is already enabled", entity.ErrConflict)
+	}
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.userRepo.SetPendingTOTPSecret(ctx, user.ID, secret); err != nil {
+		if errors.Is(err, entity.ErrConflict) {
+			return nil, fmt.Errorf("%w: two-factor authentication is already enabled", entity.ErrConflict)
+		}
+		return nil, err
+	}
+	return &entity.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// confirmEnrolment enables two-factor authentication once code proves the user's app holds
+// the pending secret, and returns the recovery codes to show them.
+func (uc *AuthUseCase) confirmEnrolment(ctx context.Context, user *entity.User, code string) ([]string, error) {
+	if user.TwoFactorEnabled {
+		return nil, fmt.Errorf("%w: two-factor authentication is already enabled", entity.ErrConflict)
+	}
+	if user.TOTPSecret == "" {
+		return nil, fmt.Errorf("%w: start two-factor setup first", entity.ErrInvalidInput)
+	}
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, entity.ErrInvalidTwoFactor
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil { return nil, err }
+	defer tx.Rollback(ctx)
+
+	if err := uc.userRepo.EnableTOTP(ctx, tx, user.ID, step); err != nil {
+		if errors.Is(err, entity.ErrConflict) {
+			return nil, fmt.Errorf("%w: two-factor authentication is already enabled", entity.ErrConflict)
+		}
+		return nil, err
+	}
+	if err := uc.userRepo.ReplaceRecoveryCodes(ctx, tx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil { return nil, err }
+
+	uc.logger.Info("two-factor authentication enabled", zap.String("user_id", user.ID))
+	return codes, nil
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(auth.RecoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
+
+// SetupTwoFactor starts enrolment for a signed-in user.
+func (uc *AuthUseCase) SetupTwoFactor(ctx context.Context, userID string) (*entity.TwoFactorSetup, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.beginEnrolment(ctx, user)
+}
+
+// EnableTwoFactor confirms enrolment with a first code and returns the recovery codes.
+func (uc *AuthUseCase) EnableTwoFactor(ctx context.Context, userID, code string, client entity.SessionClient) ([]string, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var codes []string
+	err = uc.guardSecondFactor(ctx, user, client, func() (err error) {
+		codes, err = uc.confirmEnrolment(ctx, user, code)
+		return err
+	})
+	return codes, err
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking the password and a
+// current code. Members of an organization that requires it cannot opt out there.
+func (uc *AuthUseCase) DisableTwoFactor(ctx context.Context, userID, orgID string, req entity.DisableTwoFactorRequest, client entity.SessionClient) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return fmt.Errorf("%w: two-factor authentication is not enabled", entity.ErrConflict)
+	}
+	required, err := uc.twoFactorRequired(ctx, &grant{OrganizationID: orgID})
+	if err != nil {
+		return err
+	}
+	if required {
+		return fmt.Errorf("%w: the organization requires it", entity.ErrTwoFactorRequired)
+	}
+	err = uc.guardSecondFactor(ctx, user, client, func() error {
+		if !auth.CheckPassword(req.Password, user.Password) {
+			return entity.ErrInvalidCredentials
+		}
+		return uc.verifySecondFactor(ctx, user, req.Code, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := uc.userRepo.ClearTwoFactor(ctx, nil, user.ID); err != nil {
+		return err
+	}
+	uc.logger.Info("two-factor authentication disabled", zap.String("user_id", user.ID))
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes; the old ones stop working.
+func (uc *AuthUseCase) RegenerateRecoveryCodes(ctx context.Context, userID, code string, client entity.SessionClient) ([]string, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, fmt.Errorf("%w: two-factor authentication is not enabled", entity.ErrConflict)
+	}
+	err = uc.guardSecondFactor(ctx, user, client, func() error {
+		return uc.verifySecondFactor(ctx, user, code, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.userRepo.ReplaceRecoveryCodes(ctx, nil, user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (uc *AuthUseCase) GetTwoFactorPolicy(ctx context.Context, orgID string) (*entity.TwoFactorPolicy, error) {
+	required, err := uc.orgRepo.GetTwoFactorRequired(ctx, orgID)
+	if err != nil {
+		return nil, err
 	}
+	return &entity.TwoFactorPolicy{Required: required}, nil
+}
+
+// UpdateTwoFactorPolicy turns the organization's two-factor requirement on or off. Members
+// without it are asked to enrol on their next login or token refresh.
+func (uc *AuthUseCase) UpdateTwoFactorPolicy(ctx context.Context, orgID string, req entity.TwoFactorPolicy) error {
+	return uc.orgRepo.SetTwoFactorRequired(ctx, orgID, req.Required)
+}
 
-	orgID, err := uc.orgRepo.GetUserOrganization(ctx, user.ID)
+// Register signs up a new organization with its owner in one transaction. The owner cannot
+// sign in until the address is confirmed through the emailed verification link.
+//
+// Registering an email that already has an account answers exactly like a fresh signup, so
+// the endpoint cannot be used to find out who has one; an unverified owner gets a new
+// verification email instead.
+func (uc *AuthUseCase) Register(ctx context.Context, req entity.RegisterOwnerRequest) error {
+	req.Email = strings.TrimSpace(req.Email)
+	req.OrgName = strings.TrimSpace(req.OrgName)
+	if !strings.Contains(req.Email, "@") {
+		return fmt.Errorf("%w: a valid email is required", entity.ErrInvalidInput)
+	}
+	if err := uc.passwords.Validate(req.Password); err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrInvalidInput, err)
+	}
+	if req.OrgName == "" || req.FirstName == "" || req.LastName == "" {
+		return fmt.Errorf("%w: org_name, first_name and last_name are required", entity.ErrInvalidInput)
+	}
+
+	taken, err := uc.orgRepo.NameExists(ctx, req.OrgName)
 	if err != nil {
-		return "", err
+		return err
+	}
+	if taken {
+		return fmt.Errorf("%w: organization name already taken", entity.ErrConflict)
+	}
+
+	passwordHash, err := auth.HashPassword(req.Password)
+	if err != nil { return err }
+	userSalt, err := auth.GenerateRandomSalt()
+	if err != nil { return err }
+
+	orgID, _ := uuid.NewV7()
+	userID, _ := uuid.NewV7()
+	memberID, _ := uuid.NewV7()
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil { return err }
+	defer tx.Rollback(ctx)
+
+	org := entity.Organization{
+		BaseEntity: entity.BaseEntity{ID: orgID.String()},
+		Name:       req.OrgName,
+		Code:       newOrganizationCode(req.OrgName),
+	}
+	if err := uc.orgRepo.Create(ctx, tx, org); err != nil {
+		if errors.Is(err, entity.ErrConflict) {
+			return fmt.Errorf("%w: organization name already taken", entity.ErrConflict)
+		}
+		return err
+	}
+
+	owner := entity.User{
+		BaseEntity: entity.BaseEntity{ID: userID.String()},
+		Email:      req.Email,
+		Password:   passwordHash,
+		Salt:       userSalt,
+		Role:       entity.RoleUser,
+		FirstName:  req.FirstName,
+		LastName:   req.LastName,
+		Phone:      req.Phone,
+	}
+	if err := uc.userRepo.Create(ctx, tx, owner); err != nil {
+		if errors.Is(err, entity.ErrConflict) {
+			uc.logger.Info("registration for an existing email", zap.String("organization_name", org.Name))
+			tx.Rollback(ctx)
+			return uc.ResendVerification(ctx, req.Email)
+		}
+		return err
+	}
+
+	member := entity.OrganizationMember{
+		BaseEntity:     entity.BaseEntity{ID: memberID.String()},
+		OrganizationID: org.ID,
+		UserID:         owner.ID,
+		Role:           entity.OrgRoleOwner,
+	}
+	if err := uc.orgRepo.AddMember(ctx, tx, member); err != nil {
+		return err
+	}
+
+	if err := uc.queueVerificationEmail(ctx, tx, &owner, org.Name); err != nil {
+		return err
 	}
 
-	return auth.GenerateToken(user.ID, orgID, user.Role, user.Salt)
+	if err := tx.Commit(ctx); err != nil { return err }
+
+	uc.logger.Info("organization registered",
+		zap.String("organization_id", org.ID),
+		zap.String("user_id", owner.ID),
+	)
+	return nil
 }
 
-func (uc *AuthUseCase) GetUserSalt(ctx context.Context, userID string) (string, error) {
-	return uc.userRepo.GetSaltByID(ctx, userID)
+// VerifyEmail activates the account the verification token was issued for.
+func (uc *AuthUseCase) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := auth.ParseTokenClaimsUnsafe(token)
+	if err != nil || claims.Purpose != auth.PurposeVerifyEmail {
+		return fmt.Errorf("%w: invalid verification token", entity.ErrInvalidInput)
+	}
+
+	salt, err := uc.userRepo.GetSaltByID(ctx, claims.UserID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid verification token", entity.ErrInvalidInput)
+	}
+	if _, err := auth.ValidateSignature(token, salt); err != nil {
+		return fmt.Errorf("%w: verification link expired or invalid", entity.ErrInvalidInput)
+	}
+
+	return uc.userRepo.MarkEmailVerified(ctx, claims.UserID)
+}
+
+// ResendVerification queues a fresh verification email for an unverified account. Like
+// password resets, it never reveals whether the address is registered.
+func (uc *AuthUseCase) ResendVerification(ctx context.Context, email string) error {
+	user, err := uc.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidCredentials) {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	memberships, err := uc.orgRepo.ListUserMemberships(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	orgName := ""
+	if len(memberships) > 0 {
+		orgName = memberships[0].Name
+	}
+	return uc.queueVerificationEmail(ctx, nil, user, orgName)
+}
+
+// PurgeUnverifiedSignups deletes self-registered accounts, and the organizations they created,
+// that were never verified within unverifiedSignupTTL.
+func (uc *AuthUseCase) PurgeUnverifiedSignups(ctx context.Context) (int64, error) {
+	return uc.userRepo.DeleteUnverified(ctx, time.Now().Add(-unverifiedSignupTTL))
+}
+
+func (uc *AuthUseCase) queueVerificationEmail(ctx context.Context, tx pgx.Tx, user *entity.User, orgName string) error {
+	token, err := auth.GenerateEmailVerificationToken(user.ID, user.Salt)
+	if err != nil {
+		return err
+	}
+	msg, err := uc.emailService.EmailVerification(user.Language, user.Email, user.FirstName, orgName, token)
+	if err != nil {
+		return err
+	}
+	return uc.outboxRepo.Enqueue(ctx, tx, msg)
 }
 
 func (uc *AuthUseCase) RequestPasswordReset(ctx context.Context, email string) error {
@@ -73,27 +926,39 @@ func (uc *AuthUseCase) RequestPasswordReset(ctx context.Context, email string) e
 		return err
 	}
 
+	// Resets answer the same either way, so repeated requests are dropped quietly.
+	claimed, err := uc.userRepo.ClaimPasswordReset(ctx, user.ID, passwordResetCooldown)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		uc.logger.Warn("password reset throttled",
+			zap.String("user_id", user.ID),
+		)
+		return nil
+	}
+
 	token, err := auth.GenerateResetToken(user.ID, user.Salt)
 	if err != nil {
 		uc.logger.Error("failed to generate reset token", zap.Error(err))
 		return err
 	}
 
-	userName := user.FirstName
-    if userName == "" {
-        userName = "Usuario"
-    } 
+	msg, err := uc.emailService.PasswordReset(user.Language, user.Email, user.FirstName, token)
+	if err != nil {
+		uc.logger.Error("failed to render password reset email", zap.Error(err))
+		return err
+	}
 
-	if err := uc.emailService.SendPasswordReset(user.Email, userName, token); err != nil {
-		uc.logger.Error("failed to send password reset email",
+	if err := uc.outboxRepo.Enqueue(ctx, nil, msg); err != nil {
+		uc.logger.Error("failed to queue password reset email",
 			zap.String("user_id", user.ID),
-			zap.String("email", user.Email),
 			zap.Error(err),
 		)
-		return nil 
+		return err
 	}
 	
-	uc.logger.Info("password reset email sent successfully", 
+	uc.logger.Info("password reset email queued", 
 		zap.String("user_id", user.ID),
 	)
 
@@ -119,11 +984,30 @@ func (uc *AuthUseCase) ResetPassword(ctx context.Context, req entity.ResetPasswo
 		return errors.New("token expired or invalid")
 	}
 
+	if err := uc.passwords.Validate(req.NewPassword); err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrInvalidInput, err)
+	}
+
 	newPasswordHash, err := auth.HashPassword(req.NewPassword)
 	if err != nil { return err }
 	
 	newSalt, err := auth.GenerateRandomSalt()
 	if err != nil { return err }
 
-	return uc.userRepo.UpdatePassword(ctx, claims.UserID, newPasswordHash, newSalt)
+	// A new password signs out every device, including ones holding refresh tokens, so both
+	// changes commit together.
+	tx, err := uc.db.Begin(ctx)
+	if err != nil { return err }
+	defer tx.Rollback(ctx)
+
+	if err := uc.userRepo.UpdatePassword(ctx, tx, claims.UserID, newPasswordHash, newSalt); err != nil {
+		return err
+	}
+	if _, err := uc.sessionRepo.RevokeAll(ctx, tx, claims.UserID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil { return err }
+
+	uc.logger.Info("password reset completed", zap.String("user_id", claims.UserID))
+	return nil
 }
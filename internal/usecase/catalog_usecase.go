@@ -2,13 +2,15 @@ package usecase
 
 import (
 	"context"
-	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/ecelayes/pms-backend/internal/entity"
 	"github.com/ecelayes/pms-backend/internal/repository"
 )
 
+// CatalogUseCase manages the amenity and service catalogs. Super admins maintain the global
+// entries every organization sees; organization owners add entries of their own on top.
 type CatalogUseCase struct {
 	amenityRepo *repository.AmenityRepository
 	serviceRepo *repository.HotelServiceRepository
@@ -18,20 +20,56 @@ func NewCatalogUseCase(ar *repository.AmenityRepository, sr *repository.HotelSer
 	return &CatalogUseCase{amenityRepo: ar, serviceRepo: sr}
 }
 
-func (uc *CatalogUseCase) CreateAmenity(ctx context.Context, requesterRole string, req entity.CreateCatalogRequest) (string, error) {
-	if requesterRole != entity.RoleSuperAdmin {
-		return "", errors.New("insufficient permissions: only super admin can create global catalogs")
+// catalogOwner returns the organization new entries created by the caller belong to: nil for
+// a super admin's global entries, the caller's organization for an owner. role is the one
+// security.Auth resolves from the caller's membership of orgID, never the account-wide users.role.
+func catalogOwner(role, orgID string) (*string, error) {
+	switch role {
+	case entity.RoleSuperAdmin:
+		return nil, nil
+	case entity.OrgRoleOwner:
+		if orgID == "" {
+			return nil, entity.ErrInsufficientPermissions
+		}
+		return &orgID, nil
+	default:
+		return nil, fmt.Errorf("%w: only super admins and organization owners can manage catalogs", entity.ErrInsufficientPermissions)
+	}
+}
+
+// canManageEntry reports whether the caller may change an entry it can see: super admins
+// manage global entries, owners manage their organization's.
+func canManageEntry(role string, entryOrgID *string) error {
+	if role == entity.RoleSuperAdmin && entryOrgID == nil {
+		return nil
+	}
+	if role == entity.OrgRoleOwner && entryOrgID != nil {
+		return nil
+	}
+	return entity.ErrInsufficientPermissions
+}
+
+func (uc *CatalogUseCase) CreateAmenity(ctx context.Context, role, orgID string, req entity.CreateCatalogRequest) (string, error) {
+	owner, err := catalogOwner(role, orgID)
+	if err != nil {
+		return "", err
 	}
 	if req.Name == "" {
 		return "", entity.ErrInvalidInput
 	}
+	if owner != nil {
+		if err := checkGlobalName(uc.amenityRepo.GlobalNameExists(ctx, req.Name)); err != nil {
+			return "", err
+		}
+	}
 
 	id, _ := uuid.NewV7()
 	a := entity.Amenity{
-		BaseEntity:  entity.BaseEntity{ID: id.String()},
-		Name:        req.Name,
-		Description: req.Description,
-		Icon:        req.Icon,
+		BaseEntity:     entity.BaseEntity{ID: id.String()},
+		OrganizationID: owner,
+		Name:           req.Name,
+		Description:    req.Description,
+		Icon:           req.Icon,
 	}
 
 	if err := uc.amenityRepo.Create(ctx, a); err != nil {
@@ -40,54 +78,69 @@ func (uc *CatalogUseCase) CreateAmenity(ctx context.Context, requesterRole strin
 	return id.String(), nil
 }
 
-func (uc *CatalogUseCase) GetAllAmenities(ctx context.Context, pagination entity.PaginationRequest) ([]entity.Amenity, int64, error) {
-	return uc.amenityRepo.GetAll(ctx, pagination)
+// GetAllAmenities returns the global amenities merged with the organization's own.
+func (uc *CatalogUseCase) GetAllAmenities(ctx context.Context, orgID string, pagination entity.PaginationRequest) ([]entity.Amenity, int64, error) {
+	return uc.amenityRepo.GetAll(ctx, orgID, pagination)
 }
 
-func (uc *CatalogUseCase) GetAmenityByID(ctx context.Context, id string) (*entity.Amenity, error) {
+func (uc *CatalogUseCase) GetAmenityByID(ctx context.Context, orgID, id string) (*entity.Amenity, error) {
 	if _, err := uuid.Parse(id); err != nil {
 		return nil, entity.ErrRecordNotFound
 	}
-	return uc.amenityRepo.GetByID(ctx, id)
+	return uc.amenityRepo.GetByID(ctx, id, orgID)
 }
 
-func (uc *CatalogUseCase) UpdateAmenity(ctx context.Context, role, id string, req entity.UpdateCatalogRequest) error {
-	if role != entity.RoleSuperAdmin {
-		return entity.ErrInsufficientPermissions
+func (uc *CatalogUseCase) UpdateAmenity(ctx context.Context, role, orgID, id string, req entity.UpdateCatalogRequest) error {
+	a, err := uc.GetAmenityByID(ctx, orgID, id)
+	if err != nil {
+		return err
 	}
-	if _, err := uuid.Parse(id); err != nil {
-		return entity.ErrRecordNotFound
+	if err := canManageEntry(role, a.OrganizationID); err != nil {
+		return err
 	}
 	if req.Name == "" {
 		return entity.ErrInvalidInput
 	}
+	if a.OrganizationID != nil && req.Name != a.Name {
+		if err := checkGlobalName(uc.amenityRepo.GlobalNameExists(ctx, req.Name)); err != nil {
+			return err
+		}
+	}
 	return uc.amenityRepo.Update(ctx, id, req)
 }
 
-func (uc *CatalogUseCase) DeleteAmenity(ctx context.Context, role, id string) error {
-	if role != entity.RoleSuperAdmin {
-		return entity.ErrInsufficientPermissions
+func (uc *CatalogUseCase) DeleteAmenity(ctx context.Context, role, orgID, id string) error {
+	a, err := uc.GetAmenityByID(ctx, orgID, id)
+	if err != nil {
+		return err
 	}
-	if _, err := uuid.Parse(id); err != nil {
-		return entity.ErrRecordNotFound
+	if err := canManageEntry(role, a.OrganizationID); err != nil {
+		return err
 	}
 	return uc.amenityRepo.Delete(ctx, id)
 }
 
-func (uc *CatalogUseCase) CreateService(ctx context.Context, requesterRole string, req entity.CreateCatalogRequest) (string, error) {
-	if requesterRole != entity.RoleSuperAdmin {
-		return "", errors.New("insufficient permissions")
+func (uc *CatalogUseCase) CreateService(ctx context.Context, role, orgID string, req entity.CreateCatalogRequest) (string, error) {
+	owner, err := catalogOwner(role, orgID)
+	if err != nil {
+		return "", err
 	}
 	if req.Name == "" {
 		return "", entity.ErrInvalidInput
 	}
-	
+	if owner != nil {
+		if err := checkGlobalName(uc.serviceRepo.GlobalNameExists(ctx, req.Name)); err != nil {
+			return "", err
+		}
+	}
+
 	id, _ := uuid.NewV7()
 	s := entity.HotelService{
-		BaseEntity:  entity.BaseEntity{ID: id.String()},
-		Name:        req.Name,
-		Description: req.Description,
-		Icon:        req.Icon,
+		BaseEntity:     entity.BaseEntity{ID: id.String()},
+		OrganizationID: owner,
+		Name:           req.Name,
+		Description:    req.Description,
+		Icon:           req.Icon,
 	}
 
 	if err := uc.serviceRepo.Create(ctx, s); err != nil {
@@ -96,36 +149,55 @@ func (uc *CatalogUseCase) CreateService(ctx context.Context, requesterRole strin
 	return id.String(), nil
 }
 
-func (uc *CatalogUseCase) GetAllServices(ctx context.Context, pagination entity.PaginationRequest) ([]entity.HotelService, int64, error) {
-	return uc.serviceRepo.GetAll(ctx, pagination)
+// GetAllServices returns the global services merged with the organization's own.
+func (uc *CatalogUseCase) GetAllServices(ctx context.Context, orgID string, pagination entity.PaginationRequest) ([]entity.HotelService, int64, error) {
+	return uc.serviceRepo.GetAll(ctx, orgID, pagination)
 }
 
-func (uc *CatalogUseCase) GetServiceByID(ctx context.Context, id string) (*entity.HotelService, error) {
+func (uc *CatalogUseCase) GetServiceByID(ctx context.Context, orgID, id string) (*entity.HotelService, error) {
 	if _, err := uuid.Parse(id); err != nil {
 		return nil, entity.ErrRecordNotFound
 	}
-	return uc.serviceRepo.GetByID(ctx, id)
+	return uc.serviceRepo.GetByID(ctx, id, orgID)
 }
 
-func (uc *CatalogUseCase) UpdateService(ctx context.Context, role, id string, req entity.UpdateCatalogRequest) error {
-	if role != entity.RoleSuperAdmin {
-		return entity.ErrInsufficientPermissions
+func (uc *CatalogUseCase) UpdateService(ctx context.Context, role, orgID, id string, req entity.UpdateCatalogRequest) error {
+	s, err := uc.GetServiceByID(ctx, orgID, id)
+	if err != nil {
+		return err
 	}
-	if _, err := uuid.Parse(id); err != nil {
-		return entity.ErrRecordNotFound
+	if err := canManageEntry(role, s.OrganizationID); err != nil {
+		return err
 	}
 	if req.Name == "" {
 		return entity.ErrInvalidInput
 	}
+	if s.OrganizationID != nil && req.Name != s.Name {
+		if err := checkGlobalName(uc.serviceRepo.GlobalNameExists(ctx, req.Name)); err != nil {
+			return err
+		}
+	}
 	return uc.serviceRepo.Update(ctx, id, req)
 }
 
-func (uc *CatalogUseCase) DeleteService(ctx context.Context, role, id string) error {
-	if role != entity.RoleSuperAdmin {
-		return entity.ErrInsufficientPermissions
+func (uc *CatalogUseCase) DeleteService(ctx context.Context, role, orgID, id string) error {
+	s, err := uc.GetServiceByID(ctx, orgID, id)
+	if err != nil {
+		return err
 	}
-	if _, err := uuid.Parse(id); err != nil {
-		return entity.ErrRecordNotFound
+	if err := canManageEntry(role, s.OrganizationID); err != nil {
+		return err
 	}
 	return uc.serviceRepo.Delete(ctx, id)
 }
+
+// checkGlobalName rejects organization entries that would shadow a global entry in the merged catalog.
+func checkGlobalName(exists bool, err error) error {
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: a global catalog entry already uses this name", entity.ErrConflict)
+	}
+	return nil
+}
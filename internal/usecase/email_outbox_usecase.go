@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/service"
+)
+
+const (
+	outboxBaseRetryDelay = 30 * time.Second
+	outboxMaxRetryDelay  = time.Hour
+)
+
+type EmailOutboxUseCase struct {
+	db         *pgxpool.Pool
+	outboxRepo *repository.EmailOutboxRepository
+	sender     service.EmailSender
+	logger     *zap.Logger
+}
+
+func NewEmailOutboxUseCase(
+	db *pgxpool.Pool,
+	outboxRepo *repository.EmailOutboxRepository,
+	sender service.EmailSender,
+	logger *zap.Logger,
+) *EmailOutboxUseCase {
+	return &EmailOutboxUseCase{
+		db:         db,
+		outboxRepo: outboxRepo,
+		sender:     sender,
+		logger:     logger,
+	}
+}
+
+// DeliverDue sends up to batchSize due messages. Failed sends are rescheduled with exponential
+// backoff until max_attempts is reached, after which the message is dead-lettered as failed.
+func (uc *EmailOutboxUseCase) DeliverDue(ctx context.Context, batchSize int) (int, error) {
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	due, err := uc.outboxRepo.ClaimDue(ctx, tx, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, email := range due {
+		attempts := email.Attempts + 1
+		sendErr := uc.sender.Send(ctx, entity.EmailMessage{
+			To:      email.Recipient,
+			Subject: email.Subject,
+			Body:    email.Body,
+		})
+
+		switch {
+		case sendErr == nil:
+			err = uc.outboxRepo.MarkSent(ctx, tx, email.ID, attempts)
+			sent++
+		case attempts >= email.MaxAttempts:
+			uc.logger.Error("email dead-lettered after exhausting retries",
+				zap.String("email_id", email.ID),
+				zap.Int("attempts", attempts),
+				zap.Error(sendErr),
+			)
+			err = uc.outboxRepo.MarkFailed(ctx, tx, email.ID, attempts, sendErr.Error())
+		default:
+			uc.logger.Warn("email delivery failed, retry scheduled",
+				zap.String("email_id", email.ID),
+				zap.Int("attempts", attempts),
+				zap.Error(sendErr),
+			)
+			err = uc.outboxRepo.ScheduleRetry(ctx, tx, email.ID, attempts, time.Now().Add(retryDelay(attempts)), sendErr.Error())
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return sent, nil
+}
+
+func (uc *EmailOutboxUseCase) ListFailed(ctx context.Context, pagination entity.PaginationRequest) ([]entity.OutboxEmail, int64, error) {
+	return uc.outboxRepo.ListFailed(ctx, pagination)
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := outboxBaseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxMaxRetryDelay {
+			return outboxMaxRetryDelay
+		}
+	}
+	return delay
+}
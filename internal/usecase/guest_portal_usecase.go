@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+)
+
+// GuestPortalUseCase lets guests manage their own reservation. It decides what a guest may
+// change and hands the change to ReservationUseCase, so pricing, availability checks,
+// notifications and webhooks are exactly those of staff modifications.
+type GuestPortalUseCase struct {
+	resUC        *ReservationUseCase
+	resRepo      *repository.ReservationRepository
+	unitTypeRepo *repository.UnitTypeRepository
+	ratePlanRepo *repository.RatePlanRepository
+}
+
+func NewGuestPortalUseCase(
+	resUC *ReservationUseCase,
+	resRepo *repository.ReservationRepository,
+	unitTypeRepo *repository.UnitTypeRepository,
+	ratePlanRepo *repository.RatePlanRepository,
+) *GuestPortalUseCase {
+	return &GuestPortalUseCase{
+		resUC:        resUC,
+		resRepo:      resRepo,
+		unitTypeRepo: unitTypeRepo,
+		ratePlanRepo: ratePlanRepo,
+	}
+}
+
+func (uc *GuestPortalUseCase) Get(ctx context.Context, reservationID string) (*entity.GuestPortalReservation, error) {
+	res, opts, err := uc.load(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.GuestPortalReservation{Reservation: *res, Options: *opts}, nil
+}
+
+// OfferAddOns lists the services the guest could still add to the stay.
+func (uc *GuestPortalUseCase) OfferAddOns(ctx context.Context, reservationID string) ([]entity.AddOnOffer, error) {
+	_, opts, err := uc.load(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.CanAddServices {
+		return []entity.AddOnOffer{}, nil
+	}
+	return uc.resUC.OfferAddOns(ctx, reservationID)
+}
+
+func (uc *GuestPortalUseCase) AddAddOn(ctx context.Context, reservationID string, req entity.AddOnRequest) (*entity.Reservation, error) {
+	_, opts, err := uc.load(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.CanAddServices {
+		return nil, fmt.Errorf("%w: services can no longer be added online", entity.ErrModificationNotAllowed)
+	}
+	return uc.resUC.AddAddOn(ctx, reservationID, req)
+}
+
+func (uc *GuestPortalUseCase) RemoveAddOn(ctx context.Context, reservationID, addOnID string) (*entity.Reservation, error) {
+	_, opts, err := uc.load(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.CanRemoveServices {
+		return nil, fmt.Errorf("%w: services can no longer be removed online", entity.ErrModificationNotAllowed)
+	}
+	return uc.resUC.RemoveAddOn(ctx, reservationID, addOnID)
+}
+
+// Quote prices a guest change without applying it.
+func (uc *GuestPortalUseCase) Quote(ctx context.Context, reservationID string, req entity.GuestModificationRequest) (*entity.ModificationQuote, error) {
+	_, opts, err := uc.load(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	change, err := authorizeGuestChange(opts, req)
+	if err != nil {
+		return nil, err
+	}
+	return uc.resUC.PreviewModification(ctx, reservationID, change)
+}
+
+func (uc *GuestPortalUseCase) Modify(ctx context.Context, reservationID string, req entity.GuestModificationRequest) (*entity.Reservation, error) {
+	_, opts, err := uc.load(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	change, err := authorizeGuestChange(opts, req)
+	if err != nil {
+		return nil, err
+	}
+	return uc.resUC.Modify(ctx, reservationID, change)
+}
+
+func (uc *GuestPortalUseCase) SetArrivalTime(ctx context.Context, reservationID string, req entity.ArrivalTimeRequest) (*entity.Reservation, error) {
+	_, opts, err := uc.load(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.CanSetArrivalTime {
+		return nil, fmt.Errorf("%w: the arrival time can no longer be changed", entity.ErrModificationNotAllowed)
+	}
+	return uc.resUC.SetArrivalTime(ctx, reservationID, req.ArrivalTime)
+}
+
+func (uc *GuestPortalUseCase) Cancel(ctx context.Context, reservationID string) error {
+	res, opts, err := uc.load(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	if res.Status == "cancelled" {
+		return entity.ErrReservationCancelled
+	}
+	if !opts.CanCancel {
+		return fmt.Errorf("%w: the reservation can no longer be cancelled online", entity.ErrModificationNotAllowed)
+	}
+	return uc.resUC.Cancel(ctx, reservationID)
+}
+
+func (uc *GuestPortalUseCase) load(ctx context.Context, reservationID string) (*entity.Reservation, *entity.GuestModificationOptions, error) {
+	res, err := uc.resRepo.GetByID(ctx, reservationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.AddOns, err = uc.resUC.ListAddOns(ctx, reservationID); err != nil {
+		return nil, nil, err
+	}
+	opts, err := uc.options(ctx, res)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, opts, nil
+}
+
+// options derives what the guest may still change. Once the stay could no longer be cancelled
+// for free (a non-refundable rate or inside the penalty window), dates and party size are
+// fixed, booked services stay, and the guest may only move to plans with the same cancellation
+// policy that keep or raise the total, e.g. the same rate with breakfast.
+func (uc *GuestPortalUseCase) options(ctx context.Context, res *entity.Reservation) (*entity.GuestModificationOptions, error) {
+	opts := &entity.GuestModificationOptions{RatePlans: []entity.GuestRatePlanOption{}}
+	if res.Status != "confirmed" {
+		opts.Restrictions = append(opts.Restrictions, "the reservation is no longer confirmed")
+		return opts, nil
+	}
+
+	fee, err := uc.resUC.PreviewCancellation(ctx, res.ID)
+	if err != nil {
+		return nil, err
+	}
+	opts.CanCancel = guestCanCancel(res)
+	opts.CancellationFee = fee
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	opts.CanSetArrivalTime = !res.Start.Before(today)
+	if !res.Start.After(today) {
+		opts.Restrictions = append(opts.Restrictions, "changes are closed from the day of arrival")
+		return opts, nil
+	}
+
+	locked := fee > 0
+	opts.CanChangeDates = !locked
+	opts.CanChangeRatePlan = true
+	opts.CanAddServices = true
+	opts.CanRemoveServices = !locked
+	if locked {
+		opts.Restrictions = append(opts.Restrictions, "the free cancellation period has ended: dates and guests can only be changed by the property")
+	}
+
+	unitType, err := uc.unitTypeRepo.GetByID(ctx, res.UnitTypeID)
+	if err != nil {
+		return nil, entity.ErrUnitTypeNotFound
+	}
+	plans, err := uc.ratePlanRepo.ListApplicable(ctx, unitType.PropertyID, unitType.ID)
+	if err != nil {
+		return nil, err
+	}
+	var currentPlan *entity.RatePlan
+	if res.RatePlanID != nil {
+		if currentPlan, err = uc.ratePlanRepo.GetByID(ctx, *res.RatePlanID); err != nil {
+			return nil, fmt.Errorf("failed to load rate plan: %w", err)
+		}
+	}
+
+	addOnTotal := addOnsTotal(res.AddOns)
+	for _, plan := range plans {
+		quote, err := uc.resUC.quote(ctx, unitType, &plan.ID, res.Start, res.End, res.Adults, res.Children)
+		if err != nil {
+			continue
+		}
+		total := roundMoney(quote.Total + addOnTotal)
+		current := currentPlan != nil && currentPlan.ID == plan.ID
+		if locked && !current {
+			if total < res.TotalPrice || !reflect.DeepEqual(plan.CancellationPolicy, currentPlan.CancellationPolicy) {
+				continue
+			}
+		}
+		opts.RatePlans = append(opts.RatePlans, entity.GuestRatePlanOption{
+			RatePlanID:   plan.ID,
+			Name:         plan.Name,
+			MealPlan:     plan.MealPlan,
+			IsRefundable: plan.CancellationPolicy.IsRefundable,
+			Total:        total,
+			Current:      current,
+		})
+	}
+	return opts, nil
+}
+
+// authorizeGuestChange turns a guest request into a staff modification once it fits the options.
+func authorizeGuestChange(opts *entity.GuestModificationOptions, req entity.GuestModificationRequest) (entity.UpdateReservationRequest, error) {
+	changesStay := req.Start != "" || req.End != "" || req.Adults != nil || req.Children != nil
+	if !changesStay && req.RatePlanID == nil {
+		return entity.UpdateReservationRequest{}, fmt.Errorf("%w: nothing to change", entity.ErrInvalidInput)
+	}
+	if changesStay && !opts.CanChangeDates {
+		return entity.UpdateReservationRequest{}, fmt.Errorf("%w: dates and guests can no longer be changed online", entity.ErrModificationNotAllowed)
+	}
+
+	if req.RatePlanID != nil {
+		offered := false
+		for _, option := range opts.RatePlans {
+			if option.RatePlanID == *req.RatePlanID {
+				offered = true
+				break
+			}
+		}
+		if !opts.CanChangeRatePlan || !offered {
+			return entity.UpdateReservationRequest{}, fmt.Errorf("%w: rate plan is not available for this reservation", entity.ErrModificationNotAllowed)
+		}
+	}
+
+	return entity.UpdateReservationRequest{
+		Start:      req.Start,
+		End:        req.End,
+		RatePlanID: req.RatePlanID,
+		Adults:     req.Adults,
+		Children:   req.Children,
+	}, nil
+}
@@ -0,0 +1,408 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/service"
+)
+
+// DefaultDuplicateThreshold is the minimum match score reported as a likely duplicate.
+const DefaultDuplicateThreshold = 0.7
+
+type GuestUseCase struct {
+	db          *pgxpool.Pool
+	guestRepo   *repository.GuestRepository
+	resRepo     *repository.ReservationRepository
+	invoiceRepo *repository.InvoiceRepository
+	matcher     *service.GuestMatcher
+}
+
+func NewGuestUseCase(
+	db *pgxpool.Pool,
+	guestRepo *repository.GuestRepository,
+	resRepo *repository.ReservationRepository,
+	invoiceRepo *repository.InvoiceRepository,
+	matcher *service.GuestMatcher,
+) *GuestUseCase {
+	return &GuestUseCase{
+		db:          db,
+		guestRepo:   guestRepo,
+		resRepo:     resRepo,
+		invoiceRepo: invoiceRepo,
+		matcher:     matcher,
+	}
+}
+
+func (uc *GuestUseCase) Create(ctx context.Context, orgID string, req entity.CreateGuestRequest) (string, error) {
+	if orgID == "" {
+		return "", entity.ErrInvalidInput
+	}
+	if req.Email == "" || !strings.Contains(req.Email, "@") {
+		return "", fmt.Errorf("%w: a valid email is required", entity.ErrInvalidInput)
+	}
+	if req.FirstName == "" || req.LastName == "" {
+		return "", fmt.Errorf("%w: first and last name are required", entity.ErrInvalidInput)
+	}
+	if req.Language != "" && !entity.IsSupportedLanguage(req.Language) {
+		return "", fmt.Errorf("%w: unsupported language", entity.ErrInvalidInput)
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+
+	guest := entity.Guest{
+		BaseEntity:     entity.BaseEntity{ID: id.String()},
+		OrganizationID: orgID,
+		Email:          req.Email,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		Phone:          req.Phone,
+		Language:       req.Language,
+	}
+	return uc.guestRepo.Create(ctx, nil, guest)
+}
+
+func (uc *GuestUseCase) Search(ctx context.Context, orgID, query string, pagination entity.PaginationRequest) ([]entity.Guest, int64, error) {
+	return uc.guestRepo.List(ctx, orgID, strings.TrimSpace(query), pagination)
+}
+
+// loadOwnedGuest loads a guest of the organization; guests of other organizations are reported as missing.
+func loadOwnedGuest(ctx context.Context, guestRepo *repository.GuestRepository, orgID, id string) (*entity.Guest, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	guest, err := guestRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if guest.OrganizationID != orgID {
+		return nil, entity.ErrRecordNotFound
+	}
+	return guest, nil
+}
+
+// GetDetail returns the profile together with its stay history, revenue and notes.
+func (uc *GuestUseCase) GetDetail(ctx context.Context, orgID, id string) (*entity.GuestDetail, error) {
+	guest, err := loadOwnedGuest(ctx, uc.guestRepo, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stays, err := uc.guestRepo.ListStays(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	revenue, err := uc.guestRepo.LifetimeRevenue(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := uc.guestRepo.ListNotes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &entity.GuestDetail{
+		Guest:           *guest,
+		Stays:           stays,
+		LifetimeRevenue: roundMoney(revenue),
+		Notes:           notes,
+	}
+	if detail.Stays == nil {
+		detail.Stays = []entity.GuestStay{}
+	}
+	if detail.Notes == nil {
+		detail.Notes = []entity.GuestNote{}
+	}
+	for _, s := range stays {
+		if s.Status == "checked_out" {
+			detail.CompletedStays++
+		}
+	}
+
+	return detail, nil
+}
+
+func (uc *GuestUseCase) Update(ctx context.Context, orgID, id string, req entity.UpdateGuestRequest) error {
+	if _, err := loadOwnedGuest(ctx, uc.guestRepo, orgID, id); err != nil {
+		return err
+	}
+	if req.Email != "" && !strings.Contains(req.Email, "@") {
+		return fmt.Errorf("%w: invalid email", entity.ErrInvalidInput)
+	}
+	if req.Language != "" && !entity.IsSupportedLanguage(req.Language) {
+		return fmt.Errorf("%w: unsupported language", entity.ErrInvalidInput)
+	}
+	return uc.guestRepo.Update(ctx, nil, id, req)
+}
+
+func (uc *GuestUseCase) Delete(ctx context.Context, orgID, id string) error {
+	if _, err := loadOwnedGuest(ctx, uc.guestRepo, orgID, id); err != nil {
+		return err
+	}
+
+	count, err := uc.guestRepo.CountActiveReservations(ctx, nil, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("cannot delete guest: %d active reservations: %w", count, entity.ErrConflict)
+	}
+
+	return uc.guestRepo.Delete(ctx, nil, id)
+}
+
+func (uc *GuestUseCase) AddNote(ctx context.Context, orgID, guestID, authorID string, req entity.CreateGuestNoteRequest) (*entity.GuestNote, error) {
+	if strings.TrimSpace(req.Body) == "" {
+		return nil, fmt.Errorf("%w: note body is required", entity.ErrInvalidInput)
+	}
+	if _, err := loadOwnedGuest(ctx, uc.guestRepo, orgID, guestID); err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+
+	note := entity.GuestNote{
+		BaseEntity: entity.BaseEntity{ID: id.String()},
+		GuestID:    guestID,
+		Body:       req.Body,
+	}
+	if authorID != "" {
+		note.AuthorID = &authorID
+	}
+
+	if err := uc.guestRepo.CreateNote(ctx, note); err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// FindDuplicates pages through the likely duplicates of the organization, best matches first.
+// Candidate pairs are grouped in SQL on the match index, so only guests sharing a blocking key
+// are decrypted and scored.
+func (uc *GuestUseCase) FindDuplicates(ctx context.Context, orgID string, threshold float64, pagination entity.PaginationRequest) ([]entity.GuestDuplicate, int64, error) {
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultDuplicateThreshold
+	}
+
+	pairs, err := uc.guestRepo.ListDuplicateCandidates(ctx, orgID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(pairs) == 0 {
+		return nil, 0, nil
+	}
+
+	seen := map[string]bool{}
+	var ids []string
+	for _, pair := range pairs {
+		for _, id := range pair {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	guests, err := uc.guestRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[string]entity.Guest, len(guests))
+	for _, g := range guests {
+		byID[g.ID] = g
+	}
+
+	duplicates := uc.matcher.ScorePairs(byID, pairs, threshold)
+	total := int64(len(duplicates))
+	start := (pagination.Page - 1) * pagination.Limit
+	if start >= len(duplicates) {
+		return nil, total, nil
+	}
+	end := min(start+pagination.Limit, len(duplicates))
+	return duplicates[start:end], total, nil
+}
+
+// Merge folds the duplicate into the survivor: reservations, issued documents and notes move
+// over, blank survivor fields are filled from the duplicate, and the duplicate is retired.
+// Everything happens in one transaction together with the audit record.
+func (uc *GuestUseCase) Merge(ctx context.Context, orgID, survivorID, actorID string, req entity.MergeGuestRequest) (*entity.GuestMerge, error) {
+	if _, err := uuid.Parse(survivorID); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	if _, err := uuid.Parse(req.DuplicateID); err != nil {
+		return nil, fmt.Errorf("%w: duplicate_id is required", entity.ErrInvalidInput)
+	}
+	if survivorID == req.DuplicateID {
+		return nil, fmt.Errorf("%w: a guest cannot be merged into itself", entity.ErrInvalidInput)
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock in a stable order so two opposite merges cannot deadlock.
+	first, second := survivorID, req.DuplicateID
+	if second < first {
+		first, second = second, first
+	}
+	locked := map[string]*entity.Guest{}
+	for _, id := range []string{first, second} {
+		g, err := uc.guestRepo.GetByIDLocked(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if g.OrganizationID != orgID {
+			return nil, entity.ErrRecordNotFound
+		}
+		locked[id] = g
+	}
+	survivor, duplicate := locked[survivorID], locked[req.DuplicateID]
+
+	snapshot, err := json.Marshal(duplicate)
+	if err != nil {
+		return nil, fmt.Errorf("encoding merged guest: %w", err)
+	}
+
+	mergeID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+	merge := entity.GuestMerge{
+		ID:             mergeID.String(),
+		OrganizationID: orgID,
+		SurvivorID:     survivor.ID,
+		MergedID:       duplicate.ID,
+		MergedSnapshot: snapshot,
+	}
+	if actorID != "" {
+		merge.MergedBy = &actorID
+	}
+
+	if merge.ReservationsMoved, err = uc.resRepo.ReassignGuest(ctx, tx, duplicate.ID, survivor.ID); err != nil {
+		return nil, err
+	}
+	if merge.InvoicesMoved, err = uc.invoiceRepo.ReassignGuest(ctx, tx, duplicate.ID, survivor.ID); err != nil {
+		return nil, err
+	}
+	if merge.NotesMoved, err = uc.guestRepo.ReassignNotes(ctx, tx, duplicate.ID, survivor.ID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.guestRepo.Delete(ctx, tx, duplicate.ID); err != nil {
+		return nil, err
+	}
+
+	var fill entity.UpdateGuestRequest
+	if survivor.Phone == "" {
+		fill.Phone = duplicate.Phone
+	}
+	if survivor.Language == "" {
+		fill.Language = duplicate.Language
+	}
+	if fill != (entity.UpdateGuestRequest{}) {
+		if err := uc.guestRepo.Update(ctx, tx, survivor.ID, fill); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.guestRepo.CreateMerge(ctx, tx, &merge); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+// ReencryptPII seals guests and merge snapshots still stored in plaintext or under a retired
+// key with the active key, batch by batch. It backs the initial encryption of existing rows as
+// well as key rotation. A guest whose email collides with another one is skipped and reported
+// rather than stopping the run.
+func (uc *GuestUseCase) ReencryptPII(ctx context.Context, batchSize int) (*entity.PIIReencryption, error) {
+	result := &entity.PIIReencryption{}
+	for {
+		tx, err := uc.db.Begin(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		guests, err := uc.guestRepo.ListStaleEncryption(ctx, tx, result.Conflicts, batchSize)
+		if err != nil {
+			tx.Rollback(ctx)
+			return result, err
+		}
+		sealed := 0
+		for _, g := range guests {
+			// Each row gets its own savepoint so a conflict only undoes that row.
+			row, err := tx.Begin(ctx)
+			if err != nil {
+				tx.Rollback(ctx)
+				return result, err
+			}
+			if err := uc.guestRepo.Reseal(ctx, row, g); err != nil {
+				row.Rollback(ctx)
+				if errors.Is(err, entity.ErrConflict) {
+					result.Conflicts = append(result.Conflicts, g.ID)
+					continue
+				}
+				tx.Rollback(ctx)
+				return result, fmt.Errorf("re-encrypt guest %s: %w", g.ID, err)
+			}
+			if err := row.Commit(ctx); err != nil {
+				tx.Rollback(ctx)
+				return result, err
+			}
+			sealed++
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return result, err
+		}
+
+		result.Guests += sealed
+		if len(guests) < batchSize {
+			break
+		}
+	}
+
+	for {
+		tx, err := uc.db.Begin(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		merges, err := uc.guestRepo.ListStaleMergeSnapshots(ctx, tx, batchSize)
+		if err != nil {
+			tx.Rollback(ctx)
+			return result, err
+		}
+		for _, m := range merges {
+			if err := uc.guestRepo.ResealMergeSnapshot(ctx, tx, m); err != nil {
+				tx.Rollback(ctx)
+				return result, fmt.Errorf("re-encrypt merge snapshot %s: %w", m.ID, err)
+			}
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return result, err
+		}
+
+		result.Snapshots += len(merges)
+		if len(merges) < batchSize {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/service"
+	"github.com/ecelayes/pms-backend/internal/tenant"
+	"github.com/ecelayes/pms-backend/pkg/auth"
+)
+
+// invitationTTL is how long an invitation link stays valid; resending starts it over.
+const invitationTTL = 7 * 24 * time.Hour
+
+// InvitationUseCase lets owners and managers invite people to their organization. Invitees
+// choose their own password when accepting, instead of receiving one from the inviter.
+type InvitationUseCase struct {
+	db             *pgxpool.Pool
+	invitationRepo *repository.InvitationRepository
+	orgRepo        *repository.OrganizationRepository
+	userRepo       *repository.UserRepository
+	passwords      auth.PasswordPolicy
+	emailService   *service.EmailService
+	outboxRepo     *repository.EmailOutboxRepository
+	logger         *zap.Logger
+}
+
+func NewInvitationUseCase(
+	db *pgxpool.Pool,
+	invitationRepo *repository.InvitationRepository,
+	orgRepo *repository.OrganizationRepository,
+	userRepo *repository.UserRepository,
+	passwords auth.PasswordPolicy,
+	emailService *service.EmailService,
+	outboxRepo *repository.EmailOutboxRepository,
+	logger *zap.Logger,
+) *InvitationUseCase {
+	return &InvitationUseCase{
+		db:             db,
+		invitationRepo: invitationRepo,
+		orgRepo:        orgRepo,
+		userRepo:       userRepo,
+		passwords:      passwords,
+		emailService:   emailService,
+		outboxRepo:     outboxRepo,
+		logger:         logger,
+	}
+}
+
+// canInvite reports whether requesterRole may hand out role. Owners are only appointed by
+// super admins, as with CreateUser, and managers only bring in front desk staff.
+func canInvite(requesterRole, role string) error {
+	switch role {
+	case entity.OrgRoleOwner, entity.OrgRoleManager, entity.OrgRoleStaff:
+	default:
+		return fmt.Errorf("%w: unknown role %q", entity.ErrInvalidInput, role)
+	}
+
+	switch requesterRole {
+	case entity.RoleSuperAdmin:
+		return nil
+	case entity.OrgRoleOwner:
+		if role != entity.OrgRoleOwner {
+			return nil
+		}
+	case entity.OrgRoleManager:
+		if role == entity.OrgRoleStaff {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot invite members with role %s", entity.ErrInsufficientPermissions, role)
+}
+
+func (uc *InvitationUseCase) Create(ctx context.Context, requesterRole, inviterID string, req entity.CreateInvitationRequest) (*entity.Invitation, error) {
+	req.Email = strings.TrimSpace(req.Email)
+	if !strings.Contains(req.Email, "@") {
+		return nil, fmt.Errorf("%w: a valid email is required", entity.ErrInvalidInput)
+	}
+	if orgID, scoped := tenant.OrganizationID(ctx); scoped {
+		if req.OrganizationID != "" && req.OrganizationID != orgID {
+			return nil, fmt.Errorf("%w: organization not found", entity.ErrInvalidInput)
+		}
+		req.OrganizationID = orgID
+	}
+	if req.OrganizationID == "" {
+		return nil, fmt.Errorf("%w: organization_id is required", entity.ErrInvalidInput)
+	}
+	if req.Language != "" && !entity.IsSupportedLanguage(req.Language) {
+		return nil, entity.ErrInvalidInput
+	}
+	if err := canInvite(requesterRole, req.Role); err != nil {
+		return nil, err
+	}
+
+	propertyIDs, err := uniquePropertyIDs(req.PropertyIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := withinPropertyScope(ctx, propertyIDs); err != nil {
+		return nil, err
+	}
+	if len(propertyIDs) > 0 {
+		owned, err := uc.orgRepo.OwnsProperties(ctx, req.OrganizationID, propertyIDs)
+		if err != nil {
+			return nil, err
+		}
+		if !owned {
+			return nil, fmt.Errorf("%w: unknown property", entity.ErrInvalidInput)
+		}
+	}
+
+	org, err := uc.orgRepo.GetByID(ctx, req.OrganizationID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: organization not found", entity.ErrInvalidInput)
+		}
+		return nil, err
+	}
+
+	member, err := uc.orgRepo.IsMemberEmail(ctx, org.ID, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if member {
+		return nil, fmt.Errorf("%w: %s is already a member of the organization", entity.ErrConflict, req.Email)
+	}
+
+	secret, err := auth.GenerateRandomSalt()
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+
+	inv := entity.Invitation{
+		BaseEntity:     entity.BaseEntity{ID: id.String()},
+		OrganizationID: org.ID,
+		Email:          req.Email,
+		Role:           req.Role,
+		PropertyIDs:    propertyIDs,
+		Language:       req.Language,
+		Secret:         secret,
+		ExpiresAt:      time.Now().Add(invitationTTL),
+	}
+	if inviterID != "" {
+		inv.InvitedBy = &inviterID
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := uc.invitationRepo.Create(ctx, tx, inv); err != nil {
+		if errors.Is(err, entity.ErrConflict) {
+			return nil, fmt.Errorf("%w: an invitation is already open for %s, resend it instead", entity.ErrConflict, req.Email)
+		}
+		return nil, err
+	}
+	if err := uc.queueInvitationEmail(ctx, tx, inv, org.Name); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("invitation created",
+		zap.String("invitation_id", inv.ID),
+		zap.String("organization_id", inv.OrganizationID),
+		zap.String("role", inv.Role),
+	)
+	inv.ResolveStatus(time.Now())
+	return &inv, nil
+}
+
+// List returns the organization's invitations. Callers scoped to an organization always get their own.
+func (uc *InvitationUseCase) List(ctx context.Context, orgID string) ([]entity.Invitation, error) {
+	if scopedOrg, scoped := tenant.OrganizationID(ctx); scoped {
+		orgID = scopedOrg
+	}
+	if orgID == "" {
+		return nil, fmt.Errorf("%w: organization_id is required", entity.ErrInvalidInput)
+	}
+	return uc.invitationRepo.ListByOrganization(ctx, orgID)
+}
+
+// Resend emails a fresh link for an open invitation and restarts its validity. Links sent
+// before stop working.
+func (uc *InvitationUseCase) Resend(ctx context.Context, requesterRole, id string) error {
+	inv, err := uc.getManageable(ctx, requesterRole, id)
+	if err != nil {
+		return err
+	}
+
+	secret, err := auth.GenerateRandomSalt()
+	if err != nil {
+		return err
+	}
+	inv.Secret = secret
+	inv.ExpiresAt = time.Now().Add(invitationTTL)
+	if err := uc.invitationRepo.Renew(ctx, inv.ID, inv.Secret, inv.ExpiresAt); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return fmt.Errorf("%w: invitation is no longer open", entity.ErrConflict)
+		}
+		return err
+	}
+
+	org, err := uc.orgRepo.GetByID(ctx, inv.OrganizationID)
+	if err != nil {
+		return err
+	}
+	return uc.queueInvitationEmail(ctx, nil, *inv, org.Name)
+}
+
+func (uc *InvitationUseCase) Revoke(ctx context.Context, requesterRole, id string) error {
+	inv, err := uc.getManageable(ctx, requesterRole, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.invitationRepo.Revoke(ctx, inv.ID); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return fmt.Errorf("%w: invitation is no longer open", entity.ErrConflict)
+		}
+		return err
+	}
+	return nil
+}
+
+// withinPropertyScope checks that a caller limited to some properties only hands out access
+// to those. Unrestricted callers may invite to any property, or to the whole organization.
+func withinPropertyScope(ctx context.Context, propertyIDs []string) error {
+	scope, restricted := tenant.PropertyIDs(ctx)
+	if !restricted {
+		return nil
+	}
+	if len(propertyIDs) == 0 {
+		return fmt.Errorf("%w: invitations must be limited to your properties", entity.ErrInsufficientPermissions)
+	}
+	allowed := map[string]bool{}
+	for _, id := range scope {
+		allowed[id] = true
+	}
+	for _, id := range propertyIDs {
+		if !allowed[id] {
+			return fmt.Errorf("%w: invitations must be limited to your properties", entity.ErrInsufficientPermissions)
+		}
+	}
+	return nil
+}
+
+// getManageable loads an invitation the caller may resend or revoke: it must still be open
+// and carry a role and properties the caller could have invited to.
+func (uc *InvitationUseCase) getManageable(ctx context.Context, requesterRole, id string) (*entity.Invitation, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	inv, err := uc.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := canInvite(requesterRole, inv.Role); err != nil {
+		return nil, err
+	}
+	if err := withinPropertyScope(ctx, inv.PropertyIDs); err != nil {
+		return nil, err
+	}
+	if inv.Status == entity.InvitationStatusAccepted || inv.Status == entity.InvitationStatusRevoked {
+		return nil, fmt.Errorf("%w: invitation is no longer open", entity.ErrConflict)
+	}
+	return inv, nil
+}
+
+// Accept adds the invitee to the organization with the invited role. Invitees without an
+// account get one, already verified since the link proves the address; existing users
+// confirm with their password and gain one more membership. An account that was registered
+// for the address but never verified is not trusted to be the invitee's: it is replaced.
+func (uc *InvitationUseCase) Accept(ctx context.Context, req entity.AcceptInvitationRequest) (string, string, error) {
+	invalid := fmt.Errorf("%w: invitation link is invalid or has expired", entity.ErrInvalidInput)
+
+	claims, err := auth.ParseInvitationClaimsUnsafe(req.Token)
+	if err != nil || claims.Purpose != auth.PurposeInvitation {
+		return "", "", invalid
+	}
+	if _, err := uuid.Parse(claims.InvitationID); err != nil {
+		return "", "", invalid
+	}
+	inv, err := uc.invitationRepo.GetByID(ctx, claims.InvitationID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return "", "", invalid
+		}
+		return "", "", err
+	}
+	if _, err := auth.ValidateInvitationToken(req.Token, inv.Secret); err != nil || inv.Status != entity.InvitationStatusPending {
+		return "", "", invalid
+	}
+
+	existing, err := uc.userRepo.GetByEmail(ctx, inv.Email)
+	if err != nil && !errors.Is(err, entity.ErrInvalidCredentials) {
+		return "", "", err
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if existing != nil && existing.EmailVerifiedAt == nil {
+		if err := uc.userRepo.DeleteUnverifiedUser(ctx, tx, existing.ID); err != nil {
+			return "", "", err
+		}
+		uc.logger.Info("unverified account replaced by invitation",
+			zap.String("invitation_id", inv.ID),
+			zap.String("user_id", existing.ID),
+		)
+		existing = nil
+	}
+
+	var userID string
+	if existing != nil {
+		if !auth.CheckPassword(req.Password, existing.Password) {
+			return "", "", entity.ErrInvalidCredentials
+		}
+		userID = existing.ID
+	} else {
+		if err := uc.passwords.Validate(req.Password); err != nil {
+			return "", "", fmt.Errorf("%w: %s", entity.ErrInvalidInput, err)
+		}
+		if req.FirstName == "" || req.LastName == "" {
+			return "", "", fmt.Errorf("%w: first_name and last_name are required", entity.ErrInvalidInput)
+		}
+		userID, err = uc.createInvitedUser(ctx, tx, inv, req)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	memberID, err := uuid.NewV7()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+	member := entity.OrganizationMember{
+		BaseEntity:     entity.BaseEntity{ID: memberID.String()},
+		OrganizationID: inv.OrganizationID,
+		UserID:         userID,
+		Role:           inv.Role,
+	}
+	if err := uc.orgRepo.AddMember(ctx, tx, member); err != nil {
+		if errors.Is(err, entity.ErrConflict) {
+			return "", "", fmt.Errorf("%w: already a member of the organization", entity.ErrConflict)
+		}
+		return "", "", err
+	}
+	if len(inv.PropertyIDs) > 0 {
+		if err := uc.orgRepo.SetMemberProperties(ctx, tx, inv.OrganizationID, userID, inv.PropertyIDs); err != nil {
+			return "", "", err
+		}
+	}
+
+	if err := uc.invitationRepo.MarkAccepted(ctx, tx, inv.ID); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return "", "", invalid
+		}
+		return "", "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", "", err
+	}
+
+	uc.logger.Info("invitation accepted",
+		zap.String("invitation_id", inv.ID),
+		zap.String("organization_id", inv.OrganizationID),
+		zap.String("user_id", userID),
+	)
+	return inv.OrganizationID, userID, nil
+}
+
+func (uc *InvitationUseCase) createInvitedUser(ctx context.Context, tx pgx.Tx, inv *entity.Invitation, req entity.AcceptInvitationRequest) (string, error) {
+	passwordHash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return "", err
+	}
+	userSalt, err := auth.GenerateRandomSalt()
+	if err != nil {
+		return "", err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+
+	verifiedAt := time.Now()
+	user := entity.User{
+		BaseEntity:      entity.BaseEntity{ID: id.String()},
+		Email:           inv.Email,
+		Password:        passwordHash,
+		Salt:            userSalt,
+		Role:            entity.RoleUser,
+		FirstName:       req.FirstName,
+		LastName:        req.LastName,
+		Phone:           req.Phone,
+		Language:        inv.Language,
+		EmailVerifiedAt: &verifiedAt,
+	}
+	if err := uc.userRepo.Create(ctx, tx, user); err != nil {
+		if errors.Is(err, entity.ErrConflict) {
+			return "", fmt.Errorf("%w: email is already registered", entity.ErrConflict)
+		}
+		return "", err
+	}
+	return user.ID, nil
+}
+
+func (uc *InvitationUseCase) queueInvitationEmail(ctx context.Context, tx pgx.Tx, inv entity.Invitation, orgName string) error {
+	token, err := auth.GenerateInvitationToken(inv.ID, inv.Secret, inv.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	msg, err := uc.emailService.Invitation(inv.Language, inv.Email, orgName, inv.Role, token)
+	if err != nil {
+		return err
+	}
+	return uc.outboxRepo.Enqueue(ctx, tx, msg)
+}
@@ -0,0 +1,367 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/service"
+)
+
+type InvoiceUseCase struct {
+	db           *pgxpool.Pool
+	invoiceRepo  *repository.InvoiceRepository
+	resRepo      *repository.ReservationRepository
+	unitTypeRepo *repository.UnitTypeRepository
+	propertyRepo *repository.PropertyRepository
+	guestRepo    *repository.GuestRepository
+	ratePlanRepo *repository.RatePlanRepository
+	addOnRepo    *repository.ReservationAddOnRepository
+	renderer     *service.InvoiceRenderer
+}
+
+func NewInvoiceUseCase(
+	db *pgxpool.Pool,
+	invoiceRepo *repository.InvoiceRepository,
+	resRepo *repository.ReservationRepository,
+	unitTypeRepo *repository.UnitTypeRepository,
+	propertyRepo *repository.PropertyRepository,
+	guestRepo *repository.GuestRepository,
+	ratePlanRepo *repository.RatePlanRepository,
+	addOnRepo *repository.ReservationAddOnRepository,
+	renderer *service.InvoiceRenderer,
+) *InvoiceUseCase {
+	return &InvoiceUseCase{
+		db:           db,
+		invoiceRepo:  invoiceRepo,
+		resRepo:      resRepo,
+		unitTypeRepo: unitTypeRepo,
+		propertyRepo: propertyRepo,
+		guestRepo:    guestRepo,
+		ratePlanRepo: ratePlanRepo,
+		addOnRepo:    addOnRepo,
+		renderer:     renderer,
+	}
+}
+
+// CheckOut closes the stay and issues the invoice for the reservation's folio in a single transaction.
+func (uc *InvoiceUseCase) CheckOut(ctx context.Context, reservationID string) (*entity.Invoice, error) {
+	if _, err := uuid.Parse(reservationID); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := uc.resRepo.GetByIDLocked(ctx, tx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != "confirmed" && res.Status != "checked_in" {
+		return nil, entity.ErrInvalidReservationStatus
+	}
+
+	unitType, err := uc.unitTypeRepo.GetByID(ctx, res.UnitTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load unit type: %w", err)
+	}
+	property, err := uc.propertyRepo.GetByID(ctx, unitType.PropertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load property: %w", err)
+	}
+	guest, err := uc.guestRepo.GetByID(ctx, res.GuestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load guest: %w", err)
+	}
+
+	var plan *entity.RatePlan
+	if res.RatePlanID != nil {
+		plan, err = uc.ratePlanRepo.GetByID(ctx, *res.RatePlanID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rate plan: %w", err)
+		}
+	}
+
+	addOns, err := uc.addOnRepo.ListByReservation(ctx, tx, res.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := newInvoiceDocument(entity.DocumentTypeInvoice, property.TaxRate, buildFolio(*res, *unitType, plan, addOns))
+	if err != nil {
+		return nil, err
+	}
+	invoice.PropertyID = property.ID
+	invoice.ReservationID = res.ID
+	invoice.GuestID = &guest.ID
+	invoice.CustomerName = guest.FirstName + " " + guest.LastName
+	invoice.CustomerEmail = guest.Email
+
+	prefix, sequence, err := uc.invoiceRepo.NextNumber(ctx, tx, property.ID, entity.DocumentTypeInvoice, property.Code+"-INV")
+	if err != nil {
+		return nil, err
+	}
+	invoice.Sequence = sequence
+	invoice.Number = fmt.Sprintf("%s-%06d", prefix, sequence)
+
+	if err := uc.invoiceRepo.Create(ctx, tx, *invoice); err != nil {
+		if errors.Is(err, entity.ErrConflict) {
+			return nil, entity.ErrAlreadyInvoiced
+		}
+		return nil, err
+	}
+
+	if err := uc.resRepo.UpdateStatus(ctx, tx, res.ID, "checked_out"); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (uc *InvoiceUseCase) CreateCreditNote(ctx context.Context, invoiceID string, req entity.CreateCreditNoteRequest) (*entity.Invoice, error) {
+	if _, err := uuid.Parse(invoiceID); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	if req.Reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", entity.ErrInvalidInput)
+	}
+	if req.Amount != nil && *req.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", entity.ErrInvalidInput)
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := uc.invoiceRepo.LockForCorrection(ctx, tx, invoiceID); err != nil {
+		return nil, err
+	}
+
+	original, err := uc.invoiceRepo.GetByID(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if original.DocumentType != entity.DocumentTypeInvoice {
+		return nil, fmt.Errorf("%w: only invoices can be corrected", entity.ErrInvalidInput)
+	}
+
+	credited, err := uc.invoiceRepo.CreditedTotal(ctx, tx, original.ID)
+	if err != nil {
+		return nil, err
+	}
+	outstanding := roundMoney(original.Total - credited)
+	if outstanding <= 0 {
+		return nil, entity.ErrCreditExceedsInvoice
+	}
+
+	var lines []entity.InvoiceLine
+	switch {
+	case req.Amount == nil && credited == 0:
+		for _, l := range original.Lines {
+			lines = append(lines, entity.InvoiceLine{
+				Description: l.Description,
+				Quantity:    l.Quantity,
+				UnitPrice:   -l.UnitPrice,
+				Total:       -l.Total,
+			})
+		}
+	default:
+		amount := outstanding
+		if req.Amount != nil {
+			amount = roundMoney(*req.Amount)
+		}
+		if amount > outstanding {
+			return nil, entity.ErrCreditExceedsInvoice
+		}
+		lines = append(lines, entity.InvoiceLine{
+			Description: "Adjustment: " + req.Reason,
+			Quantity:    1,
+			UnitPrice:   -amount,
+			Total:       -amount,
+		})
+	}
+
+	note, err := newInvoiceDocument(entity.DocumentTypeCreditNote, original.TaxRate, lines)
+	if err != nil {
+		return nil, err
+	}
+	note.PropertyID = original.PropertyID
+	note.ReservationID = original.ReservationID
+	note.GuestID = original.GuestID
+	note.CorrectsInvoiceID = &original.ID
+	note.Reason = req.Reason
+	note.CustomerName = original.CustomerName
+	note.CustomerEmail = original.CustomerEmail
+	note.Currency = original.Currency
+
+	property, err := uc.propertyRepo.GetByID(ctx, original.PropertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load property: %w", err)
+	}
+
+	prefix, sequence, err := uc.invoiceRepo.NextNumber(ctx, tx, property.ID, entity.DocumentTypeCreditNote, property.Code+"-CN")
+	if err != nil {
+		return nil, err
+	}
+	note.Sequence = sequence
+	note.Number = fmt.Sprintf("%s-%06d", prefix, sequence)
+
+	if err := uc.invoiceRepo.Create(ctx, tx, *note); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return note, nil
+}
+
+func (uc *InvoiceUseCase) GetByID(ctx context.Context, id string) (*entity.Invoice, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	return uc.invoiceRepo.GetByID(ctx, id)
+}
+
+func (uc *InvoiceUseCase) ListByProperty(ctx context.Context, propertyID string, pagination entity.PaginationRequest) ([]entity.Invoice, int64, error) {
+	if propertyID == "" {
+		return nil, 0, entity.ErrInvalidInput
+	}
+	return uc.invoiceRepo.ListByProperty(ctx, propertyID, pagination)
+}
+
+func (uc *InvoiceUseCase) RenderPDF(ctx context.Context, id string) ([]byte, *entity.Invoice, error) {
+	invoice, err := uc.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	property, err := uc.propertyRepo.GetByID(ctx, invoice.PropertyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load property: %w", err)
+	}
+
+	doc := service.InvoiceDocument{
+		Invoice:  *invoice,
+		Property: *property,
+	}
+
+	if res, err := uc.resRepo.GetByID(ctx, invoice.ReservationID); err == nil {
+		doc.ReservationCode = res.ReservationCode
+	}
+	if invoice.CorrectsInvoiceID != nil {
+		corrected, err := uc.invoiceRepo.GetByID(ctx, *invoice.CorrectsInvoiceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		doc.CorrectedNumber = corrected.Number
+	}
+
+	content, err := uc.renderer.RenderPDF(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, invoice, nil
+}
+
+// buildFolio splits the stored reservation total into the charges the guest was quoted.
+// The total is the source of truth so the invoice never drifts from what was booked.
+func buildFolio(res entity.Reservation, unitType entity.UnitType, plan *entity.RatePlan, addOns []entity.ReservationAddOn) []entity.InvoiceLine {
+	nights := int(res.End.Sub(res.Start).Hours() / 24)
+	if nights < 1 {
+		nights = 1
+	}
+	pax := res.Adults + res.Children
+
+	mealTotal := 0.0
+	if plan != nil && plan.MealPlan.Included && plan.MealPlan.PricePerPax > 0 {
+		mealTotal = roundMoney(plan.MealPlan.PricePerPax * float64(pax) * float64(nights))
+	}
+	roomTotal := roundMoney(res.TotalPrice - mealTotal - addOnsTotal(addOns))
+
+	lines := []entity.InvoiceLine{{
+		Description: fmt.Sprintf("Accommodation %s (%s - %s)", unitType.Name, res.Start.Format("2006-01-02"), res.End.Format("2006-01-02")),
+		Quantity:    float64(nights),
+		UnitPrice:   roundMoney(roomTotal / float64(nights)),
+		Total:       roomTotal,
+	}}
+
+	if mealTotal > 0 {
+		lines = append(lines, entity.InvoiceLine{
+			Description: "Meal plan",
+			Quantity:    float64(pax * nights),
+			UnitPrice:   plan.MealPlan.PricePerPax,
+			Total:       mealTotal,
+		})
+	}
+
+	for _, addOn := range addOns {
+		lines = append(lines, entity.InvoiceLine{
+			Description: addOn.Name,
+			Quantity:    float64(addOn.PricingUnit.ChargedUnits(addOn.Quantity, pax, nights)),
+			UnitPrice:   addOn.UnitPrice,
+			Total:       addOn.Total,
+		})
+	}
+
+	return lines
+}
+
+// newInvoiceDocument computes the tax breakdown of tax-inclusive line totals.
+func newInvoiceDocument(documentType string, taxRate float64, lines []entity.InvoiceLine) (*entity.Invoice, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+
+	invoice := &entity.Invoice{
+		BaseEntity:   entity.BaseEntity{ID: id.String()},
+		DocumentType: documentType,
+		Currency:     "USD",
+		TaxRate:      taxRate,
+		IssuedAt:     time.Now().UTC(),
+	}
+
+	for i, line := range lines {
+		lineID, err := uuid.NewV7()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
+		}
+		line.ID = lineID.String()
+		line.Position = i + 1
+		line.Total = roundMoney(line.Total)
+		line.NetAmount = roundMoney(line.Total / (1 + taxRate/100))
+		line.TaxAmount = roundMoney(line.Total - line.NetAmount)
+
+		invoice.Subtotal += line.NetAmount
+		invoice.TaxAmount += line.TaxAmount
+		invoice.Total += line.Total
+		invoice.Lines = append(invoice.Lines, line)
+	}
+
+	invoice.Subtotal = roundMoney(invoice.Subtotal)
+	invoice.TaxAmount = roundMoney(invoice.TaxAmount)
+	invoice.Total = roundMoney(invoice.Total)
+
+	return invoice, nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
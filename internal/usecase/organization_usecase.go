@@ -29,16 +29,10 @@ func (uc *OrganizationUseCase) Create(ctx context.Context, req entity.CreateOrga
 		return "", fmt.Errorf("failed to generate uuid: %w", err)
 	}
 
-	slug := strings.ReplaceAll(strings.ToUpper(req.Name), " ", "")
-	if len(slug) > 5 {
-		slug = slug[:5]
-	}
-	code := fmt.Sprintf("%s-%s", slug, utils.GenerateRandomCode(3))
-
 	org := entity.Organization{
 		BaseEntity: entity.BaseEntity{ID: orgID.String()},
 		Name:       req.Name,
-		Code:       code,
+		Code:       newOrganizationCode(req.Name),
 	}
 
 	if err := uc.repo.Create(ctx, nil, org); err != nil {
@@ -48,6 +42,15 @@ func (uc *OrganizationUseCase) Create(ctx context.Context, req entity.CreateOrga
 	return orgID.String(), nil
 }
 
+// newOrganizationCode derives a short code from the organization name plus a random suffix.
+func newOrganizationCode(name string) string {
+	slug := strings.ReplaceAll(strings.ToUpper(name), " ", "")
+	if len(slug) > 5 {
+		slug = slug[:5]
+	}
+	return fmt.Sprintf("%s-%s", slug, utils.GenerateRandomCode(3))
+}
+
 func (uc *OrganizationUseCase) GetAll(ctx context.Context, pagination entity.PaginationRequest) ([]entity.Organization, int64, error) {
 	return uc.repo.GetAll(ctx, pagination)
 }
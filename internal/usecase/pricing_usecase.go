@@ -17,6 +17,7 @@ type PricingUseCase struct {
 	db             *pgxpool.Pool
 	priceRepo      *repository.PriceRepository
 	unitTypeRepo   *repository.UnitTypeRepository
+	webhookRepo    *repository.WebhookRepository
 	inventoryLogic *service.InventoryService
 }
 
@@ -24,18 +25,21 @@ func NewPricingUseCase(
 	db *pgxpool.Pool,
 	priceRepo *repository.PriceRepository,
 	unitTypeRepo *repository.UnitTypeRepository,
+	webhookRepo *repository.WebhookRepository,
 	inventoryLogic *service.InventoryService,
 ) *PricingUseCase {
 	return &PricingUseCase{
 		db:             db,
 		priceRepo:      priceRepo,
 		unitTypeRepo:   unitTypeRepo,
+		webhookRepo:    webhookRepo,
 		inventoryLogic: inventoryLogic,
 	}
 }
 
 func (uc *PricingUseCase) BulkCreateRule(ctx context.Context, req entity.SetPriceRequest) error {
-	if _, err := uc.unitTypeRepo.GetByID(ctx, req.UnitTypeID); err != nil {
+	unitType, err := uc.unitTypeRepo.GetByID(ctx, req.UnitTypeID)
+	if err != nil {
 		if errors.Is(err, entity.ErrRecordNotFound) {
 			return entity.ErrUnitTypeNotFound
 		}
@@ -89,6 +93,18 @@ func (uc *PricingUseCase) BulkCreateRule(ctx context.Context, req entity.SetPric
 	if err := uc.priceRepo.BatchDelete(ctx, tx, toDeleteIDs); err != nil { return err }
 	if err := uc.priceRepo.BatchCreate(ctx, tx, finalRules); err != nil { return err }
 
+	change := entity.PriceRuleChange{
+		Action:     "upserted",
+		RuleID:     targetRule.ID,
+		UnitTypeID: req.UnitTypeID,
+		Start:      start,
+		End:        end,
+		Price:      req.Price,
+	}
+	if err := publishWebhookEvent(ctx, uc.webhookRepo, tx, unitType.PropertyID, entity.EventPriceRuleChanged, change); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
@@ -96,7 +112,38 @@ func (uc *PricingUseCase) DeleteRule(ctx context.Context, id string) error {
 	if _, err := uuid.Parse(id); err != nil {
 		return entity.ErrRecordNotFound
 	}
-	return uc.priceRepo.Delete(ctx, id)
+
+	rule, err := uc.priceRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	unitType, err := uc.unitTypeRepo.GetByID(ctx, rule.UnitTypeID)
+	if err != nil {
+		return fmt.Errorf("failed to load unit type: %w", err)
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := uc.priceRepo.Delete(ctx, tx, id); err != nil {
+		return err
+	}
+	change := entity.PriceRuleChange{
+		Action:     "deleted",
+		RuleID:     rule.ID,
+		UnitTypeID: rule.UnitTypeID,
+		Start:      rule.Start,
+		End:        rule.End,
+		Price:      rule.Price,
+	}
+	if err := publishWebhookEvent(ctx, uc.webhookRepo, tx, unitType.PropertyID, entity.EventPriceRuleChanged, change); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (uc *PricingUseCase) GetRules(ctx context.Context, unitTypeID, propertyID string, pagination entity.PaginationRequest) ([]entity.PriceRule, int64, error) {
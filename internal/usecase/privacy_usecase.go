@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+)
+
+type PrivacyUseCase struct {
+	db          *pgxpool.Pool
+	guestRepo   *repository.GuestRepository
+	invoiceRepo *repository.InvoiceRepository
+	outboxRepo  *repository.EmailOutboxRepository
+	orgRepo     *repository.OrganizationRepository
+	privacyRepo *repository.PrivacyRepository
+	logger      *zap.Logger
+}
+
+func NewPrivacyUseCase(
+	db *pgxpool.Pool,
+	guestRepo *repository.GuestRepository,
+	invoiceRepo *repository.InvoiceRepository,
+	outboxRepo *repository.EmailOutboxRepository,
+	orgRepo *repository.OrganizationRepository,
+	privacyRepo *repository.PrivacyRepository,
+	logger *zap.Logger,
+) *PrivacyUseCase {
+	return &PrivacyUseCase{
+		db:          db,
+		guestRepo:   guestRepo,
+		invoiceRepo: invoiceRepo,
+		outboxRepo:  outboxRepo,
+		orgRepo:     orgRepo,
+		privacyRepo: privacyRepo,
+		logger:      logger,
+	}
+}
+
+// loadPrivacyGuest is loadOwnedGuest for privacy requests, which also cover deleted and merged guests.
+func loadPrivacyGuest(ctx context.Context, guestRepo *repository.GuestRepository, orgID, id string) (*entity.Guest, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	guest, err := guestRepo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if guest.OrganizationID != orgID {
+		return nil, entity.ErrRecordNotFound
+	}
+	return guest, nil
+}
+
+// Export collects the guest's profile, stays, folios, emails and notes, along with the
+// profiles merged into it, and logs the request.
+func (uc *PrivacyUseCase) Export(ctx context.Context, orgID, guestID, actorID string) (*entity.GuestDataExport, error) {
+	guest, err := loadPrivacyGuest(ctx, uc.guestRepo, orgID, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &entity.GuestDataExport{
+		ExportedAt: time.Now().UTC(),
+		Profile:    *guest,
+	}
+	if export.Reservations, err = uc.guestRepo.ListStays(ctx, guest.ID); err != nil {
+		return nil, err
+	}
+	if export.Folios, err = uc.invoiceRepo.ListByGuest(ctx, guest.ID); err != nil {
+		return nil, err
+	}
+	if export.Communications, err = uc.outboxRepo.ListByGuest(ctx, guest.ID); err != nil {
+		return nil, err
+	}
+	if export.Notes, err = uc.guestRepo.ListNotes(ctx, guest.ID); err != nil {
+		return nil, err
+	}
+
+	mergedIDs, err := uc.guestRepo.ListMergedInto(ctx, nil, guest.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.MergedProfiles = []entity.Guest{}
+	for _, id := range mergedIDs {
+		merged, err := uc.guestRepo.GetByIDWithDeleted(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		export.MergedProfiles = append(export.MergedProfiles, *merged)
+	}
+
+	if export.Reservations == nil {
+		export.Reservations = []entity.GuestStay{}
+	}
+	if export.Folios == nil {
+		export.Folios = []entity.Invoice{}
+	}
+	if export.Communications == nil {
+		export.Communications = []entity.GuestCommunication{}
+	}
+	if export.Notes == nil {
+		export.Notes = []entity.GuestNote{}
+	}
+
+	if _, err := uc.logRequest(ctx, orgID, guest.ID, entity.PrivacyRequestExport, entity.PrivacySourceRequest, actorID); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// Erase anonymises a guest on request. Guests with open reservations cannot be erased
+// until the stay is closed or cancelled.
+func (uc *PrivacyUseCase) Erase(ctx context.Context, orgID, guestID, actorID string) (*entity.PrivacyRequest, error) {
+	guest, err := loadPrivacyGuest(ctx, uc.guestRepo, orgID, guestID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.erase(ctx, orgID, guest.ID, entity.PrivacySourceRequest, actorID)
+}
+
+// erase anonymises the profile, the duplicates merged into it, and their communications,
+// notes and merge snapshots in one transaction. The guest row is locked first, so no
+// reservation can be booked for it between the active-reservation check and the erasure.
+// Issued invoices are left untouched: they are financial records the organization is
+// legally required to keep.
+func (uc *PrivacyUseCase) erase(ctx context.Context, orgID, guestID, source, actorID string) (*entity.PrivacyRequest, error) {
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := uc.guestRepo.GetByIDWithDeletedLocked(ctx, tx, guestID); err != nil {
+		return nil, err
+	}
+	mergedIDs, err := uc.guestRepo.ListMergedInto(ctx, tx, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := append([]string{guestID}, mergedIDs...)
+	for _, id := range ids {
+		count, err := uc.guestRepo.CountActiveReservations(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("cannot erase guest: %d active reservations: %w", count, entity.ErrConflict)
+		}
+	}
+
+	for _, id := range ids {
+		placeholder := fmt.Sprintf("erased-%s@invalid", id)
+		if err := uc.guestRepo.Anonymize(ctx, tx, id, placeholder); err != nil {
+			// A duplicate erased earlier on its own needs nothing more.
+			if id != guestID && errors.Is(err, entity.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if err := uc.outboxRepo.RedactGuest(ctx, tx, id, placeholder); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := newPrivacyRequest(orgID, guestID, entity.PrivacyRequestErasure, source, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.privacyRepo.LogRequest(ctx, tx, req); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ApplyRetention anonymises every guest whose organization's retention period has elapsed.
+func (uc *PrivacyUseCase) ApplyRetention(ctx context.Context) (int, error) {
+	policies, err := uc.orgRepo.ListRetentionPolicies(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	erased := 0
+	for orgID, days := range policies {
+		ids, err := uc.guestRepo.ListExpired(ctx, orgID, days)
+		if err != nil {
+			return erased, err
+		}
+		for _, id := range ids {
+			if _, err := uc.erase(ctx, orgID, id, entity.PrivacySourceRetention, ""); err != nil {
+				uc.logger.Error("failed to apply guest retention",
+					zap.String("organization_id", orgID),
+					zap.String("guest_id", id),
+					zap.Error(err),
+				)
+				continue
+			}
+			erased++
+		}
+	}
+	return erased, nil
+}
+
+func (uc *PrivacyUseCase) ListRequests(ctx context.Context, orgID string, pagination entity.PaginationRequest) ([]entity.PrivacyRequest, int64, error) {
+	return uc.privacyRepo.ListByOrganization(ctx, orgID, pagination)
+}
+
+func (uc *PrivacyUseCase) GetRetention(ctx context.Context, orgID string) (*entity.RetentionSettings, error) {
+	days, err := uc.orgRepo.GetRetention(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.RetentionSettings{GuestRetentionDays: days}, nil
+}
+
+func (uc *PrivacyUseCase) UpdateRetention(ctx context.Context, orgID string, req entity.RetentionSettings) error {
+	if req.GuestRetentionDays != nil && *req.GuestRetentionDays <= 0 {
+		return fmt.Errorf("%w: guest_retention_days must be positive", entity.ErrInvalidInput)
+	}
+	return uc.orgRepo.SetRetention(ctx, orgID, req.GuestRetentionDays)
+}
+
+func (uc *PrivacyUseCase) logRequest(ctx context.Context, orgID, guestID, requestType, source, actorID string) (*entity.PrivacyRequest, error) {
+	req, err := newPrivacyRequest(orgID, guestID, requestType, source, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.privacyRepo.LogRequest(ctx, nil, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func newPrivacyRequest(orgID, guestID, requestType, source, actorID string) (*entity.PrivacyRequest, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+	req := &entity.PrivacyRequest{
+		ID:             id.String(),
+		OrganizationID: orgID,
+		GuestID:        guestID,
+		RequestType:    requestType,
+		Source:         source,
+	}
+	if actorID != "" {
+		req.RequestedBy = &actorID
+	}
+	return req, nil
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+)
+
+// PropertyServiceUseCase manages which catalog services a property sells as add-ons, and at what price.
+type PropertyServiceUseCase struct {
+	repo         *repository.PropertyServiceRepository
+	catalogRepo  *repository.HotelServiceRepository
+	propertyRepo *repository.PropertyRepository
+}
+
+func NewPropertyServiceUseCase(
+	repo *repository.PropertyServiceRepository,
+	catalogRepo *repository.HotelServiceRepository,
+	propertyRepo *repository.PropertyRepository,
+) *PropertyServiceUseCase {
+	return &PropertyServiceUseCase{
+		repo:         repo,
+		catalogRepo:  catalogRepo,
+		propertyRepo: propertyRepo,
+	}
+}
+
+func (uc *PropertyServiceUseCase) Create(ctx context.Context, req entity.CreatePropertyServiceRequest) (string, error) {
+	if _, err := uuid.Parse(req.PropertyID); err != nil {
+		return "", fmt.Errorf("%w: property_id is required", entity.ErrInvalidInput)
+	}
+	if _, err := uuid.Parse(req.ServiceID); err != nil {
+		return "", fmt.Errorf("%w: service_id is required", entity.ErrInvalidInput)
+	}
+	if err := validateServiceOffer(req.Price, req.PricingUnit, req.DailyLimit); err != nil {
+		return "", err
+	}
+
+	property, err := uc.propertyRepo.GetByID(ctx, req.PropertyID)
+	if err != nil {
+		return "", fmt.Errorf("%w: property not found", entity.ErrInvalidInput)
+	}
+	if _, err := uc.catalogRepo.GetByID(ctx, req.ServiceID, property.OrganizationID); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return "", fmt.Errorf("%w: service not found in catalog", entity.ErrInvalidInput)
+		}
+		return "", err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", fmt.Errorf("uuid gen: %w", err)
+	}
+
+	offer := entity.PropertyService{
+		BaseEntity:  entity.BaseEntity{ID: id.String()},
+		PropertyID:  req.PropertyID,
+		ServiceID:   req.ServiceID,
+		Price:       req.Price,
+		PricingUnit: req.PricingUnit,
+		DailyLimit:  req.DailyLimit,
+		Active:      true,
+	}
+	if err := uc.repo.Create(ctx, offer); err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func (uc *PropertyServiceUseCase) ListByProperty(ctx context.Context, propertyID string, activeOnly bool) ([]entity.PropertyService, error) {
+	if _, err := uuid.Parse(propertyID); err != nil {
+		return []entity.PropertyService{}, nil
+	}
+	return uc.repo.ListByProperty(ctx, propertyID, activeOnly)
+}
+
+func (uc *PropertyServiceUseCase) GetByID(ctx context.Context, id string) (*entity.PropertyService, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	return uc.repo.GetByID(ctx, id)
+}
+
+// Update changes the offer for future bookings; add-ons already booked keep their price.
+func (uc *PropertyServiceUseCase) Update(ctx context.Context, id string, req entity.UpdatePropertyServiceRequest) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return entity.ErrRecordNotFound
+	}
+	if req.Price != nil && *req.Price < 0 {
+		return entity.ErrPriceNegative
+	}
+	if req.PricingUnit != "" && !req.PricingUnit.Valid() {
+		return fmt.Errorf("%w: unknown pricing_unit", entity.ErrInvalidInput)
+	}
+	if req.DailyLimit != nil && *req.DailyLimit < 0 {
+		return fmt.Errorf("%w: daily_limit cannot be negative", entity.ErrInvalidInput)
+	}
+	return uc.repo.Update(ctx, id, req)
+}
+
+func (uc *PropertyServiceUseCase) Delete(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return entity.ErrRecordNotFound
+	}
+	return uc.repo.Delete(ctx, id)
+}
+
+func validateServiceOffer(price float64, unit entity.PricingUnit, dailyLimit *int) error {
+	if price < 0 {
+		return entity.ErrPriceNegative
+	}
+	if !unit.Valid() {
+		return fmt.Errorf("%w: pricing_unit must be per_stay, per_night, per_person or per_person_night", entity.ErrInvalidInput)
+	}
+	if dailyLimit != nil && *dailyLimit <= 0 {
+		return fmt.Errorf("%w: daily_limit must be positive", entity.ErrInvalidInput)
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/ecelayes/pms-backend/internal/entity"
 	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/tenant"
 )
 
 type PropertyUseCase struct {
@@ -19,6 +20,15 @@ func NewPropertyUseCase(repo *repository.PropertyRepository) *PropertyUseCase {
 }
 
 func (uc *PropertyUseCase) Create(ctx context.Context, req entity.CreatePropertyRequest) (string, error) {
+	if orgID, scoped := tenant.OrganizationID(ctx); scoped {
+		if req.OrganizationID != "" && req.OrganizationID != orgID {
+			return "", fmt.Errorf("%w: organization not found", entity.ErrInvalidInput)
+		}
+		req.OrganizationID = orgID
+	}
+	if _, restricted := tenant.PropertyIDs(ctx); restricted {
+		return "", fmt.Errorf("%w: members limited to some properties cannot create properties", entity.ErrInsufficientPermissions)
+	}
 	if req.OrganizationID == "" {
 		return "", entity.ErrInvalidInput
 	}
@@ -28,6 +38,12 @@ func (uc *PropertyUseCase) Create(ctx context.Context, req entity.CreateProperty
 	if len(req.Code) < 3 || len(req.Code) > 5 {
 		return "", entity.ErrInvalidInput
 	}
+	if req.TaxRate < 0 || req.TaxRate >= 100 {
+		return "", entity.ErrInvalidInput
+	}
+	if req.DefaultLanguage != "" && !entity.IsSupportedLanguage(req.DefaultLanguage) {
+		return "", entity.ErrInvalidInput
+	}
 	
 	propertyID, err := uuid.NewV7()
 	if err != nil {
@@ -42,15 +58,25 @@ func (uc *PropertyUseCase) Create(ctx context.Context, req entity.CreateProperty
 		Name:           req.Name,
 		Code:           strings.ToUpper(req.Code),
 		Type:           req.Type,
+		TaxRate:        req.TaxRate,
+		DefaultLanguage: req.DefaultLanguage,
 	}
 	if property.Type == "" {
 		property.Type = "HOTEL"
 	}
+	if property.DefaultLanguage == "" {
+		property.DefaultLanguage = entity.DefaultLanguage
+	}
 
 	return uc.repo.Create(ctx, property)
 }
 
+// ListByOrganization lists an organization's properties. Callers scoped to an organization
+// always get their own, whatever organization they ask for.
 func (uc *PropertyUseCase) ListByOrganization(ctx context.Context, orgID string, pagination entity.PaginationRequest) ([]entity.Property, int64, error) {
+	if scopedOrg, scoped := tenant.OrganizationID(ctx); scoped {
+		orgID = scopedOrg
+	}
 	if orgID == "" {
 		return nil, 0, entity.ErrInvalidInput
 	}
@@ -68,7 +94,13 @@ func (uc *PropertyUseCase) Update(ctx context.Context, id string, req entity.Upd
 	if _, err := uuid.Parse(id); err != nil {
 		return entity.ErrRecordNotFound
 	}
-	if req.Name == "" && req.Code == "" && req.Type == "" {
+	if req.Name == "" && req.Code == "" && req.Type == "" && req.TaxRate == nil && req.DefaultLanguage == "" {
+		return entity.ErrInvalidInput
+	}
+	if req.DefaultLanguage != "" && !entity.IsSupportedLanguage(req.DefaultLanguage) {
+		return entity.ErrInvalidInput
+	}
+	if req.TaxRate != nil && (*req.TaxRate < 0 || *req.TaxRate >= 100) {
 		return entity.ErrInvalidInput
 	}
 	if req.Code != "" {
@@ -2,23 +2,40 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ecelayes/pms-backend/internal/entity"
 	"github.com/ecelayes/pms-backend/internal/repository"
 )
 
 type RatePlanUseCase struct {
-	repo    *repository.RatePlanRepository
-	resRepo *repository.ReservationRepository
+	db           *pgxpool.Pool
+	repo         *repository.RatePlanRepository
+	resRepo      *repository.ReservationRepository
+	webhookRepo  *repository.WebhookRepository
+	propertyRepo *repository.PropertyRepository
+	unitTypeRepo *repository.UnitTypeRepository
 }
 
-func NewRatePlanUseCase(repo *repository.RatePlanRepository, resRepo *repository.ReservationRepository) *RatePlanUseCase {
+func NewRatePlanUseCase(
+	db *pgxpool.Pool,
+	repo *repository.RatePlanRepository,
+	resRepo *repository.ReservationRepository,
+	webhookRepo *repository.WebhookRepository,
+	propertyRepo *repository.PropertyRepository,
+	unitTypeRepo *repository.UnitTypeRepository,
+) *RatePlanUseCase {
 	return &RatePlanUseCase{
-		repo:    repo,
-		resRepo: resRepo,
+		db:           db,
+		repo:         repo,
+		resRepo:      resRepo,
+		webhookRepo:  webhookRepo,
+		propertyRepo: propertyRepo,
+		unitTypeRepo: unitTypeRepo,
 	}
 }
 
@@ -30,6 +47,9 @@ func (uc *RatePlanUseCase) Create(ctx context.Context, req entity.CreateRatePlan
 	if !req.CancellationPolicy.IsRefundable && len(req.CancellationPolicy.Rules) > 0 {
 		return "", entity.ErrInvalidInput
 	}
+	if err := uc.checkOwnership(ctx, req.PropertyID, req.UnitTypeID); err != nil {
+		return "", err
+	}
 
 	id, err := uuid.NewV7()
 	if err != nil {
@@ -87,7 +107,25 @@ func (uc *RatePlanUseCase) Update(ctx context.Context, id string, req entity.Upd
 		}
 	}
 
-	return uc.repo.Update(ctx, id, req)
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := uc.repo.Update(ctx, tx, id, req); err != nil {
+		return err
+	}
+
+	plan, err := uc.repo.GetByIDLocked(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := publishWebhookEvent(ctx, uc.webhookRepo, tx, plan.PropertyID, entity.EventRatePlanUpdated, plan); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (uc *RatePlanUseCase) Delete(ctx context.Context, id string) error {
@@ -106,3 +144,34 @@ func (uc *RatePlanUseCase) Delete(ctx context.Context, id string) error {
 
 	return uc.repo.Delete(ctx, id)
 }
+
+// checkOwnership makes sure the plan's property, and its unit type when given, exist and are
+// visible to the caller, and that the unit type belongs to the property.
+func (uc *RatePlanUseCase) checkOwnership(ctx context.Context, propertyID string, unitTypeID *string) error {
+	if _, err := uuid.Parse(propertyID); err != nil {
+		return fmt.Errorf("%w: property not found", entity.ErrInvalidInput)
+	}
+	if _, err := uc.propertyRepo.GetByID(ctx, propertyID); err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return fmt.Errorf("%w: property not found", entity.ErrInvalidInput)
+		}
+		return err
+	}
+	if unitTypeID == nil {
+		return nil
+	}
+	if _, err := uuid.Parse(*unitTypeID); err != nil {
+		return fmt.Errorf("%w: unit type not found", entity.ErrInvalidInput)
+	}
+	ut, err := uc.unitTypeRepo.GetByID(ctx, *unitTypeID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return fmt.Errorf("%w: unit type not found", entity.ErrInvalidInput)
+		}
+		return err
+	}
+	if ut.PropertyID != propertyID {
+		return fmt.Errorf("%w: unit type belongs to another property", entity.ErrInvalidInput)
+	}
+	return nil
+}
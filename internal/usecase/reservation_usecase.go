@@ -2,24 +2,50 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
+	"go.uber.org/zap"
+
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ecelayes/pms-backend/internal/entity"
 	"github.com/ecelayes/pms-backend/internal/repository"
 	"github.com/ecelayes/pms-backend/internal/service"
 	"github.com/ecelayes/pms-backend/internal/utils"
+	"github.com/ecelayes/pms-backend/pkg/auth"
 )
 
+// guestAccessValidity is how long guest self-service links stay valid, counted from issue
+// and from check-out, whichever ends later.
+const guestAccessValidity = 30 * 24 * time.Hour
+
 type ReservationUseCase struct {
 	db             *pgxpool.Pool
 	unitTypeRepo   *repository.UnitTypeRepository
 	resRepo        *repository.ReservationRepository
 	guestRepo      *repository.GuestRepository
 	ratePlanRepo   *repository.RatePlanRepository
+	propertyRepo   *repository.PropertyRepository
+	serviceRepo    *repository.PropertyServiceRepository
+	addOnRepo      *repository.ReservationAddOnRepository
 	pricingService *service.PricingService
+	emailService   *service.EmailService
+	outboxRepo     *repository.EmailOutboxRepository
+	webhookRepo    *repository.WebhookRepository
+	logger         *zap.Logger
+}
+
+// stayQuote is the priced result of a stay request, shared by booking and modification.
+type stayQuote struct {
+	DailyRates []entity.DailyRate
+	BaseTotal  float64
+	Total      float64
+	RatePlan   *entity.RatePlan
 }
 
 func NewReservationUseCase(
@@ -28,7 +54,14 @@ func NewReservationUseCase(
 	resRepo *repository.ReservationRepository,
 	guestRepo *repository.GuestRepository,
 	ratePlanRepo *repository.RatePlanRepository,
+	propertyRepo *repository.PropertyRepository,
+	serviceRepo *repository.PropertyServiceRepository,
+	addOnRepo *repository.ReservationAddOnRepository,
 	pricingService *service.PricingService,
+	emailService *service.EmailService,
+	outboxRepo *repository.EmailOutboxRepository,
+	webhookRepo *repository.WebhookRepository,
+	logger *zap.Logger,
 ) *ReservationUseCase {
 	return &ReservationUseCase{
 		db:             db,
@@ -36,163 +69,139 @@ func NewReservationUseCase(
 		resRepo:        resRepo,
 		guestRepo:      guestRepo,
 		ratePlanRepo:   ratePlanRepo,
+		propertyRepo:   propertyRepo,
+		serviceRepo:    serviceRepo,
+		addOnRepo:      addOnRepo,
 		pricingService: pricingService,
+		emailService:   emailService,
+		outboxRepo:     outboxRepo,
+		webhookRepo:    webhookRepo,
+		logger:         logger,
 	}
 }
 
-func (uc *ReservationUseCase) Create(ctx context.Context, req entity.CreateReservationRequest) (string, error) {
+func (uc *ReservationUseCase) Create(ctx context.Context, req entity.CreateReservationRequest) (*entity.GuestAccess, error) {
 	layout := "2006-01-02"
 
 	start, err := time.Parse(layout, req.Start)
 	if err != nil {
-		return "", entity.ErrInvalidDateFormat
+		return nil, entity.ErrInvalidDateFormat
 	}
 	end, err := time.Parse(layout, req.End)
 	if err != nil {
-		return "", entity.ErrInvalidDateFormat
+		return nil, entity.ErrInvalidDateFormat
 	}
 
 	if !end.After(start) {
-		return "", entity.ErrInvalidDateRange
+		return nil, entity.ErrInvalidDateRange
 	}
 
-	nights := int(end.Sub(start).Hours() / 24)
-
 	if req.Adults <= 0 {
-		return "", fmt.Errorf("%w: at least 1 adult is required", entity.ErrInvalidInput)
+		return nil, fmt.Errorf("%w: at least 1 adult is required", entity.ErrInvalidInput)
 	}
 	if req.Children < 0 {
-		return "", fmt.Errorf("%w: children cannot be negative", entity.ErrInvalidInput)
+		return nil, fmt.Errorf("%w: children cannot be negative", entity.ErrInvalidInput)
 	}
 
 	unitType, err := uc.unitTypeRepo.GetByID(ctx, req.UnitTypeID)
 	if err != nil {
-		return "", entity.ErrUnitTypeNotFound
+		return nil, entity.ErrUnitTypeNotFound
 	}
 
-	if req.Adults > unitType.MaxAdults {
-		return "", fmt.Errorf("%w: exceeds max adults for this unit type", entity.ErrInvalidInput)
-	}
-	if req.Children > unitType.MaxChildren {
-		return "", fmt.Errorf("%w: exceeds max children for this unit type", entity.ErrInvalidInput)
-	}
-	if (req.Adults + req.Children) > unitType.MaxOccupancy {
-		return "", fmt.Errorf("%w: exceeds max total occupancy for this unit type", entity.ErrInvalidInput)
+	if err := validateOccupancy(unitType, req.Adults, req.Children); err != nil {
+		return nil, err
 	}
 
 	propertyCode, unitTypeCode, err := uc.unitTypeRepo.GetCodesForGeneration(ctx, req.UnitTypeID)
 	if err != nil {
-		return "", entity.ErrUnitTypeNotFound
+		return nil, entity.ErrUnitTypeNotFound
 	}
 	resCode := fmt.Sprintf("%s-%s-%s", propertyCode, unitTypeCode, utils.GenerateRandomCode(4))
 
-	dailyRates, baseTotal, err := uc.pricingService.CalculateBaseRates(
-		ctx,
-		req.UnitTypeID,
-		unitType.BasePrice,
-		start,
-		end,
-	)
+	quote, err := uc.quote(ctx, unitType, req.RatePlanID, start, end, req.Adults, req.Children)
 	if err != nil {
-		return "", entity.ErrNoAvailability 
-	}
-	
-	if len(dailyRates) != nights {
-		return "", entity.ErrNoAvailability
-	}
-
-	finalPrice := baseTotal
-
-	if req.RatePlanID != nil && *req.RatePlanID != "" {
-		rp, err := uc.ratePlanRepo.GetByID(ctx, *req.RatePlanID)
-		if err != nil {
-			return "", fmt.Errorf("invalid rate plan: %w", err)
-		}
-		
-		if rp.UnitTypeID != nil && *rp.UnitTypeID != req.UnitTypeID {
-			return "", fmt.Errorf("%w: rate plan not applicable to this unit type", entity.ErrInvalidInput)
-		}
-		if !rp.Active {
-			return "", fmt.Errorf("%w: rate plan is not active", entity.ErrInvalidInput)
-		}
-
-		totalPax := req.Adults + req.Children
-		finalPrice = uc.pricingService.ApplyRatePlan(baseTotal, *rp, totalPax, nights)
+		return nil, err
 	}
 
 	tx, err := uc.db.Begin(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
 	if req.GuestEmail == "" {
-		return "", fmt.Errorf("%w: guest email is required", entity.ErrInvalidInput)
+		return nil, fmt.Errorf("%w: guest email is required", entity.ErrInvalidInput)
+	}
+	if req.GuestLanguage != "" && !entity.IsSupportedLanguage(req.GuestLanguage) {
+		return nil, fmt.Errorf("%w: unsupported guest language", entity.ErrInvalidInput)
 	}
 
-	guest, err := uc.guestRepo.GetByEmail(ctx, req.GuestEmail)
+	property, err := uc.propertyRepo.GetByID(ctx, unitType.PropertyID)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to load property: %w", err)
+	}
+
+	guest, err := uc.guestRepo.GetByEmail(ctx, property.OrganizationID, req.GuestEmail)
+	if err != nil {
+		return nil, err
 	}
 
 	var guestID string
+	guestName := req.GuestFirstName
+	guestLanguage := req.GuestLanguage
 
 	if guest != nil {
 		guestID = guest.ID
-		needsUpdate := false
-		
-		if req.GuestFirstName != "" && req.GuestFirstName != guest.FirstName { needsUpdate = true }
-		if req.GuestLastName != "" && req.GuestLastName != guest.LastName { needsUpdate = true }
-		if req.GuestPhone != "" && req.GuestPhone != guest.Phone { needsUpdate = true }
-
-		if needsUpdate {
-			updatedGuest := entity.Guest{
-				Email:     guest.Email,
-				FirstName: req.GuestFirstName,
-				LastName:  req.GuestLastName,
-				Phone:     req.GuestPhone,
-			}
-			if err := uc.guestRepo.Update(ctx, tx, updatedGuest); err != nil {
-				return "", err
+		if guestName == "" {
+			guestName = guest.FirstName
+		}
+		if guestLanguage == "" {
+			guestLanguage = guest.Language
+		}
+		if changes := bookingProfileChanges(guest, req); changes != (entity.UpdateGuestRequest{}) {
+			if err := uc.guestRepo.Update(ctx, tx, guest.ID, changes); err != nil {
+				return nil, err
 			}
 		}
 	} else {
 		if req.GuestFirstName == "" || req.GuestLastName == "" {
-			return "", fmt.Errorf("%w: guest name is required for new registration", entity.ErrInvalidInput)
+			return nil, fmt.Errorf("%w: guest name is required for new registration", entity.ErrInvalidInput)
 		}
 
 		newID, err := uuid.NewV7()
-		if err != nil { return "", fmt.Errorf("failed to generate uuid v7: %w", err) }
+		if err != nil { return nil, fmt.Errorf("failed to generate uuid v7: %w", err) }
 		
 		newGuest := entity.Guest{
 			BaseEntity: entity.BaseEntity{ ID: newID.String() },
+			OrganizationID: property.OrganizationID,
 			Email:     req.GuestEmail,
 			FirstName: req.GuestFirstName,
 			LastName:  req.GuestLastName,
 			Phone:     req.GuestPhone,
+			Language:  req.GuestLanguage,
 		}
 		
 		guestID, err = uc.guestRepo.Create(ctx, tx, newGuest)
-		if err != nil { return "", err }
+		if err != nil { return nil, err }
 	}
 	
 	lockedUnitType, err := uc.unitTypeRepo.GetByIDLocked(ctx, tx, req.UnitTypeID)
 	if err != nil {
-		return "", fmt.Errorf("failed to lock unit type inventory: %w", err)
+		return nil, fmt.Errorf("failed to lock unit type inventory: %w", err)
 	}
 
 	reservedCount, err := uc.unitTypeRepo.CountReservations(ctx, tx, req.UnitTypeID, start, end)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if (lockedUnitType.TotalQuantity - reservedCount) < 1 {
-		return "", entity.ErrNoAvailability
+		return nil, entity.ErrNoAvailability
 	}
 
 	newID, err := uuid.NewV7()
 	if err != nil {
-		return "", fmt.Errorf("failed to generate uuid v7: %w", err)
+		return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
 	}
 
 	res := entity.Reservation{
@@ -205,22 +214,374 @@ func (uc *ReservationUseCase) Create(ctx context.Context, req entity.CreateReser
 		Start:           start,
 		End:             end,
 		RatePlanID:      req.RatePlanID,
-		TotalPrice:      finalPrice,
+		TotalPrice:      quote.Total,
 		Status:          "confirmed",
 		
 		Adults:   req.Adults,
 		Children: req.Children,
 	}
 
+	addOns, err := uc.prepareAddOns(ctx, tx, &res, unitType.PropertyID, req.AddOns)
+	if err != nil {
+		return nil, err
+	}
+	res.TotalPrice = roundMoney(quote.Total + addOnsTotal(addOns))
+
 	if err := uc.resRepo.Create(ctx, tx, res); err != nil {
-		return "", err
+		return nil, err
+	}
+	for _, addOn := range addOns {
+		if err := uc.addOnRepo.Create(ctx, tx, addOn); err != nil {
+			return nil, err
+		}
+	}
+	res.AddOns = addOns
+
+	recipient := &entity.Guest{
+		BaseEntity: entity.BaseEntity{ID: guestID},
+		Email:      req.GuestEmail,
+		FirstName:  guestName,
+		Language:   guestLanguage,
+	}
+	if err := uc.queueGuestEmail(ctx, tx, res, recipient, quote, 0, uc.emailService.ReservationConfirmation); err != nil {
+		return nil, err
+	}
+
+	if err := publishWebhookEvent(ctx, uc.webhookRepo, tx, unitType.PropertyID, entity.EventReservationCreated, res); err != nil {
+		return nil, err
+	}
+
+	token, err := uc.issueGuestToken(ctx, tx, res)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return resCode, nil
+	return &entity.GuestAccess{ReservationID: res.ID, ReservationCode: resCode, AccessToken: token}, nil
+}
+
+// Modify re-prices and re-checks availability for a changed stay, then notifies the guest.
+func (uc *ReservationUseCase) Modify(ctx context.Context, id string, req entity.UpdateReservationRequest) (*entity.Reservation, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := uc.resRepo.GetByIDLocked(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if res.Status == "cancelled" {
+		return nil, entity.ErrReservationCancelled
+	}
+	if res.Status != "confirmed" {
+		return nil, entity.ErrInvalidReservationStatus
+	}
+
+	currentUnitTypeID := res.UnitTypeID
+	if err := applyModification(res, req); err != nil {
+		return nil, err
+	}
+
+	unitType, quote, err := uc.priceStay(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkSameProperty(ctx, currentUnitTypeID, unitType); err != nil {
+		return nil, err
+	}
+
+	lockedUnitType, err := uc.unitTypeRepo.GetByIDLocked(ctx, tx, res.UnitTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock unit type inventory: %w", err)
+	}
+
+	reservedCount, err := uc.unitTypeRepo.CountReservationsExcluding(ctx, tx, res.UnitTypeID, res.Start, res.End, res.ID)
+	if err != nil {
+		return nil, err
+	}
+	if (lockedUnitType.TotalQuantity - reservedCount) < 1 {
+		return nil, entity.ErrNoAvailability
+	}
+
+	addOns, err := uc.addOnRepo.ListByReservation(ctx, tx, res.ID)
+	if err != nil {
+		return nil, err
+	}
+	res.AddOns = repriceAddOns(addOns, res)
+	if err := uc.checkBookedAddOns(ctx, tx, res); err != nil {
+		return nil, err
+	}
+	for _, addOn := range res.AddOns {
+		if err := uc.addOnRepo.UpdateTotal(ctx, tx, addOn.ID, addOn.Total); err != nil {
+			return nil, err
+		}
+	}
+	res.TotalPrice = roundMoney(quote.Total + addOnsTotal(res.AddOns))
+
+	if err := uc.resRepo.Update(ctx, tx, *res); err != nil {
+		return nil, err
+	}
+
+	if err := uc.queueGuestEmail(ctx, tx, *res, nil, quote, 0, uc.emailService.ReservationModification); err != nil {
+		return nil, err
+	}
+
+	if err := publishWebhookEvent(ctx, uc.webhookRepo, tx, unitType.PropertyID, entity.EventReservationModified, res); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// bookingProfileChanges decides which booking fields may touch an existing guest profile.
+// Booking data overwrites the profile only when explicitly requested; otherwise it just
+// fills what the profile is missing.
+func bookingProfileChanges(guest *entity.Guest, req entity.CreateReservationRequest) entity.UpdateGuestRequest {
+	if req.UpdateGuestProfile {
+		return entity.UpdateGuestRequest{
+			FirstName: req.GuestFirstName,
+			LastName:  req.GuestLastName,
+			Phone:     req.GuestPhone,
+			Language:  req.GuestLanguage,
+		}
+	}
+
+	var changes entity.UpdateGuestRequest
+	if guest.Phone == "" {
+		changes.Phone = req.GuestPhone
+	}
+	if guest.Language == "" {
+		changes.Language = req.GuestLanguage
+	}
+	return changes
+}
+
+// applyModification copies the requested changes onto res and validates the resulting stay.
+func applyModification(res *entity.Reservation, req entity.UpdateReservationRequest) error {
+	var err error
+	layout := "2006-01-02"
+	if req.Start != "" {
+		if res.Start, err = time.Parse(layout, req.Start); err != nil {
+			return entity.ErrInvalidDateFormat
+		}
+	}
+	if req.End != "" {
+		if res.End, err = time.Parse(layout, req.End); err != nil {
+			return entity.ErrInvalidDateFormat
+		}
+	}
+	if !res.End.After(res.Start) {
+		return entity.ErrInvalidDateRange
+	}
+
+	if req.UnitTypeID != "" {
+		res.UnitTypeID = req.UnitTypeID
+	}
+	if req.Adults != nil {
+		res.Adults = *req.Adults
+	}
+	if req.Children != nil {
+		res.Children = *req.Children
+	}
+	if req.RatePlanID != nil {
+		res.RatePlanID = req.RatePlanID
+		if *req.RatePlanID == "" {
+			res.RatePlanID = nil
+		}
+	}
+
+	if res.Adults <= 0 {
+		return fmt.Errorf("%w: at least 1 adult is required", entity.ErrInvalidInput)
+	}
+	if res.Children < 0 {
+		return fmt.Errorf("%w: children cannot be negative", entity.ErrInvalidInput)
+	}
+	return nil
+}
+
+// priceStay checks occupancy and prices the stay described by res.
+func (uc *ReservationUseCase) priceStay(ctx context.Context, res *entity.Reservation) (*entity.UnitType, *stayQuote, error) {
+	unitType, err := uc.unitTypeRepo.GetByID(ctx, res.UnitTypeID)
+	if err != nil {
+		return nil, nil, entity.ErrUnitTypeNotFound
+	}
+	if err := validateOccupancy(unitType, res.Adults, res.Children); err != nil {
+		return nil, nil, err
+	}
+
+	quote, err := uc.quote(ctx, unitType, res.RatePlanID, res.Start, res.End, res.Adults, res.Children)
+	if err != nil {
+		return nil, nil, err
+	}
+	return unitType, quote, nil
+}
+
+// checkSameProperty refuses to move a reservation to a unit type of another property.
+func (uc *ReservationUseCase) checkSameProperty(ctx context.Context, currentUnitTypeID string, target *entity.UnitType) error {
+	if target.ID == currentUnitTypeID {
+		return nil
+	}
+	current, err := uc.unitTypeRepo.GetByID(ctx, currentUnitTypeID)
+	if err != nil {
+		return fmt.Errorf("failed to load unit type: %w", err)
+	}
+	if current.PropertyID != target.PropertyID {
+		return fmt.Errorf("%w: unit type belongs to another property", entity.ErrInvalidInput)
+	}
+	return nil
+}
+
+// PreviewModification prices a change to a confirmed reservation without applying it.
+func (uc *ReservationUseCase) PreviewModification(ctx context.Context, id string, req entity.UpdateReservationRequest) (*entity.ModificationQuote, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	res, err := uc.resRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if res.Status == "cancelled" {
+		return nil, entity.ErrReservationCancelled
+	}
+	if res.Status != "confirmed" {
+		return nil, entity.ErrInvalidReservationStatus
+	}
+
+	currentTotal := res.TotalPrice
+	currentUnitTypeID := res.UnitTypeID
+	if err := applyModification(res, req); err != nil {
+		return nil, err
+	}
+	unitType, quote, err := uc.priceStay(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkSameProperty(ctx, currentUnitTypeID, unitType); err != nil {
+		return nil, err
+	}
+
+	addOns, err := uc.addOnRepo.ListByReservation(ctx, nil, res.ID)
+	if err != nil {
+		return nil, err
+	}
+	res.AddOns = repriceAddOns(addOns, res)
+	res.TotalPrice = roundMoney(quote.Total + addOnsTotal(res.AddOns))
+
+	reserved, err := uc.unitTypeRepo.CountReservationsExcluding(ctx, nil, res.UnitTypeID, res.Start, res.End, res.ID)
+	if err != nil {
+		return nil, err
+	}
+	available := unitType.TotalQuantity-reserved >= 1
+	if err := uc.checkBookedAddOns(ctx, nil, res); errors.Is(err, entity.ErrAddOnUnavailable) {
+		available = false
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &entity.ModificationQuote{
+		Reservation:  *res,
+		RatePlan:     quote.RatePlan,
+		NightlyRates: quote.DailyRates,
+		CurrentTotal: currentTotal,
+		NewTotal:     res.TotalPrice,
+		Difference:   roundMoney(res.TotalPrice - currentTotal),
+		Available:    available,
+	}, nil
+}
+
+// SetArrivalTime records when the guest expects to arrive ("HH:MM"); an empty value clears it.
+func (uc *ReservationUseCase) SetArrivalTime(ctx context.Context, id, arrival string) (*entity.Reservation, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	if arrival != "" {
+		if _, err := time.Parse("15:04", arrival); err != nil {
+			return nil, fmt.Errorf("%w: arrival_time must use HH:MM", entity.ErrInvalidInput)
+		}
+	}
+
+	res, err := uc.resRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != "confirmed" {
+		return nil, entity.ErrInvalidReservationStatus
+	}
+	if err := uc.resRepo.SetArrivalTime(ctx, id, arrival); err != nil {
+		return nil, err
+	}
+	return uc.resRepo.GetByID(ctx, id)
+}
+
+func validateOccupancy(unitType *entity.UnitType, adults, children int) error {
+	if adults > unitType.MaxAdults {
+		return fmt.Errorf("%w: exceeds max adults for this unit type", entity.ErrInvalidInput)
+	}
+	if children > unitType.MaxChildren {
+		return fmt.Errorf("%w: exceeds max children for this unit type", entity.ErrInvalidInput)
+	}
+	if (adults + children) > unitType.MaxOccupancy {
+		return fmt.Errorf("%w: exceeds max total occupancy for this unit type", entity.ErrInvalidInput)
+	}
+	return nil
+}
+
+func (uc *ReservationUseCase) quote(ctx context.Context, unitType *entity.UnitType, ratePlanID *string, start, end time.Time, adults, children int) (*stayQuote, error) {
+	nights := int(end.Sub(start).Hours() / 24)
+
+	dailyRates, baseTotal, err := uc.pricingService.CalculateBaseRates(
+		ctx,
+		unitType.ID,
+		unitType.BasePrice,
+		start,
+		end,
+	)
+	if err != nil {
+		return nil, entity.ErrNoAvailability 
+	}
+	
+	if len(dailyRates) != nights {
+		return nil, entity.ErrNoAvailability
+	}
+
+	quote := &stayQuote{
+		DailyRates: dailyRates,
+		BaseTotal:  baseTotal,
+		Total:      baseTotal,
+	}
+
+	if ratePlanID != nil && *ratePlanID != "" {
+		rp, err := uc.ratePlanRepo.GetByID(ctx, *ratePlanID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate plan: %w", err)
+		}
+		
+		if rp.UnitTypeID != nil && *rp.UnitTypeID != unitType.ID {
+			return nil, fmt.Errorf("%w: rate plan not applicable to this unit type", entity.ErrInvalidInput)
+		}
+		if !rp.Active {
+			return nil, fmt.Errorf("%w: rate plan is not active", entity.ErrInvalidInput)
+		}
+
+		totalPax := adults + children
+		quote.Total = uc.pricingService.ApplyRatePlan(baseTotal, *rp, totalPax, nights)
+		quote.RatePlan = rp
+	}
+
+	return quote, nil
 }
 
 func (uc *ReservationUseCase) PreviewCancellation(ctx context.Context, reservationID string) (float64, error) {
@@ -233,6 +594,11 @@ func (uc *ReservationUseCase) PreviewCancellation(ctx context.Context, reservati
 		return 0, entity.ErrReservationCancelled
 	}
 
+	return uc.cancellationPenalty(ctx, res)
+}
+
+// cancellationPenalty applies the rate plan's cancellation policy to res as of now.
+func (uc *ReservationUseCase) cancellationPenalty(ctx context.Context, res *entity.Reservation) (float64, error) {
 	if res.RatePlanID == nil {
 		return 0, nil
 	}
@@ -275,14 +641,637 @@ func (uc *ReservationUseCase) PreviewCancellation(ctx context.Context, reservati
 	return penalty, nil
 }
 
+// Cancel cancels a confirmed reservation and notifies the guest. Stays already checked in or
+// out are refused. The row is locked first, so concurrent cancellations cannot both pass the
+// status check.
 func (uc *ReservationUseCase) Cancel(ctx context.Context, id string) error {
-	return uc.resRepo.UpdateStatus(ctx, id, "cancelled")
+	if _, err := uuid.Parse(id); err != nil {
+		return entity.ErrReservationNotFound
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := uc.resRepo.GetByIDLocked(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return entity.ErrReservationNotFound
+		}
+		return err
+	}
+	if res.Status == "cancelled" {
+		return entity.ErrReservationCancelled
+	}
+	if res.Status != "confirmed" {
+		return entity.ErrInvalidReservationStatus
+	}
+
+	penalty, err := uc.cancellationPenalty(ctx, res)
+	if err != nil {
+		uc.logger.Warn("failed to compute cancellation penalty",
+			zap.String("reservation_id", id),
+			zap.Error(err),
+		)
+	}
+
+	if err := uc.resRepo.UpdateStatus(ctx, tx, id, "cancelled"); err != nil {
+		return err
+	}
+
+	res.Status = "cancelled"
+	if err := uc.queueGuestEmail(ctx, tx, *res, nil, nil, penalty, uc.emailService.ReservationCancellation); err != nil {
+		return err
+	}
+
+	unitType, err := uc.unitTypeRepo.GetByID(ctx, res.UnitTypeID)
+	if err != nil {
+		return fmt.Errorf("failed to load unit type: %w", err)
+	}
+	if err := publishWebhookEvent(ctx, uc.webhookRepo, tx, unitType.PropertyID, entity.EventReservationCancelled, res); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// QueueArrivalReminders queues a reminder for every guest arriving within daysAhead days that
+// has not been reminded yet. The email and the reminder flag are written together.
+func (uc *ReservationUseCase) QueueArrivalReminders(ctx context.Context, daysAhead int) (int, error) {
+	pending, err := uc.resRepo.ListPendingReminders(ctx, daysAhead)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, res := range pending {
+		if err := uc.queueArrivalReminder(ctx, res); err != nil {
+			uc.logger.Error("failed to queue pre-arrival reminder",
+				zap.String("reservation_id", res.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		queued++
+	}
+
+	return queued, nil
+}
+
+func (uc *ReservationUseCase) queueArrivalReminder(ctx context.Context, res entity.Reservation) error {
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := uc.queueGuestEmail(ctx, tx, res, nil, nil, 0, uc.emailService.PreArrivalReminder); err != nil {
+		return err
+	}
+	if err := uc.resRepo.MarkReminderSent(ctx, tx, res.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// queueGuestEmail renders a booking email and writes it to the outbox inside tx,
+// so the guest is only notified about changes that were actually committed.
+func (uc *ReservationUseCase) queueGuestEmail(
+	ctx context.Context,
+	tx pgx.Tx,
+	res entity.Reservation,
+	guest *entity.Guest,
+	quote *stayQuote,
+	penalty float64,
+	compose func(service.ReservationEmail) (entity.EmailMessage, error),
+) error {
+	data, err := uc.buildReservationEmail(ctx, tx, res, guest, quote)
+	if err != nil {
+		return err
+	}
+	data.PenaltyAmount = penalty
+	if data.AccessToken, err = uc.issueGuestToken(ctx, tx, res); err != nil {
+		return err
+	}
+
+	msg, err := compose(data)
+	if err != nil {
+		return err
+	}
+	msg.GuestID = res.GuestID
+	return uc.outboxRepo.Enqueue(ctx, tx, msg)
+}
+
+func (uc *ReservationUseCase) buildReservationEmail(ctx context.Context, tx pgx.Tx, res entity.Reservation, guest *entity.Guest, quote *stayQuote) (service.ReservationEmail, error) {
+	unitType, err := uc.unitTypeRepo.GetByID(ctx, res.UnitTypeID)
+	if err != nil {
+		return service.ReservationEmail{}, fmt.Errorf("failed to load unit type: %w", err)
+	}
+	property, err := uc.propertyRepo.GetByID(ctx, unitType.PropertyID)
+	if err != nil {
+		return service.ReservationEmail{}, fmt.Errorf("failed to load property: %w", err)
+	}
+	if guest == nil {
+		guest, err = uc.guestRepo.GetByID(ctx, res.GuestID)
+		if err != nil {
+			return service.ReservationEmail{}, fmt.Errorf("failed to load guest: %w", err)
+		}
+	}
+
+	nights := int(res.End.Sub(res.Start).Hours() / 24)
+	data := service.ReservationEmail{
+		Language:        entity.ResolveLanguage(guest.Language, property.DefaultLanguage),
+		GuestName:       guest.FirstName,
+		GuestEmail:      guest.Email,
+		PropertyName:    property.Name,
+		ReservationCode: res.ReservationCode,
+		UnitTypeName:    unitType.Name,
+		CheckIn:         res.Start,
+		CheckOut:        res.End,
+		Nights:          nights,
+		Adults:          res.Adults,
+		Children:        res.Children,
+		Total:           res.TotalPrice,
+		Currency:        "USD",
+	}
+
+	if quote == nil && res.RatePlanID != nil {
+		plan, err := uc.ratePlanRepo.GetByID(ctx, *res.RatePlanID)
+		if err != nil {
+			return service.ReservationEmail{}, fmt.Errorf("failed to load rate plan: %w", err)
+		}
+		quote = &stayQuote{RatePlan: plan}
+	}
+
+	if quote != nil {
+		data.RatePlan = quote.RatePlan
+	}
+	if data.RatePlan != nil && data.RatePlan.MealPlan.Included {
+		data.MealPlanTotal = data.RatePlan.MealPlan.PricePerPax * float64(res.Adults+res.Children) * float64(nights)
+	}
+
+	data.AddOns = res.AddOns
+	if data.AddOns == nil {
+		if data.AddOns, err = uc.addOnRepo.ListByReservation(ctx, tx, res.ID); err != nil {
+			return service.ReservationEmail{}, err
+		}
+	}
+	data.AccommodationTotal = math.Round((res.TotalPrice-data.MealPlanTotal-addOnsTotal(data.AddOns))*100) / 100
+
+	// Nightly rates are only itemised when they still add up to what the guest was charged.
+	if quote != nil && quote.DailyRates != nil {
+		data.NightlyRates = quote.DailyRates
+	} else if rates, total, err := uc.pricingService.CalculateBaseRates(ctx, unitType.ID, unitType.BasePrice, res.Start, res.End); err == nil && math.Abs(total-data.AccommodationTotal) < 0.01 {
+		data.NightlyRates = rates
+	}
+
+	return data, nil
 }
 
 func (uc *ReservationUseCase) Delete(ctx context.Context, id string) error {
 	return uc.resRepo.Delete(ctx, id)
 }
 
+// GetByCode looks a reservation of the caller's organization up by its booking code, with its add-ons.
 func (uc *ReservationUseCase) GetByCode(ctx context.Context, code string) (*entity.Reservation, error) {
-	return uc.resRepo.GetByCode(ctx, code)
+	res, err := uc.resRepo.GetByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, entity.ErrReservationNotFound
+		}
+		return nil, err
+	}
+	if res.AddOns, err = uc.addOnRepo.ListByReservation(ctx, nil, res.ID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// issueGuestToken signs a self-service token for the reservation, creating its access secret
+// on first use. Tokens stay valid for a month after issue, and at least a month past check-out.
+func (uc *ReservationUseCase) issueGuestToken(ctx context.Context, tx pgx.Tx, res entity.Reservation) (string, error) {
+	candidate, err := auth.GenerateRandomSalt()
+	if err != nil {
+		return "", err
+	}
+	secret, err := uc.resRepo.EnsureAccessSecret(ctx, tx, res.ID, candidate)
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().Add(guestAccessValidity)
+	if afterStay := res.End.Add(guestAccessValidity); afterStay.After(expiresAt) {
+		expiresAt = afterStay
+	}
+	return auth.GenerateGuestAccessToken(res.ID, secret, expiresAt)
+}
+
+// authorizeGuest checks that token was issued for this reservation and is still valid.
+func (uc *ReservationUseCase) authorizeGuest(ctx context.Context, reservationID, token string) error {
+	if token == "" {
+		return entity.ErrGuestAccessDenied
+	}
+	secret, err := uc.resRepo.GetAccessSecret(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return entity.ErrGuestAccessDenied
+	}
+
+	claims, err := auth.ValidateGuestAccessToken(token, secret)
+	if err != nil || claims.ReservationID != reservationID {
+		return entity.ErrGuestAccessDenied
+	}
+	return nil
+}
+
+// VerifyGuestToken returns the reservation a guest access token was issued for.
+func (uc *ReservationUseCase) VerifyGuestToken(ctx context.Context, token string) (string, error) {
+	claims, err := auth.ParseGuestAccessClaimsUnsafe(token)
+	if err != nil {
+		return "", entity.ErrGuestAccessDenied
+	}
+	if _, err := uuid.Parse(claims.ReservationID); err != nil {
+		return "", entity.ErrGuestAccessDenied
+	}
+	if err := uc.authorizeGuest(ctx, claims.ReservationID, token); err != nil {
+		if errors.Is(err, entity.ErrReservationNotFound) {
+			return "", entity.ErrGuestAccessDenied
+		}
+		return "", err
+	}
+	return claims.ReservationID, nil
+}
+
+// GetForGuest returns the reservation behind code to a guest holding an access token for it.
+func (uc *ReservationUseCase) GetForGuest(ctx context.Context, code, token string) (*entity.Reservation, error) {
+	res, err := uc.resRepo.GetByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, entity.ErrReservationNotFound
+		}
+		return nil, err
+	}
+	if err := uc.authorizeGuest(ctx, res.ID, token); err != nil {
+		return nil, err
+	}
+	if res.AddOns, err = uc.addOnRepo.ListByReservation(ctx, nil, res.ID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CancelForGuest cancels the reservation on behalf of a guest holding an access token for it.
+func (uc *ReservationUseCase) CancelForGuest(ctx context.Context, id, token string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return entity.ErrReservationNotFound
+	}
+	res, err := uc.resRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return entity.ErrReservationNotFound
+		}
+		return err
+	}
+	if err := uc.authorizeGuest(ctx, res.ID, token); err != nil {
+		return err
+	}
+	if res.Status != "cancelled" && !guestCanCancel(res) {
+		return fmt.Errorf("%w: the reservation can no longer be cancelled online", entity.ErrModificationNotAllowed)
+	}
+	return uc.Cancel(ctx, id)
+}
+
+// guestCanCancel is the rule for guests cancelling their own booking, through the portal or a
+// guest access token: only stays that are still confirmed.
+func guestCanCancel(res *entity.Reservation) bool {
+	return res.Status == "confirmed"
+}
+
+// RequestGuestAccess issues an access token to a guest who proves the booking code together
+// with the last name on the booking. Unknown codes and wrong names fail alike.
+func (uc *ReservationUseCase) RequestGuestAccess(ctx context.Context, req entity.GuestAccessRequest) (*entity.GuestAccess, error) {
+	code, lastName := strings.TrimSpace(req.ReservationCode), strings.TrimSpace(req.LastName)
+	if code == "" || lastName == "" {
+		return nil, fmt.Errorf("%w: reservation_code and last_name are required", entity.ErrInvalidInput)
+	}
+
+	res, err := uc.resRepo.GetByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, entity.ErrGuestAccessDenied
+		}
+		return nil, err
+	}
+	guest, err := uc.guestRepo.GetByID(ctx, res.GuestID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, entity.ErrGuestAccessDenied
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(strings.TrimSpace(guest.LastName), lastName) {
+		return nil, entity.ErrGuestAccessDenied
+	}
+
+	token, err := uc.issueGuestToken(ctx, nil, *res)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.GuestAccess{ReservationID: res.ID, ReservationCode: res.ReservationCode, AccessToken: token}, nil
+}
+
+// ListAddOns returns the services booked with a reservation.
+func (uc *ReservationUseCase) ListAddOns(ctx context.Context, reservationID string) ([]entity.ReservationAddOn, error) {
+	if _, err := uuid.Parse(reservationID); err != nil {
+		return nil, entity.ErrReservationNotFound
+	}
+	if _, err := uc.resRepo.GetByID(ctx, reservationID); err != nil {
+		return nil, err
+	}
+	return uc.addOnRepo.ListByReservation(ctx, nil, reservationID)
+}
+
+// OfferAddOns prices one unit of every service the property sells for the reservation's stay.
+func (uc *ReservationUseCase) OfferAddOns(ctx context.Context, reservationID string) ([]entity.AddOnOffer, error) {
+	res, err := uc.resRepo.GetByID(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	unitType, err := uc.unitTypeRepo.GetByID(ctx, res.UnitTypeID)
+	if err != nil {
+		return nil, entity.ErrUnitTypeNotFound
+	}
+	services, err := uc.serviceRepo.ListByProperty(ctx, unitType.PropertyID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	offers := make([]entity.AddOnOffer, 0, len(services))
+	for _, svc := range services {
+		err := uc.checkAddOnCapacity(ctx, nil, &svc, res, 1)
+		if err != nil && !errors.Is(err, entity.ErrAddOnUnavailable) {
+			return nil, err
+		}
+		offers = append(offers, entity.AddOnOffer{
+			PropertyService: svc,
+			Total:           addOnPrice(svc.PricingUnit, svc.Price, 1, res),
+			Available:       err == nil,
+		})
+	}
+	return offers, nil
+}
+
+// AddAddOn books a service for a confirmed or in-house reservation and posts it to the total.
+func (uc *ReservationUseCase) AddAddOn(ctx context.Context, reservationID string, req entity.AddOnRequest) (*entity.Reservation, error) {
+	if _, err := uuid.Parse(reservationID); err != nil {
+		return nil, entity.ErrReservationNotFound
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := uc.resRepo.GetByIDLocked(ctx, tx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != "confirmed" && res.Status != "checked_in" {
+		return nil, entity.ErrInvalidReservationStatus
+	}
+	unitType, err := uc.unitTypeRepo.GetByID(ctx, res.UnitTypeID)
+	if err != nil {
+		return nil, entity.ErrUnitTypeNotFound
+	}
+
+	added, err := uc.prepareAddOns(ctx, tx, res, unitType.PropertyID, []entity.AddOnRequest{req})
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.addOnRepo.Create(ctx, tx, added[0]); err != nil {
+		return nil, err
+	}
+	res.TotalPrice = roundMoney(res.TotalPrice + added[0].Total)
+
+	return uc.postAddOnChange(ctx, tx, res, unitType.PropertyID)
+}
+
+// RemoveAddOn cancels a booked service and takes it off the reservation total.
+func (uc *ReservationUseCase) RemoveAddOn(ctx context.Context, reservationID, addOnID string) (*entity.Reservation, error) {
+	if _, err := uuid.Parse(reservationID); err != nil {
+		return nil, entity.ErrReservationNotFound
+	}
+	if _, err := uuid.Parse(addOnID); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := uc.resRepo.GetByIDLocked(ctx, tx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != "confirmed" && res.Status != "checked_in" {
+		return nil, entity.ErrInvalidReservationStatus
+	}
+
+	addOns, err := uc.addOnRepo.ListByReservation(ctx, tx, res.ID)
+	if err != nil {
+		return nil, err
+	}
+	var removed *entity.ReservationAddOn
+	for i := range addOns {
+		if addOns[i].ID == addOnID {
+			removed = &addOns[i]
+		}
+	}
+	if removed == nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	if err := uc.addOnRepo.Delete(ctx, tx, res.ID, addOnID); err != nil {
+		return nil, err
+	}
+	res.TotalPrice = roundMoney(res.TotalPrice - removed.Total)
+
+	unitType, err := uc.unitTypeRepo.GetByID(ctx, res.UnitTypeID)
+	if err != nil {
+		return nil, entity.ErrUnitTypeNotFound
+	}
+	return uc.postAddOnChange(ctx, tx, res, unitType.PropertyID)
+}
+
+// postAddOnChange stores the new total and tells the guest and subscribers, like any other modification.
+func (uc *ReservationUseCase) postAddOnChange(ctx context.Context, tx pgx.Tx, res *entity.Reservation, propertyID string) (*entity.Reservation, error) {
+	if err := uc.resRepo.UpdateTotal(ctx, tx, res.ID, res.TotalPrice); err != nil {
+		return nil, err
+	}
+
+	addOns, err := uc.addOnRepo.ListByReservation(ctx, tx, res.ID)
+	if err != nil {
+		return nil, err
+	}
+	res.AddOns = addOns
+
+	if err := uc.queueGuestEmail(ctx, tx, *res, nil, nil, 0, uc.emailService.ReservationModification); err != nil {
+		return nil, err
+	}
+	if err := publishWebhookEvent(ctx, uc.webhookRepo, tx, propertyID, entity.EventReservationModified, res); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// prepareAddOns validates and prices requested services for res's stay. Requests for the same
+// service are merged into one line, and every service is locked while its daily limit is checked.
+func (uc *ReservationUseCase) prepareAddOns(ctx context.Context, tx pgx.Tx, res *entity.Reservation, propertyID string, reqs []entity.AddOnRequest) ([]entity.ReservationAddOn, error) {
+	var addOns []entity.ReservationAddOn
+	merged := map[string]int{}
+	for _, req := range reqs {
+		if req.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: add-on quantity must be positive", entity.ErrInvalidInput)
+		}
+		if _, err := uuid.Parse(req.PropertyServiceID); err != nil {
+			return nil, fmt.Errorf("%w: unknown add-on service", entity.ErrInvalidInput)
+		}
+		if i, ok := merged[req.PropertyServiceID]; ok {
+			addOns[i].Quantity += req.Quantity
+			continue
+		}
+		merged[req.PropertyServiceID] = len(addOns)
+		addOns = append(addOns, entity.ReservationAddOn{PropertyServiceID: req.PropertyServiceID, Quantity: req.Quantity})
+	}
+
+	for i := range addOns {
+		svc, err := uc.serviceRepo.GetByIDLocked(ctx, tx, addOns[i].PropertyServiceID)
+		if err != nil {
+			if errors.Is(err, entity.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: unknown add-on service", entity.ErrInvalidInput)
+			}
+			return nil, err
+		}
+		if svc.PropertyID != propertyID || !svc.Active {
+			return nil, fmt.Errorf("%w: %s is not offered for this reservation", entity.ErrInvalidInput, svc.Name)
+		}
+		if err := uc.checkAddOnCapacity(ctx, tx, svc, res, addOns[i].Quantity); err != nil {
+			return nil, err
+		}
+
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
+		}
+		addOns[i].ID = id.String()
+		addOns[i].ReservationID = res.ID
+		addOns[i].Name = svc.Name
+		addOns[i].PricingUnit = svc.PricingUnit
+		addOns[i].UnitPrice = svc.Price
+		addOns[i].Total = addOnPrice(svc.PricingUnit, svc.Price, addOns[i].Quantity, res)
+	}
+	return addOns, nil
+}
+
+// checkBookedAddOns re-checks daily limits for the add-ons of res after its stay changed.
+// Services that are no longer sold keep their bookings.
+func (uc *ReservationUseCase) checkBookedAddOns(ctx context.Context, tx pgx.Tx, res *entity.Reservation) error {
+	quantities := map[string]int{}
+	for _, addOn := range res.AddOns {
+		quantities[addOn.PropertyServiceID] += addOn.Quantity
+	}
+
+	for serviceID, quantity := range quantities {
+		var svc *entity.PropertyService
+		var err error
+		if tx != nil {
+			svc, err = uc.serviceRepo.GetByIDLocked(ctx, tx, serviceID)
+		} else {
+			svc, err = uc.serviceRepo.GetByID(ctx, serviceID)
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := uc.checkAddOnCapacity(ctx, tx, svc, res, quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAddOnCapacity fails when quantity more of svc for res's stay would exceed the service's
+// daily limit. Bookings already held by res are ignored, so callers pass its full quantity.
+func (uc *ReservationUseCase) checkAddOnCapacity(ctx context.Context, tx pgx.Tx, svc *entity.PropertyService, res *entity.Reservation, quantity int) error {
+	if svc.DailyLimit == nil {
+		return nil
+	}
+	usage, err := uc.addOnRepo.ListUsage(ctx, tx, svc.ID, res.Start, res.End)
+	if err != nil {
+		return err
+	}
+
+	used := map[time.Time]int{}
+	for _, u := range usage {
+		if u.ReservationID == res.ID {
+			continue
+		}
+		for _, day := range addOnDays(u.PricingUnit, u.Start, u.End) {
+			used[day] += u.PricingUnit.DailyUnits(u.Quantity, u.Pax)
+		}
+	}
+
+	needed := svc.PricingUnit.DailyUnits(quantity, res.Adults+res.Children)
+	for _, day := range addOnDays(svc.PricingUnit, res.Start, res.End) {
+		if used[day]+needed > *svc.DailyLimit {
+			return fmt.Errorf("%w: %s is sold out on %s", entity.ErrAddOnUnavailable, svc.Name, day.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
+
+// addOnDays lists the days a service is used: every night for nightly services, otherwise the arrival day.
+func addOnDays(unit entity.PricingUnit, start, end time.Time) []time.Time {
+	if !unit.Nightly() {
+		return []time.Time{start}
+	}
+	var days []time.Time
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
+
+func addOnPrice(unit entity.PricingUnit, unitPrice float64, quantity int, res *entity.Reservation) float64 {
+	nights := int(res.End.Sub(res.Start).Hours() / 24)
+	return roundMoney(unitPrice * float64(unit.ChargedUnits(quantity, res.Adults+res.Children, nights)))
+}
+
+// repriceAddOns recomputes booked add-ons for res's current stay at the prices they were booked at.
+func repriceAddOns(addOns []entity.ReservationAddOn, res *entity.Reservation) []entity.ReservationAddOn {
+	for i := range addOns {
+		addOns[i].Total = addOnPrice(addOns[i].PricingUnit, addOns[i].UnitPrice, addOns[i].Quantity, res)
+	}
+	return addOns
+}
+
+func addOnsTotal(addOns []entity.ReservationAddOn) float64 {
+	total := 0.0
+	for _, addOn := range addOns {
+		total += addOn.Total
+	}
+	return roundMoney(total)
 }
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -11,11 +12,17 @@ import (
 )
 
 type UnitTypeUseCase struct {
-	repo *repository.UnitTypeRepository
+	repo         *repository.UnitTypeRepository
+	amenityRepo  *repository.AmenityRepository
+	propertyRepo *repository.PropertyRepository
 }
 
-func NewUnitTypeUseCase(repo *repository.UnitTypeRepository) *UnitTypeUseCase {
-	return &UnitTypeUseCase{repo: repo}
+func NewUnitTypeUseCase(
+	repo *repository.UnitTypeRepository,
+	amenityRepo *repository.AmenityRepository,
+	propertyRepo *repository.PropertyRepository,
+) *UnitTypeUseCase {
+	return &UnitTypeUseCase{repo: repo, amenityRepo: amenityRepo, propertyRepo: propertyRepo}
 }
 
 func (uc *UnitTypeUseCase) Create(ctx context.Context, req entity.CreateUnitTypeRequest) (string, error) {
@@ -38,6 +45,15 @@ func (uc *UnitTypeUseCase) Create(ctx context.Context, req entity.CreateUnitType
 		return "", entity.ErrInvalidInput
 	}
 
+	orgID, err := uc.propertyOrganization(ctx, req.PropertyID)
+	if err != nil {
+		return "", err
+	}
+	amenityIDs, err := uc.validateAmenities(ctx, orgID, req.AmenityIDs)
+	if err != nil {
+		return "", err
+	}
+
 	newID, err := uuid.NewV7()
 	if err != nil {
 		return "", fmt.Errorf("failed to generate uuid v7: %w", err)
@@ -55,10 +71,9 @@ func (uc *UnitTypeUseCase) Create(ctx context.Context, req entity.CreateUnitType
 		MaxOccupancy:  req.MaxOccupancy,
 		MaxAdults:     req.MaxAdults,
 		MaxChildren:   req.MaxChildren,
-		Amenities:     req.Amenities,
 	}
 
-	return uc.repo.Create(ctx, ut)
+	return uc.repo.Create(ctx, ut, amenityIDs)
 }
 
 func (uc *UnitTypeUseCase) ListByProperty(ctx context.Context, propertyID string, pagination entity.PaginationRequest) ([]entity.UnitType, int64, error) {
@@ -98,7 +113,22 @@ func (uc *UnitTypeUseCase) Update(ctx context.Context, id string, req entity.Upd
 	if req.Code != "" {
 		req.Code = strings.ToUpper(req.Code)
 	}
-	
+	if req.AmenityIDs != nil {
+		ut, err := uc.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		orgID, err := uc.propertyOrganization(ctx, ut.PropertyID)
+		if err != nil {
+			return err
+		}
+		amenityIDs, err := uc.validateAmenities(ctx, orgID, req.AmenityIDs)
+		if err != nil {
+			return err
+		}
+		req.AmenityIDs = amenityIDs
+	}
+
 	return uc.repo.Update(ctx, id, req)
 }
 
@@ -108,3 +138,49 @@ func (uc *UnitTypeUseCase) Delete(ctx context.Context, id string) error {
 	}
 	return uc.repo.Delete(ctx, id)
 }
+
+// propertyOrganization returns the organization of a property the caller can see.
+func (uc *UnitTypeUseCase) propertyOrganization(ctx context.Context, propertyID string) (string, error) {
+	if _, err := uuid.Parse(propertyID); err != nil {
+		return "", fmt.Errorf("%w: property not found", entity.ErrInvalidInput)
+	}
+	property, err := uc.propertyRepo.GetByID(ctx, propertyID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return "", fmt.Errorf("%w: property not found", entity.ErrInvalidInput)
+		}
+		return "", err
+	}
+	return property.OrganizationID, nil
+}
+
+// validateAmenities dedupes the requested amenity ids and checks each one is a global amenity
+// or one of the organization's own.
+func (uc *UnitTypeUseCase) validateAmenities(ctx context.Context, orgID string, ids []string) ([]string, error) {
+	unique := make([]string, 0, len(ids))
+	seen := map[string]bool{}
+	for _, id := range ids {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown amenity %q", entity.ErrInvalidInput, id)
+		}
+		key := parsed.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, key)
+	}
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	count, err := uc.amenityRepo.CountExisting(ctx, unique, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if count != len(unique) {
+		return nil, fmt.Errorf("%w: unknown amenity", entity.ErrInvalidInput)
+	}
+	return unique, nil
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -10,17 +11,31 @@ import (
 )
 
 type UnitUseCase struct {
-	repo *repository.UnitRepository
+	repo         *repository.UnitRepository
+	unitTypeRepo *repository.UnitTypeRepository
 }
 
-func NewUnitUseCase(repo *repository.UnitRepository) *UnitUseCase {
-	return &UnitUseCase{repo: repo}
+func NewUnitUseCase(repo *repository.UnitRepository, unitTypeRepo *repository.UnitTypeRepository) *UnitUseCase {
+	return &UnitUseCase{repo: repo, unitTypeRepo: unitTypeRepo}
 }
 
 func (uc *UnitUseCase) Create(ctx context.Context, req entity.CreateUnitRequest) (string, error) {
 	if req.PropertyID == "" || req.UnitTypeID == "" || req.Name == "" {
 		return "", entity.ErrInvalidInput
 	}
+	if _, err := uuid.Parse(req.UnitTypeID); err != nil {
+		return "", fmt.Errorf("%w: unit type not found", entity.ErrInvalidInput)
+	}
+	ut, err := uc.unitTypeRepo.GetByID(ctx, req.UnitTypeID)
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return "", fmt.Errorf("%w: unit type not found", entity.ErrInvalidInput)
+		}
+		return "", err
+	}
+	if ut.PropertyID != req.PropertyID {
+		return "", fmt.Errorf("%w: unit type belongs to another property", entity.ErrInvalidInput)
+	}
 
 	id, err := uuid.NewV7()
 	if err != nil {
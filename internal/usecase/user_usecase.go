@@ -4,25 +4,35 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
+
+	"go.uber.org/zap"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ecelayes/pms-backend/internal/entity"
 	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/tenant"
 	"github.com/ecelayes/pms-backend/pkg/auth"
 )
 
 type UserUseCase struct {
-	db       *pgxpool.Pool
-	userRepo *repository.UserRepository
-	orgRepo  *repository.OrganizationRepository
+	db          *pgxpool.Pool
+	userRepo    *repository.UserRepository
+	orgRepo     *repository.OrganizationRepository
+	sessionRepo *repository.SessionRepository
+	passwords   auth.PasswordPolicy
+	logger      *zap.Logger
 }
 
-func NewUserUseCase(db *pgxpool.Pool, userRepo *repository.UserRepository, orgRepo *repository.OrganizationRepository) *UserUseCase {
+func NewUserUseCase(db *pgxpool.Pool, userRepo *repository.UserRepository, orgRepo *repository.OrganizationRepository, sessionRepo *repository.SessionRepository, passwords auth.PasswordPolicy, logger *zap.Logger) *UserUseCase {
 	return &UserUseCase{
-		db:       db,
-		userRepo: userRepo,
-		orgRepo:  orgRepo,
+		db:          db,
+		userRepo:    userRepo,
+		orgRepo:     orgRepo,
+		sessionRepo: sessionRepo,
+		passwords:   passwords,
+		logger:      logger,
 	}
 }
 
@@ -33,12 +43,28 @@ func (uc *UserUseCase) CreateUser(ctx context.Context, requesterRole string, req
 	if !strings.Contains(req.Email, "@") {
 		return "", entity.ErrInvalidInput
 	}
+	if err := uc.passwords.Validate(req.Password); err != nil {
+		return "", fmt.Errorf("%w: %s", entity.ErrInvalidInput, err)
+	}
+	if orgID, scoped := tenant.OrganizationID(ctx); scoped {
+		if req.OrganizationID != "" && req.OrganizationID != orgID {
+			return "", fmt.Errorf("%w: organization not found", entity.ErrInvalidInput)
+		}
+		req.OrganizationID = orgID
+	}
 	if req.OrganizationID == "" {
 		return "", entity.ErrInvalidInput
 	}
 	if req.FirstName == "" || req.LastName == "" {
 		return "", entity.ErrInvalidInput
 	}
+	if req.Language != "" && !entity.IsSupportedLanguage(req.Language) {
+		return "", entity.ErrInvalidInput
+	}
+	propertyIDs, err := uniquePropertyIDs(req.PropertyIDs)
+	if err != nil {
+		return "", err
+	}
 
 	switch req.Role {
 	case entity.OrgRoleOwner:
@@ -71,6 +97,7 @@ func (uc *UserUseCase) CreateUser(ctx context.Context, requesterRole string, req
 	if err != nil { return "", err }
 	defer tx.Rollback(ctx)
 
+	verifiedAt := time.Now()
 	newUser := entity.User{
 		BaseEntity: entity.BaseEntity{ID: userID.String()},
 		Email:      req.Email,
@@ -80,6 +107,9 @@ func (uc *UserUseCase) CreateUser(ctx context.Context, requesterRole string, req
 		FirstName:  req.FirstName,
 		LastName:   req.LastName,
 		Phone:      req.Phone,
+		Language:   req.Language,
+		// Accounts created by an administrator are trusted; only self-registration needs confirming.
+		EmailVerifiedAt: &verifiedAt,
 	}
 
 	err = uc.userRepo.Create(ctx, tx, newUser)
@@ -94,12 +124,22 @@ func (uc *UserUseCase) CreateUser(ctx context.Context, requesterRole string, req
 	err = uc.orgRepo.AddMember(ctx, tx, member)
 	if err != nil { return "", err }
 
+	if len(propertyIDs) > 0 {
+		if err := uc.orgRepo.SetMemberProperties(ctx, tx, req.OrganizationID, userID.String(), propertyIDs); err != nil {
+			return "", err
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil { return "", err }
 
 	return userID.String(), nil
 }
 
+// GetAll lists an organization's members. Callers scoped to an organization always get their own.
 func (uc *UserUseCase) GetAll(ctx context.Context, orgID string, pagination entity.PaginationRequest) ([]entity.User, int64, error) {
+	if scopedOrg, scoped := tenant.OrganizationID(ctx); scoped {
+		orgID = scopedOrg
+	}
 	if orgID == "" {
 		return nil, 0, entity.ErrInvalidInput
 	}
@@ -124,13 +164,138 @@ func (uc *UserUseCase) Update(ctx context.Context, id string, orgID string, req
 			return entity.ErrInvalidInput
 		}
 	}
+	if req.Language != "" && !entity.IsSupportedLanguage(req.Language) {
+		return entity.ErrInvalidInput
+	}
+	if scopedOrg, scoped := tenant.OrganizationID(ctx); scoped {
+		orgID = scopedOrg
+	}
+	if (req.Role != "" || req.PropertyIDs != nil) && orgID == "" {
+		return fmt.Errorf("%w: organization_id is required for role and property updates", entity.ErrInvalidInput)
+	}
 
-	return uc.userRepo.Update(ctx, id, orgID, req)
+	var propertyIDs []string
+	if req.PropertyIDs != nil {
+		var err error
+		if propertyIDs, err = uniquePropertyIDs(*req.PropertyIDs); err != nil {
+			return err
+		}
+	}
+
+	// The profile, role and property changes apply together or not at all.
+	tx, err := uc.db.Begin(ctx)
+	if err != nil { return err }
+	defer tx.Rollback(ctx)
+
+	// An account is shared by every organization it belongs to, so one of them cannot move
+	// the address the others sign it in with.
+	if req.Email != "" && orgID != "" {
+		shared, err := uc.orgRepo.HasOtherMemberships(ctx, tx, id, orgID)
+		if err != nil {
+			return err
+		}
+		if shared {
+			return fmt.Errorf("%w: the email of a user who belongs to other organizations cannot be changed here", entity.ErrInsufficientPermissions)
+		}
+	}
+	emailChanged := false
+	if req.Email != "" {
+		current, err := uc.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		emailChanged = current.Email != req.Email
+	}
+	if err := uc.userRepo.Update(ctx, tx, id, orgID, req); err != nil {
+		return err
+	}
+	// The account has to verify the new address, and tokens issued to the old one stop working.
+	if emailChanged {
+		if err := uc.userRepo.BumpTokenVersion(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	if req.PropertyIDs != nil {
+		if err := uc.orgRepo.SetMemberProperties(ctx, tx, orgID, id, propertyIDs); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
 }
 
+// uniquePropertyIDs validates and de-duplicates the properties a member is restricted to.
+func uniquePropertyIDs(ids []string) ([]string, error) {
+	unique := make([]string, 0, len(ids))
+	seen := map[string]bool{}
+	for _, id := range ids {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown property %q", entity.ErrInvalidInput, id)
+		}
+		key := parsed.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, key)
+	}
+	return unique, nil
+}
+
+// Delete removes a member from the caller's organization. Only a super admin, acting outside
+// any organization, deletes the account itself.
 func (uc *UserUseCase) Delete(ctx context.Context, id string) error {
 	if _, err := uuid.Parse(id); err != nil {
 		return entity.ErrRecordNotFound
 	}
+	if orgID, scoped := tenant.OrganizationID(ctx); scoped {
+		return uc.orgRepo.RemoveMember(ctx, orgID, id)
+	}
 	return uc.userRepo.Delete(ctx, id)
 }
+
+// ResetTwoFactor removes a member's second factor, for when they lose their device. They
+// sign in with their password alone afterwards, or enrol again if the organization requires it.
+// Every session ends with it, so whoever holds the lost device is signed out too. The second
+// factor guards the account in all of its organizations, so only a super admin resets it for
+// users who belong to more than one.
+func (uc *UserUseCase) ResetTwoFactor(ctx context.Context, requesterID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return entity.ErrRecordNotFound
+	}
+	if _, err := uc.userRepo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	orgID, scoped := tenant.OrganizationID(ctx)
+	if scoped {
+		shared, err := uc.orgRepo.HasOtherMemberships(ctx, nil, id, orgID)
+		if err != nil {
+			return err
+		}
+		if shared {
+			return fmt.Errorf("%w: the user belongs to other organizations, ask a super admin", entity.ErrInsufficientPermissions)
+		}
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil { return err }
+	defer tx.Rollback(ctx)
+
+	if err := uc.userRepo.ClearTwoFactor(ctx, tx, id); err != nil {
+		return err
+	}
+	if _, err := uc.sessionRepo.RevokeAll(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := uc.userRepo.BumpTokenVersion(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil { return err }
+
+	uc.logger.Warn("two-factor authentication reset",
+		zap.String("user_id", id),
+		zap.String("reset_by", requesterID),
+		zap.String("organization_id", orgID),
+	)
+	return nil
+}
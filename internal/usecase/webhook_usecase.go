@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/service"
+)
+
+// webhookDeliveryLease is how long a claimed delivery is reserved for the worker sending it.
+// It must outlast a full batch of requests at the sender's timeout.
+const webhookDeliveryLease = 15 * time.Minute
+
+type WebhookUseCase struct {
+	db          *pgxpool.Pool
+	webhookRepo *repository.WebhookRepository
+	sender      *service.WebhookSender
+	logger      *zap.Logger
+}
+
+func NewWebhookUseCase(
+	db *pgxpool.Pool,
+	webhookRepo *repository.WebhookRepository,
+	sender *service.WebhookSender,
+	logger *zap.Logger,
+) *WebhookUseCase {
+	return &WebhookUseCase{
+		db:          db,
+		webhookRepo: webhookRepo,
+		sender:      sender,
+		logger:      logger,
+	}
+}
+
+// Create registers a subscription for the organization. The signing secret is only
+// returned here; when none is supplied a random one is generated.
+func (uc *WebhookUseCase) Create(ctx context.Context, orgID string, req entity.CreateWebhookRequest) (*entity.WebhookSubscription, error) {
+	if orgID == "" {
+		return nil, entity.ErrInvalidInput
+	}
+	if err := uc.validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	if err := validateEventTypes(req.EventTypes); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+
+	sub := entity.WebhookSubscription{
+		BaseEntity:     entity.BaseEntity{ID: id.String()},
+		OrganizationID: orgID,
+		URL:            req.URL,
+		Secret:         secret,
+		EventTypes:     req.EventTypes,
+		Active:         true,
+	}
+	if err := uc.webhookRepo.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+func (uc *WebhookUseCase) List(ctx context.Context, orgID string) ([]entity.WebhookSubscription, error) {
+	return uc.webhookRepo.ListByOrganization(ctx, orgID)
+}
+
+// GetByID hides subscriptions of other organizations behind ErrRecordNotFound.
+func (uc *WebhookUseCase) GetByID(ctx context.Context, orgID, id string) (*entity.WebhookSubscription, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, entity.ErrRecordNotFound
+	}
+	sub, err := uc.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub.OrganizationID != orgID {
+		return nil, entity.ErrRecordNotFound
+	}
+	return sub, nil
+}
+
+func (uc *WebhookUseCase) Update(ctx context.Context, orgID, id string, req entity.UpdateWebhookRequest) error {
+	if _, err := uc.GetByID(ctx, orgID, id); err != nil {
+		return err
+	}
+	if req.URL != "" {
+		if err := uc.validateWebhookURL(req.URL); err != nil {
+			return err
+		}
+	}
+	if req.EventTypes != nil {
+		if err := validateEventTypes(req.EventTypes); err != nil {
+			return err
+		}
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := uc.webhookRepo.Update(ctx, tx, id, req); err != nil {
+		return err
+	}
+	if req.Active != nil && !*req.Active {
+		if err := uc.webhookRepo.DeadLetterPending(ctx, tx, id, "subscription deactivated"); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Delete removes the subscription and dead-letters what is still queued for it.
+func (uc *WebhookUseCase) Delete(ctx context.Context, orgID, id string) error {
+	if _, err := uc.GetByID(ctx, orgID, id); err != nil {
+		return err
+	}
+
+	tx, err := uc.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := uc.webhookRepo.Delete(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := uc.webhookRepo.DeadLetterPending(ctx, tx, id, "subscription deleted"); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (uc *WebhookUseCase) ListDeliveries(ctx context.Context, orgID, id string, pagination entity.PaginationRequest) ([]entity.WebhookDelivery, int64, error) {
+	if _, err := uc.GetByID(ctx, orgID, id); err != nil {
+		return nil, 0, err
+	}
+	return uc.webhookRepo.ListDeliveries(ctx, id, pagination)
+}
+
+// DeliverDue posts up to batchSize due deliveries, retrying failures with the same
+// backoff as the email outbox until max_attempts is reached. The batch is claimed in its own
+// short transaction and each result is recorded separately, so no database locks are held
+// while endpoints are called.
+func (uc *WebhookUseCase) DeliverDue(ctx context.Context, batchSize int) (int, error) {
+	due, err := uc.webhookRepo.ClaimDue(ctx, batchSize, time.Now().Add(webhookDeliveryLease))
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, d := range due {
+		attempts := d.Attempts + 1
+		statusCode, sendErr := uc.sender.Send(ctx, d)
+
+		var code *int
+		if statusCode != 0 {
+			code = &statusCode
+		}
+
+		switch {
+		case sendErr == nil:
+			err = uc.webhookRepo.MarkDelivered(ctx, d.ID, attempts, statusCode)
+			delivered++
+		case attempts >= d.MaxAttempts:
+			uc.logger.Error("webhook delivery dead-lettered after exhausting retries",
+				zap.String("delivery_id", d.ID),
+				zap.String("subscription_id", d.SubscriptionID),
+				zap.Int("attempts", attempts),
+				zap.Error(sendErr),
+			)
+			err = uc.webhookRepo.MarkFailed(ctx, d.ID, attempts, code, sendErr.Error())
+		default:
+			uc.logger.Warn("webhook delivery failed, retry scheduled",
+				zap.String("delivery_id", d.ID),
+				zap.String("subscription_id", d.SubscriptionID),
+				zap.Int("attempts", attempts),
+				zap.Error(sendErr),
+			)
+			err = uc.webhookRepo.ScheduleRetry(ctx, d.ID, attempts, code, time.Now().Add(retryDelay(attempts)), sendErr.Error())
+		}
+		if err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// publishWebhookEvent queues an event for every subscriber of the property's organization.
+// Callers pass their transaction so the event is only emitted if the change commits.
+func publishWebhookEvent(ctx context.Context, repo *repository.WebhookRepository, tx pgx.Tx, propertyID, eventType string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encoding webhook data: %w", err)
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("failed to generate uuid v7: %w", err)
+	}
+
+	event := entity.WebhookEvent{
+		ID:        id.String(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      raw,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding webhook event: %w", err)
+	}
+
+	return repo.EnqueueForProperty(ctx, tx, propertyID, event, payload)
+}
+
+func (uc *WebhookUseCase) validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) url", entity.ErrInvalidInput)
+	}
+	if err := uc.sender.CheckURL(u); err != nil {
+		return fmt.Errorf("%w: url must point to a public address", entity.ErrInvalidInput)
+	}
+	return nil
+}
+
+func validateEventTypes(types []string) error {
+	if len(types) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", entity.ErrInvalidInput)
+	}
+	for _, t := range types {
+		if !entity.IsWebhookEventType(t) {
+			return fmt.Errorf("%w: unknown event type %q", entity.ErrInvalidInput, t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+// OutboxWorker drains the email outbox.
+type OutboxWorker struct {
+	uc        *usecase.EmailOutboxUseCase
+	interval  time.Duration
+	batchSize int
+	logger    *zap.Logger
+}
+
+func NewOutboxWorker(uc *usecase.EmailOutboxUseCase, interval time.Duration, batchSize int, logger *zap.Logger) *OutboxWorker {
+	return &OutboxWorker{
+		uc:        uc,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Start blocks until ctx is cancelled. A full batch is followed immediately by another
+// pass so a backlog drains without waiting for the next tick.
+func (w *OutboxWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				sent, err := w.uc.DeliverDue(ctx, w.batchSize)
+				if err != nil {
+					w.logger.Error("email outbox run failed", zap.Error(err))
+					break
+				}
+				if sent < w.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
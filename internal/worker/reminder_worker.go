@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+// ReminderWorker periodically queues reminder emails for guests whose arrival is coming up.
+type ReminderWorker struct {
+	uc        *usecase.ReservationUseCase
+	interval  time.Duration
+	daysAhead int
+	logger    *zap.Logger
+}
+
+func NewReminderWorker(uc *usecase.ReservationUseCase, interval time.Duration, daysAhead int, logger *zap.Logger) *ReminderWorker {
+	return &ReminderWorker{
+		uc:        uc,
+		interval:  interval,
+		daysAhead: daysAhead,
+		logger:    logger,
+	}
+}
+
+// Start blocks until ctx is cancelled, running one reminder pass per interval.
+func (w *ReminderWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			queued, err := w.uc.QueueArrivalReminders(ctx, w.daysAhead)
+			if err != nil {
+				w.logger.Error("pre-arrival reminder run failed", zap.Error(err))
+				continue
+			}
+			if queued > 0 {
+				w.logger.Info("pre-arrival reminders queued", zap.Int("count", queued))
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+// RetentionWorker periodically anonymises guests past their organization's retention period.
+type RetentionWorker struct {
+	uc       *usecase.PrivacyUseCase
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewRetentionWorker(uc *usecase.PrivacyUseCase, interval time.Duration, logger *zap.Logger) *RetentionWorker {
+	return &RetentionWorker{
+		uc:       uc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start blocks until ctx is cancelled, running one retention pass per interval.
+func (w *RetentionWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			erased, err := w.uc.ApplyRetention(ctx)
+			if err != nil {
+				w.logger.Error("guest retention run failed", zap.Error(err))
+				continue
+			}
+			if erased > 0 {
+				w.logger.Info("guests anonymised by retention policy", zap.Int("count", erased))
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+// SignupCleanupWorker periodically deletes self-registrations whose email was never verified.
+type SignupCleanupWorker struct {
+	uc       *usecase.AuthUseCase
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewSignupCleanupWorker(uc *usecase.AuthUseCase, interval time.Duration, logger *zap.Logger) *SignupCleanupWorker {
+	return &SignupCleanupWorker{
+		uc:       uc,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start blocks until ctx is cancelled, running one cleanup pass per interval.
+func (w *SignupCleanupWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := w.uc.PurgeUnverifiedSignups(ctx)
+			if err != nil {
+				w.logger.Error("unverified signup cleanup failed", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				w.logger.Info("unverified signups deleted", zap.Int64("count", deleted))
+			}
+		}
+	}
+}
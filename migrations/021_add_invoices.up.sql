@@ -0,0 +1,71 @@
+ALTER TABLE properties ADD COLUMN tax_rate DECIMAL(5, 2) NOT NULL DEFAULT 0;
+ALTER TABLE properties ADD CONSTRAINT check_tax_rate_range CHECK (tax_rate >= 0 AND tax_rate < 100);
+
+CREATE TABLE invoice_series (
+    property_id UUID NOT NULL REFERENCES properties(id),
+    document_type VARCHAR(20) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    next_number INT NOT NULL DEFAULT 1 CHECK (next_number > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (property_id, document_type)
+);
+CREATE TRIGGER update_invoice_series_modtime BEFORE UPDATE ON invoice_series FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+CREATE TABLE invoices (
+    id UUID PRIMARY KEY,
+    property_id UUID NOT NULL REFERENCES properties(id),
+    reservation_id UUID NOT NULL REFERENCES reservations(id),
+    guest_id UUID REFERENCES guests(id),
+    document_type VARCHAR(20) NOT NULL,
+    number TEXT NOT NULL UNIQUE,
+    sequence INT NOT NULL,
+    corrects_invoice_id UUID REFERENCES invoices(id),
+    reason TEXT NOT NULL DEFAULT '',
+
+    customer_name TEXT NOT NULL,
+    customer_email TEXT NOT NULL,
+
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    tax_rate DECIMAL(5, 2) NOT NULL,
+    subtotal DECIMAL(10, 2) NOT NULL,
+    tax_amount DECIMAL(10, 2) NOT NULL,
+    total DECIMAL(10, 2) NOT NULL,
+
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (property_id, document_type, sequence)
+);
+
+CREATE UNIQUE INDEX idx_invoices_one_per_reservation ON invoices(reservation_id) WHERE document_type = 'invoice';
+CREATE INDEX idx_invoices_property ON invoices(property_id);
+CREATE INDEX idx_invoices_corrects ON invoices(corrects_invoice_id);
+
+CREATE TABLE invoice_lines (
+    id UUID PRIMARY KEY,
+    invoice_id UUID NOT NULL REFERENCES invoices(id),
+    position INT NOT NULL,
+    description TEXT NOT NULL,
+    quantity DECIMAL(10, 2) NOT NULL,
+    unit_price DECIMAL(10, 2) NOT NULL,
+    net_amount DECIMAL(10, 2) NOT NULL,
+    tax_amount DECIMAL(10, 2) NOT NULL,
+    total DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (invoice_id, position)
+);
+
+-- Issued documents can only be corrected through credit notes.
+CREATE OR REPLACE FUNCTION prevent_invoice_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'issued invoices are immutable (%)', TG_TABLE_NAME;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER invoices_immutable BEFORE UPDATE OR DELETE ON invoices FOR EACH ROW EXECUTE PROCEDURE prevent_invoice_changes();
+CREATE TRIGGER invoice_lines_immutable BEFORE UPDATE OR DELETE ON invoice_lines FOR EACH ROW EXECUTE PROCEDURE prevent_invoice_changes();
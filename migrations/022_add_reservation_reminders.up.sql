@@ -0,0 +1,5 @@
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS reminder_sent_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_reservations_pending_reminders
+    ON reservations (lower(stay_range))
+    WHERE reminder_sent_at IS NULL AND status = 'confirmed' AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(5);
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS language VARCHAR(5);
+ALTER TABLE properties ADD COLUMN IF NOT EXISTS default_language VARCHAR(5) NOT NULL DEFAULT 'es';
@@ -0,0 +1,24 @@
+-- Soft-deleted guests must not block a new profile with the same email.
+ALTER TABLE guests DROP CONSTRAINT IF EXISTS guests_email_key;
+DROP INDEX IF EXISTS idx_guests_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guests_email_active
+    ON guests (email)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_guests_name
+    ON guests (LOWER(last_name), LOWER(first_name))
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS guest_notes (
+    id UUID PRIMARY KEY,
+    guest_id UUID NOT NULL REFERENCES guests(id),
+    author_id UUID REFERENCES users(id),
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_guest_notes_guest
+    ON guest_notes (guest_id, created_at DESC);
+
+CREATE TRIGGER update_guest_notes_modtime BEFORE UPDATE ON guest_notes FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
@@ -0,0 +1,86 @@
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id);
+
+DROP INDEX IF EXISTS idx_guests_email_active;
+
+-- Organizations each shared guest has booked with, in order of first booking.
+CREATE TEMP TABLE guest_org_split AS
+SELECT r.guest_id, p.organization_id, MIN(r.created_at) AS first_booked_at, NULL::UUID AS new_guest_id
+FROM reservations r
+JOIN unit_types ut ON ut.id = r.unit_type_id
+JOIN properties p ON p.id = ut.property_id
+WHERE r.guest_id IS NOT NULL
+GROUP BY r.guest_id, p.organization_id;
+
+-- The organization that booked the guest first keeps the existing row.
+UPDATE guests g
+SET organization_id = first_org.organization_id
+FROM (
+    SELECT DISTINCT ON (guest_id) guest_id, organization_id
+    FROM guest_org_split
+    ORDER BY guest_id, first_booked_at
+) first_org
+WHERE first_org.guest_id = g.id AND g.organization_id IS NULL;
+
+-- Every other organization gets its own copy of the profile.
+UPDATE guest_org_split s
+SET new_guest_id = gen_random_uuid()
+FROM guests g
+WHERE g.id = s.guest_id AND g.organization_id <> s.organization_id;
+
+INSERT INTO guests (id, organization_id, email, first_name, last_name, phone, language, created_at, updated_at, deleted_at)
+SELECT s.new_guest_id, s.organization_id, g.email, g.first_name, g.last_name, g.phone, g.language, g.created_at, NOW(), g.deleted_at
+FROM guest_org_split s
+JOIN guests g ON g.id = s.guest_id
+WHERE s.new_guest_id IS NOT NULL;
+
+UPDATE reservations r
+SET guest_id = s.new_guest_id
+FROM guest_org_split s, unit_types ut, properties p
+WHERE r.guest_id = s.guest_id
+  AND s.new_guest_id IS NOT NULL
+  AND ut.id = r.unit_type_id
+  AND p.id = ut.property_id
+  AND p.organization_id = s.organization_id;
+
+-- Only the guest link moves; the customer snapshot on issued documents is untouched.
+ALTER TABLE invoices DISABLE TRIGGER invoices_immutable;
+
+UPDATE invoices i
+SET guest_id = s.new_guest_id
+FROM guest_org_split s, properties p
+WHERE i.guest_id = s.guest_id
+  AND s.new_guest_id IS NOT NULL
+  AND p.id = i.property_id
+  AND p.organization_id = s.organization_id;
+
+ALTER TABLE invoices ENABLE TRIGGER invoices_immutable;
+
+-- Notes follow their author to that organization's copy. Notes by members of the organization
+-- keeping the original row, or by authors outside every organization involved, stay put.
+UPDATE guest_notes n
+SET guest_id = target.new_guest_id
+FROM (
+    SELECT DISTINCT ON (gn.id) gn.id AS note_id, s.new_guest_id
+    FROM guest_notes gn
+    JOIN guests g ON g.id = gn.guest_id
+    JOIN guest_org_split s ON s.guest_id = gn.guest_id AND s.new_guest_id IS NOT NULL
+    JOIN organization_members m ON m.user_id = gn.author_id AND m.organization_id = s.organization_id
+    WHERE NOT EXISTS (
+        SELECT 1 FROM organization_members own
+        WHERE own.user_id = gn.author_id AND own.organization_id = g.organization_id
+    )
+    ORDER BY gn.id, s.first_booked_at
+) target
+WHERE n.id = target.note_id;
+
+DROP TABLE guest_org_split;
+
+-- Profiles that never booked cannot be attributed to an organization and are retired.
+UPDATE guests SET deleted_at = NOW() WHERE organization_id IS NULL AND deleted_at IS NULL;
+
+ALTER TABLE guests ADD CONSTRAINT guests_organization_required
+    CHECK (organization_id IS NOT NULL OR deleted_at IS NOT NULL);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guests_org_email_active
+    ON guests (organization_id, email)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,28 @@
+-- Merging guests re-links issued documents to the surviving profile. The guest link is the
+-- only column that may change; the customer snapshot and amounts stay immutable.
+CREATE OR REPLACE FUNCTION prevent_invoice_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND TG_TABLE_NAME = 'invoices'
+        AND (to_jsonb(NEW) - 'guest_id') = (to_jsonb(OLD) - 'guest_id') THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'issued invoices are immutable (%)', TG_TABLE_NAME;
+END;
+$$ language 'plpgsql';
+
+CREATE TABLE IF NOT EXISTS guest_merges (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id),
+    survivor_id UUID NOT NULL REFERENCES guests(id),
+    merged_id UUID NOT NULL REFERENCES guests(id),
+    merged_by UUID REFERENCES users(id),
+    merged_snapshot JSONB NOT NULL,
+    reservations_moved INT NOT NULL DEFAULT 0,
+    invoices_moved INT NOT NULL DEFAULT 0,
+    notes_moved INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_guest_merges_org
+    ON guest_merges (organization_id, created_at DESC);
@@ -0,0 +1,26 @@
+-- Days after a guest's last stay before the profile is anonymised automatically. NULL keeps profiles.
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS guest_retention_days INT;
+ALTER TABLE organizations ADD CONSTRAINT check_guest_retention_days CHECK (guest_retention_days IS NULL OR guest_retention_days > 0);
+
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ;
+
+-- Links guest communications to the profile so they can be exported and redacted.
+ALTER TABLE email_outbox ADD COLUMN IF NOT EXISTS guest_id UUID REFERENCES guests(id);
+CREATE INDEX IF NOT EXISTS idx_email_outbox_guest
+    ON email_outbox (guest_id)
+    WHERE guest_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS privacy_requests (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id),
+    guest_id UUID NOT NULL REFERENCES guests(id),
+    request_type VARCHAR(20) NOT NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'request',
+    requested_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT privacy_requests_type_check CHECK (request_type IN ('export', 'erasure')),
+    CONSTRAINT privacy_requests_source_check CHECK (source IN ('request', 'retention'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_privacy_requests_org
+    ON privacy_requests (organization_id, created_at DESC);
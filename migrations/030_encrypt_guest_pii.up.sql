@@ -0,0 +1,23 @@
+-- Guest email, names and phone are stored encrypted by the application (AES-GCM).
+-- pii_key_version records the key that sealed the row; NULL marks legacy plaintext rows,
+-- which `make db-encrypt-guests` re-writes with the active key.
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS pii_key_version INT;
+
+-- Keyed hash of the normalised email used for lookups, since ciphertexts are randomised.
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS email_index TEXT;
+
+-- Plaintext uniqueness only applies to rows that have not been encrypted yet.
+DROP INDEX IF EXISTS idx_guests_org_email_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guests_org_email_plain
+    ON guests (organization_id, email)
+    WHERE deleted_at IS NULL AND pii_key_version IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guests_org_email_index
+    ON guests (organization_id, email_index)
+    WHERE deleted_at IS NULL AND email_index IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_guests_pii_key_version
+    ON guests (pii_key_version);
+
+-- Ordering and searching by name now happen after decryption.
+DROP INDEX IF EXISTS idx_guests_name;
@@ -0,0 +1,3 @@
+-- Per-reservation secret that signs the guest self-service links. Rotating it revokes every
+-- link issued for the booking. Older reservations get one on their first verified lookup.
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS access_secret TEXT;
@@ -0,0 +1,2 @@
+-- Expected arrival time shared by the guest, e.g. for late check-ins.
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS arrival_time TIME;
@@ -0,0 +1,32 @@
+-- Catalog services a property sells as add-ons.
+CREATE TABLE property_services (
+    id UUID PRIMARY KEY,
+    property_id UUID NOT NULL REFERENCES properties(id),
+    service_id UUID NOT NULL REFERENCES hotel_services(id),
+    price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
+    pricing_unit VARCHAR(20) NOT NULL CHECK (pricing_unit IN ('per_stay', 'per_night', 'per_person', 'per_person_night')),
+    daily_limit INT CHECK (daily_limit > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX idx_property_services_unique ON property_services(property_id, service_id) WHERE deleted_at IS NULL;
+CREATE TRIGGER update_property_services_modtime BEFORE UPDATE ON property_services FOR EACH ROW EXECUTE PROCEDURE update_updated_at_column();
+
+-- Services booked with a reservation. Their totals are part of reservations.total_price.
+CREATE TABLE reservation_add_ons (
+    id UUID PRIMARY KEY,
+    reservation_id UUID NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    property_service_id UUID NOT NULL REFERENCES property_services(id),
+    name VARCHAR(100) NOT NULL,
+    pricing_unit VARCHAR(20) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(10, 2) NOT NULL,
+    total DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reservation_add_ons_reservation ON reservation_add_ons(reservation_id);
+CREATE INDEX idx_reservation_add_ons_service ON reservation_add_ons(property_service_id);
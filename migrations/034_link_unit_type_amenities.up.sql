@@ -0,0 +1,32 @@
+-- Unit type amenities become references to the amenity catalog instead of free text.
+CREATE TABLE unit_type_amenities (
+    unit_type_id UUID NOT NULL REFERENCES unit_types(id) ON DELETE CASCADE,
+    amenity_id UUID NOT NULL REFERENCES amenities(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (unit_type_id, amenity_id)
+);
+
+CREATE INDEX idx_unit_type_amenities_amenity ON unit_type_amenities(amenity_id);
+
+-- Every free-text amenity without a catalog entry (compared case-insensitively) gets one.
+INSERT INTO amenities (id, name, created_at, updated_at)
+SELECT gen_random_uuid(), MIN(trimmed), NOW(), NOW()
+FROM (
+    SELECT btrim(a) AS trimmed
+    FROM unit_types CROSS JOIN LATERAL unnest(amenities) AS a
+    WHERE btrim(a) <> ''
+) free_text
+WHERE NOT EXISTS (
+    SELECT 1 FROM amenities am WHERE lower(am.name) = lower(free_text.trimmed) AND am.deleted_at IS NULL
+)
+GROUP BY lower(trimmed)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO unit_type_amenities (unit_type_id, amenity_id)
+SELECT DISTINCT ut.id, am.id
+FROM unit_types ut
+CROSS JOIN LATERAL unnest(ut.amenities) AS a
+JOIN amenities am ON lower(am.name) = lower(btrim(a)) AND am.deleted_at IS NULL;
+
+ALTER TABLE unit_types DROP COLUMN amenities;
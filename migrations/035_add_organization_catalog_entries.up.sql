@@ -0,0 +1,12 @@
+-- Catalog entries with an organization belong to that organization; entries without one are global.
+ALTER TABLE amenities ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE hotel_services ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
+
+-- Names stay unique among global entries and within each organization's own entries.
+ALTER TABLE amenities DROP CONSTRAINT amenities_name_key;
+ALTER TABLE hotel_services DROP CONSTRAINT hotel_services_name_key;
+
+CREATE UNIQUE INDEX idx_amenities_global_name ON amenities(name) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX idx_amenities_org_name ON amenities(organization_id, name) WHERE organization_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_hotel_services_global_name ON hotel_services(name) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX idx_hotel_services_org_name ON hotel_services(organization_id, name) WHERE organization_id IS NOT NULL;
@@ -0,0 +1,10 @@
+-- Members listed here only work with these properties; members without rows see the whole organization.
+CREATE TABLE organization_member_properties (
+    member_id UUID NOT NULL REFERENCES organization_members(id) ON DELETE CASCADE,
+    property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (member_id, property_id)
+);
+
+CREATE INDEX idx_organization_member_properties_property ON organization_member_properties(property_id);
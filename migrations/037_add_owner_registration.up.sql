@@ -0,0 +1,18 @@
+-- Self-registered owners stay inactive until they confirm their email address. Accounts
+-- created any other way are verified on creation, which the column default covers.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ DEFAULT NOW();
+UPDATE users SET email_verified_at = created_at;
+
+-- Organization names become unique (ignoring case and surrounding spaces). Existing
+-- duplicates keep the oldest name and get their code appended.
+UPDATE organizations o
+SET name = o.name || ' (' || o.code || ')'
+WHERE o.deleted_at IS NULL
+  AND EXISTS (
+      SELECT 1 FROM organizations d
+      WHERE d.deleted_at IS NULL
+        AND lower(btrim(d.name)) = lower(btrim(o.name))
+        AND (d.created_at, d.id) < (o.created_at, o.id)
+  );
+
+CREATE UNIQUE INDEX organizations_name_unique ON organizations (lower(btrim(name))) WHERE deleted_at IS NULL;
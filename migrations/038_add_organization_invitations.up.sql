@@ -0,0 +1,22 @@
+-- Pending invitations to join an organization. Each row signs its own links with secret,
+-- so resending rotates it and invalidates earlier links.
+CREATE TABLE organization_invitations (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    property_ids UUID[] NOT NULL DEFAULT '{}',
+    language VARCHAR(5),
+    secret VARCHAR(64) NOT NULL,
+    invited_by UUID REFERENCES users(id),
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One open invitation per address and organization.
+CREATE UNIQUE INDEX idx_organization_invitations_open
+    ON organization_invitations (organization_id, lower(email))
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
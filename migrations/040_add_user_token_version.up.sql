@@ -0,0 +1,2 @@
+-- Access tokens carry the version they were issued under; bumping it revokes all of a user's tokens.
+ALTER TABLE users ADD COLUMN token_version INT NOT NULL DEFAULT 0;
@@ -0,0 +1,20 @@
+-- TOTP two-factor authentication. The secret is written when enrolment starts and only takes
+-- effect once a first code confirms it (totp_enabled_at). The last accepted time step is kept
+-- so a code cannot be replayed within its validity window.
+ALTER TABLE users ADD COLUMN totp_secret TEXT;
+ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT;
+
+-- Single-use recovery codes, stored as hashes.
+CREATE TABLE user_recovery_codes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_user_recovery_codes_hash ON user_recovery_codes(user_id, code_hash);
+
+-- Organizations can make two-factor authentication mandatory for their members.
+ALTER TABLE organizations ADD COLUMN require_two_factor BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,8 @@
+-- Failed sign-ins are counted per account. Past a few failures each one makes the account
+-- wait longer before the next attempt, up to a temporary lockout; a successful sign-in or a
+-- password reset clears both.
+ALTER TABLE users ADD COLUMN failed_login_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN locked_until TIMESTAMPTZ;
+
+-- Password reset emails are rate limited per account.
+ALTER TABLE users ADD COLUMN password_reset_requested_at TIMESTAMPTZ;
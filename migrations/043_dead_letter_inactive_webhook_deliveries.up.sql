@@ -0,0 +1,8 @@
+-- Deliveries queued for subscriptions that were deleted or deactivated must never be sent.
+UPDATE webhook_deliveries d
+SET status = 'failed',
+    last_error = CASE WHEN s.deleted_at IS NOT NULL THEN 'subscription deleted' ELSE 'subscription deactivated' END
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.status = 'pending'
+  AND (NOT s.active OR s.deleted_at IS NOT NULL);
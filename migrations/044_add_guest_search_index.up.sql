@@ -0,0 +1,7 @@
+-- Blind indexes of the terms each guest can be searched by (name and email prefixes, phone
+-- digits), so listing no longer decrypts every guest. Existing rows are filled in by
+-- `make db-encrypt-guests`.
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS search_index TEXT[];
+
+CREATE INDEX IF NOT EXISTS idx_guests_search_index
+    ON guests USING GIN (search_index);
@@ -0,0 +1,8 @@
+-- Whether a member works with every property of the organization is stored explicitly.
+-- Inferring it from an empty organization_member_properties set opened the whole organization
+-- to members whose last assigned property was deleted, as the rows cascade away with it.
+ALTER TABLE organization_members ADD COLUMN IF NOT EXISTS all_properties BOOLEAN NOT NULL DEFAULT TRUE;
+
+UPDATE organization_members om
+SET all_properties = FALSE
+WHERE EXISTS (SELECT 1 FROM organization_member_properties mp WHERE mp.member_id = om.id);
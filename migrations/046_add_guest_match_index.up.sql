@@ -0,0 +1,7 @@
+-- Blind indexes of the duplicate-detection blocking keys (phone suffix, email prefix, name
+-- prefix), so candidate pairs are grouped in SQL instead of decrypting every guest.
+-- Existing rows are filled in by `make db-encrypt-guests`.
+ALTER TABLE guests ADD COLUMN IF NOT EXISTS match_index TEXT[];
+
+CREATE INDEX IF NOT EXISTS idx_guests_match_index
+    ON guests USING GIN (match_index);
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKeyRing signs access tokens with server-held Ed25519 keys. Every token names the
+// key that signed it in its "kid" header; all configured keys verify, only the active one
+// signs, so a new key can be published before it takes over and an old one kept until the
+// tokens it signed have expired.
+type SigningKeyRing struct {
+	keys   map[string]ed25519.PrivateKey
+	active string
+}
+
+// NewSigningKeyRing builds a key ring from 32-byte Ed25519 seeds indexed by version.
+func NewSigningKeyRing(seeds map[int][]byte, active int) (*SigningKeyRing, error) {
+	if len(seeds) == 0 {
+		return nil, errors.New("auth: at least one signing key is required")
+	}
+
+	ring := &SigningKeyRing{keys: make(map[string]ed25519.PrivateKey, len(seeds)), active: strconv.Itoa(active)}
+	for version, seed := range seeds {
+		if version <= 0 {
+			return nil, fmt.Errorf("auth: invalid signing key version %d", version)
+		}
+		if len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("auth: signing key %d must be %d bytes", version, ed25519.SeedSize)
+		}
+		ring.keys[strconv.Itoa(version)] = ed25519.NewKeyFromSeed(seed)
+	}
+	if _, ok := ring.keys[ring.active]; !ok {
+		return nil, fmt.Errorf("auth: active signing key %d is not configured", active)
+	}
+	return ring, nil
+}
+
+// NewSigningKeyRingFromEnv reads JWT_SIGNING_KEYS ("1:<base64 seed>,2:<base64 seed>") and
+// JWT_ACTIVE_KEY. The active key defaults to the highest version.
+func NewSigningKeyRingFromEnv() (*SigningKeyRing, error) {
+	raw := os.Getenv("JWT_SIGNING_KEYS")
+	if raw == "" {
+		return nil, errors.New("auth: JWT_SIGNING_KEYS is not set")
+	}
+
+	seeds := map[int][]byte{}
+	active := 0
+	for _, entry := range strings.Split(raw, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("auth: JWT_SIGNING_KEYS entries must look like <version>:<base64 seed>")
+		}
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("auth: invalid signing key version %q", parts[0])
+		}
+		seed, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("auth: signing key %d is not valid base64", version)
+		}
+		seeds[version] = seed
+		active = max(active, version)
+	}
+
+	if v := os.Getenv("JWT_ACTIVE_KEY"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("auth: invalid JWT_ACTIVE_KEY %q", v)
+		}
+		active = version
+	}
+	return NewSigningKeyRing(seeds, active)
+}
+
+// ActiveKeyID is the "kid" new tokens are signed with.
+func (k *SigningKeyRing) ActiveKeyID() string {
+	return k.active
+}
+
+func (k *SigningKeyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = k.active
+	return token.SignedString(k.keys[k.active])
+}
+
+func (k *SigningKeyRing) verify(tokenString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key.Public(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}))
+	if err != nil || !token.Valid {
+		return errors.New("invalid token signature")
+	}
+	return nil
+}
+
+// JWK is the public half of a signing key in JSON Web Key form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS publishes every configured key, so other services keep verifying tokens across a rotation.
+func (k *SigningKeyRing) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(k.keys))}
+	for kid, key := range k.keys {
+		set.Keys = append(set.Keys, JWK{
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+			KeyID:     kid,
+			Use:       "sig",
+			Algorithm: jwt.SigningMethodEdDSA.Alg(),
+		})
+	}
+	sort.Slice(set.Keys, func(i, j int) bool {
+		a, _ := strconv.Atoi(set.Keys[i].KeyID)
+		b, _ := strconv.Atoi(set.Keys[j].KeyID)
+		return a < b
+	})
+	return set
+}
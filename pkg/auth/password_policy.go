@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxPasswordBytes is bcrypt's input limit; longer passwords would be rejected when hashed.
+const maxPasswordBytes = 72
+
+// PasswordPolicy is what a new password must satisfy.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy only asks for a minimum length.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: 8}
+}
+
+// NewPasswordPolicyFromEnv reads PASSWORD_MIN_LENGTH and PASSWORD_REQUIRE, a comma
+// separated list of the character classes every password needs ("upper,lower,digit,symbol").
+func NewPasswordPolicyFromEnv() (PasswordPolicy, error) {
+	policy := DefaultPasswordPolicy()
+
+	if v := os.Getenv("PASSWORD_MIN_LENGTH"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPasswordBytes {
+			return policy, fmt.Errorf("auth: PASSWORD_MIN_LENGTH must be between 1 and %d", maxPasswordBytes)
+		}
+		policy.MinLength = n
+	}
+
+	if v := os.Getenv("PASSWORD_REQUIRE"); v != "" {
+		for _, class := range strings.Split(v, ",") {
+			switch strings.TrimSpace(class) {
+			case "upper":
+				policy.RequireUpper = true
+			case "lower":
+				policy.RequireLower = true
+			case "digit":
+				policy.RequireDigit = true
+			case "symbol":
+				policy.RequireSymbol = true
+			case "":
+			default:
+				return policy, fmt.Errorf("auth: unknown PASSWORD_REQUIRE class %q", class)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// Validate explains the first rule password breaks, or returns nil.
+func (p PasswordPolicy) Validate(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("password must have at least %d characters", p.MinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("password must not exceed %d bytes", maxPasswordBytes)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	var missing []string
+	if p.RequireUpper && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return errors.New("password must contain " + strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"time"
@@ -11,15 +12,32 @@ import (
 )
 
 const (
-	PurposeAuth  = "auth"
-	PurposeReset = "reset"
+	PurposeAuth        = "auth"
+	PurposeReset       = "reset"
+	PurposeGuestAccess = "guest_access"
+	PurposeVerifyEmail = "verify_email"
+	PurposeInvitation  = "invitation"
+	PurposeTwoFactor   = "two_factor"
 )
 
+// AccessTokenTTL keeps access tokens short-lived; clients renew them with a refresh token.
+const AccessTokenTTL = 15 * time.Minute
+
 type Claims struct {
 	UserID         string `json:"user_id"`
 	OrganizationID string `json:"organization_id"`
 	Role           string `json:"role"`
 	Purpose        string `json:"purpose"`
+	SessionID      string `json:"session_id,omitempty"`
+	// TokenVersion must match the user's current version; bumping it revokes every token.
+	TokenVersion int `json:"token_version,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GuestAccessClaims grant a guest self-service access to a single reservation.
+type GuestAccessClaims struct {
+	ReservationID string `json:"reservation_id"`
+	Purpose       string `json:"purpose"`
 	jwt.RegisteredClaims
 }
 
@@ -41,19 +59,84 @@ func CheckPassword(passwordRaw, passwordHash string) bool {
 	return err == nil
 }
 
-func GenerateToken(userID, organizationID, role, userSalt string) (string, error) {
+// GenerateToken issues an access token signed by the key ring's active key. It is bound to
+// the server-side session it was issued for and to the user's token version, so revoking
+// either cuts it off.
+func GenerateToken(keys *SigningKeyRing, userID, organizationID, role, sessionID string, tokenVersion int) (string, error) {
 	claims := Claims{
 		UserID:         userID,
 		OrganizationID: organizationID,
 		Role:           role,
 		Purpose:        PurposeAuth,
+		SessionID:      sessionID,
+		TokenVersion:   tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(userSalt))
+	return keys.sign(claims)
+}
+
+// ValidateAccessToken checks an access token's signature against the key ring before any
+// of its claims are trusted.
+func ValidateAccessToken(keys *SigningKeyRing, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	if err := keys.verify(tokenString, claims); err != nil {
+		return nil, err
+	}
+	if claims.Purpose != PurposeAuth {
+		return nil, errors.New("invalid token purpose")
+	}
+	return claims, nil
+}
+
+// TwoFactorChallengeTTL is how long a user has to enter their second factor after the password.
+const TwoFactorChallengeTTL = 5 * time.Minute
+
+// GenerateTwoFactorChallenge issues the token a password login returns when a second factor
+// is still owed. It names the organization the login asked for and is bound to the user's
+// token version, so a password change voids pending challenges.
+func GenerateTwoFactorChallenge(keys *SigningKeyRing, userID, organizationID string, tokenVersion int) (string, error) {
+	claims := Claims{
+		UserID:         userID,
+		OrganizationID: organizationID,
+		Role:           "none",
+		Purpose:        PurposeTwoFactor,
+		TokenVersion:   tokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TwoFactorChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return keys.sign(claims)
+}
+
+func ValidateTwoFactorChallenge(keys *SigningKeyRing, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	if err := keys.verify(tokenString, claims); err != nil {
+		return nil, err
+	}
+	if claims.Purpose != PurposeTwoFactor {
+		return nil, errors.New("invalid token purpose")
+	}
+	return claims, nil
+}
+
+// GenerateRefreshToken returns an opaque refresh token and the hash to store for it.
+func GenerateRefreshToken() (string, string, error) {
+	token, err := GenerateRandomSalt()
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashRefreshToken(token), nil
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 func GenerateResetToken(userID, userSalt string) (string, error) {
@@ -70,6 +153,21 @@ func GenerateResetToken(userID, userSalt string) (string, error) {
 	return token.SignedString([]byte(userSalt))
 }
 
+// GenerateEmailVerificationToken signs the link that activates a self-registered account.
+func GenerateEmailVerificationToken(userID, userSalt string) (string, error) {
+	claims := Claims{
+		UserID:  userID,
+		Role:    "none",
+		Purpose: PurposeVerifyEmail,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(48 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(userSalt))
+}
+
 func ParseTokenClaimsUnsafe(tokenString string) (*Claims, error) {
 	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &Claims{})
 	if err != nil {
@@ -85,10 +183,93 @@ func ValidateSignature(tokenString, userSalt string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		return []byte(userSalt), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil || !token.Valid {
 		return nil, errors.New("invalid token signature")
 	}
 	return claims, nil
 }
+
+// GenerateGuestAccessToken signs a guest access token with the reservation's own secret, so
+// rotating that secret revokes every link issued for the booking.
+func GenerateGuestAccessToken(reservationID, secret string, expiresAt time.Time) (string, error) {
+	claims := GuestAccessClaims{
+		ReservationID: reservationID,
+		Purpose:       PurposeGuestAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+func ParseGuestAccessClaimsUnsafe(tokenString string) (*GuestAccessClaims, error) {
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &GuestAccessClaims{})
+	if err != nil {
+		return nil, err
+	}
+	if claims, ok := token.Claims.(*GuestAccessClaims); ok {
+		return claims, nil
+	}
+	return nil, errors.New("invalid claims structure")
+}
+
+func ValidateGuestAccessToken(tokenString, secret string) (*GuestAccessClaims, error) {
+	claims := &GuestAccessClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	if err != nil || !token.Valid || claims.Purpose != PurposeGuestAccess {
+		return nil, errors.New("invalid guest access token")
+	}
+	return claims, nil
+}
+
+// InvitationClaims identify the organization invitation a link was issued for.
+type InvitationClaims struct {
+	InvitationID string `json:"invitation_id"`
+	Purpose      string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// GenerateInvitationToken signs an invitation link with the invitation's own secret, so
+// rotating that secret on resend invalidates the links sent before.
+func GenerateInvitationToken(invitationID, secret string, expiresAt time.Time) (string, error) {
+	claims := InvitationClaims{
+		InvitationID: invitationID,
+		Purpose:      PurposeInvitation,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+func ParseInvitationClaimsUnsafe(tokenString string) (*InvitationClaims, error) {
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &InvitationClaims{})
+	if err != nil {
+		return nil, err
+	}
+	if claims, ok := token.Claims.(*InvitationClaims); ok {
+		return claims, nil
+	}
+	return nil, errors.New("invalid claims structure")
+}
+
+func ValidateInvitationToken(tokenString, secret string) (*InvitationClaims, error) {
+	claims := &InvitationClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	if err != nil || !token.Valid || claims.Purpose != PurposeInvitation {
+		return nil, errors.New("invalid invitation token")
+	}
+	return claims, nil
+}
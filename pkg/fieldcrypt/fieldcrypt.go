@@ -0,0 +1,157 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrMalformedCiphertext = errors.New("malformed ciphertext")
+
+// KeyRing encrypts individual fields with AES-256-GCM. Every ciphertext carries the version
+// of the key that produced it ("v2:<base64>"), so retired keys keep decrypting old values
+// until they have been rotated away.
+type KeyRing struct {
+	keys     map[int]cipher.AEAD
+	active   int
+	indexKey []byte
+}
+
+// NewKeyRing builds a key ring from 32-byte AES keys indexed by version. The index key
+// feeds the blind index and must stay stable: changing it invalidates every stored index.
+func NewKeyRing(keys map[int][]byte, active int, indexKey []byte) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("fieldcrypt: at least one key is required")
+	}
+	if len(indexKey) < 32 {
+		return nil, errors.New("fieldcrypt: index key must be at least 32 bytes")
+	}
+
+	ring := &KeyRing{keys: make(map[int]cipher.AEAD, len(keys)), active: active, indexKey: indexKey}
+	for version, key := range keys {
+		if version <= 0 {
+			return nil, fmt.Errorf("fieldcrypt: invalid key version %d", version)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("fieldcrypt: key %d must be 32 bytes", version)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		ring.keys[version] = aead
+	}
+	if _, ok := ring.keys[active]; !ok {
+		return nil, fmt.Errorf("fieldcrypt: active key %d is not configured", active)
+	}
+	return ring, nil
+}
+
+// NewKeyRingFromEnv reads GUEST_PII_KEYS ("1:<base64>,2:<base64>"), GUEST_PII_INDEX_KEY
+// (base64) and GUEST_PII_ACTIVE_KEY. The active key defaults to the highest version.
+func NewKeyRingFromEnv() (*KeyRing, error) {
+	raw := os.Getenv("GUEST_PII_KEYS")
+	if raw == "" {
+		return nil, errors.New("fieldcrypt: GUEST_PII_KEYS is not set")
+	}
+
+	keys := map[int][]byte{}
+	active := 0
+	for _, entry := range strings.Split(raw, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("fieldcrypt: GUEST_PII_KEYS entries must look like <version>:<base64 key>")
+		}
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: invalid key version %q", parts[0])
+		}
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: key %d is not valid base64", version)
+		}
+		keys[version] = key
+		active = max(active, version)
+	}
+
+	if v := os.Getenv("GUEST_PII_ACTIVE_KEY"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: invalid GUEST_PII_ACTIVE_KEY %q", v)
+		}
+		active = version
+	}
+
+	indexKey, err := base64.StdEncoding.DecodeString(os.Getenv("GUEST_PII_INDEX_KEY"))
+	if err != nil {
+		return nil, errors.New("fieldcrypt: GUEST_PII_INDEX_KEY is not valid base64")
+	}
+	return NewKeyRing(keys, active, indexKey)
+}
+
+func (k *KeyRing) ActiveVersion() int {
+	return k.active
+}
+
+// Encrypt seals plaintext with the active key. Empty values stay empty.
+func (k *KeyRing) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	aead := k.keys[k.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return fmt.Sprintf("v%d:%s", k.active, base64.RawStdEncoding.EncodeToString(sealed)), nil
+}
+
+// Decrypt opens a value produced by Encrypt with whichever key version sealed it.
+func (k *KeyRing) Decrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	prefix, payload, ok := strings.Cut(value, ":")
+	if !ok || !strings.HasPrefix(prefix, "v") {
+		return "", ErrMalformedCiphertext
+	}
+	version, err := strconv.Atoi(prefix[1:])
+	if err != nil {
+		return "", ErrMalformedCiphertext
+	}
+	aead, ok := k.keys[version]
+	if !ok {
+		return "", fmt.Errorf("fieldcrypt: key %d is not configured", version)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrMalformedCiphertext
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex returns a keyed hash of the normalized value, allowing equality lookups on
+// encrypted columns without revealing the value.
+func (k *KeyRing) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return hex.EncodeToString(mac.Sum(nil))
+}
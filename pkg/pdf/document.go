@@ -0,0 +1,127 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pageWidth    = 595 // A4 in points
+	pageHeight   = 842
+	marginLeft   = 50
+	marginTop    = 60
+	marginBottom = 60
+	fontSize     = 10
+	lineHeight   = 14
+)
+
+// Document is a minimal text-only PDF writer. It lays out monospaced lines on A4 pages
+// using the built-in Courier font, so no font files or external tools are required.
+type Document struct {
+	lines []string
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+func (d *Document) AddLine(text string) {
+	d.lines = append(d.lines, text)
+}
+
+func (d *Document) AddText(text string) {
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		d.AddLine(line)
+	}
+}
+
+func (d *Document) Bytes() []byte {
+	linesPerPage := (pageHeight - marginTop - marginBottom) / lineHeight
+
+	var pages [][]string
+	for start := 0; start < len(d.lines); start += linesPerPage {
+		end := start + linesPerPage
+		if end > len(d.lines) {
+			end = len(d.lines)
+		}
+		pages = append(pages, d.lines[start:end])
+	}
+	if len(pages) == 0 {
+		pages = append(pages, nil)
+	}
+
+	// Object layout: 1 catalog, 2 pages tree, 3 font, then (page, content) pairs.
+	var objects []string
+	objects = append(objects, "<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, pageLines := range pages {
+		contentRef := 5 + i*2
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, contentRef,
+		))
+
+		var stream bytes.Buffer
+		stream.WriteString("BT\n")
+		fmt.Fprintf(&stream, "/F1 %d Tf\n%d TL\n", fontSize, lineHeight)
+		fmt.Fprintf(&stream, "%d %d Td\n", marginLeft, pageHeight-marginTop)
+		for _, line := range pageLines {
+			stream.WriteString("(")
+			stream.Write(escape(line))
+			stream.WriteString(") Tj T*\n")
+		}
+		stream.WriteString("ET")
+
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", stream.Len(), stream.String()))
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n", len(objects)+1)
+	out.WriteString("0000000000 65535 f \n")
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return out.Bytes()
+}
+
+// escape converts text to WinAnsi (Latin-1 subset) and escapes PDF string delimiters.
+func escape(text string) []byte {
+	var b bytes.Buffer
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString("    ")
+		case r < 32:
+			continue
+		case r < 128:
+			b.WriteRune(r)
+		case r >= 160 && r < 256:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.Bytes()
+}
@@ -72,8 +72,8 @@ VALUES ('018e9a9d-0c8e-7000-0000-000000000009', '018e9a9d-0c8e-7000-0000-0000000
 INSERT INTO price_rules (id, room_type_id, validity_range, price, created_at, updated_at)
 VALUES ('018e9a9d-0c8e-7000-0000-000000000006', '018e9a9d-0c8e-7000-0000-000000000005', '[2025-01-01, 2025-12-31)', 250.00, NOW(), NOW());
 
-INSERT INTO guests (id, email, first_name, last_name, phone, created_at, updated_at) 
-VALUES ('018e9a9d-0c8e-7000-0000-000000000007', 'leomessi@mail.com', 'Lionel', 'Messi', '10101010', NOW(), NOW());
+INSERT INTO guests (id, organization_id, email, first_name, last_name, phone, created_at, updated_at) 
+VALUES ('018e9a9d-0c8e-7000-0000-000000000007', '018e9a9d-0c8e-7000-0000-000000000001', 'leomessi@mail.com', 'Lionel', 'Messi', '10101010', NOW(), NOW());
 
 INSERT INTO reservations (id, reservation_code, room_type_id, rate_plan_id, guest_id, stay_range, total_price, status, adults, children, created_at, updated_at)
 VALUES ('018e9a9d-0c8e-7000-0000-000000000008', 'MIA-OCN-SEED', '018e9a9d-0c8e-7000-0000-000000000005', '018e9a9d-0c8e-7000-0000-000000000009', '018e9a9d-0c8e-7000-0000-000000000007', '[2025-06-10, 2025-06-15)', 1250.00, 'confirmed', 2, 2, NOW(), NOW());
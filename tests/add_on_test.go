@@ -0,0 +1,223 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type AddOnSuite struct {
+	BaseSuite
+	token      string
+	propertyID string
+	unitTypeID string
+	parkingID  string
+	transferID string
+}
+
+func (s *AddOnSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	superToken := s.GetSuperAdminToken()
+	var orgID string
+	s.token, orgID = s.GetAdminTokenAndOrg()
+
+	resH := s.MakeRequest("POST", "/api/v1/properties", map[string]interface{}{
+		"organization_id": orgID, "name": "Add-on Hotel", "code": "ADD", "type": "HOTEL",
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resH.Code)
+	var dataH map[string]string
+	json.Unmarshal(resH.Body.Bytes(), &dataH)
+	s.propertyID = dataH["property_id"]
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": s.propertyID, "name": "Std", "code": "STD",
+		"total_quantity": 5, "base_price": 100.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resU.Code)
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+	s.unitTypeID = dataU["unit_type_id"]
+
+	limit := 1
+	s.parkingID = s.offerService(superToken, "Parking", 10, entity.PricingPerNight, &limit)
+	s.transferID = s.offerService(superToken, "Airport Transfer", 25, entity.PricingPerPerson, nil)
+}
+
+func (s *AddOnSuite) offerService(superToken, name string, price float64, unit entity.PricingUnit, dailyLimit *int) string {
+	resS := s.MakeRequest("POST", "/api/v1/services", map[string]string{"name": name}, superToken)
+	s.Require().Equal(http.StatusCreated, resS.Code, resS.Body.String())
+	var dataS map[string]string
+	json.Unmarshal(resS.Body.Bytes(), &dataS)
+
+	res := s.MakeRequest("POST", "/api/v1/property-services", map[string]interface{}{
+		"property_id": s.propertyID, "service_id": dataS["id"],
+		"price": price, "pricing_unit": unit, "daily_limit": dailyLimit,
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	return data["property_service_id"]
+}
+
+func (s *AddOnSuite) book(email, start, end string, addOns []map[string]interface{}) *httptest.ResponseRecorder {
+	return s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+		"unit_type_id":     s.unitTypeID,
+		"guest_email":      email,
+		"guest_first_name": "Ada", "guest_last_name": "Addon",
+		"start":            start, "end": end,
+		"adults":           2, "children": 0,
+		"add_ons":          addOns,
+	}, "")
+}
+
+func (s *AddOnSuite) reservation(access entity.GuestAccess) entity.Reservation {
+	res := s.MakeGuestRequest("GET", "/api/v1/reservations/"+access.ReservationCode, nil, access.AccessToken)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	var reservation entity.Reservation
+	json.Unmarshal(res.Body.Bytes(), &reservation)
+	return reservation
+}
+
+func (s *AddOnSuite) TestOfferValidation() {
+	resList := s.MakeRequest("GET", "/api/v1/properties/"+s.propertyID+"/add-ons", nil, "")
+	s.Require().Equal(http.StatusOK, resList.Code)
+	var offers []entity.PropertyService
+	json.Unmarshal(resList.Body.Bytes(), &offers)
+	s.Len(offers, 2)
+
+	resBad := s.MakeRequest("POST", "/api/v1/property-services", map[string]interface{}{
+		"property_id": s.propertyID, "service_id": offers[0].ServiceID,
+		"price": 5.0, "pricing_unit": "per_week",
+	}, s.token)
+	s.Equal(http.StatusBadRequest, resBad.Code)
+
+	resDup := s.MakeRequest("POST", "/api/v1/property-services", map[string]interface{}{
+		"property_id": s.propertyID, "service_id": offers[0].ServiceID,
+		"price": 5.0, "pricing_unit": entity.PricingPerStay,
+	}, s.token)
+	s.Equal(http.StatusConflict, resDup.Code)
+
+	resUpd := s.MakeRequest("PUT", "/api/v1/property-services/"+s.transferID, map[string]interface{}{"active": false}, s.token)
+	s.Require().Equal(http.StatusOK, resUpd.Code)
+
+	resList = s.MakeRequest("GET", "/api/v1/properties/"+s.propertyID+"/add-ons", nil, "")
+	json.Unmarshal(resList.Body.Bytes(), &offers)
+	s.Len(offers, 1, "withdrawn services are not offered to guests")
+
+	resBook := s.book("late@test.com", "2027-05-01", "2027-05-03", []map[string]interface{}{
+		{"property_service_id": s.transferID, "quantity": 1},
+	})
+	s.Equal(http.StatusBadRequest, resBook.Code)
+}
+
+func (s *AddOnSuite) TestBookingAddOnsPostToTotal() {
+	resBook := s.book("ada@test.com", "2027-05-01", "2027-05-03", []map[string]interface{}{
+		{"property_service_id": s.parkingID, "quantity": 1},
+		{"property_service_id": s.transferID, "quantity": 1},
+	})
+	s.Require().Equal(http.StatusCreated, resBook.Code, resBook.Body.String())
+	var access entity.GuestAccess
+	json.Unmarshal(resBook.Body.Bytes(), &access)
+
+	// 2 nights at 100, parking 10 per night, transfer 25 per person for 2 adults.
+	reservation := s.reservation(access)
+	s.Equal(270.0, reservation.TotalPrice)
+	s.Len(reservation.AddOns, 2)
+
+	resModify := s.MakeRequest("PUT", "/api/v1/reservations/"+access.ReservationID, map[string]interface{}{
+		"end": "2027-05-04",
+	}, s.token)
+	s.Require().Equal(http.StatusOK, resModify.Code, resModify.Body.String())
+	s.Equal(380.0, s.reservation(access).TotalPrice, "nightly add-ons follow the new stay")
+
+	var transfer entity.ReservationAddOn
+	for _, addOn := range reservation.AddOns {
+		if addOn.PropertyServiceID == s.transferID {
+			transfer = addOn
+		}
+	}
+	resRemove := s.MakeRequest("DELETE", "/api/v1/reservations/"+access.ReservationID+"/add-ons/"+transfer.ID, nil, s.token)
+	s.Require().Equal(http.StatusOK, resRemove.Code, resRemove.Body.String())
+	s.Equal(330.0, s.reservation(access).TotalPrice)
+
+	resCheckOut := s.MakeRequest("POST", "/api/v1/reservations/"+access.ReservationID+"/check-out", nil, s.token)
+	s.Require().Equal(http.StatusCreated, resCheckOut.Code, resCheckOut.Body.String())
+	var invoice entity.Invoice
+	json.Unmarshal(resCheckOut.Body.Bytes(), &invoice)
+	s.Require().Len(invoice.Lines, 2)
+	s.Equal(300.0, invoice.Lines[0].Total)
+	s.Equal("Parking", invoice.Lines[1].Description)
+	s.Equal(30.0, invoice.Lines[1].Total)
+	s.Equal(330.0, invoice.Total)
+}
+
+func (s *AddOnSuite) TestDailyLimit() {
+	parking := []map[string]interface{}{{"property_service_id": s.parkingID, "quantity": 1}}
+
+	resFirst := s.book("first@test.com", "2027-05-01", "2027-05-03", parking)
+	s.Require().Equal(http.StatusCreated, resFirst.Code, resFirst.Body.String())
+	var first entity.GuestAccess
+	json.Unmarshal(resFirst.Body.Bytes(), &first)
+
+	resSoldOut := s.book("second@test.com", "2027-05-02", "2027-05-04", parking)
+	s.Equal(http.StatusConflict, resSoldOut.Code)
+
+	resAfter := s.book("after@test.com", "2027-05-03", "2027-05-05", parking)
+	s.Equal(http.StatusCreated, resAfter.Code, "the spot is free again from the first guest's check-out day")
+
+	resSecond := s.book("second@test.com", "2027-05-02", "2027-05-03", nil)
+	s.Require().Equal(http.StatusCreated, resSecond.Code, resSecond.Body.String())
+	var second entity.GuestAccess
+	json.Unmarshal(resSecond.Body.Bytes(), &second)
+
+	offers := s.guestOffers(second.AccessToken)
+	s.False(offers[s.parkingID].Available)
+	s.True(offers[s.transferID].Available)
+	s.Equal(50.0, offers[s.transferID].Total)
+
+	s.Equal(http.StatusOK, s.MakeGuestRequest("POST", "/api/v1/reservations/"+first.ReservationID+"/cancel", nil, first.AccessToken).Code)
+
+	s.True(s.guestOffers(second.AccessToken)[s.parkingID].Available)
+	resAdd := s.guestPortalRequest("POST", "/api/v1/guest/reservation/add-ons", map[string]interface{}{
+		"property_service_id": s.parkingID, "quantity": 1,
+	}, second.AccessToken)
+	s.Require().Equal(http.StatusCreated, resAdd.Code, resAdd.Body.String())
+	var updated entity.Reservation
+	json.Unmarshal(resAdd.Body.Bytes(), &updated)
+	s.Equal(110.0, updated.TotalPrice)
+	s.Len(updated.AddOns, 1)
+}
+
+func (s *AddOnSuite) guestOffers(guestToken string) map[string]entity.AddOnOffer {
+	res := s.guestPortalRequest("GET", "/api/v1/guest/reservation/add-ons", nil, guestToken)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	var list []entity.AddOnOffer
+	json.Unmarshal(res.Body.Bytes(), &list)
+	offers := map[string]entity.AddOnOffer{}
+	for _, offer := range list {
+		offers[offer.ID] = offer
+	}
+	return offers
+}
+
+func (s *AddOnSuite) guestPortalRequest(method, url string, body interface{}, guestToken string) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, url, bytes.NewReader(jsonBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Guest-Token", guestToken)
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAddOnSuite(t *testing.T) {
+	suite.Run(t, new(AddOnSuite))
+}
@@ -2,11 +2,15 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
 	"github.com/ecelayes/pms-backend/pkg/auth"
 )
 
@@ -97,6 +101,176 @@ func (s *AuthSuite) TestPasswordResetFlow() {
 	s.Equal(http.StatusUnauthorized, resReplay.Code, "El token usado no debería servir una segunda vez")
 }
 
+func (s *AuthSuite) TestMultipleOrganizations() {
+	ctx := context.Background()
+	userID, _ := uuid.NewV7()
+	hash, _ := auth.HashPassword("pass123")
+	salt, _ := auth.GenerateRandomSalt()
+	orgX, _ := uuid.NewV7()
+	orgY, _ := uuid.NewV7()
+
+	s.db.Exec(ctx, `INSERT INTO organizations (id, name, code, created_at, updated_at) VALUES ($1, 'Org X', 'ORGX', NOW(), NOW()), ($2, 'Org Y', 'ORGY', NOW(), NOW())`, orgX.String(), orgY.String())
+	s.db.Exec(ctx, `INSERT INTO users (id, email, password, salt, role, first_name, last_name, phone, created_at, updated_at) VALUES ($1, 'consultant@test.com', $2, $3, 'user', 'Con', 'Sultant', '1', NOW(), NOW())`, userID.String(), hash, salt)
+	s.db.Exec(ctx, `INSERT INTO organization_members (id, organization_id, user_id, role, created_at, updated_at) VALUES ($1, $2, $3, 'owner', NOW() - INTERVAL '1 day', NOW())`, uuid.NewString(), orgX.String(), userID.String())
+	s.db.Exec(ctx, `INSERT INTO organization_members (id, organization_id, user_id, role, created_at, updated_at) VALUES ($1, $2, $3, 'staff', NOW(), NOW())`, uuid.NewString(), orgY.String(), userID.String())
+
+	res := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": "consultant@test.com", "password": "pass123"}, "")
+	s.Require().Equal(http.StatusOK, res.Code)
+	var login entity.AuthResponse
+	json.Unmarshal(res.Body.Bytes(), &login)
+	s.Equal(orgX.String(), login.OrganizationID)
+	s.Require().Len(login.Organizations, 2)
+	s.Equal("owner", login.Organizations[0].Role)
+	s.Equal("staff", login.Organizations[1].Role)
+	tokenX := login.Token
+
+	resProp := s.MakeRequest("POST", "/api/v1/properties", map[string]string{"name": "X Hotel", "code": "XHT", "type": "HOTEL"}, tokenX)
+	s.Equal(http.StatusCreated, resProp.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{
+		"email": "consultant@test.com", "password": "pass123", "organization_id": orgY.String(),
+	}, "")
+	s.Require().Equal(http.StatusOK, res.Code)
+	var loginY entity.AuthResponse
+	json.Unmarshal(res.Body.Bytes(), &loginY)
+	s.Equal(orgY.String(), loginY.OrganizationID)
+	resForbidden := s.MakeRequest("POST", "/api/v1/properties", map[string]string{"name": "Y Hotel", "code": "YHT", "type": "HOTEL"}, loginY.Token)
+	s.Equal(http.StatusForbidden, resForbidden.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/auth/switch-organization", map[string]string{"organization_id": orgY.String()}, tokenX)
+	s.Require().Equal(http.StatusOK, res.Code)
+	var switched entity.AuthResponse
+	json.Unmarshal(res.Body.Bytes(), &switched)
+	s.Equal(orgY.String(), switched.OrganizationID)
+
+	resList := s.MakeRequest("GET", "/api/v1/properties", nil, switched.Token)
+	s.Equal(http.StatusOK, resList.Code)
+	var props entity.PaginatedResponse[entity.Property]
+	json.Unmarshal(resList.Body.Bytes(), &props)
+	s.Empty(props.Data)
+
+	res = s.MakeRequest("POST", "/api/v1/auth/switch-organization", map[string]string{"organization_id": uuid.NewString()}, tokenX)
+	s.Equal(http.StatusForbidden, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{
+		"email": "consultant@test.com", "password": "pass123", "organization_id": uuid.NewString(),
+	}, "")
+	s.Equal(http.StatusForbidden, res.Code)
+
+	s.db.Exec(ctx, `DELETE FROM organization_members WHERE user_id = $1 AND organization_id = $2`, userID.String(), orgX.String())
+	res = s.MakeRequest("GET", "/api/v1/properties", nil, tokenX)
+	s.Equal(http.StatusForbidden, res.Code)
+}
+
+func (s *AuthSuite) TestOwnerRegistration() {
+	ctx := context.Background()
+	email := "founder@test.com"
+	pass := "founder123"
+
+	resReg := s.MakeRequest("POST", "/api/v1/auth/register", map[string]string{
+		"email": email, "password": pass, "org_name": "Seaside Group",
+		"first_name": "Ana", "last_name": "Founder",
+	}, "")
+	s.Require().Equal(http.StatusAccepted, resReg.Code, resReg.Body.String())
+	dataReg := map[string]string{}
+	var userID, orgID string
+	s.Require().NoError(s.db.QueryRow(ctx,
+		`SELECT u.id, om.organization_id FROM users u JOIN organization_members om ON om.user_id = u.id WHERE u.email = $1`, email,
+	).Scan(&userID, &orgID))
+	dataReg["user_id"], dataReg["organization_id"] = userID, orgID
+
+	var queued int
+	s.db.QueryRow(ctx, `SELECT COUNT(*) FROM email_outbox WHERE recipient = $1`, email).Scan(&queued)
+	s.Equal(1, queued)
+
+	resLogin := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": email, "password": pass}, "")
+	s.Equal(http.StatusForbidden, resLogin.Code, "unverified accounts cannot log in")
+
+	resBad := s.MakeRequest("POST", "/api/v1/auth/verify-email", map[string]string{"token": "garbage"}, "")
+	s.Equal(http.StatusBadRequest, resBad.Code)
+
+	var salt string
+	s.Require().NoError(s.db.QueryRow(ctx, "SELECT salt FROM users WHERE id=$1", dataReg["user_id"]).Scan(&salt))
+	token, err := auth.GenerateEmailVerificationToken(dataReg["user_id"], salt)
+	s.Require().NoError(err)
+
+	resVerify := s.MakeRequest("POST", "/api/v1/auth/verify-email", map[string]string{"token": token}, "")
+	s.Require().Equal(http.StatusOK, resVerify.Code, resVerify.Body.String())
+
+	resLogin = s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": email, "password": pass}, "")
+	s.Require().Equal(http.StatusOK, resLogin.Code, resLogin.Body.String())
+	var login entity.AuthResponse
+	json.Unmarshal(resLogin.Body.Bytes(), &login)
+	s.Equal(dataReg["organization_id"], login.OrganizationID)
+	s.Require().Len(login.Organizations, 1)
+	s.Equal(entity.OrgRoleOwner, login.Organizations[0].Role)
+
+	resProp := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"name": "Seaside Inn", "code": "SSI", "type": "HOTEL",
+	}, login.Token)
+	s.Equal(http.StatusCreated, resProp.Code, resProp.Body.String())
+
+	resDupOrg := s.MakeRequest("POST", "/api/v1/auth/register", map[string]string{
+		"email": "copycat@test.com", "password": pass, "org_name": "  seaside GROUP ",
+		"first_name": "Copy", "last_name": "Cat",
+	}, "")
+	s.Equal(http.StatusConflict, resDupOrg.Code)
+
+	resDupEmail := s.MakeRequest("POST", "/api/v1/auth/register", map[string]string{
+		"email": email, "password": pass, "org_name": "Mountain Group",
+		"first_name": "Ana", "last_name": "Founder",
+	}, "")
+	s.Equal(resReg.Code, resDupEmail.Code, "a registered email is not revealed")
+	s.Equal(resReg.Body.String(), resDupEmail.Body.String())
+
+	var orgs int
+	s.db.QueryRow(ctx, `SELECT COUNT(*) FROM organizations WHERE name = 'Mountain Group'`).Scan(&orgs)
+	s.Zero(orgs, "a failed registration must not leave an organization behind")
+}
+
+func (s *AuthSuite) TestUnverifiedSignupsExpire() {
+	ctx := context.Background()
+	register := func(email, orgName string) {
+		res := s.MakeRequest("POST", "/api/v1/auth/register", map[string]string{
+			"email": email, "password": "squatter123", "org_name": orgName,
+			"first_name": "Sam", "last_name": "Squat",
+		}, "")
+		s.Require().Equal(http.StatusAccepted, res.Code, res.Body.String())
+	}
+	register("squatter@test.com", "Harbour Hotels")
+
+	res := s.MakeRequest("POST", "/api/v1/auth/register", map[string]string{
+		"email": "squatter@test.com", "password": "squatter123", "org_name": "Other Name",
+		"first_name": "Sam", "last_name": "Squat",
+	}, "")
+	s.Equal(http.StatusAccepted, res.Code)
+	var queued int
+	s.db.QueryRow(ctx, `SELECT COUNT(*) FROM email_outbox WHERE recipient = 'squatter@test.com'`).Scan(&queued)
+	s.Equal(2, queued, "registering again re-sends the verification email")
+
+	verifiedToken, _ := s.CreateOrgOwner("kept@test.com", "KEPT")
+	s.NotEmpty(verifiedToken)
+
+	users := repository.NewUserRepository(s.db, s.piiKeys)
+	deleted, err := users.DeleteUnverified(ctx, time.Now().Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Zero(deleted, "recent signups are left alone")
+
+	deleted, err = users.DeleteUnverified(ctx, time.Now().Add(time.Minute))
+	s.Require().NoError(err)
+	s.Equal(int64(1), deleted)
+
+	var orgs int
+	s.db.QueryRow(ctx, `SELECT COUNT(*) FROM organizations WHERE name = 'Harbour Hotels'`).Scan(&orgs)
+	s.Zero(orgs)
+	s.db.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE email = 'kept@test.com'`).Scan(&orgs)
+	s.Equal(1, orgs, "verified owners are kept")
+
+	register("squatter@test.com", "Harbour Hotels")
+	s.db.QueryRow(ctx, `SELECT COUNT(*) FROM organizations WHERE name = 'Harbour Hotels'`).Scan(&orgs)
+	s.Equal(1, orgs, "the email and the organization name are free again")
+}
+
 func TestAuthSuite(t *testing.T) {
 	suite.Run(t, new(AuthSuite))
 }
@@ -37,7 +37,6 @@ func (s *AvailabilitySuite) SetupTest() {
 		"total_quantity": 10,
 		"base_price":     100.0,
 		"max_occupancy":  2, "max_adults": 2, "max_children": 0,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	var dataR map[string]string
 	json.Unmarshal(resR.Body.Bytes(), &dataR)
@@ -88,6 +87,32 @@ func (s *AvailabilitySuite) TestAvailabilitySearch() {
 	}
 }
 
+func (s *AvailabilitySuite) TestAvailabilityIncludesAmenities() {
+	res := s.MakeRequest("POST", "/api/v1/amenities", map[string]interface{}{
+		"name": "Wifi", "icon": "wifi-icon",
+	}, s.GetSuperAdminToken())
+	s.Require().Equal(http.StatusCreated, res.Code)
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+
+	res = s.MakeRequest("PUT", "/api/v1/unit-types/"+s.unitTypeID, map[string]interface{}{
+		"amenity_ids": []string{data["id"]},
+	}, s.token)
+	s.Require().Equal(http.StatusOK, res.Code)
+
+	url := "/api/v1/availability?property_id=" + s.propertyID +
+		"&start=2025-06-02&end=2025-06-05&adults=2&children=0&rooms=1"
+	res = s.MakeRequest("GET", url, nil, "")
+	s.Require().Equal(http.StatusOK, res.Code)
+
+	var response entity.PaginatedResponse[entity.AvailabilitySearch]
+	json.Unmarshal(res.Body.Bytes(), &response)
+	s.Require().NotEmpty(response.Data)
+	s.Require().Len(response.Data[0].Amenities, 1)
+	s.Equal("Wifi", response.Data[0].Amenities[0].Name)
+	s.Equal("wifi-icon", response.Data[0].Amenities[0].Icon)
+}
+
 func (s *AvailabilitySuite) TestGlobalAvailabilitySearch() {
 	url := "/api/v1/availability?start=2025-06-02&end=2025-06-05&adults=2&children=0&rooms=1"
 	
@@ -116,7 +141,6 @@ func (s *AvailabilitySuite) TestAvailabilityPagination() {
 		"total_quantity": 5,
 		"base_price":     200.0,
 		"max_occupancy":  4, "max_adults": 4, "max_children": 2,
-		"amenities":      []string{"wifi", "jacuzzi"},
 	}, s.token)
 	var dataR2 map[string]string
 	json.Unmarshal(resR2.Body.Bytes(), &dataR2)
@@ -172,7 +196,6 @@ func (s *AvailabilitySuite) TestAvailabilitySoldOut() {
 		"total_quantity": 1,
 		"base_price":     100.0,
 		"max_occupancy":  2, "max_adults": 2, "max_children": 0,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	var dataR map[string]string
 	json.Unmarshal(resR.Body.Bytes(), &dataR)
@@ -248,7 +271,6 @@ func (s *AvailabilitySuite) TestAvailabilityMissingPrice() {
 		"total_quantity": 5,
 		"base_price":     0.0,
 		"max_occupancy":  2, "max_adults": 2, "max_children": 0,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	var dataR map[string]string
 	json.Unmarshal(resR.Body.Bytes(), &dataR)
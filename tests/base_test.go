@@ -5,21 +5,42 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/suite"
 	"github.com/ecelayes/pms-backend/internal/bootstrap"
+	"github.com/ecelayes/pms-backend/internal/entity"
 	"github.com/ecelayes/pms-backend/pkg/auth"
+	"github.com/ecelayes/pms-backend/pkg/fieldcrypt"
 )
 
+// Fixed guest PII and token signing keys so the suites run without extra configuration.
+const (
+	testPIIKeyV1     = "dGVzdC1ndWVzdC1waWkta2V5LXZlcnNpb24tb25lISE="
+	testPIIKeyV2     = "dGVzdC1ndWVzdC1waWkta2V5LXZlcnNpb24tdHdvISE="
+	testPIIIndexKey  = "dGVzdC1ndWVzdC1waWktYmxpbmQtaW5kZXgta2V5ISE="
+	testSigningKeyV1 = "dGVzdC1qd3Qtc2lnbmluZy1rZXktdmVyc2lvbi1vbmU="
+	testSigningKeyV2 = "dGVzdC1qd3Qtc2lnbmluZy1rZXktdmVyc2lvbi10d28="
+)
+
+// testPassword is the password of the organization owners created by CreateOrgOwner.
+const testPassword = "owner-password"
+
+var verifyLinkPattern = regexp.MustCompile(`verify-email\?token=([^"'<\s]+)`)
+
 type BaseSuite struct {
 	suite.Suite
-	echo *echo.Echo
-	db   *pgxpool.Pool
+	echo    *echo.Echo
+	db      *pgxpool.Pool
+	piiKeys *fieldcrypt.KeyRing
+	signups int
 }
 
 func (s *BaseSuite) SetupSuite() {
@@ -29,13 +50,26 @@ func (s *BaseSuite) SetupSuite() {
 	pool, err := pgxpool.New(ctx, dsn)
 	if err != nil { s.T().Fatal(err) }
 	s.db = pool
-	s.echo = bootstrap.NewApp(pool)
+
+	if os.Getenv("GUEST_PII_KEYS") == "" {
+		os.Setenv("GUEST_PII_KEYS", "1:"+testPIIKeyV1)
+		os.Setenv("GUEST_PII_INDEX_KEY", testPIIIndexKey)
+	}
+	keys, err := fieldcrypt.NewKeyRingFromEnv()
+	if err != nil { s.T().Fatal(err) }
+	s.piiKeys = keys
+	// Test receivers listen on 127.0.0.1.
+	os.Setenv("WEBHOOK_ALLOW_LOOPBACK", "true")
+	if os.Getenv("JWT_SIGNING_KEYS") == "" {
+		os.Setenv("JWT_SIGNING_KEYS", "1:"+testSigningKeyV1)
+	}
+	s.echo = bootstrap.NewApp(pool).Echo
 }
 
 func (s *BaseSuite) TearDownSuite() { s.db.Close() }
 
 func (s *BaseSuite) SetupTest() {
-	tables := []string{"reservations", "price_rules", "unit_types", "properties", "hotel_services", "amenities", "organization_members", "users", "organizations", "guests"}
+	tables := []string{"privacy_requests", "webhook_deliveries", "webhook_subscriptions", "email_outbox", "invoice_lines", "invoices", "invoice_series", "guest_merges", "guest_notes", "reservation_add_ons", "reservations", "property_services", "price_rules", "unit_type_amenities", "unit_types", "organization_invitations", "organization_member_properties", "properties", "hotel_services", "amenities", "organization_members", "user_sessions", "user_recovery_codes", "users", "organizations", "guests"}
 	for _, table := range tables {
 		s.db.Exec(context.Background(), fmt.Sprintf("TRUNCATE TABLE %s CASCADE", table))
 	}
@@ -59,23 +93,68 @@ func (s *BaseSuite) MakeRequest(method, url string, body interface{}, token stri
 	return rec
 }
 
+// MakeGuestRequest calls a guest self-service endpoint with a guest access token.
+func (s *BaseSuite) MakeGuestRequest(method, url string, body interface{}, guestToken string) *httptest.ResponseRecorder {
+	var bodyReader *bytes.Reader
+	if body != nil {
+		jsonBody, _ := json.Marshal(body)
+		bodyReader = bytes.NewReader(jsonBody)
+	} else {
+		bodyReader = bytes.NewReader([]byte{})
+	}
+	req := httptest.NewRequest(method, url, bodyReader)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if guestToken != "" {
+		req.Header.Set("X-Guest-Token", guestToken)
+	}
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	return rec
+}
+
 func (s *BaseSuite) GetAdminTokenAndOrg() (string, string) {
+	return s.CreateOrgOwner("owner@test.com", "TEST")
+}
+
+// CreateOrgOwner registers an organization through the signup flow, verifies the owner's email
+// and returns the owner's token and the organization id. Each call comes from its own client
+// address so signups are not throttled.
+func (s *BaseSuite) CreateOrgOwner(email, orgCode string) (string, string) {
 	ctx := context.Background()
-	orgID, _ := uuid.NewV7()
-	userID, _ := uuid.NewV7()
-	email := "owner@test.com"
-	pass := "pass"
-	hash, _ := auth.HashPassword(pass)
-	salt, _ := auth.GenerateRandomSalt()
+	s.signups++
+	clientIP := fmt.Sprintf("10.0.%d.%d", s.signups/250, s.signups%250+1)
 
-	s.db.Exec(ctx, `INSERT INTO organizations (id, name, code, created_at, updated_at) VALUES ($1, 'Test Corp', 'TEST', NOW(), NOW())`, orgID.String())
-	s.db.Exec(ctx, `INSERT INTO users (id, email, password, salt, role, created_at, updated_at) VALUES ($1, $2, $3, $4, 'owner', NOW(), NOW())`, userID.String(), email, hash, salt)
-	s.db.Exec(ctx, `INSERT INTO organization_members (id, organization_id, user_id, role, created_at, updated_at) VALUES ($1, $2, $3, 'owner', NOW(), NOW())`, uuid.NewString(), orgID.String(), userID.String())
+	res := s.requestFromClient(clientIP, "POST", "/api/v1/auth/register", map[string]string{
+		"email": email, "password": testPassword, "org_name": orgCode + " Corp",
+		"first_name": "Test", "last_name": "Owner",
+	})
+	s.Require().Less(res.Code, 300, res.Body.String())
 
-	res := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": email, "password": pass}, "")
-	var data map[string]string
-	json.Unmarshal(res.Body.Bytes(), &data)
-	return data["token"], orgID.String()
+	var body string
+	s.Require().NoError(s.db.QueryRow(ctx,
+		`SELECT body FROM email_outbox WHERE recipient = $1 ORDER BY created_at DESC LIMIT 1`, email,
+	).Scan(&body))
+	link := verifyLinkPattern.FindStringSubmatch(body)
+	s.Require().NotNil(link, "the verification email carries a link")
+	res = s.requestFromClient(clientIP, "POST", "/api/v1/auth/verify-email", map[string]string{"token": html.UnescapeString(link[1])})
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+
+	res = s.requestFromClient(clientIP, "POST", "/api/v1/auth/login", map[string]string{"email": email, "password": testPassword})
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	var login entity.AuthResponse
+	json.Unmarshal(res.Body.Bytes(), &login)
+	return login.Token, login.OrganizationID
+}
+
+// requestFromClient sends an unauthenticated request from the given client IP.
+func (s *BaseSuite) requestFromClient(clientIP, method, url string, body interface{}) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, url, bytes.NewReader(jsonBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.RemoteAddr = clientIP + ":40000"
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	return rec
 }
 
 func (s *BaseSuite) GetSuperAdminToken() string {
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -174,6 +175,92 @@ func (s *CatalogSuite) TestCatalogDuplicate() {
 	s.Equal(http.StatusConflict, res4.Code)
 }
 
+func (s *CatalogSuite) TestOrganizationCatalogEntries() {
+	createEntry := func(path, name, token string) (int, string) {
+		res := s.MakeRequest("POST", path, map[string]interface{}{"name": name, "icon": "icon"}, token)
+		var data map[string]string
+		json.Unmarshal(res.Body.Bytes(), &data)
+		return res.Code, data["id"]
+	}
+	listAmenities := func(token string) []entity.Amenity {
+		res := s.MakeRequest("GET", "/api/v1/amenities", nil, token)
+		s.Require().Equal(http.StatusOK, res.Code)
+		var list entity.PaginatedResponse[entity.Amenity]
+		json.Unmarshal(res.Body.Bytes(), &list)
+		return list.Data
+	}
+
+	tokenA, orgA := s.CreateOrgOwner("owner-a@test.com", "ORGA")
+	tokenB, _ := s.CreateOrgOwner("owner-b@test.com", "ORGB")
+
+	// Owners sign up as plain users: catalog rights come from the membership alone.
+	var accountRole string
+	s.Require().NoError(s.db.QueryRow(context.Background(), `SELECT role FROM users WHERE email = 'owner-a@test.com'`).Scan(&accountRole))
+	s.Equal(entity.RoleUser, accountRole)
+
+	code, wifiID := createEntry("/api/v1/amenities", "Wifi", s.superToken)
+	s.Require().Equal(http.StatusCreated, code)
+
+	code, rooftopA := createEntry("/api/v1/amenities", "Rooftop", tokenA)
+	s.Require().Equal(http.StatusCreated, code)
+	code, _ = createEntry("/api/v1/amenities", "Rooftop", tokenA)
+	s.Equal(http.StatusConflict, code)
+	code, _ = createEntry("/api/v1/amenities", "Wifi", tokenA)
+	s.Equal(http.StatusConflict, code, "organization entries cannot shadow global ones")
+	code, rooftopB := createEntry("/api/v1/amenities", "Rooftop", tokenB)
+	s.Equal(http.StatusCreated, code, "names only need to be unique within an organization")
+
+	listA := listAmenities(tokenA)
+	s.Require().Len(listA, 2)
+	s.Equal("Rooftop", listA[0].Name)
+	s.Require().NotNil(listA[0].OrganizationID)
+	s.Equal(orgA, *listA[0].OrganizationID)
+	s.Equal(wifiID, listA[1].ID)
+	s.Nil(listA[1].OrganizationID)
+
+	global := listAmenities(s.superToken)
+	s.Require().Len(global, 1)
+	s.Equal(wifiID, global[0].ID)
+
+	res := s.MakeRequest("GET", "/api/v1/amenities/"+rooftopB, nil, tokenA)
+	s.Equal(http.StatusNotFound, res.Code)
+	res = s.MakeRequest("PUT", "/api/v1/amenities/"+rooftopB, map[string]interface{}{"name": "Mine"}, tokenA)
+	s.Equal(http.StatusNotFound, res.Code)
+	res = s.MakeRequest("PUT", "/api/v1/amenities/"+wifiID, map[string]interface{}{"name": "Free Wifi"}, tokenA)
+	s.Equal(http.StatusForbidden, res.Code)
+	res = s.MakeRequest("PUT", "/api/v1/amenities/"+rooftopA, map[string]interface{}{"name": "Rooftop Terrace"}, tokenA)
+	s.Equal(http.StatusOK, res.Code)
+	res = s.MakeRequest("PUT", "/api/v1/amenities/"+rooftopA, map[string]interface{}{"name": "Wifi"}, tokenA)
+	s.Equal(http.StatusConflict, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"organization_id": orgA, "name": "Catalog Hotel", "code": "CAT", "type": "HOTEL",
+	}, tokenA)
+	s.Require().Equal(http.StatusCreated, res.Code)
+	var prop map[string]string
+	json.Unmarshal(res.Body.Bytes(), &prop)
+
+	unitType := map[string]interface{}{
+		"property_id": prop["property_id"], "name": "Loft", "code": "LFT",
+		"total_quantity": 1, "base_price": 100.0, "max_occupancy": 2, "max_adults": 2,
+		"amenity_ids": []string{wifiID, rooftopB},
+	}
+	res = s.MakeRequest("POST", "/api/v1/unit-types", unitType, tokenA)
+	s.Equal(http.StatusBadRequest, res.Code, "another organization's amenities cannot be used")
+	unitType["amenity_ids"] = []string{wifiID, rooftopA}
+	res = s.MakeRequest("POST", "/api/v1/unit-types", unitType, tokenA)
+	s.Equal(http.StatusCreated, res.Code)
+
+	code, serviceA := createEntry("/api/v1/services", "Boat Trip", tokenA)
+	s.Require().Equal(http.StatusCreated, code)
+	res = s.MakeRequest("GET", "/api/v1/services/"+serviceA, nil, tokenB)
+	s.Equal(http.StatusNotFound, res.Code)
+	res = s.MakeRequest("DELETE", "/api/v1/services/"+serviceA, nil, tokenB)
+	s.Equal(http.StatusNotFound, res.Code)
+	res = s.MakeRequest("DELETE", "/api/v1/services/"+serviceA, nil, tokenA)
+	s.Equal(http.StatusOK, res.Code)
+}
+
 func TestCatalogSuite(t *testing.T) {
 	suite.Run(t, new(CatalogSuite))
 }
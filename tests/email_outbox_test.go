@@ -0,0 +1,294 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/service"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type EmailOutboxSuite struct {
+	BaseSuite
+}
+
+func (s *EmailOutboxSuite) countQueued(recipient string) int {
+	var count int
+	err := s.db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM email_outbox WHERE recipient = $1`, recipient,
+	).Scan(&count)
+	s.Require().NoError(err)
+	return count
+}
+
+func (s *EmailOutboxSuite) subjectFor(recipient string) string {
+	var subject string
+	err := s.db.QueryRow(context.Background(),
+		`SELECT subject FROM email_outbox WHERE recipient = $1 ORDER BY created_at DESC LIMIT 1`, recipient,
+	).Scan(&subject)
+	s.Require().NoError(err)
+	return subject
+}
+
+func (s *EmailOutboxSuite) TestPasswordResetIsQueued() {
+	s.GetAdminTokenAndOrg()
+
+	res := s.MakeRequest("POST", "/api/v1/auth/forgot-password", map[string]string{
+		"email": "owner@test.com",
+	}, "")
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(1, s.countQueued("owner@test.com"))
+	s.Equal("Recuperación de Contraseña", s.subjectFor("owner@test.com"))
+
+	resUnknown := s.MakeRequest("POST", "/api/v1/auth/forgot-password", map[string]string{
+		"email": "nobody@test.com",
+	}, "")
+	s.Equal(http.StatusOK, resUnknown.Code)
+	s.Equal(0, s.countQueued("nobody@test.com"))
+}
+
+func (s *EmailOutboxSuite) TestReservationEmailsAreQueued() {
+	token, orgID := s.GetAdminTokenAndOrg()
+
+	resH := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"organization_id": orgID, "name": "Mail Property", "code": "MAIL", "type": "HOTEL",
+	}, token)
+	s.Require().Equal(http.StatusCreated, resH.Code)
+	var dataH map[string]string
+	json.Unmarshal(resH.Body.Bytes(), &dataH)
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": dataH["property_id"], "name": "Std", "code": "STD",
+		"total_quantity": 2, "base_price": 80.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, token)
+	s.Require().Equal(http.StatusCreated, resU.Code)
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+
+	res := s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+		"unit_type_id":     dataU["unit_type_id"],
+		"guest_email":      "mailguest@test.com",
+		"guest_first_name": "Mail", "guest_last_name": "Guest",
+		"start":            "2025-02-01", "end": "2025-02-03",
+		"adults":           1, "children": 0,
+	}, "")
+	s.Require().Equal(http.StatusCreated, res.Code)
+	s.Equal(1, s.countQueued("mailguest@test.com"))
+
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+data["reservation_code"], nil, data["access_token"])
+	var reservation entity.Reservation
+	json.Unmarshal(resGet.Body.Bytes(), &reservation)
+
+	resCancel := s.MakeGuestRequest("POST", "/api/v1/reservations/"+reservation.ID+"/cancel", nil, data["access_token"])
+	s.Equal(http.StatusOK, resCancel.Code)
+	s.Equal(2, s.countQueued("mailguest@test.com"))
+}
+
+func (s *EmailOutboxSuite) TestListFailedDeliveries() {
+	superToken := s.GetSuperAdminToken()
+
+	_, err := s.db.Exec(context.Background(), `
+		INSERT INTO email_outbox (id, recipient, subject, body, status, attempts, last_error)
+		VALUES ($1, 'bounced@test.com', 'Hello', '<p>hi</p>', 'failed', 8, 'mailbox unavailable')
+	`, uuid.NewString())
+	s.Require().NoError(err)
+
+	res := s.MakeRequest("GET", "/api/v1/emails/failed", nil, superToken)
+	s.Require().Equal(http.StatusOK, res.Code)
+
+	var page entity.PaginatedResponse[entity.OutboxEmail]
+	json.Unmarshal(res.Body.Bytes(), &page)
+	s.Require().Len(page.Data, 1)
+	s.Equal("bounced@test.com", page.Data[0].Recipient)
+	s.Equal(entity.EmailStatusFailed, page.Data[0].Status)
+	s.Require().NotNil(page.Data[0].LastError)
+
+	ownerToken, _ := s.GetAdminTokenAndOrg()
+	resForbidden := s.MakeRequest("GET", "/api/v1/emails/failed", nil, ownerToken)
+	s.Equal(http.StatusForbidden, resForbidden.Code)
+}
+
+func (s *EmailOutboxSuite) TestEmailLanguageResolution() {
+	token, orgID := s.GetAdminTokenAndOrg()
+
+	resH := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"organization_id": orgID, "name": "Beach House", "code": "BCH", "type": "HOTEL",
+		"default_language": "en",
+	}, token)
+	s.Require().Equal(http.StatusCreated, resH.Code)
+	var dataH map[string]string
+	json.Unmarshal(resH.Body.Bytes(), &dataH)
+
+	resBad := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"organization_id": orgID, "name": "Bad", "code": "BAD", "type": "HOTEL",
+		"default_language": "xx",
+	}, token)
+	s.Equal(http.StatusBadRequest, resBad.Code)
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": dataH["property_id"], "name": "Std", "code": "STD",
+		"total_quantity": 2, "base_price": 80.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, token)
+	s.Require().Equal(http.StatusCreated, resU.Code)
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+
+	book := func(email, lang string) {
+		res := s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+			"unit_type_id":     dataU["unit_type_id"],
+			"guest_email":      email,
+			"guest_first_name": "Lang", "guest_last_name": "Guest",
+			"guest_language":   lang,
+			"start":            "2025-03-01", "end": "2025-03-02",
+			"adults":           1, "children": 0,
+		}, "")
+		s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	}
+
+	book("default@test.com", "")
+	s.Contains(s.subjectFor("default@test.com"), "Booking confirmation")
+
+	book("spanish@test.com", "es")
+	s.Contains(s.subjectFor("spanish@test.com"), "Confirmación de reserva")
+}
+
+// failingSender rejects every message, like a mail server that is down.
+type failingSender struct{ calls int }
+
+func (f *failingSender) Send(ctx context.Context, msg entity.EmailMessage) error {
+	f.calls++
+	return errors.New("connection refused")
+}
+
+func (s *EmailOutboxSuite) deliverDue(sender service.EmailSender) int {
+	uc := usecase.NewEmailOutboxUseCase(s.db, repository.NewEmailOutboxRepository(s.db), sender, zap.NewNop())
+	sent, err := uc.DeliverDue(context.Background(), 50)
+	s.Require().NoError(err)
+	return sent
+}
+
+func (s *EmailOutboxSuite) queue(recipient string, maxAttempts int) string {
+	id := uuid.NewString()
+	_, err := s.db.Exec(context.Background(), `
+		INSERT INTO email_outbox (id, recipient, subject, body, max_attempts)
+		VALUES ($1, $2, 'Hello', '<p>hi</p>', $3)
+	`, id, recipient, maxAttempts)
+	s.Require().NoError(err)
+	return id
+}
+
+func (s *EmailOutboxSuite) TestDeliverySendsDueMessages() {
+	sink := filepath.Join(s.T().TempDir(), "emails.log")
+	id := s.queue("inbox@test.com", 8)
+
+	s.Equal(1, s.deliverDue(service.NewLogSender(sink, zap.NewNop())))
+
+	var status string
+	var attempts int
+	s.db.QueryRow(context.Background(), `SELECT status, attempts FROM email_outbox WHERE id = $1`, id).Scan(&status, &attempts)
+	s.Equal(entity.EmailStatusSent, status)
+	s.Equal(1, attempts)
+
+	written, err := os.ReadFile(sink)
+	s.Require().NoError(err)
+	s.Contains(string(written), "To: inbox@test.com")
+	s.Equal(0, s.deliverDue(service.NewLogSender(sink, zap.NewNop())), "sent messages are not sent again")
+}
+
+func (s *EmailOutboxSuite) TestFailedDeliveryBacksOffThenDeadLetters() {
+	ctx := context.Background()
+	id := s.queue("bounce@test.com", 3)
+	sender := &failingSender{}
+
+	var status string
+	var attempts int
+	var lastError *string
+	var nextAttempt time.Time
+	load := func() {
+		s.db.QueryRow(ctx, `SELECT status, attempts, last_error, next_attempt_at FROM email_outbox WHERE id = $1`, id).
+			Scan(&status, &attempts, &lastError, &nextAttempt)
+	}
+
+	s.Equal(0, s.deliverDue(sender))
+	load()
+	s.Equal(entity.EmailStatusPending, status)
+	s.Equal(1, attempts)
+	s.Require().NotNil(lastError)
+	s.Contains(*lastError, "connection refused")
+	firstDelay := time.Until(nextAttempt)
+	s.InDelta((30 * time.Second).Seconds(), firstDelay.Seconds(), 5)
+
+	s.Equal(0, s.deliverDue(sender), "retries wait for their turn")
+	s.Equal(1, sender.calls)
+
+	_, err := s.db.Exec(ctx, `UPDATE email_outbox SET next_attempt_at = NOW() WHERE id = $1`, id)
+	s.Require().NoError(err)
+	s.deliverDue(sender)
+	load()
+	s.Equal(2, attempts)
+	s.InDelta((60 * time.Second).Seconds(), time.Until(nextAttempt).Seconds(), 5, "the delay doubles")
+
+	_, err = s.db.Exec(ctx, `UPDATE email_outbox SET next_attempt_at = NOW() WHERE id = $1`, id)
+	s.Require().NoError(err)
+	s.deliverDue(sender)
+	load()
+	s.Equal(entity.EmailStatusFailed, status, "the last attempt dead-letters the message")
+	s.Equal(3, attempts)
+
+	_, err = s.db.Exec(ctx, `UPDATE email_outbox SET next_attempt_at = NOW() WHERE id = $1`, id)
+	s.Require().NoError(err)
+	s.deliverDue(sender)
+	s.Equal(3, sender.calls, "dead letters are left alone")
+}
+
+func TestEmailOutboxSuite(t *testing.T) {
+	suite.Run(t, new(EmailOutboxSuite))
+}
+
+// An SMTP server that accepts connections but never answers must not hold a send past its context.
+func TestSMTPSenderHonoursContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	sender := service.NewSMTPSender(host, port, "noreply@test.com", "secret")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	err = sender.Send(ctx, entity.EmailMessage{To: "guest@test.com", Subject: "Hi", Body: "<p>hi</p>"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to abort the send, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("send took %s after its deadline", elapsed)
+	}
+}
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type GuestAccessSuite struct {
+	BaseSuite
+	token      string
+	unitTypeID string
+}
+
+func (s *GuestAccessSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	var orgID string
+	s.token, orgID = s.GetAdminTokenAndOrg()
+
+	resH := s.MakeRequest("POST", "/api/v1/properties", map[string]interface{}{
+		"organization_id": orgID, "name": "Access Hotel", "code": "ACC", "type": "HOTEL",
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resH.Code)
+	var dataH map[string]string
+	json.Unmarshal(resH.Body.Bytes(), &dataH)
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": dataH["property_id"], "name": "Std", "code": "STD",
+		"total_quantity": 5, "base_price": 100.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resU.Code)
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+	s.unitTypeID = dataU["unit_type_id"]
+}
+
+// guestRequest sends a public request from the given client IP with an optional guest token.
+func (s *GuestAccessSuite) guestRequest(method, url string, body interface{}, guestToken, clientIP string) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, url, bytes.NewReader(jsonBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.RemoteAddr = clientIP + ":40000"
+	if guestToken != "" {
+		req.Header.Set("X-Guest-Token", guestToken)
+	}
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *GuestAccessSuite) book(email, lastName string) entity.GuestAccess {
+	res := s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+		"unit_type_id":     s.unitTypeID,
+		"guest_email":      email,
+		"guest_first_name": "Gina", "guest_last_name": lastName,
+		"start":            "2026-12-01", "end": "2026-12-03",
+		"adults":           1, "children": 0,
+	}, "")
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var access entity.GuestAccess
+	json.Unmarshal(res.Body.Bytes(), &access)
+	s.Require().NotEmpty(access.AccessToken)
+	return access
+}
+
+func (s *GuestAccessSuite) TestReadAndCancelRequireToken() {
+	const ip = "198.51.100.10"
+	mine := s.book("gina@test.com", "Access")
+	other := s.book("other@test.com", "Other")
+
+	s.Equal(http.StatusUnauthorized, s.guestRequest("GET", "/api/v1/reservations/"+mine.ReservationCode, nil, "", ip).Code)
+	s.Equal(http.StatusUnauthorized, s.guestRequest("GET", "/api/v1/reservations/"+mine.ReservationCode, nil, other.AccessToken, ip).Code)
+
+	resGet := s.guestRequest("GET", "/api/v1/reservations/"+mine.ReservationCode, nil, mine.AccessToken, ip)
+	s.Require().Equal(http.StatusOK, resGet.Code)
+	var reservation entity.Reservation
+	json.Unmarshal(resGet.Body.Bytes(), &reservation)
+	s.Equal(mine.ReservationID, reservation.ID)
+
+	s.Equal(http.StatusUnauthorized, s.guestRequest("POST", "/api/v1/reservations/"+mine.ReservationID+"/cancel", nil, "", ip).Code)
+	s.Equal(http.StatusUnauthorized, s.guestRequest("POST", "/api/v1/reservations/"+mine.ReservationID+"/cancel", nil, other.AccessToken, ip).Code)
+	s.Equal(http.StatusOK, s.guestRequest("POST", "/api/v1/reservations/"+mine.ReservationID+"/cancel", nil, mine.AccessToken, "198.51.100.11").Code)
+
+	// The emailed link carries a token for the booking.
+	var body string
+	s.Require().NoError(s.db.QueryRow(context.Background(),
+		`SELECT body FROM email_outbox WHERE guest_id = $1 ORDER BY created_at LIMIT 1`, reservation.GuestID,
+	).Scan(&body))
+	s.Contains(body, "/reservations/"+mine.ReservationCode+"?token=")
+}
+
+func (s *GuestAccessSuite) TestCodeAndLastNameVerification() {
+	const ip = "198.51.100.20"
+	booking := s.book("verify@test.com", "Verified")
+
+	resWrong := s.guestRequest("POST", "/api/v1/reservations/access", map[string]string{
+		"reservation_code": booking.ReservationCode, "last_name": "Someone",
+	}, "", ip)
+	s.Equal(http.StatusUnauthorized, resWrong.Code)
+
+	resUnknown := s.guestRequest("POST", "/api/v1/reservations/access", map[string]string{
+		"reservation_code": "NOPE-0000", "last_name": "Verified",
+	}, "", ip)
+	s.Equal(http.StatusUnauthorized, resUnknown.Code)
+
+	resOK := s.guestRequest("POST", "/api/v1/reservations/access", map[string]string{
+		"reservation_code": booking.ReservationCode, "last_name": " verified ",
+	}, "", ip)
+	s.Require().Equal(http.StatusOK, resOK.Code, resOK.Body.String())
+	var access entity.GuestAccess
+	json.Unmarshal(resOK.Body.Bytes(), &access)
+	s.Equal(booking.ReservationID, access.ReservationID)
+
+	resGet := s.guestRequest("GET", "/api/v1/reservations/"+booking.ReservationCode, nil, access.AccessToken, ip)
+	s.Equal(http.StatusOK, resGet.Code)
+
+	// Tokens in the query string would end up in access logs, so they are not accepted.
+	resQuery := s.guestRequest("GET", "/api/v1/reservations/"+booking.ReservationCode+"?token="+access.AccessToken, nil, "", ip)
+	s.Equal(http.StatusUnauthorized, resQuery.Code)
+}
+
+func (s *GuestAccessSuite) TestStaffLookUpAndCancel() {
+	booking := s.book("desk@test.com", "Desk")
+	otherToken, _ := s.CreateOrgOwner("rival@test.com", "RIVAL")
+
+	resGet := s.MakeRequest("GET", "/api/v1/reservations/code/"+booking.ReservationCode, nil, s.token)
+	s.Require().Equal(http.StatusOK, resGet.Code, resGet.Body.String())
+	var reservation entity.Reservation
+	json.Unmarshal(resGet.Body.Bytes(), &reservation)
+	s.Equal(booking.ReservationID, reservation.ID)
+
+	s.Equal(http.StatusUnauthorized, s.MakeRequest("GET", "/api/v1/reservations/code/"+booking.ReservationCode, nil, "").Code)
+	s.Equal(http.StatusNotFound, s.MakeRequest("GET", "/api/v1/reservations/code/"+booking.ReservationCode, nil, otherToken).Code)
+	s.Equal(http.StatusNotFound, s.MakeRequest("POST", "/api/v1/reservations/"+booking.ReservationID+"/cancel", nil, otherToken).Code)
+
+	s.Equal(http.StatusOK, s.MakeRequest("POST", "/api/v1/reservations/"+booking.ReservationID+"/cancel", nil, s.token).Code)
+	s.Equal(http.StatusConflict, s.MakeRequest("POST", "/api/v1/reservations/"+booking.ReservationID+"/cancel", nil, s.token).Code)
+}
+
+func (s *GuestAccessSuite) TestStartedStaysCannotBeCancelled() {
+	const ip = "198.51.100.40"
+	for _, status := range []string{"checked_in", "checked_out"} {
+		booking := s.book(status+"@test.com", "Started")
+		_, err := s.db.Exec(context.Background(), `UPDATE reservations SET status = $2 WHERE id = $1`, booking.ReservationID, status)
+		s.Require().NoError(err)
+
+		s.Equal(http.StatusConflict, s.guestRequest("POST", "/api/v1/reservations/"+booking.ReservationID+"/cancel", nil, booking.AccessToken, ip).Code, status)
+		s.Equal(http.StatusConflict, s.MakeRequest("POST", "/api/v1/reservations/"+booking.ReservationID+"/cancel", nil, s.token).Code, status)
+
+		var current string
+		s.Require().NoError(s.db.QueryRow(context.Background(), `SELECT status FROM reservations WHERE id = $1`, booking.ReservationID).Scan(&current))
+		s.Equal(status, current)
+	}
+}
+
+func (s *GuestAccessSuite) TestFailedLookupsAreRateLimited() {
+	const ip = "198.51.100.30"
+	booking := s.book("limit@test.com", "Limited")
+
+	for i := 0; i < 5; i++ {
+		res := s.guestRequest("POST", "/api/v1/reservations/access", map[string]string{
+			"reservation_code": booking.ReservationCode, "last_name": "Guess",
+		}, "", ip)
+		s.Equal(http.StatusUnauthorized, res.Code)
+	}
+
+	// Even a valid token is refused while the client is locked out.
+	s.Equal(http.StatusTooManyRequests, s.guestRequest("GET", "/api/v1/reservations/"+booking.ReservationCode, nil, booking.AccessToken, ip).Code)
+
+	// Other clients are unaffected.
+	s.Equal(http.StatusOK, s.guestRequest("GET", "/api/v1/reservations/"+booking.ReservationCode, nil, booking.AccessToken, "198.51.100.31").Code)
+}
+
+func TestGuestAccessSuite(t *testing.T) {
+	suite.Run(t, new(GuestAccessSuite))
+}
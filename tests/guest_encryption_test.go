@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/service"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+	"github.com/ecelayes/pms-backend/pkg/fieldcrypt"
+)
+
+type GuestEncryptionSuite struct {
+	BaseSuite
+	token string
+	orgID string
+}
+
+func (s *GuestEncryptionSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.token, s.orgID = s.GetAdminTokenAndOrg()
+}
+
+func (s *GuestEncryptionSuite) keyRing(active int) *fieldcrypt.KeyRing {
+	v1, _ := base64.StdEncoding.DecodeString(testPIIKeyV1)
+	v2, _ := base64.StdEncoding.DecodeString(testPIIKeyV2)
+	index, _ := base64.StdEncoding.DecodeString(testPIIIndexKey)
+	keys := map[int][]byte{1: v1}
+	if active == 2 {
+		keys[2] = v2
+	}
+	ring, err := fieldcrypt.NewKeyRing(keys, active, index)
+	s.Require().NoError(err)
+	return ring
+}
+
+func (s *GuestEncryptionSuite) guestUseCase(keys *fieldcrypt.KeyRing) *usecase.GuestUseCase {
+	return usecase.NewGuestUseCase(s.db, repository.NewGuestRepository(s.db, keys),
+		repository.NewReservationRepository(s.db), repository.NewInvoiceRepository(s.db), service.NewGuestMatcher())
+}
+
+func (s *GuestEncryptionSuite) TestPersonalDataIsEncryptedAtRest() {
+	res := s.MakeRequest("POST", "/api/v1/guests", map[string]string{
+		"email": "ana@test.com", "first_name": "Ana", "last_name": "Lopez", "phone": "+34600111222",
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, res.Code)
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	guestID := data["guest_id"]
+
+	var email, firstName, lastName, phone string
+	var emailIndex *string
+	var keyVersion *int
+	s.Require().NoError(s.db.QueryRow(context.Background(),
+		`SELECT email, first_name, last_name, phone, email_index, pii_key_version FROM guests WHERE id = $1`, guestID,
+	).Scan(&email, &firstName, &lastName, &phone, &emailIndex, &keyVersion))
+	for _, stored := range []string{email, firstName, lastName, phone} {
+		s.Contains(stored, "v1:")
+	}
+	s.NotContains(email, "ana")
+	s.NotContains(phone, "600111222")
+	s.NotNil(emailIndex)
+	s.Require().NotNil(keyVersion)
+	s.Equal(1, *keyVersion)
+
+	resGet := s.MakeRequest("GET", "/api/v1/guests/"+guestID, nil, s.token)
+	s.Require().Equal(http.StatusOK, resGet.Code)
+	var detail entity.GuestDetail
+	json.Unmarshal(resGet.Body.Bytes(), &detail)
+	s.Equal("ana@test.com", detail.Email)
+	s.Equal("+34600111222", detail.Phone)
+
+	resSearch := s.MakeRequest("GET", "/api/v1/guests?q=lope", nil, s.token)
+	s.Require().Equal(http.StatusOK, resSearch.Code)
+	var page entity.PaginatedResponse[entity.Guest]
+	json.Unmarshal(resSearch.Body.Bytes(), &page)
+	s.Require().Len(page.Data, 1)
+	s.Equal("Ana", page.Data[0].FirstName)
+
+	// The blind index keeps email lookups and uniqueness working.
+	resDup := s.MakeRequest("POST", "/api/v1/guests", map[string]string{
+		"email": "ANA@test.com", "first_name": "Ana", "last_name": "Other",
+	}, s.token)
+	s.Equal(http.StatusConflict, resDup.Code)
+
+	found, err := repository.NewGuestRepository(s.db, s.piiKeys).GetByEmail(context.Background(), s.orgID, "ana@test.com")
+	s.Require().NoError(err)
+	s.Require().NotNil(found)
+	s.Equal(guestID, found.ID)
+}
+
+func (s *GuestEncryptionSuite) TestExistingRowsAreEncryptedAndKeysRotate() {
+	ctx := context.Background()
+	legacyID := uuid.NewString()
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO guests (id, organization_id, email, first_name, last_name, phone, created_at, updated_at)
+		VALUES ($1, $2, 'legacy@test.com', 'Old', 'Row', '+1555', NOW(), NOW())
+	`, legacyID, s.orgID)
+	s.Require().NoError(err)
+
+	repoV1 := repository.NewGuestRepository(s.db, s.keyRing(1))
+	found, err := repoV1.GetByEmail(ctx, s.orgID, "legacy@test.com")
+	s.Require().NoError(err)
+	s.Require().NotNil(found)
+	s.Equal("Old", found.FirstName)
+
+	result, err := s.guestUseCase(s.keyRing(1)).ReencryptPII(ctx, 10)
+	s.Require().NoError(err)
+	s.Equal(1, result.Guests)
+
+	var email string
+	s.Require().NoError(s.db.QueryRow(ctx, `SELECT email FROM guests WHERE id = $1`, legacyID).Scan(&email))
+	s.NotEqual("legacy@test.com", email)
+
+	// Rotate: key 2 becomes active and every row is sealed again with it.
+	rotated := s.keyRing(2)
+	result, err = s.guestUseCase(rotated).ReencryptPII(ctx, 10)
+	s.Require().NoError(err)
+	s.Equal(1, result.Guests)
+
+	var version int
+	s.Require().NoError(s.db.QueryRow(ctx, `SELECT pii_key_version FROM guests WHERE id = $1`, legacyID).Scan(&version))
+	s.Equal(2, version)
+
+	v2, _ := base64.StdEncoding.DecodeString(testPIIKeyV2)
+	index, _ := base64.StdEncoding.DecodeString(testPIIIndexKey)
+	onlyV2, err := fieldcrypt.NewKeyRing(map[int][]byte{2: v2}, 2, index)
+	s.Require().NoError(err)
+	found, err = repository.NewGuestRepository(s.db, onlyV2).GetByEmail(ctx, s.orgID, "legacy@test.com")
+	s.Require().NoError(err)
+	s.Require().NotNil(found)
+	s.Equal("+1555", found.Phone)
+
+	result, err = s.guestUseCase(rotated).ReencryptPII(ctx, 10)
+	s.Require().NoError(err)
+	s.Zero(result.Guests)
+}
+
+func (s *GuestEncryptionSuite) TestRotationResealsSnapshotsAndSkipsCollisions() {
+	ctx := context.Background()
+	create := func(email, lastName string) string {
+		res := s.MakeRequest("POST", "/api/v1/guests", map[string]string{
+			"email": email, "first_name": "Rita", "last_name": lastName,
+		}, s.token)
+		s.Require().Equal(http.StatusCreated, res.Code)
+		var data map[string]string
+		json.Unmarshal(res.Body.Bytes(), &data)
+		return data["guest_id"]
+	}
+	survivorID := create("rita@test.com", "Keep")
+	duplicateID := create("rita.old@test.com", "Gone")
+	resMerge := s.MakeRequest("POST", "/api/v1/guests/"+survivorID+"/merge", map[string]string{"duplicate_id": duplicateID}, s.token)
+	s.Require().Equal(http.StatusOK, resMerge.Code, resMerge.Body.String())
+
+	// Two legacy rows that only differ in case collide once they get a blind index.
+	upperID, lowerID := uuid.NewString(), uuid.NewString()
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO guests (id, organization_id, email, first_name, last_name, created_at, updated_at)
+		VALUES ($1, $3, 'Twin@test.com', 'Upper', 'Case', NOW(), NOW()), ($2, $3, 'twin@test.com', 'Lower', 'Case', NOW(), NOW())
+	`, upperID, lowerID, s.orgID)
+	s.Require().NoError(err)
+
+	result, err := s.guestUseCase(s.keyRing(2)).ReencryptPII(ctx, 1)
+	s.Require().NoError(err)
+	s.Equal(3, result.Guests, "the run carries on past the collision")
+	s.Equal(1, result.Snapshots)
+	s.Require().Len(result.Conflicts, 1)
+
+	var snapshot string
+	s.Require().NoError(s.db.QueryRow(ctx, `SELECT merged_snapshot #>> '{}' FROM guest_merges WHERE survivor_id = $1`, survivorID).Scan(&snapshot))
+	s.True(strings.HasPrefix(snapshot, "v2:"))
+
+	var stale int
+	s.Require().NoError(s.db.QueryRow(ctx, `SELECT COUNT(*) FROM guests WHERE pii_key_version IS NULL`).Scan(&stale))
+	s.Equal(1, stale)
+}
+
+func (s *GuestEncryptionSuite) TestSearchMatchesTheSameBeforeAndAfterEncryption() {
+	ctx := context.Background()
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO guests (id, organization_id, email, first_name, last_name, phone, created_at, updated_at)
+		VALUES ($1, $2, 'olivia.m@test.com', 'Olivia', 'Martinez', '+34 611-222-333', NOW(), NOW())
+	`, uuid.NewString(), s.orgID)
+	s.Require().NoError(err)
+
+	queries := map[string]bool{
+		"oli":             true,
+		"MART":            true,
+		"olivia martinez": true,
+		"olivia.m@":       true,
+		"222-333":         true,
+		"ol":              false,
+		"livia":           false,
+		"tinez":           false,
+		"test.com":        false,
+		"333":             false,
+	}
+	search := func(stage string) {
+		for q, found := range queries {
+			res := s.MakeRequest("GET", "/api/v1/guests?q="+url.QueryEscape(q), nil, s.token)
+			s.Require().Equal(http.StatusOK, res.Code)
+			var page entity.PaginatedResponse[entity.Guest]
+			json.Unmarshal(res.Body.Bytes(), &page)
+			if found {
+				s.Len(page.Data, 1, "%s: %q", stage, q)
+			} else {
+				s.Empty(page.Data, "%s: %q", stage, q)
+			}
+		}
+	}
+
+	search("plaintext")
+	result, err := s.guestUseCase(s.keyRing(1)).ReencryptPII(ctx, 10)
+	s.Require().NoError(err)
+	s.Equal(1, result.Guests)
+	search("encrypted")
+}
+
+func TestGuestEncryptionSuite(t *testing.T) {
+	suite.Run(t, new(GuestEncryptionSuite))
+}
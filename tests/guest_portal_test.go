@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type GuestPortalSuite struct {
+	BaseSuite
+	token      string
+	propertyID string
+	unitTypeID string
+}
+
+func (s *GuestPortalSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	var orgID string
+	s.token, orgID = s.GetAdminTokenAndOrg()
+
+	resH := s.MakeRequest("POST", "/api/v1/properties", map[string]interface{}{
+		"organization_id": orgID, "name": "Portal Hotel", "code": "PTL", "type": "HOTEL",
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resH.Code)
+	var dataH map[string]string
+	json.Unmarshal(resH.Body.Bytes(), &dataH)
+	s.propertyID = dataH["property_id"]
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": s.propertyID, "name": "Std", "code": "STD",
+		"total_quantity": 5, "base_price": 100.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resU.Code)
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+	s.unitTypeID = dataU["unit_type_id"]
+}
+
+func (s *GuestPortalSuite) createPlan(name string, refundable bool, breakfast float64) string {
+	res := s.MakeRequest("POST", "/api/v1/rate-plans", map[string]interface{}{
+		"property_id":  s.propertyID,
+		"unit_type_id": s.unitTypeID,
+		"name":         name,
+		"meal_plan": map[string]interface{}{
+			"included": breakfast > 0, "price_per_pax": breakfast, "type": 1,
+		},
+		"cancellation_policy": map[string]interface{}{
+			"is_refundable": refundable,
+		},
+		"payment_policy": map[string]interface{}{"timing": 0, "method": 0},
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	return data["rate_plan_id"]
+}
+
+func (s *GuestPortalSuite) book(ratePlanID string) entity.GuestAccess {
+	res := s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+		"unit_type_id":     s.unitTypeID,
+		"rate_plan_id":     ratePlanID,
+		"guest_email":      "portal@test.com",
+		"guest_first_name": "Paula", "guest_last_name": "Portal",
+		"start":            "2027-03-01", "end": "2027-03-03",
+		"adults":           1, "children": 0,
+	}, "")
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var access entity.GuestAccess
+	json.Unmarshal(res.Body.Bytes(), &access)
+	s.Require().NotEmpty(access.AccessToken)
+	return access
+}
+
+func (s *GuestPortalSuite) guestRequest(method, url string, body interface{}, guestToken string) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, url, bytes.NewReader(jsonBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if guestToken != "" {
+		req.Header.Set("X-Guest-Token", guestToken)
+	}
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *GuestPortalSuite) portal(guestToken string) entity.GuestPortalReservation {
+	res := s.guestRequest("GET", "/api/v1/guest/reservation", nil, guestToken)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	var portal entity.GuestPortalReservation
+	json.Unmarshal(res.Body.Bytes(), &portal)
+	return portal
+}
+
+func (s *GuestPortalSuite) TestFlexibleBookingCanBeChanged() {
+	roomOnly := s.createPlan("Flex", true, 0)
+	breakfast := s.createPlan("Flex Breakfast", true, 15)
+	access := s.book(roomOnly)
+
+	s.Equal(http.StatusUnauthorized, s.guestRequest("GET", "/api/v1/guest/reservation", nil, "").Code)
+
+	portal := s.portal(access.AccessToken)
+	s.Equal(access.ReservationID, portal.Reservation.ID)
+	s.True(portal.Options.CanChangeDates)
+	s.True(portal.Options.CanCancel)
+	s.Equal(0.0, portal.Options.CancellationFee)
+	s.Len(portal.Options.RatePlans, 2)
+
+	resQuote := s.guestRequest("POST", "/api/v1/guest/reservation/quote", map[string]interface{}{
+		"rate_plan_id": breakfast,
+	}, access.AccessToken)
+	s.Require().Equal(http.StatusOK, resQuote.Code, resQuote.Body.String())
+	var quote entity.ModificationQuote
+	json.Unmarshal(resQuote.Body.Bytes(), &quote)
+	s.Equal(200.0, quote.CurrentTotal)
+	s.Equal(230.0, quote.NewTotal)
+	s.Equal(30.0, quote.Difference)
+	s.True(quote.Available)
+
+	s.Equal(200.0, s.portal(access.AccessToken).Reservation.TotalPrice, "quoting must not change the booking")
+
+	resModify := s.guestRequest("PUT", "/api/v1/guest/reservation", map[string]interface{}{
+		"end": "2027-03-04", "rate_plan_id": breakfast,
+	}, access.AccessToken)
+	s.Require().Equal(http.StatusOK, resModify.Code, resModify.Body.String())
+	var modified entity.Reservation
+	json.Unmarshal(resModify.Body.Bytes(), &modified)
+	s.Equal(345.0, modified.TotalPrice)
+	s.Equal("2027-03-04", modified.End.Format("2006-01-02"))
+
+	resArrival := s.guestRequest("PUT", "/api/v1/guest/reservation/arrival-time", map[string]string{
+		"arrival_time": "22:30",
+	}, access.AccessToken)
+	s.Require().Equal(http.StatusOK, resArrival.Code, resArrival.Body.String())
+	s.Require().NotNil(s.portal(access.AccessToken).Reservation.ArrivalTime)
+	s.Equal("22:30", *s.portal(access.AccessToken).Reservation.ArrivalTime)
+
+	s.Equal(http.StatusBadRequest, s.guestRequest("PUT", "/api/v1/guest/reservation/arrival-time", map[string]string{
+		"arrival_time": "25:00",
+	}, access.AccessToken).Code)
+
+	s.Equal(http.StatusOK, s.guestRequest("POST", "/api/v1/guest/reservation/cancel", nil, access.AccessToken).Code)
+	cancelled := s.portal(access.AccessToken)
+	s.Equal("cancelled", cancelled.Reservation.Status)
+	s.False(cancelled.Options.CanChangeRatePlan)
+}
+
+func (s *GuestPortalSuite) TestCheckedInStayCannotBeCancelled() {
+	access := s.book(s.createPlan("Flex", true, 0))
+	_, err := s.db.Exec(context.Background(), `UPDATE reservations SET status = 'checked_in' WHERE id = $1`, access.ReservationID)
+	s.Require().NoError(err)
+
+	s.False(s.portal(access.AccessToken).Options.CanCancel)
+	s.Equal(http.StatusConflict, s.guestRequest("POST", "/api/v1/guest/reservation/cancel", nil, access.AccessToken).Code)
+	s.Equal("checked_in", s.portal(access.AccessToken).Reservation.Status)
+}
+
+func (s *GuestPortalSuite) TestNonRefundableBookingOnlyAllowsUpgrades() {
+	saver := s.createPlan("Saver", false, 0)
+	saverBreakfast := s.createPlan("Saver Breakfast", false, 15)
+	flex := s.createPlan("Flex", true, 0)
+	access := s.book(saver)
+
+	portal := s.portal(access.AccessToken)
+	s.False(portal.Options.CanChangeDates)
+	s.Equal(200.0, portal.Options.CancellationFee)
+	offered := map[string]bool{}
+	for _, option := range portal.Options.RatePlans {
+		offered[option.RatePlanID] = true
+	}
+	s.True(offered[saver])
+	s.True(offered[saverBreakfast])
+	s.False(offered[flex], "a locked booking must not be moved to a more flexible rate")
+
+	s.Equal(http.StatusConflict, s.guestRequest("PUT", "/api/v1/guest/reservation", map[string]interface{}{
+		"end": "2027-03-04",
+	}, access.AccessToken).Code)
+	s.Equal(http.StatusConflict, s.guestRequest("PUT", "/api/v1/guest/reservation", map[string]interface{}{
+		"rate_plan_id": flex,
+	}, access.AccessToken).Code)
+
+	resUpgrade := s.guestRequest("PUT", "/api/v1/guest/reservation", map[string]interface{}{
+		"rate_plan_id": saverBreakfast,
+	}, access.AccessToken)
+	s.Require().Equal(http.StatusOK, resUpgrade.Code, resUpgrade.Body.String())
+	var upgraded entity.Reservation
+	json.Unmarshal(resUpgrade.Body.Bytes(), &upgraded)
+	s.Equal(230.0, upgraded.TotalPrice)
+}
+
+func TestGuestPortalSuite(t *testing.T) {
+	suite.Run(t, new(GuestPortalSuite))
+}
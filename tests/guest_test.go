@@ -0,0 +1,313 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type GuestSuite struct {
+	BaseSuite
+	token      string
+	unitTypeID string
+}
+
+func (s *GuestSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	var orgID string
+	s.token, orgID = s.GetAdminTokenAndOrg()
+
+	resH := s.MakeRequest("POST", "/api/v1/properties", map[string]interface{}{
+		"organization_id": orgID, "name": "Guest Property", "code": "GST", "type": "HOTEL",
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resH.Code)
+	var dataH map[string]string
+	json.Unmarshal(resH.Body.Bytes(), &dataH)
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": dataH["property_id"], "name": "Std", "code": "STD",
+		"total_quantity": 5, "base_price": 100.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resU.Code)
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+	s.unitTypeID = dataU["unit_type_id"]
+}
+
+func (s *GuestSuite) book(body map[string]interface{}) entity.Reservation {
+	body["unit_type_id"] = s.unitTypeID
+	body["adults"] = 1
+	res := s.MakeRequest("POST", "/api/v1/reservations", body, "")
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+data["reservation_code"], nil, data["access_token"])
+	var reservation entity.Reservation
+	json.Unmarshal(resGet.Body.Bytes(), &reservation)
+	return reservation
+}
+
+func (s *GuestSuite) detail(id string) entity.GuestDetail {
+	res := s.MakeRequest("GET", "/api/v1/guests/"+id, nil, s.token)
+	s.Require().Equal(http.StatusOK, res.Code)
+	var detail entity.GuestDetail
+	json.Unmarshal(res.Body.Bytes(), &detail)
+	return detail
+}
+
+func (s *GuestSuite) TestCRUDAndSearch() {
+	res := s.MakeRequest("POST", "/api/v1/guests", map[string]string{
+		"email": "maria@test.com", "first_name": "María", "last_name": "Gómez", "phone": "+34600111222",
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, res.Code)
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	id := data["guest_id"]
+
+	resDup := s.MakeRequest("POST", "/api/v1/guests", map[string]string{
+		"email": "maria@test.com", "first_name": "Other", "last_name": "Person",
+	}, s.token)
+	s.Equal(http.StatusConflict, resDup.Code)
+
+	resInvalid := s.MakeRequest("POST", "/api/v1/guests", map[string]string{"email": "nope"}, s.token)
+	s.Equal(http.StatusBadRequest, resInvalid.Code)
+
+	s.MakeRequest("POST", "/api/v1/guests", map[string]string{
+		"email": "john@test.com", "first_name": "John", "last_name": "Smith",
+	}, s.token)
+
+	for _, q := range []string{"gómez", "maria@", "600111"} {
+		resSearch := s.MakeRequest("GET", "/api/v1/guests?q="+q, nil, s.token)
+		s.Require().Equal(http.StatusOK, resSearch.Code)
+		var page entity.PaginatedResponse[entity.Guest]
+		json.Unmarshal(resSearch.Body.Bytes(), &page)
+		s.Require().Len(page.Data, 1, q)
+		s.Equal(id, page.Data[0].ID)
+	}
+
+	resUpdate := s.MakeRequest("PUT", "/api/v1/guests/"+id, map[string]string{"phone": "+34600999888"}, s.token)
+	s.Equal(http.StatusOK, resUpdate.Code)
+	s.Equal("+34600999888", s.detail(id).Phone)
+	s.Equal("María", s.detail(id).FirstName)
+
+	resDelete := s.MakeRequest("DELETE", "/api/v1/guests/"+id, nil, s.token)
+	s.Equal(http.StatusOK, resDelete.Code)
+	resGone := s.MakeRequest("GET", "/api/v1/guests/"+id, nil, s.token)
+	s.Equal(http.StatusNotFound, resGone.Code)
+
+	resRecreate := s.MakeRequest("POST", "/api/v1/guests", map[string]string{
+		"email": "maria@test.com", "first_name": "María", "last_name": "Gómez",
+	}, s.token)
+	s.Equal(http.StatusCreated, resRecreate.Code)
+}
+
+func (s *GuestSuite) TestDetailWithStayHistoryAndNotes() {
+	first := s.book(map[string]interface{}{
+		"guest_email": "loyal@test.com", "guest_first_name": "Lo", "guest_last_name": "Yal",
+		"start": "2025-01-10", "end": "2025-01-12",
+	})
+	s.book(map[string]interface{}{
+		"guest_email": "loyal@test.com", "guest_first_name": "Lo", "guest_last_name": "Yal",
+		"start": "2025-05-10", "end": "2025-05-11",
+	})
+
+	resCheckOut := s.MakeRequest("POST", "/api/v1/reservations/"+first.ID+"/check-out", nil, s.token)
+	s.Require().Equal(http.StatusCreated, resCheckOut.Code)
+
+	resNote := s.MakeRequest("POST", "/api/v1/guests/"+first.GuestID+"/notes", map[string]string{
+		"body": "Prefers a quiet room",
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resNote.Code)
+
+	resActive := s.MakeRequest("DELETE", "/api/v1/guests/"+first.GuestID, nil, s.token)
+	s.Equal(http.StatusConflict, resActive.Code)
+
+	detail := s.detail(first.GuestID)
+	s.Len(detail.Stays, 2)
+	s.Equal(1, detail.CompletedStays)
+	s.Equal(first.TotalPrice, detail.LifetimeRevenue)
+	s.Require().Len(detail.Notes, 1)
+	s.Equal("Prefers a quiet room", detail.Notes[0].Body)
+	s.NotNil(detail.Notes[0].AuthorID)
+}
+
+func (s *GuestSuite) TestBookingOnlyUpdatesProfileWhenRequested() {
+	first := s.book(map[string]interface{}{
+		"guest_email": "keep@test.com", "guest_first_name": "Original", "guest_last_name": "Name",
+		"start": "2025-02-01", "end": "2025-02-02",
+	})
+
+	s.book(map[string]interface{}{
+		"guest_email": "keep@test.com", "guest_first_name": "Typo", "guest_last_name": "Nmae",
+		"guest_phone": "+1555000", "start": "2025-02-03", "end": "2025-02-04",
+	})
+	detail := s.detail(first.GuestID)
+	s.Equal("Original", detail.FirstName)
+	s.Equal("Name", detail.LastName)
+	s.Equal("+1555000", detail.Phone)
+
+	s.book(map[string]interface{}{
+		"guest_email": "keep@test.com", "guest_first_name": "Renamed", "guest_last_name": "Guest",
+		"update_guest_profile": true, "start": "2025-02-05", "end": "2025-02-06",
+	})
+	detail = s.detail(first.GuestID)
+	s.Equal("Renamed", detail.FirstName)
+	s.Equal("Guest", detail.LastName)
+	s.Equal("+1555000", detail.Phone)
+}
+
+func (s *GuestSuite) TestGuestsAreScopedPerOrganization() {
+	first := s.book(map[string]interface{}{
+		"guest_email": "shared@test.com", "guest_first_name": "Shared", "guest_last_name": "Guest",
+		"start": "2025-04-01", "end": "2025-04-02",
+	})
+
+	otherToken, otherOrgID := s.CreateOrgOwner("other@test.com", "OTHER")
+	resH := s.MakeRequest("POST", "/api/v1/properties", map[string]interface{}{
+		"organization_id": otherOrgID, "name": "Other Property", "code": "OTH", "type": "HOTEL",
+	}, otherToken)
+	s.Require().Equal(http.StatusCreated, resH.Code)
+	var dataH map[string]string
+	json.Unmarshal(resH.Body.Bytes(), &dataH)
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": dataH["property_id"], "name": "Std", "code": "STD",
+		"total_quantity": 1, "base_price": 90.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, otherToken)
+	s.Require().Equal(http.StatusCreated, resU.Code)
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+
+	res := s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+		"unit_type_id": dataU["unit_type_id"], "adults": 1,
+		"guest_email": "shared@test.com", "guest_first_name": "Other", "guest_last_name": "Spelling",
+		"update_guest_profile": true, "start": "2025-04-01", "end": "2025-04-02",
+	}, "")
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+
+	// The second organization got its own profile and could not touch the first one.
+	detail := s.detail(first.GuestID)
+	s.Equal("Shared", detail.FirstName)
+	s.Len(detail.Stays, 1)
+
+	resForeign := s.MakeRequest("GET", "/api/v1/guests/"+first.GuestID, nil, otherToken)
+	s.Equal(http.StatusNotFound, resForeign.Code)
+
+	resList := s.MakeRequest("GET", "/api/v1/guests?q=shared@test.com", nil, otherToken)
+	var page entity.PaginatedResponse[entity.Guest]
+	json.Unmarshal(resList.Body.Bytes(), &page)
+	s.Require().Len(page.Data, 1)
+	s.NotEqual(first.GuestID, page.Data[0].ID)
+	s.Equal("Other", page.Data[0].FirstName)
+	s.Equal(otherOrgID, page.Data[0].OrganizationID)
+}
+
+func (s *GuestSuite) TestDuplicateDetectionAndMerge() {
+	create := func(body map[string]string) string {
+		res := s.MakeRequest("POST", "/api/v1/guests", body, s.token)
+		s.Require().Equal(http.StatusCreated, res.Code)
+		var data map[string]string
+		json.Unmarshal(res.Body.Bytes(), &data)
+		return data["guest_id"]
+	}
+	survivorID := create(map[string]string{
+		"email": "john.smith@test.com", "first_name": "John", "last_name": "Smith", "phone": "+1 555 123 4567",
+	})
+	create(map[string]string{
+		"email": "peter@test.com", "first_name": "Peter", "last_name": "Smithers",
+	})
+
+	stay := s.book(map[string]interface{}{
+		"guest_email": "jonsmith@test.com", "guest_first_name": "Jon", "guest_last_name": "Smith",
+		"guest_phone": "555-123-4567", "guest_language": "en",
+		"start": "2025-06-01", "end": "2025-06-03",
+	})
+	duplicateID := stay.GuestID
+	s.Require().Equal(http.StatusCreated, s.MakeRequest("POST", "/api/v1/reservations/"+stay.ID+"/check-out", nil, s.token).Code)
+	s.Require().Equal(http.StatusCreated, s.MakeRequest("POST", "/api/v1/guests/"+duplicateID+"/notes", map[string]string{
+		"body": "Booked under a typo",
+	}, s.token).Code)
+
+	resDup := s.MakeRequest("GET", "/api/v1/guests/duplicates?limit=5", nil, s.token)
+	s.Require().Equal(http.StatusOK, resDup.Code)
+	var dupPage entity.PaginatedResponse[entity.GuestDuplicate]
+	json.Unmarshal(resDup.Body.Bytes(), &dupPage)
+	s.Equal(int64(1), dupPage.Meta.TotalItems)
+	duplicates := dupPage.Data
+	s.Require().Len(duplicates, 1)
+	s.ElementsMatch([]string{survivorID, duplicateID}, []string{duplicates[0].Guest.ID, duplicates[0].Candidate.ID})
+	s.Contains(duplicates[0].Reasons, "same_phone")
+
+	resSelf := s.MakeRequest("POST", "/api/v1/guests/"+survivorID+"/merge", map[string]string{"duplicate_id": survivorID}, s.token)
+	s.Equal(http.StatusBadRequest, resSelf.Code)
+
+	resMerge := s.MakeRequest("POST", "/api/v1/guests/"+survivorID+"/merge", map[string]string{"duplicate_id": duplicateID}, s.token)
+	s.Require().Equal(http.StatusOK, resMerge.Code, resMerge.Body.String())
+	var merge entity.GuestMerge
+	json.Unmarshal(resMerge.Body.Bytes(), &merge)
+	s.Equal(1, merge.ReservationsMoved)
+	s.Equal(1, merge.InvoicesMoved)
+	s.Equal(1, merge.NotesMoved)
+
+	detail := s.detail(survivorID)
+	s.Equal("John", detail.FirstName)
+	s.Equal("en", detail.Language)
+	s.Len(detail.Stays, 1)
+	s.Equal(stay.TotalPrice, detail.LifetimeRevenue)
+	s.Len(detail.Notes, 1)
+
+	s.Equal(http.StatusNotFound, s.MakeRequest("GET", "/api/v1/guests/"+duplicateID, nil, s.token).Code)
+
+	var audited int
+	s.Require().NoError(s.db.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM guest_merges WHERE survivor_id = $1 AND merged_id = $2`, survivorID, duplicateID,
+	).Scan(&audited))
+	s.Equal(1, audited)
+}
+
+func (s *GuestSuite) TestDuplicatesArePaged() {
+	for _, pair := range [][2]map[string]string{
+		{{"email": "ana.lopez@test.com", "first_name": "Ana", "last_name": "Lopez", "phone": "+34 600 111 222"},
+			{"email": "analopez@test.com", "first_name": "Ana", "last_name": "López", "phone": "600111222"}},
+		{{"email": "marc.roig@test.com", "first_name": "Marc", "last_name": "Roig", "phone": "+34 600 333 444"},
+			{"email": "marcroig@test.com", "first_name": "Marc", "last_name": "Roig", "phone": "600333444"}},
+		{{"email": "eva.sanz@test.com", "first_name": "Eva", "last_name": "Sanz", "phone": "+34 600 555 666"},
+			{"email": "evasanz@test.com", "first_name": "Eva", "last_name": "Sanz", "phone": "600555666"}},
+	} {
+		for _, body := range pair {
+			s.Require().Equal(http.StatusCreated, s.MakeRequest("POST", "/api/v1/guests", body, s.token).Code)
+		}
+	}
+	// Shares no phone, email or name prefix with anyone, so it never becomes a candidate.
+	s.Require().Equal(http.StatusCreated, s.MakeRequest("POST", "/api/v1/guests", map[string]string{
+		"email": "zoe@test.com", "first_name": "Zoe", "last_name": "Quint",
+	}, s.token).Code)
+
+	seen := map[string]bool{}
+	for page, want := range map[int]int{1: 2, 2: 1} {
+		res := s.MakeRequest("GET", fmt.Sprintf("/api/v1/guests/duplicates?limit=2&page=%d", page), nil, s.token)
+		s.Require().Equal(http.StatusOK, res.Code)
+		var data entity.PaginatedResponse[entity.GuestDuplicate]
+		json.Unmarshal(res.Body.Bytes(), &data)
+		s.Equal(int64(3), data.Meta.TotalItems)
+		s.Equal(2, data.Meta.TotalPages)
+		s.Len(data.Data, want)
+		for _, d := range data.Data {
+			s.False(seen[d.Guest.ID])
+			seen[d.Guest.ID] = true
+			s.Equal(d.Guest.FirstName, d.Candidate.FirstName)
+		}
+	}
+}
+
+func TestGuestSuite(t *testing.T) {
+	suite.Run(t, new(GuestSuite))
+}
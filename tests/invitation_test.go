@@ -0,0 +1,286 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/pkg/auth"
+)
+
+type InvitationSuite struct {
+	BaseSuite
+	ownerToken string
+	orgID      string
+	propertyID string
+}
+
+func (s *InvitationSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.ownerToken, s.orgID = s.GetAdminTokenAndOrg()
+
+	res := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"name": "Invite Hotel", "code": "INV", "type": "HOTEL",
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	s.propertyID = data["property_id"]
+}
+
+func (s *InvitationSuite) invite(token string, body map[string]interface{}) entity.Invitation {
+	res := s.MakeRequest("POST", "/api/v1/invitations", body, token)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var inv entity.Invitation
+	json.Unmarshal(res.Body.Bytes(), &inv)
+	return inv
+}
+
+// linkToken rebuilds the token of the link most recently emailed for the invitation.
+func (s *InvitationSuite) linkToken(invitationID string) string {
+	var secret string
+	var expiresAt time.Time
+	err := s.db.QueryRow(context.Background(),
+		`SELECT secret, expires_at FROM organization_invitations WHERE id = $1`, invitationID,
+	).Scan(&secret, &expiresAt)
+	s.Require().NoError(err)
+	token, err := auth.GenerateInvitationToken(invitationID, secret, expiresAt)
+	s.Require().NoError(err)
+	return token
+}
+
+func (s *InvitationSuite) login(email, password string) string {
+	res := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": email, "password": password}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	return data["token"]
+}
+
+func (s *InvitationSuite) TestInviteeSetsPasswordAndJoins() {
+	inv := s.invite(s.ownerToken, map[string]interface{}{
+		"email": "desk@test.com", "role": "staff", "property_ids": []string{s.propertyID},
+	})
+	s.Equal(entity.InvitationStatusPending, inv.Status)
+
+	var queued int
+	s.db.QueryRow(context.Background(), `SELECT COUNT(*) FROM email_outbox WHERE recipient = 'desk@test.com'`).Scan(&queued)
+	s.Equal(1, queued)
+
+	token := s.linkToken(inv.ID)
+	res := s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{
+		"token": token, "password": "short",
+		"first_name": "Front", "last_name": "Desk",
+	}, "")
+	s.Equal(http.StatusBadRequest, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{
+		"token": token, "password": "deskpass123",
+		"first_name": "Front", "last_name": "Desk",
+	}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+
+	staffToken := s.login("desk@test.com", "deskpass123")
+	resProps := s.MakeRequest("GET", "/api/v1/properties", nil, staffToken)
+	s.Equal(http.StatusOK, resProps.Code)
+	var props entity.PaginatedResponse[entity.Property]
+	json.Unmarshal(resProps.Body.Bytes(), &props)
+	s.Require().Len(props.Data, 1)
+	s.Equal(s.propertyID, props.Data[0].ID)
+
+	resPricing := s.MakeRequest("PUT", "/api/v1/properties/"+s.propertyID, map[string]string{"name": "Nope"}, staffToken)
+	s.Equal(http.StatusForbidden, resPricing.Code, "the invited role applies")
+
+	res = s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{
+		"token": token, "password": "deskpass123", "first_name": "Front", "last_name": "Desk",
+	}, "")
+	s.Equal(http.StatusBadRequest, res.Code, "an invitation is accepted only once")
+
+	resList := s.MakeRequest("GET", "/api/v1/invitations", nil, s.ownerToken)
+	s.Equal(http.StatusOK, resList.Code)
+	var list []entity.Invitation
+	json.Unmarshal(resList.Body.Bytes(), &list)
+	s.Require().Len(list, 1)
+	s.Equal(entity.InvitationStatusAccepted, list[0].Status)
+}
+
+func (s *InvitationSuite) TestResendAndRevoke() {
+	inv := s.invite(s.ownerToken, map[string]interface{}{"email": "late@test.com", "role": "manager"})
+	oldToken := s.linkToken(inv.ID)
+
+	res := s.MakeRequest("POST", "/api/v1/invitations", map[string]interface{}{"email": "late@test.com", "role": "staff"}, s.ownerToken)
+	s.Equal(http.StatusConflict, res.Code, "one open invitation per address")
+
+	res = s.MakeRequest("POST", "/api/v1/invitations/"+inv.ID+"/resend", nil, s.ownerToken)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	newToken := s.linkToken(inv.ID)
+
+	var queued int
+	s.db.QueryRow(context.Background(), `SELECT COUNT(*) FROM email_outbox WHERE recipient = 'late@test.com'`).Scan(&queued)
+	s.Equal(2, queued)
+
+	res = s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{
+		"token": oldToken, "password": "latepass123", "first_name": "Late", "last_name": "Comer",
+	}, "")
+	s.Equal(http.StatusBadRequest, res.Code, "resending invalidates earlier links")
+
+	res = s.MakeRequest("DELETE", "/api/v1/invitations/"+inv.ID, nil, s.ownerToken)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+
+	res = s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{
+		"token": newToken, "password": "latepass123", "first_name": "Late", "last_name": "Comer",
+	}, "")
+	s.Equal(http.StatusBadRequest, res.Code, "revoked invitations cannot be accepted")
+
+	res = s.MakeRequest("DELETE", "/api/v1/invitations/"+inv.ID, nil, s.ownerToken)
+	s.Equal(http.StatusConflict, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/invitations/"+inv.ID+"/resend", nil, s.ownerToken)
+	s.Equal(http.StatusConflict, res.Code)
+}
+
+func (s *InvitationSuite) TestExistingUserJoinsWithOwnPassword() {
+	otherToken, _ := s.CreateOrgOwner("consultant@test.com", "CONS")
+	s.NotEmpty(otherToken)
+
+	inv := s.invite(s.ownerToken, map[string]interface{}{"email": "consultant@test.com", "role": "manager"})
+	token := s.linkToken(inv.ID)
+
+	res := s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{"token": token, "password": "wrong-password"}, "")
+	s.Equal(http.StatusUnauthorized, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{"token": token, "password": testPassword}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+
+	resLogin := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": "consultant@test.com", "password": testPassword}, "")
+	s.Require().Equal(http.StatusOK, resLogin.Code)
+	var login entity.AuthResponse
+	json.Unmarshal(resLogin.Body.Bytes(), &login)
+	s.Len(login.Organizations, 2)
+
+	res = s.MakeRequest("POST", "/api/v1/invitations", map[string]interface{}{"email": "consultant@test.com", "role": "staff"}, s.ownerToken)
+	s.Equal(http.StatusConflict, res.Code, "members cannot be invited again")
+}
+
+func (s *InvitationSuite) TestSharedAccountIsOnlyAMembershipHere() {
+	s.CreateOrgOwner("shared@test.com", "SHRD")
+	inv := s.invite(s.ownerToken, map[string]interface{}{"email": "shared@test.com", "role": "manager"})
+	res := s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{"token": s.linkToken(inv.ID), "password": testPassword}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+
+	var userID string
+	s.Require().NoError(s.db.QueryRow(context.Background(), `SELECT id FROM users WHERE email = 'shared@test.com'`).Scan(&userID))
+
+	res = s.MakeRequest("PUT", "/api/v1/users/"+userID, map[string]string{"email": "taken-over@test.com"}, s.ownerToken)
+	s.Equal(http.StatusForbidden, res.Code)
+	res = s.MakeRequest("PUT", "/api/v1/users/"+userID, map[string]string{"first_name": "Shared"}, s.ownerToken)
+	s.Equal(http.StatusOK, res.Code)
+
+	res = s.MakeRequest("DELETE", "/api/v1/users/"+userID, nil, s.ownerToken)
+	s.Require().Equal(http.StatusOK, res.Code)
+	res = s.MakeRequest("DELETE", "/api/v1/users/"+userID, nil, s.ownerToken)
+	s.Equal(http.StatusNotFound, res.Code)
+
+	resLogin := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": "shared@test.com", "password": testPassword}, "")
+	s.Require().Equal(http.StatusOK, resLogin.Code, "the account survives for its other organization")
+	var login entity.AuthResponse
+	json.Unmarshal(resLogin.Body.Bytes(), &login)
+	s.Len(login.Organizations, 1)
+}
+
+func (s *InvitationSuite) TestInvitersOnlyHandOutLowerRoles() {
+	managerInv := s.invite(s.ownerToken, map[string]interface{}{"email": "boss@test.com", "role": "manager"})
+	res := s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{
+		"token": s.linkToken(managerInv.ID), "password": "bosspass123", "first_name": "Bo", "last_name": "Ss",
+	}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	managerToken := s.login("boss@test.com", "bosspass123")
+
+	res = s.MakeRequest("POST", "/api/v1/invitations", map[string]interface{}{"email": "owner2@test.com", "role": "owner"}, s.ownerToken)
+	s.Equal(http.StatusForbidden, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/invitations", map[string]interface{}{"email": "peer@test.com", "role": "manager"}, managerToken)
+	s.Equal(http.StatusForbidden, res.Code)
+
+	staffInv := s.invite(managerToken, map[string]interface{}{"email": "helper@test.com", "role": "staff"})
+	res = s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{
+		"token": s.linkToken(staffInv.ID), "password": "helperpass1", "first_name": "He", "last_name": "Lper",
+	}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	staffToken := s.login("helper@test.com", "helperpass1")
+
+	res = s.MakeRequest("POST", "/api/v1/invitations", map[string]interface{}{"email": "friend@test.com", "role": "staff"}, staffToken)
+	s.Equal(http.StatusForbidden, res.Code)
+
+	otherToken, _ := s.CreateOrgOwner("other@test.com", "OTHR")
+	res = s.MakeRequest("DELETE", "/api/v1/invitations/"+staffInv.ID, nil, otherToken)
+	s.Equal(http.StatusNotFound, res.Code)
+}
+
+func (s *InvitationSuite) TestInvitationReplacesUnverifiedSignup() {
+	res := s.MakeRequest("POST", "/api/v1/auth/register", map[string]string{
+		"email": "claimed@test.com", "password": "squatter123", "org_name": "Squat Inc",
+		"first_name": "Sq", "last_name": "Uatter",
+	}, "")
+	s.Require().Equal(http.StatusAccepted, res.Code, res.Body.String())
+
+	inv := s.invite(s.ownerToken, map[string]interface{}{"email": "claimed@test.com", "role": "staff"})
+	res = s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{
+		"token": s.linkToken(inv.ID), "password": "rightful123", "first_name": "Real", "last_name": "Owner",
+	}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+
+	s.login("claimed@test.com", "rightful123")
+	res = s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": "claimed@test.com", "password": "squatter123"}, "")
+	s.Equal(http.StatusUnauthorized, res.Code)
+
+	var orgs int
+	s.db.QueryRow(context.Background(), `SELECT COUNT(*) FROM organizations WHERE name = 'Squat Inc'`).Scan(&orgs)
+	s.Zero(orgs, "the squatted signup goes away with its account")
+}
+
+func (s *InvitationSuite) TestRestrictedManagersOnlyManageTheirPropertiesInvitations() {
+	res := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"name": "Other Hotel", "code": "OTH", "type": "HOTEL",
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	otherProperty := data["property_id"]
+
+	managerInv := s.invite(s.ownerToken, map[string]interface{}{
+		"email": "local@test.com", "role": "manager", "property_ids": []string{s.propertyID},
+	})
+	res = s.MakeRequest("POST", "/api/v1/invitations/accept", map[string]string{
+		"token": s.linkToken(managerInv.ID), "password": "localpass1", "first_name": "Lo", "last_name": "Cal",
+	}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	managerToken := s.login("local@test.com", "localpass1")
+
+	elsewhere := s.invite(s.ownerToken, map[string]interface{}{
+		"email": "elsewhere@test.com", "role": "staff", "property_ids": []string{otherProperty},
+	})
+	everywhere := s.invite(s.ownerToken, map[string]interface{}{"email": "everywhere@test.com", "role": "staff"})
+	mine := s.invite(managerToken, map[string]interface{}{
+		"email": "mine@test.com", "role": "staff", "property_ids": []string{s.propertyID},
+	})
+
+	for _, inv := range []entity.Invitation{elsewhere, everywhere} {
+		res = s.MakeRequest("POST", "/api/v1/invitations/"+inv.ID+"/resend", nil, managerToken)
+		s.Equal(http.StatusForbidden, res.Code)
+		res = s.MakeRequest("DELETE", "/api/v1/invitations/"+inv.ID, nil, managerToken)
+		s.Equal(http.StatusForbidden, res.Code)
+	}
+	res = s.MakeRequest("POST", "/api/v1/invitations/"+mine.ID+"/resend", nil, managerToken)
+	s.Equal(http.StatusOK, res.Code)
+	res = s.MakeRequest("DELETE", "/api/v1/invitations/"+mine.ID, nil, managerToken)
+	s.Equal(http.StatusOK, res.Code)
+}
+
+func TestInvitationSuite(t *testing.T) {
+	suite.Run(t, new(InvitationSuite))
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type InvoiceSuite struct {
+	BaseSuite
+	token      string
+	orgID      string
+	propertyID string
+	unitTypeID string
+}
+
+func (s *InvoiceSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.token, s.orgID = s.GetAdminTokenAndOrg()
+
+	resH := s.MakeRequest("POST", "/api/v1/properties", map[string]interface{}{
+		"organization_id": s.orgID,
+		"name":            "Invoice Property",
+		"code":            "INV",
+		"type":            "HOTEL",
+		"tax_rate":        21.0,
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resH.Code, resH.Body.String())
+
+	var dataH map[string]string
+	json.Unmarshal(resH.Body.Bytes(), &dataH)
+	s.propertyID = dataH["property_id"]
+
+	resR := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id":    s.propertyID,
+		"name":           "Std", "code": "STD",
+		"total_quantity": 5,
+		"base_price":     121.0,
+		"max_occupancy":  2, "max_adults": 2, "max_children": 0,
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resR.Code)
+
+	var dataR map[string]string
+	json.Unmarshal(resR.Body.Bytes(), &dataR)
+	s.unitTypeID = dataR["unit_type_id"]
+}
+
+func (s *InvoiceSuite) createReservation(email, start, end string) string {
+	res := s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+		"unit_type_id":     s.unitTypeID,
+		"guest_email":      email,
+		"guest_first_name": "Ana", "guest_last_name": "Invoice",
+		"start":            start, "end": end,
+		"adults":           1, "children": 0,
+	}, "")
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+
+	var data map[string]interface{}
+	json.Unmarshal(res.Body.Bytes(), &data)
+	code := data["reservation_code"].(string)
+
+	resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+code, nil, data["access_token"].(string))
+	s.Require().Equal(http.StatusOK, resGet.Code)
+
+	var reservation entity.Reservation
+	json.Unmarshal(resGet.Body.Bytes(), &reservation)
+	return reservation.ID
+}
+
+func (s *InvoiceSuite) checkOut(reservationID string) entity.Invoice {
+	res := s.MakeRequest("POST", "/api/v1/reservations/"+reservationID+"/check-out", nil, s.token)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+
+	var invoice entity.Invoice
+	json.Unmarshal(res.Body.Bytes(), &invoice)
+	return invoice
+}
+
+func (s *InvoiceSuite) TestCheckOutIssuesInvoice() {
+	resID := s.createReservation("ana@test.com", "2025-03-01", "2025-03-03")
+
+	invoice := s.checkOut(resID)
+	s.Equal("INV-INV-000001", invoice.Number)
+	s.Equal(entity.DocumentTypeInvoice, invoice.DocumentType)
+	s.Equal(242.0, invoice.Total)
+	s.Equal(200.0, invoice.Subtotal)
+	s.Equal(42.0, invoice.TaxAmount)
+	s.Equal(21.0, invoice.TaxRate)
+	s.NotEmpty(invoice.Lines)
+
+	again := s.MakeRequest("POST", "/api/v1/reservations/"+resID+"/check-out", nil, s.token)
+	s.Equal(http.StatusConflict, again.Code)
+
+	resGet := s.MakeRequest("GET", "/api/v1/invoices/"+invoice.ID, nil, s.token)
+	s.Equal(http.StatusOK, resGet.Code)
+	s.Contains(resGet.Body.String(), invoice.Number)
+}
+
+func (s *InvoiceSuite) TestSequentialNumbering() {
+	first := s.checkOut(s.createReservation("one@test.com", "2025-04-01", "2025-04-02"))
+	second := s.checkOut(s.createReservation("two@test.com", "2025-04-03", "2025-04-04"))
+
+	s.Equal(1, first.Sequence)
+	s.Equal(2, second.Sequence)
+	s.Equal("INV-INV-000002", second.Number)
+
+	res := s.MakeRequest("GET", "/api/v1/invoices?property_id="+s.propertyID, nil, s.token)
+	s.Equal(http.StatusOK, res.Code)
+
+	var page entity.PaginatedResponse[entity.Invoice]
+	json.Unmarshal(res.Body.Bytes(), &page)
+	s.Equal(int64(2), page.Meta.TotalItems)
+}
+
+func (s *InvoiceSuite) TestCreditNote() {
+	invoice := s.checkOut(s.createReservation("credit@test.com", "2025-05-01", "2025-05-03"))
+
+	resMissing := s.MakeRequest("POST", "/api/v1/invoices/"+invoice.ID+"/credit-notes", map[string]interface{}{}, s.token)
+	s.Equal(http.StatusBadRequest, resMissing.Code)
+
+	resPartial := s.MakeRequest("POST", "/api/v1/invoices/"+invoice.ID+"/credit-notes", map[string]interface{}{
+		"reason": "Minibar charge disputed",
+		"amount": 42.0,
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resPartial.Code, resPartial.Body.String())
+
+	var note entity.Invoice
+	json.Unmarshal(resPartial.Body.Bytes(), &note)
+	s.Equal("INV-CN-000001", note.Number)
+	s.Equal(-42.0, note.Total)
+	s.Require().NotNil(note.CorrectsInvoiceID)
+	s.Equal(invoice.ID, *note.CorrectsInvoiceID)
+
+	resTooMuch := s.MakeRequest("POST", "/api/v1/invoices/"+invoice.ID+"/credit-notes", map[string]interface{}{
+		"reason": "Refund",
+		"amount": 500.0,
+	}, s.token)
+	s.Equal(http.StatusConflict, resTooMuch.Code)
+
+	resRest := s.MakeRequest("POST", "/api/v1/invoices/"+invoice.ID+"/credit-notes", map[string]interface{}{
+		"reason": "Stay cancelled",
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resRest.Code)
+
+	var rest entity.Invoice
+	json.Unmarshal(resRest.Body.Bytes(), &rest)
+	s.Equal(-200.0, rest.Total)
+}
+
+func (s *InvoiceSuite) TestInvoicePDF() {
+	invoice := s.checkOut(s.createReservation("pdf@test.com", "2025-06-01", "2025-06-02"))
+
+	res := s.MakeRequest("GET", "/api/v1/invoices/"+invoice.ID+"/pdf", nil, s.token)
+	s.Require().Equal(http.StatusOK, res.Code)
+	s.Equal("application/pdf", res.Header().Get("Content-Type"))
+	s.True(strings.HasPrefix(res.Body.String(), "%PDF"))
+	s.Contains(res.Header().Get("Content-Disposition"), invoice.Number)
+}
+
+func TestInvoiceSuite(t *testing.T) {
+	suite.Run(t, new(InvoiceSuite))
+}
@@ -36,7 +36,7 @@ func (s *LifecycleSuite) TestFullLifecycle() {
 		res := s.MakeRequest("POST", "/api/v1/users", map[string]string{
 			"organization_id": s.orgID,
 			"email":           "ceo@global.com",
-			"password":        "pass",
+			"password":        "ceopass123",
 			"role":            "owner",
 			"first_name":      "The",
 			"last_name":       "CEO",
@@ -48,7 +48,7 @@ func (s *LifecycleSuite) TestFullLifecycle() {
 	s.Run("3. Login Owner", func() {
 		res := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{
 			"email":    "ceo@global.com",
-			"password": "pass",
+			"password": "ceopass123",
 		}, "")
 		s.Equal(http.StatusOK, res.Code)
 		var data map[string]string
@@ -77,7 +77,6 @@ func (s *LifecycleSuite) TestFullLifecycle() {
 			"code":           "SUI",
 			"total_quantity": 10,
 			"max_occupancy":  2, "max_adults": 2, "max_children": 0,
-			"amenities":      []string{"wifi"},
 		}, s.ownerToken)
 		
 		s.Equal(http.StatusCreated, res.Code)
@@ -142,7 +141,7 @@ func (s *LifecycleSuite) TestFullLifecycle() {
 		json.Unmarshal(res.Body.Bytes(), &dataRes)
 		code := dataRes["reservation_code"].(string)
 
-		resGet := s.MakeRequest("GET", "/api/v1/reservations/"+code, nil, "")
+		resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+code, nil, dataRes["access_token"].(string))
 		s.Equal(http.StatusOK, resGet.Code)
 
 		var reservation entity.Reservation
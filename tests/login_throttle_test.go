@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ecelayes/pms-backend/pkg/auth"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoginThrottleSuite struct {
+	BaseSuite
+	ownerToken string
+}
+
+func (s *LoginThrottleSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.ownerToken, _ = s.GetAdminTokenAndOrg()
+}
+
+// requestFrom sends an unauthenticated request from the given client IP.
+func (s *LoginThrottleSuite) requestFrom(clientIP, url string, body interface{}) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", url, bytes.NewReader(jsonBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.RemoteAddr = clientIP + ":40000"
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *LoginThrottleSuite) login(password string) *httptest.ResponseRecorder {
+	return s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": "owner@test.com", "password": password}, "")
+}
+
+func (s *LoginThrottleSuite) TestFailuresSlowDownAndLockAccount() {
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		s.Equal(http.StatusUnauthorized, s.login("wrong").Code)
+	}
+	s.Equal(http.StatusOK, s.login(testPassword).Code, "the first failures are free")
+
+	for i := 0; i < 4; i++ {
+		s.Equal(http.StatusUnauthorized, s.login("wrong").Code)
+	}
+	res := s.login(testPassword)
+	s.Equal(http.StatusUnauthorized, res.Code, "even the right password waits out the delay")
+	s.Empty(res.Header().Get("Retry-After"), "a throttled account answers like an unknown one")
+	unknown := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": "nobody@test.com", "password": "wrong"}, "")
+	s.Equal(unknown.Code, res.Code)
+	s.Equal(unknown.Body.String(), res.Body.String())
+
+	_, err := s.db.Exec(ctx, `UPDATE users SET locked_until = NULL, failed_login_attempts = 9 WHERE email = 'owner@test.com'`)
+	s.Require().NoError(err)
+	s.Equal(http.StatusUnauthorized, s.login("wrong").Code)
+
+	var lockedUntil time.Time
+	s.db.QueryRow(ctx, `SELECT locked_until FROM users WHERE email = 'owner@test.com'`).Scan(&lockedUntil)
+	s.True(lockedUntil.After(time.Now().Add(14*time.Minute)), "the tenth failure locks the account")
+	s.Equal(http.StatusUnauthorized, s.login(testPassword).Code)
+
+	var failures int
+	_, err = s.db.Exec(ctx, `UPDATE users SET locked_until = NOW() - INTERVAL '1 second' WHERE email = 'owner@test.com'`)
+	s.Require().NoError(err)
+	s.Equal(http.StatusUnauthorized, s.login("wrong").Code)
+	var wait *time.Time
+	s.db.QueryRow(ctx, `SELECT failed_login_attempts, locked_until FROM users WHERE email = 'owner@test.com'`).Scan(&failures, &wait)
+	s.Equal(1, failures, "the count starts over once a lockout has run out")
+	s.Nil(wait, "the first failure after a lockout is free again")
+
+	s.Equal(http.StatusOK, s.login(testPassword).Code)
+	s.db.QueryRow(ctx, `SELECT failed_login_attempts FROM users WHERE email = 'owner@test.com'`).Scan(&failures)
+	s.Equal(0, failures, "signing in clears the count")
+}
+
+func (s *LoginThrottleSuite) TestConcurrentGuessesAreCountedBeforeChecking() {
+	ctx := context.Background()
+	_, err := s.db.Exec(ctx, `UPDATE users SET failed_login_attempts = 9 WHERE email = 'owner@test.com'`)
+	s.Require().NoError(err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.requestFrom("203.0.113.20", "/api/v1/auth/login", map[string]string{"email": "owner@test.com", "password": "wrong"})
+		}()
+	}
+	wg.Wait()
+
+	var failures int
+	s.db.QueryRow(ctx, `SELECT failed_login_attempts FROM users WHERE email = 'owner@test.com'`).Scan(&failures)
+	s.Equal(10, failures, "only one guess got in before the lockout")
+	res := s.requestFrom("203.0.113.20", "/api/v1/auth/login", map[string]string{"email": "owner@test.com", "password": testPassword})
+	s.Equal(http.StatusUnauthorized, res.Code)
+}
+
+func (s *LoginThrottleSuite) TestClientsAreThrottledPerIP() {
+	for i := 0; i < 20; i++ {
+		res := s.requestFrom("203.0.113.7", "/api/v1/auth/login", map[string]string{"email": "nobody@test.com", "password": "guess"})
+		s.Require().Equal(http.StatusUnauthorized, res.Code)
+	}
+	res := s.requestFrom("203.0.113.7", "/api/v1/auth/login", map[string]string{"email": "owner@test.com", "password": testPassword})
+	s.Equal(http.StatusTooManyRequests, res.Code)
+	res = s.requestFrom("203.0.113.7", "/api/v1/auth/reset-password", map[string]string{"token": "guess", "new_password": "long-enough-now"})
+	s.Equal(http.StatusTooManyRequests, res.Code, "reset tokens share the sign-in budget")
+
+	for i := 0; i < 20; i++ {
+		res = s.requestFrom("203.0.113.9", "/api/v1/auth/reset-password", map[string]string{"token": "guess", "new_password": "long-enough-now"})
+		s.Require().Equal(http.StatusUnauthorized, res.Code)
+	}
+	res = s.requestFrom("203.0.113.9", "/api/v1/auth/reset-password", map[string]string{"token": "guess", "new_password": "long-enough-now"})
+	s.Equal(http.StatusTooManyRequests, res.Code)
+
+	res = s.requestFrom("198.51.100.9", "/api/v1/auth/login", map[string]string{"email": "owner@test.com", "password": testPassword})
+	s.Equal(http.StatusOK, res.Code, "other clients are unaffected")
+
+	for i := 0; i < 10; i++ {
+		res = s.requestFrom("203.0.113.8", "/api/v1/auth/forgot-password", map[string]string{"email": "nobody@test.com"})
+		s.Require().Equal(http.StatusOK, res.Code)
+	}
+	res = s.requestFrom("203.0.113.8", "/api/v1/auth/forgot-password", map[string]string{"email": "nobody@test.com"})
+	s.Equal(http.StatusTooManyRequests, res.Code)
+}
+
+func (s *LoginThrottleSuite) TestResetEmailsAreRateLimitedPerAccount() {
+	ctx := context.Background()
+	countQueued := func() int {
+		var n int
+		s.db.QueryRow(ctx, `SELECT COUNT(*) FROM email_outbox WHERE recipient = 'owner@test.com'`).Scan(&n)
+		return n
+	}
+
+	for i := 0; i < 3; i++ {
+		res := s.MakeRequest("POST", "/api/v1/auth/forgot-password", map[string]string{"email": "owner@test.com"}, "")
+		s.Equal(http.StatusOK, res.Code)
+	}
+	s.Equal(1, countQueued())
+
+	_, err := s.db.Exec(ctx, `UPDATE users SET password_reset_requested_at = NOW() - INTERVAL '6 minutes' WHERE email = 'owner@test.com'`)
+	s.Require().NoError(err)
+	res := s.MakeRequest("POST", "/api/v1/auth/forgot-password", map[string]string{"email": "owner@test.com"}, "")
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(2, countQueued())
+}
+
+func (s *LoginThrottleSuite) TestPasswordPolicy() {
+	res := s.MakeRequest("POST", "/api/v1/users", map[string]string{
+		"email": "desk@test.com", "password": "short", "role": "staff",
+		"first_name": "Front", "last_name": "Desk",
+	}, s.ownerToken)
+	s.Equal(http.StatusBadRequest, res.Code)
+
+	var userID, salt string
+	err := s.db.QueryRow(context.Background(), `SELECT id, salt FROM users WHERE email = 'owner@test.com'`).Scan(&userID, &salt)
+	s.Require().NoError(err)
+	resetToken, _ := auth.GenerateResetToken(userID, salt)
+
+	res = s.MakeRequest("POST", "/api/v1/auth/reset-password", map[string]string{"token": resetToken, "new_password": "short"}, "")
+	s.Equal(http.StatusBadRequest, res.Code)
+	res = s.MakeRequest("POST", "/api/v1/auth/reset-password", map[string]string{"token": resetToken, "new_password": "long-enough-now"}, "")
+	s.Equal(http.StatusOK, res.Code, "a rejected password leaves the link usable")
+
+	strict := auth.PasswordPolicy{MinLength: 10, RequireUpper: true, RequireDigit: true, RequireSymbol: true}
+	s.Error(strict.Validate("Sh0rt!"))
+	s.Error(strict.Validate("nouppercase1!"))
+	s.Error(strict.Validate("NoDigitsHere!"))
+	s.Error(strict.Validate("NoSymbols123"))
+	s.NoError(strict.Validate("Correct-Horse-9"))
+}
+
+func TestLoginThrottleSuite(t *testing.T) {
+	suite.Run(t, new(LoginThrottleSuite))
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PermissionSuite struct {
+	BaseSuite
+	ownerToken   string
+	orgID        string
+	managerToken string
+	staffToken   string
+	propertyID   string
+	unitTypeID   string
+	ratePlanID   string
+}
+
+func (s *PermissionSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.ownerToken, s.orgID = s.GetAdminTokenAndOrg()
+	s.managerToken = s.createMember("manager@test.com", "manager")
+	s.staffToken = s.createMember("staff@test.com", "staff")
+
+	resP := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"name": "Perm Hotel", "code": "PRM", "type": "HOTEL",
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, resP.Code, resP.Body.String())
+	var dataP map[string]string
+	json.Unmarshal(resP.Body.Bytes(), &dataP)
+	s.propertyID = dataP["property_id"]
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": s.propertyID, "name": "Std", "code": "STD",
+		"total_quantity": 5, "base_price": 100.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, resU.Code, resU.Body.String())
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+	s.unitTypeID = dataU["unit_type_id"]
+
+	resRP := s.MakeRequest("POST", "/api/v1/rate-plans", map[string]interface{}{
+		"property_id": s.propertyID, "unit_type_id": s.unitTypeID, "name": "Flexible",
+		"cancellation_policy": map[string]interface{}{"is_refundable": true},
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, resRP.Code, resRP.Body.String())
+	var dataRP map[string]string
+	json.Unmarshal(resRP.Body.Bytes(), &dataRP)
+	s.ratePlanID = dataRP["rate_plan_id"]
+}
+
+func (s *PermissionSuite) createMember(email, role string) string {
+	res := s.MakeRequest("POST", "/api/v1/users", map[string]string{
+		"email": email, "password": "secret123", "role": role,
+		"first_name": "Team", "last_name": "Member",
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+
+	resLogin := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": email, "password": "secret123"}, "")
+	s.Require().Equal(http.StatusOK, resLogin.Code)
+	var data map[string]string
+	json.Unmarshal(resLogin.Body.Bytes(), &data)
+	return data["token"]
+}
+
+func (s *PermissionSuite) TestStaffCannotChangeRatesOrPrices() {
+	res := s.MakeRequest("GET", "/api/v1/properties/"+s.propertyID, nil, s.staffToken)
+	s.Equal(http.StatusOK, res.Code)
+
+	res = s.MakeRequest("GET", "/api/v1/rate-plans/"+s.ratePlanID, nil, s.staffToken)
+	s.Equal(http.StatusOK, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/pricing/bulk", map[string]interface{}{
+		"unit_type_id": s.unitTypeID, "start": "2026-01-01", "end": "2026-01-31", "price": 1.0,
+	}, s.staffToken)
+	s.Equal(http.StatusForbidden, res.Code)
+	s.Contains(res.Body.String(), "pricing.write")
+
+	res = s.MakeRequest("DELETE", "/api/v1/rate-plans/"+s.ratePlanID, nil, s.staffToken)
+	s.Equal(http.StatusForbidden, res.Code)
+
+	res = s.MakeRequest("PUT", "/api/v1/properties/"+s.propertyID, map[string]string{"name": "Renamed"}, s.staffToken)
+	s.Equal(http.StatusForbidden, res.Code)
+
+	res = s.MakeRequest("GET", "/api/v1/users", nil, s.staffToken)
+	s.Equal(http.StatusForbidden, res.Code)
+}
+
+func (s *PermissionSuite) TestManagerRunsPropertyButNotOrganization() {
+	res := s.MakeRequest("POST", "/api/v1/pricing/bulk", map[string]interface{}{
+		"unit_type_id": s.unitTypeID, "start": "2026-01-01", "end": "2026-01-31", "price": 120.0,
+	}, s.managerToken)
+	s.Equal(http.StatusOK, res.Code)
+
+	res = s.MakeRequest("DELETE", "/api/v1/rate-plans/"+s.ratePlanID, nil, s.managerToken)
+	s.Equal(http.StatusOK, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/users", map[string]string{
+		"email": "another@test.com", "password": "secret123", "role": "staff",
+		"first_name": "Another", "last_name": "Member",
+	}, s.managerToken)
+	s.Equal(http.StatusForbidden, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+		"url": "https://example.com/hook", "events": []string{"reservation.created"},
+	}, s.managerToken)
+	s.Equal(http.StatusForbidden, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/amenities", map[string]string{"name": "Sauna"}, s.managerToken)
+	s.Equal(http.StatusForbidden, res.Code)
+}
+
+func (s *PermissionSuite) TestOwnerManagesMembers() {
+	res := s.MakeRequest("GET", "/api/v1/users", nil, s.ownerToken)
+	s.Equal(http.StatusOK, res.Code)
+
+	res = s.MakeRequest("DELETE", "/api/v1/rate-plans/"+s.ratePlanID, nil, s.ownerToken)
+	s.Equal(http.StatusOK, res.Code)
+}
+
+func TestPermissionSuite(t *testing.T) {
+	suite.Run(t, new(PermissionSuite))
+}
@@ -35,7 +35,6 @@ func (s *PricingSuite) SetupTest() {
 		"name":           "R", "code": "RRR", 
 		"total_quantity": 5,
 		"max_occupancy":  2, "max_adults": 2, "max_children": 0,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	
 	var dataR map[string]string
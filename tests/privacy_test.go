@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type PrivacySuite struct {
+	BaseSuite
+	token      string
+	orgID      string
+	unitTypeID string
+}
+
+func (s *PrivacySuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.token, s.orgID = s.GetAdminTokenAndOrg()
+
+	resH := s.MakeRequest("POST", "/api/v1/properties", map[string]interface{}{
+		"organization_id": s.orgID, "name": "Privacy Property", "code": "PRV", "type": "HOTEL",
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resH.Code)
+	var dataH map[string]string
+	json.Unmarshal(resH.Body.Bytes(), &dataH)
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": dataH["property_id"], "name": "Std", "code": "STD",
+		"total_quantity": 5, "base_price": 100.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, resU.Code)
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+	s.unitTypeID = dataU["unit_type_id"]
+}
+
+func (s *PrivacySuite) book(email string) entity.Reservation {
+	res := s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+		"unit_type_id":     s.unitTypeID,
+		"guest_email":      email,
+		"guest_first_name": "Eva", "guest_last_name": "Private", "guest_phone": "+34911222333",
+		"start":            "2025-01-10", "end": "2025-01-12",
+		"adults":           1, "children": 0,
+	}, "")
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+data["reservation_code"], nil, data["access_token"])
+	var reservation entity.Reservation
+	json.Unmarshal(resGet.Body.Bytes(), &reservation)
+	return reservation
+}
+
+func (s *PrivacySuite) TestExportAndErasure() {
+	stay := s.book("eva@test.com")
+	s.Require().Equal(http.StatusCreated, s.MakeRequest("POST", "/api/v1/guests/"+stay.GuestID+"/notes", map[string]string{
+		"body": "Allergic to feathers",
+	}, s.token).Code)
+
+	resBlocked := s.MakeRequest("POST", "/api/v1/guests/"+stay.GuestID+"/erase", nil, s.token)
+	s.Equal(http.StatusConflict, resBlocked.Code)
+
+	resCheckOut := s.MakeRequest("POST", "/api/v1/reservations/"+stay.ID+"/check-out", nil, s.token)
+	s.Require().Equal(http.StatusCreated, resCheckOut.Code)
+	var invoice entity.Invoice
+	json.Unmarshal(resCheckOut.Body.Bytes(), &invoice)
+
+	resExport := s.MakeRequest("GET", "/api/v1/guests/"+stay.GuestID+"/export", nil, s.token)
+	s.Require().Equal(http.StatusOK, resExport.Code)
+	var export entity.GuestDataExport
+	json.Unmarshal(resExport.Body.Bytes(), &export)
+	s.Equal("eva@test.com", export.Profile.Email)
+	s.Len(export.Reservations, 1)
+	s.Require().Len(export.Folios, 1)
+	s.NotEmpty(export.Folios[0].Lines)
+	s.Require().Len(export.Communications, 1)
+	s.Contains(export.Communications[0].Body, stay.ReservationCode)
+	s.Len(export.Notes, 1)
+
+	resErase := s.MakeRequest("POST", "/api/v1/guests/"+stay.GuestID+"/erase", nil, s.token)
+	s.Require().Equal(http.StatusOK, resErase.Code, resErase.Body.String())
+	s.Equal(http.StatusNotFound, s.MakeRequest("GET", "/api/v1/guests/"+stay.GuestID, nil, s.token).Code)
+
+	ctx := context.Background()
+	var email, firstName string
+	s.Require().NoError(s.db.QueryRow(ctx, `SELECT email, first_name FROM guests WHERE id = $1`, stay.GuestID).Scan(&email, &firstName))
+	email, _ = s.piiKeys.Decrypt(email)
+	firstName, _ = s.piiKeys.Decrypt(firstName)
+	s.NotContains(email, "eva")
+	s.Equal("Erased", firstName)
+
+	var recipient, body string
+	s.Require().NoError(s.db.QueryRow(ctx, `SELECT recipient, body FROM email_outbox WHERE guest_id = $1`, stay.GuestID).Scan(&recipient, &body))
+	s.NotEqual("eva@test.com", recipient)
+	s.Empty(body)
+
+	var notes int
+	s.Require().NoError(s.db.QueryRow(ctx, `SELECT COUNT(*) FROM guest_notes WHERE guest_id = $1`, stay.GuestID).Scan(&notes))
+	s.Zero(notes)
+
+	// Financial records are kept as issued.
+	resInvoice := s.MakeRequest("GET", "/api/v1/invoices/"+invoice.ID, nil, s.token)
+	s.Require().Equal(http.StatusOK, resInvoice.Code)
+	var kept entity.Invoice
+	json.Unmarshal(resInvoice.Body.Bytes(), &kept)
+	s.Equal("eva@test.com", kept.CustomerEmail)
+	s.Equal(invoice.Total, kept.Total)
+
+	resLog := s.MakeRequest("GET", "/api/v1/privacy/requests", nil, s.token)
+	s.Require().Equal(http.StatusOK, resLog.Code)
+	var page entity.PaginatedResponse[entity.PrivacyRequest]
+	json.Unmarshal(resLog.Body.Bytes(), &page)
+	s.Require().Len(page.Data, 2)
+	s.Equal(entity.PrivacyRequestErasure, page.Data[0].RequestType)
+	s.Equal(entity.PrivacyRequestExport, page.Data[1].RequestType)
+	s.NotNil(page.Data[0].RequestedBy)
+}
+
+func (s *PrivacySuite) TestRetentionPolicy() {
+	resBad := s.MakeRequest("PUT", "/api/v1/privacy/retention", map[string]int{"guest_retention_days": 0}, s.token)
+	s.Equal(http.StatusBadRequest, resBad.Code)
+
+	resSet := s.MakeRequest("PUT", "/api/v1/privacy/retention", map[string]int{"guest_retention_days": 30}, s.token)
+	s.Require().Equal(http.StatusOK, resSet.Code)
+
+	resGet := s.MakeRequest("GET", "/api/v1/privacy/retention", nil, s.token)
+	var settings entity.RetentionSettings
+	json.Unmarshal(resGet.Body.Bytes(), &settings)
+	s.Require().NotNil(settings.GuestRetentionDays)
+	s.Equal(30, *settings.GuestRetentionDays)
+
+	past := s.book("old@test.com")
+	s.Require().Equal(http.StatusCreated, s.MakeRequest("POST", "/api/v1/reservations/"+past.ID+"/check-out", nil, s.token).Code)
+	open := s.book("open@test.com")
+
+	uc := usecase.NewPrivacyUseCase(s.db,
+		repository.NewGuestRepository(s.db, s.piiKeys),
+		repository.NewInvoiceRepository(s.db),
+		repository.NewEmailOutboxRepository(s.db),
+		repository.NewOrganizationRepository(s.db),
+		repository.NewPrivacyRepository(s.db),
+		zap.NewNop(),
+	)
+	erased, err := uc.ApplyRetention(context.Background())
+	s.Require().NoError(err)
+	s.Equal(1, erased)
+
+	s.Equal(http.StatusNotFound, s.MakeRequest("GET", "/api/v1/guests/"+past.GuestID, nil, s.token).Code)
+	s.Equal(http.StatusOK, s.MakeRequest("GET", "/api/v1/guests/"+open.GuestID, nil, s.token).Code)
+
+	var source string
+	s.Require().NoError(s.db.QueryRow(context.Background(),
+		`SELECT source FROM privacy_requests WHERE guest_id = $1`, past.GuestID,
+	).Scan(&source))
+	s.Equal(entity.PrivacySourceRetention, source)
+}
+
+func (s *PrivacySuite) TestDeletedAndMergedGuestsAreCovered() {
+	ctx := context.Background()
+	survivor := s.book("keep@test.com")
+	duplicate := s.book("dup@test.com")
+	for _, stay := range []entity.Reservation{survivor, duplicate} {
+		s.Require().Equal(http.StatusCreated, s.MakeRequest("POST", "/api/v1/reservations/"+stay.ID+"/check-out", nil, s.token).Code)
+	}
+	resMerge := s.MakeRequest("POST", "/api/v1/guests/"+survivor.GuestID+"/merge", map[string]string{"duplicate_id": duplicate.GuestID}, s.token)
+	s.Require().Equal(http.StatusOK, resMerge.Code, resMerge.Body.String())
+
+	resExport := s.MakeRequest("GET", "/api/v1/guests/"+duplicate.GuestID+"/export", nil, s.token)
+	s.Require().Equal(http.StatusOK, resExport.Code, "merged guests can still be exported")
+	resExport = s.MakeRequest("GET", "/api/v1/guests/"+survivor.GuestID+"/export", nil, s.token)
+	s.Require().Equal(http.StatusOK, resExport.Code)
+	var export entity.GuestDataExport
+	json.Unmarshal(resExport.Body.Bytes(), &export)
+	s.Require().Len(export.MergedProfiles, 1)
+	s.Equal("dup@test.com", export.MergedProfiles[0].Email)
+
+	resErase := s.MakeRequest("POST", "/api/v1/guests/"+survivor.GuestID+"/erase", nil, s.token)
+	s.Require().Equal(http.StatusOK, resErase.Code, resErase.Body.String())
+
+	var email string
+	var anonymized bool
+	s.Require().NoError(s.db.QueryRow(ctx,
+		`SELECT email, anonymized_at IS NOT NULL FROM guests WHERE id = $1`, duplicate.GuestID,
+	).Scan(&email, &anonymized))
+	email, _ = s.piiKeys.Decrypt(email)
+	s.True(anonymized, "erasing the survivor erases the duplicate merged into it")
+	s.NotContains(email, "dup")
+
+	var recipient string
+	s.Require().NoError(s.db.QueryRow(ctx,
+		`SELECT recipient FROM email_outbox WHERE guest_id = $1 LIMIT 1`, duplicate.GuestID,
+	).Scan(&recipient))
+	s.NotEqual("dup@test.com", recipient)
+
+	deleted := s.book("gone@test.com")
+	s.Require().Equal(http.StatusCreated, s.MakeRequest("POST", "/api/v1/reservations/"+deleted.ID+"/check-out", nil, s.token).Code)
+	s.Require().Equal(http.StatusOK, s.MakeRequest("DELETE", "/api/v1/guests/"+deleted.GuestID, nil, s.token).Code)
+	resErase = s.MakeRequest("POST", "/api/v1/guests/"+deleted.GuestID+"/erase", nil, s.token)
+	s.Equal(http.StatusOK, resErase.Code, "deleted guests can still be erased")
+}
+
+func TestPrivacySuite(t *testing.T) {
+	suite.Run(t, new(PrivacySuite))
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type PropertyScopeSuite struct {
+	BaseSuite
+	ownerToken  string
+	orgID       string
+	propertyA   string
+	propertyB   string
+	unitTypeB   string
+	staffUserID string
+	staffToken  string
+}
+
+func (s *PropertyScopeSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.ownerToken, s.orgID = s.GetAdminTokenAndOrg()
+	s.propertyA = s.createProperty("Hotel A", "HTA")
+	s.propertyB = s.createProperty("Hotel B", "HTB")
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": s.propertyB, "name": "Std", "code": "STD",
+		"total_quantity": 5, "base_price": 100.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, resU.Code, resU.Body.String())
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+	s.unitTypeB = dataU["unit_type_id"]
+
+	res := s.MakeRequest("POST", "/api/v1/users", map[string]interface{}{
+		"email": "desk@test.com", "password": "secret123", "role": "staff",
+		"first_name": "Front", "last_name": "Desk",
+		"property_ids": []string{s.propertyA},
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var dataUser map[string]string
+	json.Unmarshal(res.Body.Bytes(), &dataUser)
+	s.staffUserID = dataUser["user_id"]
+
+	resLogin := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": "desk@test.com", "password": "secret123"}, "")
+	s.Require().Equal(http.StatusOK, resLogin.Code)
+	var dataLogin map[string]string
+	json.Unmarshal(resLogin.Body.Bytes(), &dataLogin)
+	s.staffToken = dataLogin["token"]
+}
+
+func (s *PropertyScopeSuite) createProperty(name, code string) string {
+	res := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"name": name, "code": code, "type": "HOTEL",
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	return data["property_id"]
+}
+
+func (s *PropertyScopeSuite) listProperties(token string) []entity.Property {
+	res := s.MakeRequest("GET", "/api/v1/properties", nil, token)
+	s.Require().Equal(http.StatusOK, res.Code)
+	var response entity.PaginatedResponse[entity.Property]
+	json.Unmarshal(res.Body.Bytes(), &response)
+	return response.Data
+}
+
+func (s *PropertyScopeSuite) TestRestrictedMemberOnlySeesAssignedProperties() {
+	props := s.listProperties(s.staffToken)
+	s.Require().Len(props, 1)
+	s.Equal(s.propertyA, props[0].ID)
+
+	res := s.MakeRequest("GET", "/api/v1/properties/"+s.propertyA, nil, s.staffToken)
+	s.Equal(http.StatusOK, res.Code)
+
+	res = s.MakeRequest("GET", "/api/v1/properties/"+s.propertyB, nil, s.staffToken)
+	s.Equal(http.StatusNotFound, res.Code)
+
+	res = s.MakeRequest("GET", "/api/v1/unit-types/"+s.unitTypeB, nil, s.staffToken)
+	s.Equal(http.StatusNotFound, res.Code)
+
+	resUT := s.MakeRequest("GET", "/api/v1/unit-types?property_id="+s.propertyB, nil, s.staffToken)
+	s.Equal(http.StatusOK, resUT.Code)
+	var unitTypes entity.PaginatedResponse[entity.UnitType]
+	json.Unmarshal(resUT.Body.Bytes(), &unitTypes)
+	s.Empty(unitTypes.Data)
+
+	s.Len(s.listProperties(s.ownerToken), 2)
+}
+
+func (s *PropertyScopeSuite) TestOwnerChangesRestriction() {
+	resList := s.MakeRequest("GET", "/api/v1/users", nil, s.ownerToken)
+	var users entity.PaginatedResponse[entity.User]
+	json.Unmarshal(resList.Body.Bytes(), &users)
+	for _, u := range users.Data {
+		if u.ID == s.staffUserID {
+			s.Equal([]string{s.propertyA}, u.PropertyIDs)
+		}
+	}
+
+	res := s.MakeRequest("PUT", "/api/v1/users/"+s.staffUserID, map[string]interface{}{
+		"property_ids": []string{s.propertyB},
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+
+	props := s.listProperties(s.staffToken)
+	s.Require().Len(props, 1)
+	s.Equal(s.propertyB, props[0].ID)
+
+	res = s.MakeRequest("PUT", "/api/v1/users/"+s.staffUserID, map[string]interface{}{
+		"property_ids": []string{},
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	s.Len(s.listProperties(s.staffToken), 2)
+}
+
+func (s *PropertyScopeSuite) TestRestrictionOnlyAcceptsOwnProperties() {
+	otherToken, _ := s.CreateOrgOwner("other@test.com", "OTHR")
+	resP := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"name": "Elsewhere", "code": "ELS", "type": "HOTEL",
+	}, otherToken)
+	s.Require().Equal(http.StatusCreated, resP.Code)
+	var dataP map[string]string
+	json.Unmarshal(resP.Body.Bytes(), &dataP)
+
+	res := s.MakeRequest("PUT", "/api/v1/users/"+s.staffUserID, map[string]interface{}{
+		"property_ids": []string{dataP["property_id"]},
+	}, s.ownerToken)
+	s.Equal(http.StatusBadRequest, res.Code)
+
+	res = s.MakeRequest("PUT", "/api/v1/users/"+s.staffUserID, map[string]interface{}{
+		"property_ids": []string{"not-a-uuid"},
+	}, s.ownerToken)
+	s.Equal(http.StatusBadRequest, res.Code)
+}
+
+func (s *PropertyScopeSuite) TestLosingTheLastPropertyDoesNotOpenTheOrganization() {
+	_, err := s.db.Exec(context.Background(), `DELETE FROM properties WHERE id = $1`, s.propertyA)
+	s.Require().NoError(err)
+
+	s.Empty(s.listProperties(s.staffToken))
+	res := s.MakeRequest("GET", "/api/v1/properties/"+s.propertyB, nil, s.staffToken)
+	s.Equal(http.StatusNotFound, res.Code)
+}
+
+func (s *PropertyScopeSuite) TestRejectedUpdateChangesNothing() {
+	res := s.MakeRequest("PUT", "/api/v1/users/"+s.staffUserID, map[string]interface{}{
+		"role": "manager", "property_ids": []string{s.propertyB, uuid.NewString()},
+	}, s.ownerToken)
+	s.Equal(http.StatusBadRequest, res.Code)
+
+	var role string
+	s.Require().NoError(s.db.QueryRow(context.Background(),
+		`SELECT role FROM organization_members WHERE user_id = $1`, s.staffUserID,
+	).Scan(&role))
+	s.Equal(entity.OrgRoleStaff, role, "the role change is rolled back with the property change")
+	s.Equal(s.propertyA, s.listProperties(s.staffToken)[0].ID)
+}
+
+func TestPropertyScopeSuite(t *testing.T) {
+	suite.Run(t, new(PropertyScopeSuite))
+}
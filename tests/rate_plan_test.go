@@ -37,7 +37,6 @@ func (s *RatePlanSuite) SetupTest() {
 		"code":           "DLX",
 		"total_quantity": 10,
 		"max_occupancy":  2, "max_adults": 2, "max_children": 0,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	var dataR map[string]string
 	json.Unmarshal(resR.Body.Bytes(), &dataR)
@@ -121,12 +120,12 @@ func (s *RatePlanSuite) TestRatePlanLifecycle() {
 	json.Unmarshal(resRes.Body.Bytes(), &resData)
 	resCode := resData["reservation_code"]
 	
-	resGet := s.MakeRequest("GET", "/api/v1/reservations/"+resCode, nil, "")
+	resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+resCode, nil, resData["access_token"])
 	var resObj map[string]interface{}
 	json.Unmarshal(resGet.Body.Bytes(), &resObj)
 	resID := resObj["id"].(string)
 
-	s.MakeRequest("POST", "/api/v1/reservations/"+resID+"/cancel", nil, "")
+	s.MakeGuestRequest("POST", "/api/v1/reservations/"+resID+"/cancel", nil, resData["access_token"])
 
 	resDelSuccess := s.MakeRequest("DELETE", "/api/v1/rate-plans/"+planID, nil, s.token)
 	s.Equal(http.StatusOK, resDelSuccess.Code)
@@ -41,7 +41,6 @@ func (s *ReservationSuite) SetupTest() {
 		"total_quantity": 5,
 		"base_price":     100.0,
 		"max_occupancy":  4, "max_adults": 2, "max_children": 2,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	s.Require().Equal(http.StatusCreated, resR.Code)
 
@@ -71,7 +70,7 @@ func (s *ReservationSuite) TestReservationCRUD() {
 	json.Unmarshal(res.Body.Bytes(), &data)
 	code := data["reservation_code"].(string)
 
-	resGet := s.MakeRequest("GET", "/api/v1/reservations/"+code, nil, "")
+	resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+code, nil, data["access_token"].(string))
 	s.Equal(http.StatusOK, resGet.Code)
 	
 	bodyString := resGet.Body.String()
@@ -114,7 +113,7 @@ func (s *ReservationSuite) TestReservationWithMealPlan() {
 	json.Unmarshal(res.Body.Bytes(), &dataRes)
 	code := dataRes["reservation_code"].(string)
 
-	resGet := s.MakeRequest("GET", "/api/v1/reservations/"+code, nil, "")
+	resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+code, nil, dataRes["access_token"].(string))
 	s.Equal(http.StatusOK, resGet.Code)
 	
 	var resData entity.Reservation
@@ -132,7 +131,6 @@ func (s *ReservationSuite) TestReservationFallbackPrice() {
 		"total_quantity": 5,
 		"base_price":     120.0,
 		"max_occupancy":  2, "max_adults": 2, "max_children": 0,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	s.Require().Equal(http.StatusCreated, resR.Code)
 
@@ -154,7 +152,7 @@ func (s *ReservationSuite) TestReservationFallbackPrice() {
 	json.Unmarshal(res.Body.Bytes(), &dataRes)
 	code := dataRes["reservation_code"].(string)
 
-	resGet := s.MakeRequest("GET", "/api/v1/reservations/"+code, nil, "")
+	resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+code, nil, dataRes["access_token"].(string))
 	
 	var resData entity.Reservation
 	json.Unmarshal(resGet.Body.Bytes(), &resData)
@@ -207,7 +205,7 @@ func (s *ReservationSuite) TestCancellationPenalty() {
 	json.Unmarshal(res.Body.Bytes(), &dataRes)
 	resCode := dataRes["reservation_code"].(string)
 
-	resGet := s.MakeRequest("GET", "/api/v1/reservations/"+resCode, nil, "")
+	resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+resCode, nil, dataRes["access_token"].(string))
 	var resData entity.Reservation
 	json.Unmarshal(resGet.Body.Bytes(), &resData)
 	resID := resData.ID
@@ -228,7 +226,6 @@ func (s *ReservationSuite) TestConcurrencyOverbooking() {
 		"total_quantity": 1,
 		"base_price":     100.0,
 		"max_occupancy":  2, "max_adults": 2, "max_children": 0,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	s.Require().Equal(http.StatusCreated, resR.Code)
 
@@ -319,6 +316,93 @@ func (s *ReservationSuite) TestReservationValidation() {
 	s.True(res3.Code == http.StatusBadRequest || res3.Code == http.StatusInternalServerError)
 }
 
+func (s *ReservationSuite) TestReservationModifyAndCancel() {
+	res := s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+		"unit_type_id":     s.unitTypeID,
+		"guest_email":      "modify@test.com",
+		"guest_first_name": "Mod", "guest_last_name": "Ify",
+		"start":            "2025-01-01", "end": "2025-01-03",
+		"adults":           1, "children": 0,
+	}, "")
+	s.Require().Equal(http.StatusCreated, res.Code)
+
+	var data map[string]interface{}
+	json.Unmarshal(res.Body.Bytes(), &data)
+	code := data["reservation_code"].(string)
+
+	resGet := s.MakeGuestRequest("GET", "/api/v1/reservations/"+code, nil, data["access_token"].(string))
+	var reservation entity.Reservation
+	json.Unmarshal(resGet.Body.Bytes(), &reservation)
+	s.Equal(200.0, reservation.TotalPrice)
+
+	resMod := s.MakeRequest("PUT", "/api/v1/reservations/"+reservation.ID, map[string]interface{}{
+		"end":    "2025-01-05",
+		"adults": 2,
+	}, s.token)
+	s.Require().Equal(http.StatusOK, resMod.Code, resMod.Body.String())
+
+	var modified entity.Reservation
+	json.Unmarshal(resMod.Body.Bytes(), &modified)
+	s.Equal(400.0, modified.TotalPrice)
+	s.Equal(2, modified.Adults)
+	s.Equal(code, modified.ReservationCode)
+
+	resBad := s.MakeRequest("PUT", "/api/v1/reservations/"+reservation.ID, map[string]interface{}{
+		"adults": 3,
+	}, s.token)
+	s.Equal(http.StatusBadRequest, resBad.Code)
+
+	resCancel := s.MakeGuestRequest("POST", "/api/v1/reservations/"+reservation.ID+"/cancel", nil, data["access_token"].(string))
+	s.Equal(http.StatusOK, resCancel.Code)
+
+	resAgain := s.MakeGuestRequest("POST", "/api/v1/reservations/"+reservation.ID+"/cancel", nil, data["access_token"].(string))
+	s.Equal(http.StatusConflict, resAgain.Code)
+
+	resModCancelled := s.MakeRequest("PUT", "/api/v1/reservations/"+reservation.ID, map[string]interface{}{
+		"adults": 1,
+	}, s.token)
+	s.Equal(http.StatusConflict, resModCancelled.Code)
+}
+
+func (s *ReservationSuite) TestModifyStaysWithinProperty() {
+	res := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"organization_id": s.orgID,
+		"name":            "Other Property",
+		"code":            "OTH",
+		"type":            "HOTEL",
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, res.Code)
+	var property map[string]string
+	json.Unmarshal(res.Body.Bytes(), &property)
+
+	res = s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id":    property["property_id"],
+		"name":           "Std", "code": "STD",
+		"total_quantity": 5,
+		"base_price":     100.0,
+		"max_occupancy":  4, "max_adults": 2, "max_children": 2,
+	}, s.token)
+	s.Require().Equal(http.StatusCreated, res.Code)
+	var otherUnitType map[string]string
+	json.Unmarshal(res.Body.Bytes(), &otherUnitType)
+
+	res = s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+		"unit_type_id":     s.unitTypeID,
+		"guest_email":      "move@test.com",
+		"guest_first_name": "Mo", "guest_last_name": "Ve",
+		"start":            "2025-01-01", "end": "2025-01-03",
+		"adults":           1, "children": 0,
+	}, "")
+	s.Require().Equal(http.StatusCreated, res.Code)
+	var booking map[string]interface{}
+	json.Unmarshal(res.Body.Bytes(), &booking)
+
+	res = s.MakeRequest("PUT", "/api/v1/reservations/"+booking["reservation_id"].(string), map[string]interface{}{
+		"unit_type_id": otherUnitType["unit_type_id"],
+	}, s.token)
+	s.Equal(http.StatusBadRequest, res.Code, "a booking cannot move to another property's unit type")
+}
+
 func TestReservationSuite(t *testing.T) {
 	suite.Run(t, new(ReservationSuite))
 }
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/pkg/auth"
+)
+
+type SessionSuite struct {
+	BaseSuite
+}
+
+func (s *SessionSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.GetAdminTokenAndOrg()
+}
+
+// loginFrom signs owner@test.com in from a device identified by its user agent.
+func (s *SessionSuite) loginFrom(userAgent string) entity.AuthResponse {
+	body, _ := json.Marshal(map[string]string{"email": "owner@test.com", "password": testPassword})
+	req := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("User-Agent", userAgent)
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	s.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+
+	var res entity.AuthResponse
+	json.Unmarshal(rec.Body.Bytes(), &res)
+	s.Require().NotEmpty(res.RefreshToken)
+	return res
+}
+
+func (s *SessionSuite) refresh(refreshToken string) *httptest.ResponseRecorder {
+	return s.MakeRequest("POST", "/api/v1/auth/refresh", map[string]string{"refresh_token": refreshToken}, "")
+}
+
+func (s *SessionSuite) TestRefreshRotatesToken() {
+	login := s.loginFrom("laptop")
+	s.Equal(int(auth.AccessTokenTTL.Seconds()), login.ExpiresIn)
+
+	res := s.refresh(login.RefreshToken)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	var refreshed entity.AuthResponse
+	json.Unmarshal(res.Body.Bytes(), &refreshed)
+	s.NotEqual(login.RefreshToken, refreshed.RefreshToken)
+	s.Equal(login.OrganizationID, refreshed.OrganizationID)
+
+	resProps := s.MakeRequest("GET", "/api/v1/properties", nil, refreshed.Token)
+	s.Equal(http.StatusOK, resProps.Code)
+
+	// Replaying the rotated token signals theft and ends the session for everyone holding it.
+	res = s.refresh(login.RefreshToken)
+	s.Equal(http.StatusUnauthorized, res.Code)
+
+	res = s.refresh(refreshed.RefreshToken)
+	s.Equal(http.StatusUnauthorized, res.Code)
+	resProps = s.MakeRequest("GET", "/api/v1/properties", nil, refreshed.Token)
+	s.Equal(http.StatusUnauthorized, resProps.Code)
+
+	res = s.refresh("not-a-token")
+	s.Equal(http.StatusUnauthorized, res.Code)
+}
+
+func (s *SessionSuite) TestLogoutRevokesSession() {
+	login := s.loginFrom("laptop")
+
+	res := s.MakeRequest("POST", "/api/v1/auth/logout", nil, login.Token)
+	s.Require().Equal(http.StatusOK, res.Code)
+
+	res = s.MakeRequest("GET", "/api/v1/properties", nil, login.Token)
+	s.Equal(http.StatusUnauthorized, res.Code, "access tokens die with their session")
+
+	res = s.refresh(login.RefreshToken)
+	s.Equal(http.StatusUnauthorized, res.Code)
+}
+
+func (s *SessionSuite) TestSessionsListAndLogoutAll() {
+	laptop := s.loginFrom("laptop")
+	phone := s.loginFrom("phone")
+
+	res := s.MakeRequest("GET", "/api/v1/auth/sessions", nil, laptop.Token)
+	s.Require().Equal(http.StatusOK, res.Code)
+	var sessions []entity.Session
+	json.Unmarshal(res.Body.Bytes(), &sessions)
+	s.Require().Len(sessions, 2)
+
+	var phoneSessionID string
+	for _, session := range sessions {
+		if session.UserAgent == "laptop" {
+			s.True(session.Current)
+		} else {
+			s.Equal("phone", session.UserAgent)
+			s.False(session.Current)
+			phoneSessionID = session.ID
+		}
+		s.False(session.LastUsedAt.IsZero())
+	}
+
+	otherToken, _ := s.CreateOrgOwner("other@test.com", "OTHR")
+	res = s.MakeRequest("DELETE", "/api/v1/auth/sessions/"+phoneSessionID, nil, otherToken)
+	s.Equal(http.StatusNotFound, res.Code, "sessions of other users are out of reach")
+
+	res = s.MakeRequest("DELETE", "/api/v1/auth/sessions/"+phoneSessionID, nil, laptop.Token)
+	s.Require().Equal(http.StatusOK, res.Code)
+	res = s.MakeRequest("GET", "/api/v1/properties", nil, phone.Token)
+	s.Equal(http.StatusUnauthorized, res.Code)
+
+	tablet := s.loginFrom("tablet")
+	res = s.MakeRequest("POST", "/api/v1/auth/logout-all", nil, laptop.Token)
+	s.Require().Equal(http.StatusOK, res.Code)
+
+	for _, token := range []string{laptop.Token, tablet.Token} {
+		res = s.MakeRequest("GET", "/api/v1/properties", nil, token)
+		s.Equal(http.StatusUnauthorized, res.Code)
+	}
+	res = s.refresh(tablet.RefreshToken)
+	s.Equal(http.StatusUnauthorized, res.Code)
+
+	res = s.MakeRequest("GET", "/api/v1/properties", nil, otherToken)
+	s.Equal(http.StatusOK, res.Code, "other users stay signed in")
+}
+
+func (s *SessionSuite) TestPasswordResetEndsSessions() {
+	login := s.loginFrom("laptop")
+
+	var userID, salt string
+	err := s.db.QueryRow(context.Background(), `SELECT id, salt FROM users WHERE email = 'owner@test.com'`).Scan(&userID, &salt)
+	s.Require().NoError(err)
+	resetToken, err := auth.GenerateResetToken(userID, salt)
+	s.Require().NoError(err)
+
+	res := s.MakeRequest("POST", "/api/v1/auth/reset-password", map[string]string{
+		"token": resetToken, "new_password": "brand-new-pass",
+	}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+
+	res = s.refresh(login.RefreshToken)
+	s.Equal(http.StatusUnauthorized, res.Code)
+}
+
+func TestSessionSuite(t *testing.T) {
+	suite.Run(t, new(SessionSuite))
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/pkg/auth"
+)
+
+type SigningKeySuite struct {
+	BaseSuite
+	token string
+}
+
+func (s *SigningKeySuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.token, _ = s.GetAdminTokenAndOrg()
+}
+
+func (s *SigningKeySuite) keyRing(versions []int, active int) *auth.SigningKeyRing {
+	v1, _ := base64.StdEncoding.DecodeString(testSigningKeyV1)
+	v2, _ := base64.StdEncoding.DecodeString(testSigningKeyV2)
+	all := map[int][]byte{1: v1, 2: v2}
+
+	seeds := map[int][]byte{}
+	for _, v := range versions {
+		seeds[v] = all[v]
+	}
+	ring, err := auth.NewSigningKeyRing(seeds, active)
+	s.Require().NoError(err)
+	return ring
+}
+
+func (s *SigningKeySuite) TestOtherServicesVerifyWithJWKS() {
+	res := s.MakeRequest("GET", "/.well-known/jwks.json", nil, "")
+	s.Require().Equal(http.StatusOK, res.Code)
+	var set auth.JWKSet
+	json.Unmarshal(res.Body.Bytes(), &set)
+	s.Require().Len(set.Keys, 1)
+	s.Equal("OKP", set.Keys[0].KeyType)
+	s.Equal("EdDSA", set.Keys[0].Algorithm)
+
+	claims := &auth.Claims{}
+	parsed, err := jwt.ParseWithClaims(s.token, claims, func(token *jwt.Token) (interface{}, error) {
+		for _, key := range set.Keys {
+			if key.KeyID == token.Header["kid"] {
+				x, err := base64.RawURLEncoding.DecodeString(key.X)
+				return ed25519.PublicKey(x), err
+			}
+		}
+		return nil, jwt.ErrTokenUnverifiable
+	}, jwt.WithValidMethods([]string{"EdDSA"}))
+	s.Require().NoError(err)
+	s.True(parsed.Valid)
+	s.NotEmpty(claims.UserID)
+	s.Equal(claims.UserID, claims.Subject)
+}
+
+func (s *SigningKeySuite) TestSaltSignedTokensAreRejected() {
+	ctx := context.Background()
+	var userID, salt string
+	err := s.db.QueryRow(ctx, `SELECT id, salt FROM users WHERE email = 'owner@test.com'`).Scan(&userID, &salt)
+	s.Require().NoError(err)
+
+	valid, err := auth.ValidateAccessToken(s.keyRing([]int{1}, 1), s.token)
+	s.Require().NoError(err)
+
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, valid)
+	forged, err := legacy.SignedString([]byte(salt))
+	s.Require().NoError(err)
+	res := s.MakeRequest("GET", "/api/v1/properties", nil, forged)
+	s.Equal(http.StatusUnauthorized, res.Code)
+
+	unknownKey, err := auth.GenerateToken(s.keyRing([]int{2}, 2), userID, valid.OrganizationID, valid.Role, valid.SessionID, valid.TokenVersion)
+	s.Require().NoError(err)
+	res = s.MakeRequest("GET", "/api/v1/properties", nil, unknownKey)
+	s.Equal(http.StatusUnauthorized, res.Code)
+
+	res = s.MakeRequest("GET", "/api/v1/properties", nil, s.token)
+	s.Equal(http.StatusOK, res.Code)
+}
+
+func (s *SigningKeySuite) TestRotationKeepsOldTokensValid() {
+	before := s.keyRing([]int{1}, 1)
+	during := s.keyRing([]int{1, 2}, 2)
+	after := s.keyRing([]int{2}, 2)
+
+	oldToken, err := auth.GenerateToken(before, "user", "org", "owner", "session", 0)
+	s.Require().NoError(err)
+	newToken, err := auth.GenerateToken(during, "user", "org", "owner", "session", 0)
+	s.Require().NoError(err)
+
+	_, err = auth.ValidateAccessToken(during, oldToken)
+	s.NoError(err, "tokens of the previous key verify while both are configured")
+	_, err = auth.ValidateAccessToken(after, newToken)
+	s.NoError(err)
+	_, err = auth.ValidateAccessToken(after, oldToken)
+	s.Error(err, "removing a key retires its tokens")
+
+	s.Len(during.JWKS().Keys, 2)
+	s.Equal("2", during.ActiveKeyID())
+}
+
+func (s *SigningKeySuite) TestTokenVersionRevokesTokens() {
+	_, err := s.db.Exec(context.Background(), `UPDATE users SET token_version = token_version + 1 WHERE email = 'owner@test.com'`)
+	s.Require().NoError(err)
+
+	res := s.MakeRequest("GET", "/api/v1/properties", nil, s.token)
+	s.Equal(http.StatusUnauthorized, res.Code)
+
+	res = s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": "owner@test.com", "password": testPassword}, "")
+	s.Require().Equal(http.StatusOK, res.Code)
+	var login entity.AuthResponse
+	json.Unmarshal(res.Body.Bytes(), &login)
+
+	res = s.MakeRequest("GET", "/api/v1/properties", nil, login.Token)
+	s.Equal(http.StatusOK, res.Code, "new logins carry the current version")
+}
+
+func TestSigningKeySuite(t *testing.T) {
+	suite.Run(t, new(SigningKeySuite))
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/ecelayes/pms-backend/internal/entity"
+)
+
+type TenantIsolationSuite struct {
+	BaseSuite
+	tokenA            string
+	orgA              string
+	tokenB            string
+	orgB              string
+	propertyID        string
+	unitTypeID        string
+	unitID            string
+	ratePlanID        string
+	priceRuleID       string
+	propertyServiceID string
+}
+
+func (s *TenantIsolationSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.tokenA, s.orgA = s.CreateOrgOwner("owner-a@test.com", "ORGA")
+	s.tokenB, s.orgB = s.CreateOrgOwner("owner-b@test.com", "ORGB")
+
+	resP := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"organization_id": s.orgA, "name": "Tenant A Hotel", "code": "TAH", "type": "HOTEL",
+	}, s.tokenA)
+	s.Require().Equal(http.StatusCreated, resP.Code, resP.Body.String())
+	var dataP map[string]string
+	json.Unmarshal(resP.Body.Bytes(), &dataP)
+	s.propertyID = dataP["property_id"]
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": s.propertyID, "name": "Std", "code": "STD",
+		"total_quantity": 5, "base_price": 100.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, s.tokenA)
+	s.Require().Equal(http.StatusCreated, resU.Code, resU.Body.String())
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+	s.unitTypeID = dataU["unit_type_id"]
+
+	resUnit := s.MakeRequest("POST", "/api/v1/units", map[string]interface{}{
+		"property_id": s.propertyID, "unit_type_id": s.unitTypeID, "name": "101",
+	}, s.tokenA)
+	s.Require().Equal(http.StatusCreated, resUnit.Code, resUnit.Body.String())
+	var dataUnit map[string]string
+	json.Unmarshal(resUnit.Body.Bytes(), &dataUnit)
+	s.unitID = dataUnit["unit_id"]
+
+	resRP := s.MakeRequest("POST", "/api/v1/rate-plans", map[string]interface{}{
+		"property_id": s.propertyID, "unit_type_id": s.unitTypeID, "name": "Flexible",
+		"cancellation_policy": map[string]interface{}{"is_refundable": true},
+	}, s.tokenA)
+	s.Require().Equal(http.StatusCreated, resRP.Code, resRP.Body.String())
+	var dataRP map[string]string
+	json.Unmarshal(resRP.Body.Bytes(), &dataRP)
+	s.ratePlanID = dataRP["rate_plan_id"]
+
+	resPrice := s.MakeRequest("POST", "/api/v1/pricing/bulk", map[string]interface{}{
+		"unit_type_id": s.unitTypeID, "start": "2026-01-01", "end": "2026-01-31", "price": 100.0,
+	}, s.tokenA)
+	s.Require().Equal(http.StatusOK, resPrice.Code, resPrice.Body.String())
+	resRules := s.MakeRequest("GET", "/api/v1/pricing/rules?unit_type_id="+s.unitTypeID, nil, s.tokenA)
+	var rules entity.PaginatedResponse[entity.PriceRule]
+	json.Unmarshal(resRules.Body.Bytes(), &rules)
+	s.Require().Len(rules.Data, 1)
+	s.priceRuleID = rules.Data[0].ID
+
+	resS := s.MakeRequest("POST", "/api/v1/services", map[string]string{"name": "Parking"}, s.tokenA)
+	s.Require().Equal(http.StatusCreated, resS.Code, resS.Body.String())
+	var dataS map[string]string
+	json.Unmarshal(resS.Body.Bytes(), &dataS)
+	resPS := s.MakeRequest("POST", "/api/v1/property-services", map[string]interface{}{
+		"property_id": s.propertyID, "service_id": dataS["id"],
+		"price": 10.0, "pricing_unit": entity.PricingPerNight,
+	}, s.tokenA)
+	s.Require().Equal(http.StatusCreated, resPS.Code, resPS.Body.String())
+	var dataPS map[string]string
+	json.Unmarshal(resPS.Body.Bytes(), &dataPS)
+	s.propertyServiceID = dataPS["property_service_id"]
+}
+
+func (s *TenantIsolationSuite) TestCrossTenantAccessReturnsNotFound() {
+	resources := map[string]string{
+		"/api/v1/properties/":        s.propertyID,
+		"/api/v1/unit-types/":        s.unitTypeID,
+		"/api/v1/units/":             s.unitID,
+		"/api/v1/rate-plans/":        s.ratePlanID,
+		"/api/v1/property-services/": s.propertyServiceID,
+	}
+	for prefix, id := range resources {
+		res := s.MakeRequest("GET", prefix+id, nil, s.tokenB)
+		s.Equal(http.StatusNotFound, res.Code, "GET "+prefix)
+
+		res = s.MakeRequest("PUT", prefix+id, map[string]interface{}{"name": "Hijacked"}, s.tokenB)
+		s.Equal(http.StatusNotFound, res.Code, "PUT "+prefix)
+
+		res = s.MakeRequest("DELETE", prefix+id, nil, s.tokenB)
+		s.Equal(http.StatusNotFound, res.Code, "DELETE "+prefix)
+
+		res = s.MakeRequest("GET", prefix+id, nil, s.tokenA)
+		s.Equal(http.StatusOK, res.Code, "owner GET "+prefix)
+	}
+
+	resRule := s.MakeRequest("DELETE", "/api/v1/pricing/rules/"+s.priceRuleID, nil, s.tokenB)
+	s.Equal(http.StatusNotFound, resRule.Code)
+
+	resBulk := s.MakeRequest("POST", "/api/v1/pricing/bulk", map[string]interface{}{
+		"unit_type_id": s.unitTypeID, "start": "2026-02-01", "end": "2026-02-10", "price": 1.0,
+	}, s.tokenB)
+	s.Equal(http.StatusNotFound, resBulk.Code)
+
+	var prop entity.Property
+	resProp := s.MakeRequest("GET", "/api/v1/properties/"+s.propertyID, nil, s.tokenA)
+	json.Unmarshal(resProp.Body.Bytes(), &prop)
+	s.Equal("Tenant A Hotel", prop.Name)
+}
+
+func (s *TenantIsolationSuite) TestCrossTenantListsAreEmpty() {
+	resRules := s.MakeRequest("GET", "/api/v1/pricing/rules?unit_type_id="+s.unitTypeID, nil, s.tokenB)
+	s.Equal(http.StatusOK, resRules.Code)
+	var rules entity.PaginatedResponse[entity.PriceRule]
+	json.Unmarshal(resRules.Body.Bytes(), &rules)
+	s.Empty(rules.Data)
+
+	resUnits := s.MakeRequest("GET", "/api/v1/units?property_id="+s.propertyID, nil, s.tokenB)
+	s.Equal(http.StatusOK, resUnits.Code)
+	var units []entity.Unit
+	json.Unmarshal(resUnits.Body.Bytes(), &units)
+	s.Empty(units)
+
+	resPlans := s.MakeRequest("GET", "/api/v1/rate-plans?property_id="+s.propertyID, nil, s.tokenB)
+	s.Equal(http.StatusOK, resPlans.Code)
+	var plans entity.PaginatedResponse[entity.RatePlan]
+	json.Unmarshal(resPlans.Body.Bytes(), &plans)
+	s.Empty(plans.Data)
+
+	resProps := s.MakeRequest("GET", "/api/v1/properties?organization_id="+s.orgA, nil, s.tokenB)
+	s.Equal(http.StatusOK, resProps.Code)
+	var props entity.PaginatedResponse[entity.Property]
+	json.Unmarshal(resProps.Body.Bytes(), &props)
+	s.Empty(props.Data)
+
+	resUsers := s.MakeRequest("GET", "/api/v1/users?organization_id="+s.orgA, nil, s.tokenB)
+	s.Equal(http.StatusOK, resUsers.Code)
+	var users entity.PaginatedResponse[entity.User]
+	json.Unmarshal(resUsers.Body.Bytes(), &users)
+	s.Require().Len(users.Data, 1)
+	s.Equal("owner-b@test.com", users.Data[0].Email)
+}
+
+func (s *TenantIsolationSuite) TestCrossTenantCreatesAreRejected() {
+	resProp := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"organization_id": s.orgA, "name": "Sneaky", "code": "SNK", "type": "HOTEL",
+	}, s.tokenB)
+	s.Equal(http.StatusBadRequest, resProp.Code)
+
+	resUnit := s.MakeRequest("POST", "/api/v1/units", map[string]interface{}{
+		"property_id": s.propertyID, "unit_type_id": s.unitTypeID, "name": "999",
+	}, s.tokenB)
+	s.Equal(http.StatusBadRequest, resUnit.Code)
+
+	resUser := s.MakeRequest("POST", "/api/v1/users", map[string]interface{}{
+		"organization_id": s.orgA, "email": "mole@test.com", "password": "password123",
+		"first_name": "Mo", "last_name": "Le", "role": "manager",
+	}, s.tokenB)
+	s.Equal(http.StatusBadRequest, resUser.Code)
+}
+
+func (s *TenantIsolationSuite) TestSuperAdminSeesEveryTenant() {
+	superToken := s.GetSuperAdminToken()
+
+	res := s.MakeRequest("GET", "/api/v1/properties/"+s.propertyID, nil, superToken)
+	s.Equal(http.StatusOK, res.Code)
+
+	resUT := s.MakeRequest("GET", "/api/v1/unit-types/"+s.unitTypeID, nil, superToken)
+	s.Equal(http.StatusOK, resUT.Code)
+
+	resProps := s.MakeRequest("GET", "/api/v1/properties?organization_id="+s.orgA, nil, superToken)
+	var props entity.PaginatedResponse[entity.Property]
+	json.Unmarshal(resProps.Body.Bytes(), &props)
+	s.Len(props.Data, 1)
+}
+
+func TestTenantIsolationSuite(t *testing.T) {
+	suite.Run(t, new(TenantIsolationSuite))
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/pkg/auth"
+)
+
+type TwoFactorSuite struct {
+	BaseSuite
+	ownerToken string
+}
+
+func (s *TwoFactorSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.ownerToken, _ = s.GetAdminTokenAndOrg()
+}
+
+func (s *TwoFactorSuite) login(email, password string) entity.AuthResponse {
+	res := s.MakeRequest("POST", "/api/v1/auth/login", map[string]string{"email": email, "password": password}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	var login entity.AuthResponse
+	json.Unmarshal(res.Body.Bytes(), &login)
+	return login
+}
+
+func (s *TwoFactorSuite) completeLogin(body map[string]string) (*http.Response, entity.AuthResponse) {
+	res := s.MakeRequest("POST", "/api/v1/auth/two-factor/login", body, "")
+	var login entity.AuthResponse
+	json.Unmarshal(res.Body.Bytes(), &login)
+	return res.Result(), login
+}
+
+// enrol turns two-factor authentication on for the token's user and returns the secret and
+// recovery codes.
+func (s *TwoFactorSuite) enrol(token string) (string, []string) {
+	res := s.MakeRequest("POST", "/api/v1/auth/two-factor/setup", nil, token)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	var setup entity.TwoFactorSetup
+	json.Unmarshal(res.Body.Bytes(), &setup)
+	s.Require().NotEmpty(setup.Secret)
+	s.True(strings.HasPrefix(setup.ProvisioningURI, "otpauth://totp/"))
+	s.Contains(setup.ProvisioningURI, "secret="+setup.Secret)
+
+	res = s.MakeRequest("POST", "/api/v1/auth/two-factor/enable", map[string]string{"code": "000000"}, token)
+	s.Equal(http.StatusUnauthorized, res.Code, "enrolment needs a code from the app")
+
+	code, err := auth.GenerateTOTPCode(setup.Secret, time.Now())
+	s.Require().NoError(err)
+	res = s.MakeRequest("POST", "/api/v1/auth/two-factor/enable", map[string]string{"code": code}, token)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	var codes entity.RecoveryCodesResponse
+	json.Unmarshal(res.Body.Bytes(), &codes)
+	s.Require().Len(codes.RecoveryCodes, auth.RecoveryCodeCount)
+	return setup.Secret, codes.RecoveryCodes
+}
+
+func (s *TwoFactorSuite) TestLoginAsksForSecondFactor() {
+	secret, recoveryCodes := s.enrol(s.ownerToken)
+
+	login := s.login("owner@test.com", testPassword)
+	s.True(login.TwoFactorRequired)
+	s.False(login.TwoFactorSetupRequired)
+	s.Empty(login.Token, "no access before the second factor")
+	s.Require().NotEmpty(login.ChallengeToken)
+
+	res := s.MakeRequest("GET", "/api/v1/properties", nil, login.ChallengeToken)
+	s.Equal(http.StatusUnauthorized, res.Code, "a challenge is not an access token")
+
+	// The code that confirmed enrolment has been spent; the next one works.
+	used, _ := auth.GenerateTOTPCode(secret, time.Now())
+	resp, _ := s.completeLogin(map[string]string{"challenge_token": login.ChallengeToken, "code": used})
+	s.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	next, _ := auth.GenerateTOTPCode(secret, time.Now().Add(30*time.Second))
+	resp, done := s.completeLogin(map[string]string{"challenge_token": login.ChallengeToken, "code": next})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.NotEmpty(done.Token)
+	s.NotEmpty(done.RefreshToken)
+	res = s.MakeRequest("GET", "/api/v1/properties", nil, done.Token)
+	s.Equal(http.StatusOK, res.Code)
+
+	login = s.login("owner@test.com", testPassword)
+	resp, done = s.completeLogin(map[string]string{"challenge_token": login.ChallengeToken, "recovery_code": strings.ToUpper(recoveryCodes[0])})
+	s.Require().Equal(http.StatusOK, resp.StatusCode, "recovery codes stand in for a lost phone")
+	s.NotEmpty(done.Token)
+
+	login = s.login("owner@test.com", testPassword)
+	resp, _ = s.completeLogin(map[string]string{"challenge_token": login.ChallengeToken, "recovery_code": recoveryCodes[0]})
+	s.Equal(http.StatusUnauthorized, resp.StatusCode, "recovery codes work once")
+}
+
+func (s *TwoFactorSuite) TestOrganizationRequiresTwoFactor() {
+	res := s.MakeRequest("PUT", "/api/v1/two-factor/policy", map[string]bool{"required": true}, s.ownerToken)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+
+	res = s.MakeRequest("GET", "/api/v1/two-factor/policy", nil, s.ownerToken)
+	var policy entity.TwoFactorPolicy
+	json.Unmarshal(res.Body.Bytes(), &policy)
+	s.True(policy.Required)
+
+	login := s.login("owner@test.com", testPassword)
+	s.True(login.TwoFactorRequired)
+	s.True(login.TwoFactorSetupRequired)
+
+	res = s.MakeRequest("POST", "/api/v1/auth/two-factor/login/setup", map[string]string{"challenge_token": login.ChallengeToken}, "")
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	var setup entity.TwoFactorSetup
+	json.Unmarshal(res.Body.Bytes(), &setup)
+
+	code, _ := auth.GenerateTOTPCode(setup.Secret, time.Now())
+	resp, done := s.completeLogin(map[string]string{"challenge_token": login.ChallengeToken, "code": code})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.NotEmpty(done.Token)
+	s.Len(done.RecoveryCodes, auth.RecoveryCodeCount)
+
+	next, _ := auth.GenerateTOTPCode(setup.Secret, time.Now().Add(30*time.Second))
+	res = s.MakeRequest("POST", "/api/v1/auth/two-factor/disable", map[string]string{"password": testPassword, "code": next}, done.Token)
+	s.Equal(http.StatusForbidden, res.Code, "members cannot opt out where it is required")
+
+	res = s.MakeRequest("POST", "/api/v1/users", map[string]string{
+		"email": "desk@test.com", "password": "secret123", "role": "staff",
+		"first_name": "Front", "last_name": "Desk",
+	}, done.Token)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	staff := s.login("desk@test.com", "secret123")
+	s.True(staff.TwoFactorSetupRequired)
+	s.Empty(staff.Token)
+}
+
+func (s *TwoFactorSuite) TestDisableAndAdminReset() {
+	res := s.MakeRequest("POST", "/api/v1/users", map[string]string{
+		"email": "desk@test.com", "password": "secret123", "role": "staff",
+		"first_name": "Front", "last_name": "Desk",
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var created map[string]string
+	json.Unmarshal(res.Body.Bytes(), &created)
+
+	staffToken := s.login("desk@test.com", "secret123").Token
+	s.enrol(staffToken)
+	s.True(s.login("desk@test.com", "secret123").TwoFactorRequired)
+
+	res = s.MakeRequest("DELETE", "/api/v1/users/"+created["user_id"]+"/two-factor", nil, staffToken)
+	s.Equal(http.StatusForbidden, res.Code, "only user managers reset a second factor")
+
+	otherToken, _ := s.CreateOrgOwner("other@test.com", "OTHR")
+	res = s.MakeRequest("DELETE", "/api/v1/users/"+created["user_id"]+"/two-factor", nil, otherToken)
+	s.Equal(http.StatusNotFound, res.Code)
+
+	res = s.MakeRequest("DELETE", "/api/v1/users/"+created["user_id"]+"/two-factor", nil, s.ownerToken)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	res = s.MakeRequest("GET", "/api/v1/properties", nil, staffToken)
+	s.Equal(http.StatusUnauthorized, res.Code, "a reset signs the user out everywhere")
+	login := s.login("desk@test.com", "secret123")
+	s.False(login.TwoFactorRequired)
+	s.NotEmpty(login.Token)
+
+	secret, _ := s.enrol(login.Token)
+	code, _ := auth.GenerateTOTPCode(secret, time.Now().Add(30*time.Second))
+	res = s.MakeRequest("POST", "/api/v1/auth/two-factor/disable", map[string]string{"password": "wrong", "code": code}, login.Token)
+	s.Equal(http.StatusUnauthorized, res.Code)
+	res = s.MakeRequest("POST", "/api/v1/auth/two-factor/disable", map[string]string{"password": "secret123", "code": code}, login.Token)
+	s.Require().Equal(http.StatusOK, res.Code, res.Body.String())
+	s.False(s.login("desk@test.com", "secret123").TwoFactorRequired)
+}
+
+func (s *TwoFactorSuite) TestSecretsAreSealedAndGuessesCountPerAccount() {
+	ctx := context.Background()
+	secret, _ := s.enrol(s.ownerToken)
+
+	var stored string
+	var failures int
+	s.Require().NoError(s.db.QueryRow(ctx, `SELECT totp_secret, failed_login_attempts FROM users WHERE email = 'owner@test.com'`).Scan(&stored, &failures))
+	s.NotContains(stored, secret)
+	s.True(strings.HasPrefix(stored, "v1:"))
+	s.Zero(failures, "confirming enrolment clears the failed first try")
+
+	for i := 0; i < 4; i++ {
+		res := s.MakeRequest("POST", "/api/v1/auth/two-factor/recovery-codes", map[string]string{"code": "000000"}, s.ownerToken)
+		s.Equal(http.StatusUnauthorized, res.Code)
+	}
+	s.Require().NoError(s.db.QueryRow(ctx, `SELECT failed_login_attempts FROM users WHERE email = 'owner@test.com'`).Scan(&failures))
+	s.Equal(4, failures)
+
+	code, _ := auth.GenerateTOTPCode(secret, time.Now().Add(30*time.Second))
+	res := s.MakeRequest("POST", "/api/v1/auth/two-factor/recovery-codes", map[string]string{"code": code}, s.ownerToken)
+	s.NotEqual(http.StatusOK, res.Code, "the account waits after repeated wrong codes")
+}
+
+func (s *TwoFactorSuite) TestResetForSharedAccountsNeedsSuperAdmin() {
+	_, sharedOrg := s.CreateOrgOwner("shared@test.com", "SHRD")
+	var userID string
+	s.Require().NoError(s.db.QueryRow(context.Background(), `SELECT id FROM users WHERE email = 'shared@test.com'`).Scan(&userID))
+	var orgID string
+	s.Require().NoError(s.db.QueryRow(context.Background(), `SELECT organization_id FROM organization_members om JOIN users u ON u.id = om.user_id WHERE u.email = 'owner@test.com'`).Scan(&orgID))
+	s.NotEqual(sharedOrg, orgID)
+	_, err := s.db.Exec(context.Background(),
+		`INSERT INTO organization_members (id, organization_id, user_id, role, created_at, updated_at) VALUES ($1, $2, $3, 'staff', NOW(), NOW())`,
+		uuid.NewString(), orgID, userID)
+	s.Require().NoError(err)
+
+	res := s.MakeRequest("DELETE", "/api/v1/users/"+userID+"/two-factor", nil, s.ownerToken)
+	s.Equal(http.StatusForbidden, res.Code)
+	res = s.MakeRequest("DELETE", "/api/v1/users/"+userID+"/two-factor", nil, s.GetSuperAdminToken())
+	s.Equal(http.StatusOK, res.Code, res.Body.String())
+}
+
+func TestTwoFactorSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorSuite))
+}
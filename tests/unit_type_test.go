@@ -43,7 +43,6 @@ func (s *UnitTypeSuite) TestCRUDUnitType() {
 		"max_occupancy":  4,
 		"max_adults":     2,
 		"max_children":   2,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	s.Equal(http.StatusCreated, res.Code)
 	
@@ -76,7 +75,6 @@ func (s *UnitTypeSuite) TestListUnitTypes() {
 		"total_quantity": 10,
 		"base_price":     100.0,
 		"max_occupancy":  2, "max_adults": 2, "max_children": 0,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 
 	res := s.MakeRequest("GET", "/api/v1/unit-types?property_id="+s.propertyID+"&page=1&limit=5", nil, s.token)
@@ -140,7 +138,6 @@ func (s *UnitTypeSuite) TestUnitTypeDuplicateCode() {
 		"max_occupancy":  2,
 		"max_adults":     2,
 		"max_children":   0,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	s.Equal(http.StatusCreated, res.Code)
 
@@ -153,11 +150,77 @@ func (s *UnitTypeSuite) TestUnitTypeDuplicateCode() {
 		"max_occupancy":  2,
 		"max_adults":     2,
 		"max_children":   0,
-		"amenities":      []string{"wifi"},
 	}, s.token)
 	s.Equal(http.StatusConflict, res2.Code)
 }
 
+func (s *UnitTypeSuite) TestUnitTypeAmenities() {
+	superToken := s.GetSuperAdminToken()
+	amenityIDs := []string{}
+	for _, name := range []string{"Wifi", "Jacuzzi"} {
+		res := s.MakeRequest("POST", "/api/v1/amenities", map[string]interface{}{
+			"name": name, "icon": name + "-icon",
+		}, superToken)
+		s.Require().Equal(http.StatusCreated, res.Code)
+		var data map[string]string
+		json.Unmarshal(res.Body.Bytes(), &data)
+		amenityIDs = append(amenityIDs, data["id"])
+	}
+
+	unitType := map[string]interface{}{
+		"property_id":    s.propertyID,
+		"name":           "Amenity Suite",
+		"code":           "AMS",
+		"total_quantity": 2,
+		"base_price":     100.0,
+		"max_occupancy":  2,
+		"max_adults":     2,
+		"amenity_ids":    []string{"00000000-0000-0000-0000-000000000000"},
+	}
+	res := s.MakeRequest("POST", "/api/v1/unit-types", unitType, s.token)
+	s.Equal(http.StatusBadRequest, res.Code, "unknown amenities are rejected")
+
+	unitType["amenity_ids"] = []string{amenityIDs[1], amenityIDs[0], amenityIDs[1]}
+	res = s.MakeRequest("POST", "/api/v1/unit-types", unitType, s.token)
+	s.Require().Equal(http.StatusCreated, res.Code)
+	var data map[string]string
+	json.Unmarshal(res.Body.Bytes(), &data)
+	id := data["unit_type_id"]
+
+	res = s.MakeRequest("GET", "/api/v1/unit-types/"+id, nil, s.token)
+	s.Require().Equal(http.StatusOK, res.Code)
+	var ut entity.UnitType
+	json.Unmarshal(res.Body.Bytes(), &ut)
+	s.Require().Len(ut.Amenities, 2)
+	s.Equal("Jacuzzi", ut.Amenities[0].Name)
+	s.Equal("Jacuzzi-icon", ut.Amenities[0].Icon)
+	s.Equal("Wifi", ut.Amenities[1].Name)
+
+	res = s.MakeRequest("PUT", "/api/v1/unit-types/"+id, map[string]interface{}{
+		"amenity_ids": []string{"not-a-uuid"},
+	}, s.token)
+	s.Equal(http.StatusBadRequest, res.Code)
+
+	res = s.MakeRequest("PUT", "/api/v1/unit-types/"+id, map[string]interface{}{
+		"base_price": 120.0,
+	}, s.token)
+	s.Equal(http.StatusOK, res.Code)
+	res = s.MakeRequest("GET", "/api/v1/unit-types/"+id, nil, s.token)
+	ut = entity.UnitType{}
+	json.Unmarshal(res.Body.Bytes(), &ut)
+	s.Len(ut.Amenities, 2, "amenities are kept when amenity_ids is omitted")
+
+	res = s.MakeRequest("PUT", "/api/v1/unit-types/"+id, map[string]interface{}{
+		"amenity_ids": []string{},
+	}, s.token)
+	s.Equal(http.StatusOK, res.Code)
+	res = s.MakeRequest("GET", "/api/v1/unit-types/"+id, nil, s.token)
+	ut = entity.UnitType{}
+	json.Unmarshal(res.Body.Bytes(), &ut)
+	s.NotNil(ut.Amenities)
+	s.Empty(ut.Amenities)
+}
+
 func TestUnitTypeSuite(t *testing.T) {
 	suite.Run(t, new(UnitTypeSuite))
 }
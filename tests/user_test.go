@@ -1,9 +1,11 @@
 package tests
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/ecelayes/pms-backend/internal/entity"
@@ -121,6 +123,45 @@ func (s *UserSuite) TestUserDuplicateEmail() {
 	s.Equal(http.StatusConflict, res2.Code)
 }
 
+func (s *UserSuite) TestEmailChangeNeedsVerificationAndEndsSessions() {
+	const clientIP = "203.0.113.60"
+	res := s.MakeRequest("POST", "/api/v1/users", map[string]string{
+		"organization_id": s.orgID,
+		"email":           "mover@corp.com",
+		"password":        "secret123",
+		"role":            "staff",
+		"first_name":      "Staff", "last_name": "Mover", "phone": "1",
+	}, s.ownerToken)
+	s.Require().Equal(http.StatusCreated, res.Code, res.Body.String())
+	var created map[string]string
+	json.Unmarshal(res.Body.Bytes(), &created)
+
+	resLogin := s.requestFromClient(clientIP, "POST", "/api/v1/auth/login", map[string]string{"email": "mover@corp.com", "password": "secret123"})
+	s.Require().Equal(http.StatusOK, resLogin.Code, resLogin.Body.String())
+	var login entity.AuthResponse
+	json.Unmarshal(resLogin.Body.Bytes(), &login)
+	s.Equal(http.StatusOK, s.MakeRequest("GET", "/api/v1/properties", nil, login.Token).Code)
+
+	// Saving the same address again changes nothing.
+	s.Require().Equal(http.StatusOK, s.MakeRequest("PUT", "/api/v1/users/"+created["user_id"]+"?organization_id="+s.orgID, map[string]string{
+		"email": "mover@corp.com",
+	}, s.ownerToken).Code)
+	s.Equal(http.StatusOK, s.MakeRequest("GET", "/api/v1/properties", nil, login.Token).Code)
+
+	s.Require().Equal(http.StatusOK, s.MakeRequest("PUT", "/api/v1/users/"+created["user_id"]+"?organization_id="+s.orgID, map[string]string{
+		"email": "moved@corp.com",
+	}, s.ownerToken).Code)
+	s.Equal(http.StatusUnauthorized, s.MakeRequest("GET", "/api/v1/properties", nil, login.Token).Code)
+
+	var verifiedAt *time.Time
+	s.Require().NoError(s.db.QueryRow(context.Background(),
+		`SELECT email_verified_at FROM users WHERE id = $1`, created["user_id"],
+	).Scan(&verifiedAt))
+	s.Nil(verifiedAt)
+	resNew := s.requestFromClient(clientIP, "POST", "/api/v1/auth/login", map[string]string{"email": "moved@corp.com", "password": "secret123"})
+	s.Equal(http.StatusForbidden, resNew.Code)
+}
+
 func TestUserSuite(t *testing.T) {
 	suite.Run(t, new(UserSuite))
 }
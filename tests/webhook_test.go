@@ -0,0 +1,263 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+
+	"github.com/ecelayes/pms-backend/internal/entity"
+	"github.com/ecelayes/pms-backend/internal/repository"
+	"github.com/ecelayes/pms-backend/internal/service"
+	"github.com/ecelayes/pms-backend/internal/usecase"
+)
+
+type WebhookSuite struct {
+	BaseSuite
+}
+
+type receivedWebhook struct {
+	Header http.Header
+	Body   []byte
+}
+
+// newReceiver starts a local endpoint that answers with status and records every request.
+func (s *WebhookSuite) newReceiver(status int) (*httptest.Server, func() []receivedWebhook) {
+	var mu sync.Mutex
+	var received []receivedWebhook
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedWebhook{Header: r.Header.Clone(), Body: body})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	s.T().Cleanup(srv.Close)
+
+	return srv, func() []receivedWebhook {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedWebhook(nil), received...)
+	}
+}
+
+func (s *WebhookSuite) deliverDue() int {
+	uc := usecase.NewWebhookUseCase(s.db, repository.NewWebhookRepository(s.db), service.NewWebhookSender(true), zap.NewNop())
+	delivered, err := uc.DeliverDue(context.Background(), 50)
+	s.Require().NoError(err)
+	return delivered
+}
+
+func (s *WebhookSuite) setupInventory(token, orgID string) string {
+	resH := s.MakeRequest("POST", "/api/v1/properties", map[string]string{
+		"organization_id": orgID, "name": "Hook Property", "code": "HOOK", "type": "HOTEL",
+	}, token)
+	s.Require().Equal(http.StatusCreated, resH.Code)
+	var dataH map[string]string
+	json.Unmarshal(resH.Body.Bytes(), &dataH)
+
+	resU := s.MakeRequest("POST", "/api/v1/unit-types", map[string]interface{}{
+		"property_id": dataH["property_id"], "name": "Std", "code": "STD",
+		"total_quantity": 2, "base_price": 80.0,
+		"max_occupancy": 2, "max_adults": 2, "max_children": 0,
+	}, token)
+	s.Require().Equal(http.StatusCreated, resU.Code)
+	var dataU map[string]string
+	json.Unmarshal(resU.Body.Bytes(), &dataU)
+
+	return dataU["unit_type_id"]
+}
+
+func (s *WebhookSuite) book(unitTypeID string) {
+	res := s.MakeRequest("POST", "/api/v1/reservations", map[string]interface{}{
+		"unit_type_id":     unitTypeID,
+		"guest_email":      "hookguest@test.com",
+		"guest_first_name": "Hook", "guest_last_name": "Guest",
+		"start":            "2025-03-01", "end": "2025-03-03",
+		"adults":           1, "children": 0,
+	}, "")
+	s.Require().Equal(http.StatusCreated, res.Code)
+}
+
+func (s *WebhookSuite) TestSubscriptionValidation() {
+	token, _ := s.GetAdminTokenAndOrg()
+
+	resBadURL := s.MakeRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+		"url": "not-a-url", "event_types": []string{entity.EventReservationCreated},
+	}, token)
+	s.Equal(http.StatusBadRequest, resBadURL.Code)
+
+	for _, internal := range []string{"http://169.254.169.254/latest/meta-data", "http://10.0.0.5/hook", "http://[::ffff:192.168.1.1]/hook"} {
+		resInternal := s.MakeRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+			"url": internal, "event_types": []string{entity.EventReservationCreated},
+		}, token)
+		s.Equal(http.StatusBadRequest, resInternal.Code, internal)
+	}
+
+	resBadEvent := s.MakeRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+		"url": "http://localhost/hook", "event_types": []string{"guest.deleted"},
+	}, token)
+	s.Equal(http.StatusBadRequest, resBadEvent.Code)
+
+	res := s.MakeRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+		"url": "http://localhost/hook", "event_types": []string{entity.EventReservationCreated},
+	}, token)
+	s.Require().Equal(http.StatusCreated, res.Code)
+	var sub entity.WebhookSubscription
+	json.Unmarshal(res.Body.Bytes(), &sub)
+	s.NotEmpty(sub.Secret)
+
+	resGet := s.MakeRequest("GET", "/api/v1/webhooks/"+sub.ID, nil, token)
+	s.Require().Equal(http.StatusOK, resGet.Code)
+	var fetched entity.WebhookSubscription
+	json.Unmarshal(resGet.Body.Bytes(), &fetched)
+	s.Empty(fetched.Secret)
+
+	resDelete := s.MakeRequest("DELETE", "/api/v1/webhooks/"+sub.ID, nil, token)
+	s.Equal(http.StatusOK, resDelete.Code)
+	resGone := s.MakeRequest("GET", "/api/v1/webhooks/"+sub.ID, nil, token)
+	s.Equal(http.StatusNotFound, resGone.Code)
+}
+
+func (s *WebhookSuite) TestSenderRefusesInternalTargets() {
+	srv, received := s.newReceiver(http.StatusOK)
+	delivery := entity.WebhookDelivery{EventType: entity.EventReservationCreated, Secret: "s", Payload: []byte("{}")}
+	delivery.ID = "d1"
+
+	// Without the loopback flag the resolved 127.0.0.1 address is refused at dial time.
+	delivery.URL = srv.URL
+	_, err := service.NewWebhookSender(false).Send(context.Background(), delivery)
+	s.ErrorIs(err, service.ErrWebhookTargetBlocked)
+	s.Empty(received())
+
+	// Redirects are reported as the endpoint's answer instead of being followed.
+	redirect := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/latest/meta-data", http.StatusFound))
+	s.T().Cleanup(redirect.Close)
+	delivery.URL = redirect.URL
+	status, err := service.NewWebhookSender(true).Send(context.Background(), delivery)
+	s.Error(err)
+	s.Equal(http.StatusFound, status)
+}
+
+func (s *WebhookSuite) TestSignedDelivery() {
+	token, orgID := s.GetAdminTokenAndOrg()
+	srv, received := s.newReceiver(http.StatusOK)
+
+	resSub := s.MakeRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":         srv.URL,
+		"event_types": []string{entity.EventReservationCreated},
+	}, token)
+	s.Require().Equal(http.StatusCreated, resSub.Code)
+	var sub entity.WebhookSubscription
+	json.Unmarshal(resSub.Body.Bytes(), &sub)
+
+	s.book(s.setupInventory(token, orgID))
+	s.Equal(1, s.deliverDue())
+
+	calls := received()
+	s.Require().Len(calls, 1)
+	s.Equal(entity.EventReservationCreated, calls[0].Header.Get(service.WebhookEventHeader))
+
+	timestamp, err := strconv.ParseInt(calls[0].Header.Get(service.WebhookTimestampHeader), 10, 64)
+	s.Require().NoError(err)
+	s.Equal(service.SignWebhook(sub.Secret, timestamp, calls[0].Body), calls[0].Header.Get(service.WebhookSignatureHeader))
+
+	var event entity.WebhookEvent
+	s.Require().NoError(json.Unmarshal(calls[0].Body, &event))
+	s.Equal(entity.EventReservationCreated, event.Type)
+	var reservation entity.Reservation
+	s.Require().NoError(json.Unmarshal(event.Data, &reservation))
+	s.NotEmpty(reservation.ReservationCode)
+	s.Equal("confirmed", reservation.Status)
+
+	resLog := s.MakeRequest("GET", "/api/v1/webhooks/"+sub.ID+"/deliveries", nil, token)
+	s.Require().Equal(http.StatusOK, resLog.Code)
+	var page entity.PaginatedResponse[entity.WebhookDelivery]
+	json.Unmarshal(resLog.Body.Bytes(), &page)
+	s.Require().Len(page.Data, 1)
+	s.Equal(entity.WebhookStatusDelivered, page.Data[0].Status)
+	s.Equal(1, page.Data[0].Attempts)
+}
+
+func (s *WebhookSuite) TestFailedDeliveryIsRetried() {
+	token, orgID := s.GetAdminTokenAndOrg()
+	srv, received := s.newReceiver(http.StatusInternalServerError)
+
+	resSub := s.MakeRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":         srv.URL,
+		"event_types": []string{entity.EventReservationCreated, entity.EventReservationCancelled},
+	}, token)
+	s.Require().Equal(http.StatusCreated, resSub.Code)
+	var sub entity.WebhookSubscription
+	json.Unmarshal(resSub.Body.Bytes(), &sub)
+
+	s.book(s.setupInventory(token, orgID))
+	s.Equal(0, s.deliverDue())
+	s.Len(received(), 1)
+
+	// The retry is scheduled in the future, so an immediate run sends nothing.
+	s.Equal(0, s.deliverDue())
+	s.Len(received(), 1)
+
+	resLog := s.MakeRequest("GET", "/api/v1/webhooks/"+sub.ID+"/deliveries", nil, token)
+	s.Require().Equal(http.StatusOK, resLog.Code)
+	var page entity.PaginatedResponse[entity.WebhookDelivery]
+	json.Unmarshal(resLog.Body.Bytes(), &page)
+	s.Require().Len(page.Data, 1)
+	s.Equal(entity.WebhookStatusPending, page.Data[0].Status)
+	s.Equal(1, page.Data[0].Attempts)
+	s.Require().NotNil(page.Data[0].LastStatusCode)
+	s.Equal(http.StatusInternalServerError, *page.Data[0].LastStatusCode)
+}
+
+func (s *WebhookSuite) TestInactiveSubscriptionsReceiveNothing() {
+	token, orgID := s.GetAdminTokenAndOrg()
+	srv, received := s.newReceiver(http.StatusOK)
+
+	subscribe := func() entity.WebhookSubscription {
+		res := s.MakeRequest("POST", "/api/v1/webhooks", map[string]interface{}{
+			"url": srv.URL, "event_types": []string{entity.EventReservationCreated},
+		}, token)
+		s.Require().Equal(http.StatusCreated, res.Code)
+		var sub entity.WebhookSubscription
+		json.Unmarshal(res.Body.Bytes(), &sub)
+		return sub
+	}
+	paused, deleted := subscribe(), subscribe()
+
+	s.book(s.setupInventory(token, orgID))
+
+	res := s.MakeRequest("PUT", "/api/v1/webhooks/"+paused.ID, map[string]interface{}{"active": false}, token)
+	s.Require().Equal(http.StatusOK, res.Code)
+	res = s.MakeRequest("DELETE", "/api/v1/webhooks/"+deleted.ID, nil, token)
+	s.Require().Equal(http.StatusOK, res.Code)
+
+	s.Equal(0, s.deliverDue())
+	s.Empty(received(), "queued deliveries are not sent once the subscription is off")
+
+	res = s.MakeRequest("GET", "/api/v1/webhooks/"+paused.ID+"/deliveries", nil, token)
+	s.Require().Equal(http.StatusOK, res.Code)
+	var page entity.PaginatedResponse[entity.WebhookDelivery]
+	json.Unmarshal(res.Body.Bytes(), &page)
+	s.Require().Len(page.Data, 1)
+	s.Equal(entity.WebhookStatusFailed, page.Data[0].Status)
+
+	// Reactivating does not resurrect dead letters; new events flow again.
+	res = s.MakeRequest("PUT", "/api/v1/webhooks/"+paused.ID, map[string]interface{}{"active": true}, token)
+	s.Require().Equal(http.StatusOK, res.Code)
+	_, err := s.db.Exec(context.Background(), `UPDATE webhook_deliveries SET next_attempt_at = NOW()`)
+	s.Require().NoError(err)
+	s.Equal(0, s.deliverDue())
+}
+
+func TestWebhookSuite(t *testing.T) {
+	suite.Run(t, new(WebhookSuite))
+}